@@ -73,7 +73,7 @@ func ExtractBlockReferenceFromRequest(ctx context.Context, r *common.NormalizedR
 				// For example eth_getBlockByNumber(latest) will have a "latest" ref, so it'll be cached under "latest" ref,
 				// and we don't want it to be stored as the actual blockHash returned in the response, so that we can have a cache hit.
 				// In case of "*" since it means any block, we can still augment it from response ref, because during cache.Get()
-				// we'll be using reverse index (i.e. ignoring ref), but after reorg invalidation is added a specific block ref is useful.
+				// we'll be using reverse index (i.e. ignoring ref), while a specific block ref helps reorg invalidation find the entry.
 				//
 				// TODO An ideal version stores the data for all eth_getBlockByNumber(latest) and eth_getBlockByNumber(blockNumber),
 				// and eth_getBlockByNumber(blockHash) where blockNumber/blockHash are the actual values returned in the response.
@@ -232,11 +232,9 @@ func extractRefFromJsonRpcRequest(ctx context.Context, req *common.NormalizedReq
 							blockRef = bref
 						} else if blockRef != bref {
 							// This special case is when a method has multiple block parameters (eth_getLogs)
-							// We can't use a specific block reference because later if reorg invalidation is added
-							// it is not easy to check if this cache entry includes that reorged block.
-							// Therefore users must ONLY cache finalized data for these methods (or short TTLs for unfinalized data).
-							// Later we'll either need bespoke caching logic for these methods, or find a nice way
-							// to invalidate these cache entries when ANY of their blocks are reorged.
+							// We can't use a specific block reference as the entry covers several blocks.
+							// Reorg invalidation still works because entries are tracked under the highest block number
+							// of the range, and a reorg invalidates all tracked entries at or above the reorged height.
 							blockRef = "*"
 						}
 					}
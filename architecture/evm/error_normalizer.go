@@ -2,9 +2,13 @@ package evm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	bdscommon "github.com/blockchain-data-standards/manifesto/common"
 	"github.com/erpc/erpc/common"
@@ -17,7 +21,46 @@ func init() {
 	_ = (&bdscommon.ErrorDetails{}).ProtoReflect().Descriptor()
 }
 
+// backoffHintPattern matches hints such as "try again in 5s" or "retry after 2 seconds" that some
+// vendors put in the error message instead of (or in addition to) a Retry-After header.
+var backoffHintPattern = regexp.MustCompile(`(?i)(?:try again|retry)\s+(?:in|after)\s+(\d+(?:\.\d+)?)\s*(ms|milliseconds?|s|secs?|seconds?|m|mins?|minutes?)\b`)
+
 func ExtractJsonRpcError(r *http.Response, nr *common.NormalizedResponse, jr *common.JsonRpcResponse, upstream common.Upstream) error {
+	err := extractJsonRpcError(r, nr, jr, upstream)
+
+	// Keep the backoff window requested by the endpoint so that the upstream can be put in cooldown
+	var ce *common.ErrEndpointCapacityExceeded
+	if err != nil && errors.As(err, &ce) && ce.RetryAfter() == 0 {
+		d := util.ExtractBackoffHint(r)
+		if d == 0 && jr != nil && jr.Error != nil {
+			d = extractBackoffHintFromMessage(jr.Error.Message)
+		}
+		ce.WithRetryAfter(d)
+	}
+
+	return err
+}
+
+func extractBackoffHintFromMessage(msg string) time.Duration {
+	m := backoffHintPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	unit := time.Second
+	switch u := strings.ToLower(m[2]); {
+	case strings.HasPrefix(u, "ms") || strings.HasPrefix(u, "milli"):
+		unit = time.Millisecond
+	case strings.HasPrefix(u, "m"):
+		unit = time.Minute
+	}
+	return time.Duration(v * float64(unit))
+}
+
+func extractJsonRpcError(r *http.Response, nr *common.NormalizedResponse, jr *common.JsonRpcResponse, upstream common.Upstream) error {
 	if (jr != nil && jr.Error != nil) || r.StatusCode > 299 {
 		var details map[string]interface{} = make(map[string]interface{})
 		details["statusCode"] = r.StatusCode
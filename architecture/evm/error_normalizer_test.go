@@ -0,0 +1,56 @@
+package evm
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractJsonRpcError_BackoffHints(t *testing.T) {
+	extract := func(t *testing.T, statusCode int, headers map[string]string, message string) *common.ErrEndpointCapacityExceeded {
+		r := &http.Response{StatusCode: statusCode, Header: http.Header{}}
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		jr, err := common.NewJsonRpcResponse(1, nil, common.NewErrJsonRpcExceptionExternal(-32005, message, ""))
+		require.NoError(t, err)
+
+		err = ExtractJsonRpcError(r, common.NewNormalizedResponse(), jr, nil)
+		var ce *common.ErrEndpointCapacityExceeded
+		require.True(t, errors.As(err, &ce), "expected capacity exceeded error, got: %v", err)
+		return ce
+	}
+
+	t.Run("RetryAfterSeconds", func(t *testing.T) {
+		ce := extract(t, 429, map[string]string{"Retry-After": "3"}, "Too many requests")
+		assert.Equal(t, 3*time.Second, ce.RetryAfter())
+	})
+
+	t.Run("RetryAfterHttpDate", func(t *testing.T) {
+		at := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+		ce := extract(t, 429, map[string]string{"Retry-After": at}, "Too many requests")
+		assert.InDelta(t, 10*time.Second, ce.RetryAfter(), float64(2*time.Second))
+	})
+
+	t.Run("VendorResetHeaderAsTimestamp", func(t *testing.T) {
+		at := time.Now().Add(5 * time.Second).Unix()
+		ce := extract(t, 429, map[string]string{"X-RateLimit-Reset": strconv.FormatInt(at, 10)}, "Too many requests")
+		assert.InDelta(t, 5*time.Second, ce.RetryAfter(), float64(2*time.Second))
+	})
+
+	t.Run("HintInErrorMessage", func(t *testing.T) {
+		ce := extract(t, 200, nil, "rate limit exceeded, please try again in 1500ms")
+		assert.Equal(t, 1500*time.Millisecond, ce.RetryAfter())
+	})
+
+	t.Run("NoHint", func(t *testing.T) {
+		ce := extract(t, 429, nil, "Too many requests")
+		assert.Zero(t, ce.RetryAfter())
+	})
+}
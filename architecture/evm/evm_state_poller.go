@@ -685,6 +685,10 @@ func (e *EvmStatePoller) fetchBlock(ctx context.Context, blockTag string) (int64
 		return 0, err
 	}
 
+	if hash, err := jrr.PeekStringByPath(ctx, "hash"); err == nil && hash != "" && e.tracker != nil {
+		e.tracker.RecordBlockHash(e.upstream, blockNum, string(append([]byte(nil), hash...)))
+	}
+
 	return blockNum, nil
 }
 
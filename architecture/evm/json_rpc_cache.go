@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 )
 
 type EvmJsonRpcCache struct {
+	appCtx    context.Context
 	projectId string
 	policies  []*data.CachePolicy
 	logger    *zerolog.Logger
@@ -30,6 +32,9 @@ type EvmJsonRpcCache struct {
 	compressionLevel     zstd.EncoderLevel
 	encoderPool          *sync.Pool
 	decoderPool          *sync.Pool
+
+	// Shared by all project-scoped copies, as cache keys are not scoped to a project
+	reorgs *evmReorgTracker
 }
 
 const (
@@ -65,10 +70,15 @@ func NewEvmJsonRpcCache(ctx context.Context, logger *zerolog.Logger, cfg *common
 	}
 
 	cache := &EvmJsonRpcCache{
+		appCtx:   ctx,
 		policies: policies,
 		logger:   logger,
 	}
 
+	if cfg.Reorgs != nil && cfg.Reorgs.Enabled != nil && *cfg.Reorgs.Enabled && cfg.Reorgs.TrackedBlocks > 0 {
+		cache.reorgs = newEvmReorgTracker(cfg.Reorgs.TrackedBlocks)
+	}
+
 	// Initialize compression if configured
 	if cfg.Compression != nil && cfg.Compression.Enabled != nil && *cfg.Compression.Enabled {
 		cache.compressionEnabled = true
@@ -134,6 +144,7 @@ func (c *EvmJsonRpcCache) WithProjectId(projectId string) *EvmJsonRpcCache {
 	lg := c.logger.With().Str("projectId", projectId).Logger()
 	lg.Debug().Msgf("cloning EvmJsonRpcCache for project")
 	return &EvmJsonRpcCache{
+		appCtx:               c.appCtx,
 		logger:               &lg,
 		policies:             c.policies,
 		projectId:            projectId,
@@ -142,6 +153,7 @@ func (c *EvmJsonRpcCache) WithProjectId(projectId string) *EvmJsonRpcCache {
 		compressionLevel:     c.compressionLevel,
 		encoderPool:          c.encoderPool,
 		decoderPool:          c.decoderPool,
+		reorgs:               c.reorgs,
 	}
 }
 
@@ -370,6 +382,12 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 		return err
 	}
 
+	// Entries of the new fork written to the same partitions before the invalidation runs might be removed too,
+	// which only costs a cache miss.
+	if reorg := c.observeBlockHash(ctx, ntwId, rpcReq.Method, rpcResp); reorg != nil {
+		c.enqueueInvalidation(ntwId, reorg)
+	}
+
 	// Use response finality if available, otherwise fall back to request finality
 	var finState common.DataFinalityState
 	if resp != nil {
@@ -528,7 +546,12 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 
 			ctx, cancel := context.WithTimeoutCause(ctx, 5*time.Second, errors.New("evm json-rpc cache driver timeout during set"))
 			defer cancel()
-			err = connector.Set(ctx, pk, rk, valueToStore, ttl)
+			if indexer, ok := connector.(data.PartitionIndexer); ok && c.reorgs != nil && finState != common.DataFinalityStateFinalized {
+				// Indexed so that invalidation of reorged blocks finds the entry by its partition
+				err = indexer.SetIndexed(ctx, pk, rk, valueToStore, ttl)
+			} else {
+				err = connector.Set(ctx, pk, rk, valueToStore, ttl)
+			}
 			if err != nil {
 				errsMu.Lock()
 				errs = append(errs, err)
@@ -552,6 +575,13 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 					common.ErrorSummary(err),
 				).Observe(time.Since(start).Seconds())
 			} else {
+				if c.reorgs != nil && finState != common.DataFinalityStateFinalized {
+					c.reorgs.trackEntry(ntwId, blockNumber, blockRef, evmCacheEntryRef{
+						connector:    connector,
+						partitionKey: pk,
+						rangeKey:     rk,
+					})
+				}
 				telemetry.MetricCacheSetSuccessTotal.WithLabelValues(
 					c.projectId,
 					req.NetworkLabel(),
@@ -588,6 +618,147 @@ func (c *EvmJsonRpcCache) Set(ctx context.Context, req *common.NormalizedRequest
 	return nil
 }
 
+// RecordBlockHash must be called whenever the hash of a block is observed (e.g. by state pollers).
+// When it differs from the hash previously seen for the same height, cached entries of the reorged blocks are invalidated.
+func (c *EvmJsonRpcCache) RecordBlockHash(networkId string, blockNumber int64, blockHash string) {
+	if reorg := c.detectReorg(networkId, blockNumber, blockHash); reorg != nil {
+		c.enqueueInvalidation(networkId, reorg)
+	}
+}
+
+// enqueueInvalidation hands the reorged blocks to the invalidation worker of the network, which is started on first use.
+func (c *EvmJsonRpcCache) enqueueInvalidation(networkId string, reorg *evmReorg) {
+	q, created := c.reorgs.invalidationQueue(networkId)
+	if created {
+		go c.runInvalidations(networkId, q)
+	}
+	if dropped := q.push(reorg); dropped > 0 {
+		c.logger.Warn().
+			Str("networkId", networkId).
+			Int("dropped", dropped).
+			Msg("reorg invalidation queue is full, some cached entries of reorged blocks will not be invalidated")
+	}
+}
+
+func (c *EvmJsonRpcCache) runInvalidations(networkId string, q *evmReorgInvalidationQueue) {
+	for {
+		select {
+		case <-c.appCtx.Done():
+			return
+		case <-q.signal:
+			blockRefs, entries := q.pop()
+			c.invalidateReorgedEntries(networkId, blockRefs, entries)
+		}
+	}
+}
+
+func (c *EvmJsonRpcCache) observeBlockHash(ctx context.Context, networkId, method string, rpcResp *common.JsonRpcResponse) *evmReorg {
+	if c.reorgs == nil || rpcResp == nil || rpcResp.Error != nil {
+		return nil
+	}
+	if method != "eth_getBlockByNumber" && method != "eth_getBlockByHash" {
+		return nil
+	}
+	numberStr, err := rpcResp.PeekStringByPath(ctx, "number")
+	if err != nil || numberStr == "" {
+		return nil
+	}
+	blockNumber, err := common.HexToInt64(numberStr)
+	if err != nil {
+		return nil
+	}
+	blockHash, err := rpcResp.PeekStringByPath(ctx, "hash")
+	if err != nil || blockHash == "" {
+		return nil
+	}
+	return c.detectReorg(networkId, blockNumber, string(append([]byte(nil), blockHash...)))
+}
+
+func (c *EvmJsonRpcCache) detectReorg(networkId string, blockNumber int64, blockHash string) *evmReorg {
+	if c.reorgs == nil || blockNumber <= 0 || blockHash == "" {
+		return nil
+	}
+	reorg := c.reorgs.recordBlockHash(networkId, blockNumber, blockHash)
+	if reorg == nil {
+		return nil
+	}
+
+	telemetry.MetricCacheReorgDetectedTotal.WithLabelValues(networkId, strconv.FormatInt(reorg.depth, 10)).Inc()
+	c.logger.Warn().
+		Str("networkId", networkId).
+		Int64("blockNumber", blockNumber).
+		Str("previousHash", reorg.prevHash).
+		Str("newHash", blockHash).
+		Int64("depth", reorg.depth).
+		Int("invalidatedEntries", len(reorg.entries)).
+		Msg("detected block reorg, invalidating cached entries of reorged blocks")
+
+	return reorg
+}
+
+// invalidateReorgedEntries deletes the partitions of reorged blocks from every connector that supports it, so that
+// entries written by other eRPC instances sharing the connector are invalidated too. Entries this instance has written
+// are deleted one by one on the remaining connectors (e.g. memory), or when they are keyed outside those partitions.
+func (c *EvmJsonRpcCache) invalidateReorgedEntries(networkId string, blockRefs []string, entries []evmCacheEntryRef) {
+	ctx, cancel := context.WithTimeoutCause(c.appCtx, 30*time.Second, errors.New("evm json-rpc cache driver timeout during reorg invalidation"))
+	defer cancel()
+
+	deleted := make(map[string]map[string]bool)
+	for _, policy := range c.policies {
+		connector, ok := policy.GetConnector().(data.PartitionDeleter)
+		if !ok || deleted[connector.Id()] != nil {
+			continue
+		}
+		partitions := make(map[string]bool, len(blockRefs))
+		deleted[connector.Id()] = partitions
+		pks := make([]string, 0, len(blockRefs))
+		for _, blockRef := range blockRefs {
+			pks = append(pks, fmt.Sprintf("%s:%s", networkId, blockRef))
+		}
+		if batch, ok := connector.(data.PartitionBatchDeleter); ok {
+			if err := batch.DeletePartitions(ctx, pks); err != nil {
+				// Remembered entries are still deleted one by one below
+				c.logger.Warn().Err(err).
+					Str("connector", connector.Id()).
+					Strs("partitionKeys", pks).
+					Msg("failed to invalidate cache partitions of reorged blocks")
+				continue
+			}
+			for _, pk := range pks {
+				partitions[pk] = true
+			}
+			telemetry.MetricCacheReorgInvalidatedTotal.WithLabelValues(networkId, connector.Id()).Add(float64(len(pks)))
+			continue
+		}
+		for _, pk := range pks {
+			if err := connector.DeletePartition(ctx, pk); err != nil {
+				c.logger.Warn().Err(err).
+					Str("connector", connector.Id()).
+					Str("partitionKey", pk).
+					Msg("failed to invalidate cache partition of reorged block")
+				continue
+			}
+			partitions[pk] = true
+			telemetry.MetricCacheReorgInvalidatedTotal.WithLabelValues(networkId, connector.Id()).Inc()
+		}
+	}
+
+	for _, ref := range entries {
+		if deleted[ref.connector.Id()][ref.partitionKey] {
+			continue
+		}
+		if err := ref.connector.Delete(ctx, ref.partitionKey, ref.rangeKey); err != nil {
+			c.logger.Warn().Err(err).
+				Str("connector", ref.connector.Id()).
+				Str("partitionKey", ref.partitionKey).
+				Str("rangeKey", ref.rangeKey).
+				Msg("failed to invalidate cache entry of reorged block")
+			continue
+		}
+		telemetry.MetricCacheReorgInvalidatedTotal.WithLabelValues(networkId, ref.connector.Id()).Inc()
+	}
+}
+
 func (c *EvmJsonRpcCache) IsObjectNull() bool {
 	return c == nil || c.logger == nil
 }
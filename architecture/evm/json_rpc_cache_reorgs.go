@@ -0,0 +1,229 @@
+package evm
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/erpc/erpc/data"
+)
+
+// Maximum block refs (and separately entries) waiting to be invalidated per network, reorgs detected while
+// the queue is full are not invalidated.
+const evmReorgMaxPendingInvalidations = 10_000
+
+// evmCacheEntryRef identifies a cache entry written on a specific connector.
+type evmCacheEntryRef struct {
+	connector    data.Connector
+	partitionKey string
+	rangeKey     string
+}
+
+type evmReorgNetworkState struct {
+	highest int64
+	hashes  map[int64]string
+	entries map[int64]map[evmCacheEntryRef]struct{}
+}
+
+// evmReorgTracker remembers the block hash of recent heights and the unfinalized cache entries written for them,
+// so that when a different hash is observed for a height, entries of that height and above can be invalidated.
+type evmReorgTracker struct {
+	trackedBlocks int64
+
+	mu       sync.Mutex
+	networks map[string]*evmReorgNetworkState
+
+	queuesMu sync.Mutex
+	queues   map[string]*evmReorgInvalidationQueue
+}
+
+func newEvmReorgTracker(trackedBlocks int64) *evmReorgTracker {
+	return &evmReorgTracker{
+		trackedBlocks: trackedBlocks,
+		networks:      make(map[string]*evmReorgNetworkState),
+		queues:        make(map[string]*evmReorgInvalidationQueue),
+	}
+}
+
+// invalidationQueue returns the queue of a network, created reports whether its worker must be started.
+func (t *evmReorgTracker) invalidationQueue(networkId string) (q *evmReorgInvalidationQueue, created bool) {
+	t.queuesMu.Lock()
+	defer t.queuesMu.Unlock()
+
+	q, ok := t.queues[networkId]
+	if !ok {
+		q = &evmReorgInvalidationQueue{
+			blockRefs: make(map[string]struct{}),
+			entries:   make(map[evmCacheEntryRef]struct{}),
+			signal:    make(chan struct{}, 1),
+		}
+		t.queues[networkId] = q
+	}
+	return q, !ok
+}
+
+func (t *evmReorgTracker) network(networkId string) *evmReorgNetworkState {
+	st, ok := t.networks[networkId]
+	if !ok {
+		st = &evmReorgNetworkState{
+			hashes:  make(map[int64]string),
+			entries: make(map[int64]map[evmCacheEntryRef]struct{}),
+		}
+		t.networks[networkId] = st
+	}
+	return st
+}
+
+// trackEntry remembers a cache entry written for an unfinalized block. When block number is not known
+// (e.g. entries keyed by block hash) the height is resolved from known hashes, or the highest known block.
+func (t *evmReorgTracker) trackEntry(networkId string, blockNumber int64, blockRef string, ref evmCacheEntryRef) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.network(networkId)
+	height := blockNumber
+	if height <= 0 && strings.HasPrefix(blockRef, "0x") {
+		for h, hash := range st.hashes {
+			if strings.EqualFold(hash, blockRef) {
+				height = h
+				break
+			}
+		}
+		if height <= 0 {
+			height = st.highest
+		}
+	}
+	if height <= 0 || height <= st.highest-t.trackedBlocks {
+		return
+	}
+
+	bucket, ok := st.entries[height]
+	if !ok {
+		bucket = make(map[evmCacheEntryRef]struct{})
+		st.entries[height] = bucket
+	}
+	bucket[ref] = struct{}{}
+}
+
+// evmReorg describes the blocks replaced by a reorg.
+type evmReorg struct {
+	// depth is the number of replaced blocks up to the highest known block
+	depth    int64
+	prevHash string
+	// blockRefs are the heights and known hashes of replaced blocks, as used in partition keys of cache entries
+	blockRefs []string
+	// entries are the cache entries this instance has written for replaced blocks
+	entries []evmCacheEntryRef
+}
+
+// recordBlockHash stores the hash of a block. If a different hash was known for the same height it returns the
+// replaced blocks (that height and above up to the highest known block) and the entries to invalidate.
+func (t *evmReorgTracker) recordBlockHash(networkId string, blockNumber int64, blockHash string) *evmReorg {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.network(networkId)
+	if blockNumber <= st.highest-t.trackedBlocks {
+		return nil
+	}
+
+	var reorg *evmReorg
+	prevHash, known := st.hashes[blockNumber]
+	if known && !strings.EqualFold(prevHash, blockHash) {
+		reorg = &evmReorg{
+			depth:    max(st.highest, blockNumber) - blockNumber + 1,
+			prevHash: prevHash,
+		}
+		for h := blockNumber; h <= max(st.highest, blockNumber); h++ {
+			reorg.blockRefs = append(reorg.blockRefs, strconv.FormatInt(h, 10))
+		}
+		for h, bucket := range st.entries {
+			if h < blockNumber {
+				continue
+			}
+			for ref := range bucket {
+				reorg.entries = append(reorg.entries, ref)
+			}
+			delete(st.entries, h)
+		}
+		// Hashes of descendants belong to the old fork, they'll be learned again
+		reorg.blockRefs = append(reorg.blockRefs, prevHash)
+		for h, hash := range st.hashes {
+			if h > blockNumber {
+				reorg.blockRefs = append(reorg.blockRefs, hash)
+				delete(st.hashes, h)
+			}
+		}
+	}
+	st.hashes[blockNumber] = blockHash
+
+	if blockNumber > st.highest {
+		st.highest = blockNumber
+		oldest := st.highest - t.trackedBlocks
+		for h := range st.hashes {
+			if h <= oldest {
+				delete(st.hashes, h)
+			}
+		}
+		for h := range st.entries {
+			if h <= oldest {
+				delete(st.entries, h)
+			}
+		}
+	}
+
+	return reorg
+}
+
+// evmReorgInvalidationQueue coalesces the block refs and entries of reorgs detected for a network, so that a
+// single background worker invalidates them no matter how many reorgs are detected while it is busy.
+type evmReorgInvalidationQueue struct {
+	mu        sync.Mutex
+	blockRefs map[string]struct{}
+	entries   map[evmCacheEntryRef]struct{}
+	signal    chan struct{}
+}
+
+// push adds the reorged blocks and entries to the queue and returns how many did not fit.
+func (q *evmReorgInvalidationQueue) push(reorg *evmReorg) (dropped int) {
+	q.mu.Lock()
+	for _, ref := range reorg.blockRefs {
+		if _, ok := q.blockRefs[ref]; !ok && len(q.blockRefs) >= evmReorgMaxPendingInvalidations {
+			dropped++
+			continue
+		}
+		q.blockRefs[ref] = struct{}{}
+	}
+	for _, ref := range reorg.entries {
+		if _, ok := q.entries[ref]; !ok && len(q.entries) >= evmReorgMaxPendingInvalidations {
+			dropped++
+			continue
+		}
+		q.entries[ref] = struct{}{}
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// pop takes everything queued so far.
+func (q *evmReorgInvalidationQueue) pop() (blockRefs []string, entries []evmCacheEntryRef) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	blockRefs = make([]string, 0, len(q.blockRefs))
+	for ref := range q.blockRefs {
+		blockRefs = append(blockRefs, ref)
+	}
+	entries = make([]evmCacheEntryRef, 0, len(q.entries))
+	for ref := range q.entries {
+		entries = append(entries, ref)
+	}
+	clear(q.blockRefs)
+	clear(q.entries)
+	return blockRefs, entries
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+)
+
+const (
+	// ApiKeyHashPrefix marks ids of API keys that are stored as a keyed hash (HMAC-SHA256) rather than their
+	// raw value, it must not contain ":" which some connectors use to separate partition and range keys.
+	ApiKeyHashPrefix = "hmac_"
+
+	apiKeyDisplayPrefixLength = 8
+	apiKeyMigrationPageSize   = 100
+)
+
+// KeyId returns the id under which an API key is stored, i.e. its keyed hash when hashing is enabled or
+// the key itself otherwise. Ids of hashed keys (e.g. as returned by erpc_listApiKeys) are returned as-is
+// so that admins can still manage keys whose raw value is not known anymore.
+func (s *DatabaseStrategy) KeyId(apiKeyOrId string) string {
+	if strings.HasPrefix(apiKeyOrId, ApiKeyHashPrefix) {
+		return apiKeyOrId
+	}
+	return s.storageKey(apiKeyOrId)
+}
+
+// storageKey must be used for keys presented by clients, as a hash must never be accepted as a credential.
+func (s *DatabaseStrategy) storageKey(apiKey string) string {
+	if s.pepper == nil {
+		return apiKey
+	}
+	mac := hmac.New(sha256.New, s.pepper)
+	mac.Write([]byte(apiKey))
+	return ApiKeyHashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashesKeys tells whether API keys are stored as keyed hashes.
+func (s *DatabaseStrategy) HashesKeys() bool {
+	return s.pepper != nil
+}
+
+// ApiKeyDisplayPrefix returns the beginning of an API key which is stored along with its hash so that
+// users can still recognize their keys.
+func ApiKeyDisplayPrefix(apiKey string) string {
+	if len(apiKey) <= apiKeyDisplayPrefixLength {
+		return ""
+	}
+	return apiKey[:apiKeyDisplayPrefixLength]
+}
+
+// GenerateApiKey returns a new random API key.
+func GenerateApiKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return "erpc_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+type ApiKeyMigrationResult struct {
+	Migrated int `json:"migrated"`
+	Skipped  int `json:"skipped"`
+}
+
+// MigratePlaintextKeys re-stores all API keys that are still stored by their raw value under their keyed
+// hash (along with their display prefix and current usage), and removes the plaintext entries.
+func (s *DatabaseStrategy) MigratePlaintextKeys(ctx context.Context) (*ApiKeyMigrationResult, error) {
+	if !s.HashesKeys() {
+		return nil, fmt.Errorf("keyHashing is not enabled for this database strategy")
+	}
+
+	// Entries are collected first, as modifying them while paginating might skip or repeat some of them
+	var items []data.KeyValuePair
+	token := ""
+	for {
+		page, nextToken, err := s.connector.List(ctx, data.ConnectorMainIndex, apiKeyMigrationPageSize, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list API keys: %w", err)
+		}
+		items = append(items, page...)
+		if nextToken == "" {
+			break
+		}
+		token = nextToken
+	}
+
+	result := &ApiKeyMigrationResult{}
+	for _, item := range items {
+		if strings.HasPrefix(item.PartitionKey, ApiKeyHashPrefix) || strings.HasPrefix(item.PartitionKey, quotaKeyPrefix) {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(item.Value, &record); err != nil {
+			result.Skipped++
+			continue
+		}
+		if userId, _ := record["userId"].(string); userId == "" {
+			result.Skipped++
+			continue
+		}
+		// HMAC keys might be stored in the same table, and must keep being found by their key id
+		if _, ok := record["secret"]; ok {
+			result.Skipped++
+			continue
+		}
+		if err := s.migratePlaintextKey(ctx, item.PartitionKey, item.RangeKey, record); err != nil {
+			return result, fmt.Errorf("failed to migrate API key %s: %w", ApiKeyDisplayPrefix(item.PartitionKey), err)
+		}
+		result.Migrated++
+	}
+
+	s.logger.Info().Int("migrated", result.Migrated).Int("skipped", result.Skipped).Msg("migrated plaintext API keys to hashed keys")
+	return result, nil
+}
+
+func (s *DatabaseStrategy) migratePlaintextKey(ctx context.Context, apiKey, rangeKey string, record map[string]interface{}) error {
+	keyId := s.storageKey(apiKey)
+	record["keyPrefix"] = ApiKeyDisplayPrefix(apiKey)
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := s.connector.Set(ctx, keyId, rangeKey, value, nil); err != nil {
+		return err
+	}
+
+	// Usage is only accounted for keys with a quota
+	if _, hasQuota := record["quota"]; hasQuota {
+		if err := s.carryOverUsage(ctx, apiKey, keyId); err != nil {
+			return err
+		}
+	}
+
+	if err := s.connector.Delete(ctx, apiKey, rangeKey); err != nil && !common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+		return err
+	}
+	s.InvalidateCache(keyId)
+	return nil
+}
+
+// carryOverUsage moves usage of the current windows to the new id of a key so that quotas keep being enforced,
+// the previous counters are zeroed and expire on their own.
+func (s *DatabaseStrategy) carryOverUsage(ctx context.Context, fromKey, toKey string) error {
+	now := time.Now()
+	for _, w := range quotaWindows(now) {
+		for _, unit := range []string{QuotaUnitRequests, QuotaUnitComputeUnits} {
+			used, err := s.usage.get(ctx, quotaCounterKey(fromKey, w, unit), w.ttl(now))
+			if err != nil {
+				return err
+			}
+			if used == 0 {
+				continue
+			}
+			if _, err := s.usage.increment(ctx, quotaCounterKey(toKey, w, unit), used, w.ttl(now)); err != nil {
+				return err
+			}
+			if err := s.usage.reset(ctx, quotaCounterKey(fromKey, w, unit), w.ttl(now)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erpc/erpc/common"
+)
+
+// ApiKeyScope restricts where and until when an API key can be used, so that keys can be safely shipped to
+// browsers (e.g. only from a specific origin, for a few methods on a single chain). It is stored along with
+// the key in the database, empty lists mean no restriction.
+type ApiKeyScope struct {
+	AllowedNetworks []string   `json:"allowedNetworks,omitempty"`
+	AllowedMethods  []string   `json:"allowedMethods,omitempty"`
+	AllowedOrigins  []string   `json:"allowedOrigins,omitempty"`
+	AllowedCIDRs    []string   `json:"allowedCIDRs,omitempty"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Normalize validates the scope and expands bare chain ids to EVM network ids (e.g. "1" to "evm:1").
+func (s *ApiKeyScope) Normalize() error {
+	s.AllowedNetworks = normalizeNetworkIds(s.AllowedNetworks)
+	for _, cidr := range s.AllowedCIDRs {
+		if _, err := parseCIDROrIP(cidr); err != nil {
+			return fmt.Errorf("allowedCIDRs must only contain IPs or CIDRs, invalid value: %s", cidr)
+		}
+	}
+	for _, pattern := range append(append([]string{}, s.AllowedMethods...), s.AllowedOrigins...) {
+		if _, err := common.WildcardMatch(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// compiledApiKeyScope is what is kept (and cached) for an API key to check each request against.
+type compiledApiKeyScope struct {
+	allowedOrigins []string
+	allowedCIDRs   []*net.IPNet
+	expiresAt      *time.Time
+}
+
+func compileApiKeyScope(s *ApiKeyScope) (*compiledApiKeyScope, error) {
+	cs := &compiledApiKeyScope{
+		allowedOrigins: s.AllowedOrigins,
+		expiresAt:      s.ExpiresAt,
+	}
+	for _, cidr := range s.AllowedCIDRs {
+		ipNet, err := parseCIDROrIP(cidr)
+		if err != nil {
+			return nil, err
+		}
+		cs.allowedCIDRs = append(cs.allowedCIDRs, ipNet)
+	}
+	return cs, nil
+}
+
+func (cs *compiledApiKeyScope) isExpired(now time.Time) bool {
+	return cs.expiresAt != nil && !now.Before(*cs.expiresAt)
+}
+
+// check verifies the origin and client IP of the request, the allowed networks and methods are enforced
+// by the authorizer through the user.
+func (cs *compiledApiKeyScope) check(ap *AuthPayload, trustedProxies []*net.IPNet) error {
+	if len(cs.allowedOrigins) > 0 {
+		if ap.Origin == "" {
+			return fmt.Errorf("API key can only be used from allowed origins but request has no Origin header")
+		}
+		allowed := false
+		for _, pattern := range cs.allowedOrigins {
+			if match, err := common.WildcardMatch(pattern, ap.Origin); err == nil && match {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("origin %s is not allowed for this API key", ap.Origin)
+		}
+	}
+
+	if len(cs.allowedCIDRs) > 0 {
+		var clientIP net.IP
+		if ap.Network != nil {
+			clientIP = resolvePeerClientIP(ap.Network, trustedProxies)
+		}
+		if clientIP == nil {
+			return fmt.Errorf("unable to determine client IP for API key restricted to allowed CIDRs")
+		}
+		allowed := false
+		for _, cidr := range cs.allowedCIDRs {
+			if cidr.Contains(clientIP) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("IP %s is not allowed for this API key", clientIP.String())
+		}
+	}
+
+	return nil
+}
+
+// resolvePeerClientIP returns the IP of the direct peer, unless that peer is a trusted proxy in which case
+// X-Forwarded-For is walked from the right to the first IP that is not a trusted proxy. Unlike resolveClientIP
+// the header is never honoured when sent by an untrusted peer, so it cannot be spoofed to bypass allowedCIDRs.
+func resolvePeerClientIP(np *NetworkPayload, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(np.Address)
+	if err != nil {
+		host = np.Address
+	}
+	peerIP := net.ParseIP(host)
+	if peerIP == nil || !isTrustedProxy(trustedProxies, peerIP) {
+		return peerIP
+	}
+	for i := len(np.ForwardProxies) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(np.ForwardProxies[i]))
+		if ip == nil {
+			continue
+		}
+		if !isTrustedProxy(trustedProxies, ip) {
+			return ip
+		}
+	}
+	return peerIP
+}
+
+func parseCIDROrIP(value string) (*net.IPNet, error) {
+	if _, ipNet, err := net.ParseCIDR(value); err == nil {
+		return ipNet, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP or CIDR: %s", value)
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// normalizeNetworkIds treats bare chain ids as EVM networks, as that is how most users would express them.
+func normalizeNetworkIds(networks []string) []string {
+	for i, network := range networks {
+		if _, err := strconv.ParseInt(network, 10, 64); err == nil {
+			networks[i] = "evm:" + network
+		}
+	}
+	return networks
+}
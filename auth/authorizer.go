@@ -2,7 +2,9 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
@@ -38,7 +40,7 @@ func NewAuthorizer(appCtx context.Context, logger *zerolog.Logger, projectId str
 		if cfg.Jwt == nil {
 			return nil, common.NewErrInvalidConfig("JWT strategy config is nil")
 		}
-		strategy, err = NewJwtStrategy(cfg.Jwt)
+		strategy, err = NewJwtStrategy(appCtx, logger, cfg.Jwt)
 		if err != nil {
 			return nil, err
 		}
@@ -46,7 +48,10 @@ func NewAuthorizer(appCtx context.Context, logger *zerolog.Logger, projectId str
 		if cfg.Siwe == nil {
 			return nil, common.NewErrInvalidConfig("SIWE strategy config is nil")
 		}
-		strategy = NewSiweStrategy(cfg.Siwe)
+		strategy, err = NewSiweStrategy(appCtx, logger, cfg.Siwe)
+		if err != nil {
+			return nil, err
+		}
 	case common.AuthTypeNetwork:
 		if cfg.Network == nil {
 			return nil, common.NewErrInvalidConfig("network strategy config is nil")
@@ -63,11 +68,28 @@ func NewAuthorizer(appCtx context.Context, logger *zerolog.Logger, projectId str
 		if err != nil {
 			return nil, err
 		}
+	case common.AuthTypeHmac:
+		if cfg.Hmac == nil {
+			return nil, common.NewErrInvalidConfig("HMAC strategy config is nil")
+		}
+		strategy, err = NewHmacStrategy(appCtx, logger, cfg.Hmac)
+		if err != nil {
+			return nil, err
+		}
+	case common.AuthTypeMtls:
+		if cfg.Mtls == nil {
+			return nil, common.NewErrInvalidConfig("mTLS strategy config is nil")
+		}
+		strategy, err = NewMtlsStrategy(cfg.Mtls)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, common.NewErrInvalidConfig(fmt.Sprintf("unknown auth strategy type: %s", cfg.Type))
 	}
 
 	return &Authorizer{
+		projectId:            projectId,
 		logger:               logger,
 		cfg:                  cfg,
 		strategy:             strategy,
@@ -112,13 +134,67 @@ func (a *Authorizer) shouldApplyToMethod(method string) bool {
 	return shouldApply
 }
 
-func (a *Authorizer) acquireRateLimitPermit(user *common.User, method string) error {
-	if a.cfg.RateLimitBudget == "" {
+// authorizeMethod rejects methods that are not allowed for the authenticated user.
+func (a *Authorizer) authorizeMethod(user *common.User, method string) error {
+	if user.IsMethodAllowed(method) {
 		return nil
 	}
+	return common.NewErrAuthForbidden(
+		string(a.cfg.Type),
+		user.Id,
+		fmt.Sprintf("method %s is not allowed for this user", method),
+	)
+}
 
-	rlb, errNetLimit := a.rateLimitersRegistry.GetBudget(a.cfg.RateLimitBudget)
+// acquireUserRateLimitPermit enforces the per-second rate limit of the user (e.g. mapped from JWT claims), using the
+// limiters kept by the rate limiters registry so that they are bounded and shared across instances with a store.
+func (a *Authorizer) acquireUserRateLimitPermit(ctx context.Context, user *common.User, method string) error {
+	if user == nil || user.PerSecondRateLimit <= 0 || a.rateLimitersRegistry == nil {
+		return nil
+	}
+
+	limiter := a.rateLimitersRegistry.UserRateLimiter(user.Id, uint(user.PerSecondRateLimit))
+	if !limiter.TryAcquirePermitsContext(ctx, 1) {
+		telemetry.MetricAuthRequestSelfRateLimited.WithLabelValues(
+			a.projectId,
+			string(a.cfg.Type),
+			method,
+		).Inc()
+		return common.NewErrAuthRateLimitRuleExceeded(
+			a.projectId,
+			string(a.cfg.Type),
+			&common.RateLimitRuleInfo{
+				Budget:   "user:" + user.Id,
+				Method:   "*",
+				MaxCount: uint(user.PerSecondRateLimit),
+				Period:   common.Duration(time.Second),
+				ResetIn:  common.Duration(limiter.ResetIn().Round(time.Millisecond)),
+			},
+		)
+	}
+
+	return nil
+}
+
+func (a *Authorizer) acquireRateLimitPermit(ctx context.Context, user *common.User, method string) error {
+	budgetId := a.cfg.RateLimitBudget
+	if user != nil && user.RateLimitBudget != "" {
+		budgetId = user.RateLimitBudget
+	}
+	if budgetId == "" {
+		return nil
+	}
+
+	rlb, errNetLimit := a.rateLimitersRegistry.GetBudget(budgetId)
 	if errNetLimit != nil {
+		var notFound *common.ErrRateLimitBudgetNotFound
+		if user != nil && budgetId == user.RateLimitBudget && errors.As(errNetLimit, &notFound) {
+			return common.NewErrAuthForbidden(
+				string(a.cfg.Type),
+				user.Id,
+				fmt.Sprintf("rate limit budget %s assigned to this user does not exist", budgetId),
+			)
+		}
 		return errNetLimit
 	}
 	if rlb == nil {
@@ -135,7 +211,7 @@ func (a *Authorizer) acquireRateLimitPermit(user *common.User, method string) er
 
 	if len(rules) > 0 {
 		for _, rule := range rules {
-			permit := rule.Limiter.TryAcquirePermit()
+			permit := rlb.TryAcquirePermit(ctx, rule, method)
 			if !permit {
 				telemetry.MetricAuthRequestSelfRateLimited.WithLabelValues(
 					a.projectId,
@@ -145,8 +221,7 @@ func (a *Authorizer) acquireRateLimitPermit(user *common.User, method string) er
 				return common.NewErrAuthRateLimitRuleExceeded(
 					a.projectId,
 					string(a.cfg.Type),
-					a.cfg.RateLimitBudget,
-					fmt.Sprintf("%+v", rule.Config),
+					rlb.RuleInfo(rule),
 				)
 			} else {
 				lg.Debug().Object("rateLimitRule", rule.Config).Msgf("auth-level rate limit passed")
@@ -156,3 +231,27 @@ func (a *Authorizer) acquireRateLimitPermit(user *common.User, method string) er
 
 	return nil
 }
+
+func (a *Authorizer) consumeQuota(ctx context.Context, ap *AuthPayload, user *common.User, method string) error {
+	qs, ok := a.strategy.(QuotaStrategy)
+	if !ok || user == nil || user.Quota.IsEmpty() {
+		return nil
+	}
+
+	var computeUnits uint = 1
+	if a.rateLimitersRegistry != nil {
+		computeUnits = a.rateLimitersRegistry.ComputeUnits(method)
+	}
+	if err := qs.ConsumeQuota(ctx, ap, user, computeUnits); err != nil {
+		if common.HasErrorCode(err, common.ErrCodeAuthQuotaExceeded) {
+			telemetry.MetricAuthRequestQuotaExceeded.WithLabelValues(
+				a.projectId,
+				string(a.cfg.Type),
+				user.Id,
+			).Inc()
+		}
+		return err
+	}
+
+	return nil
+}
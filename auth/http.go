@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"crypto/tls"
 	"encoding/base64"
 	"errors"
 	"net/http"
@@ -10,12 +11,29 @@ import (
 	"github.com/erpc/erpc/common"
 )
 
-func NewPayloadFromHttp(method string, remoteAddr string, headers http.Header, args url.Values) (*AuthPayload, error) {
+// NewPayloadFromHttp extracts credentials of a request, digest must be provided for signed requests
+// to be verifiable (it is nil when the signed body is not available, e.g. for websocket messages).
+func NewPayloadFromHttp(method string, remoteAddr string, headers http.Header, args url.Values, tlsState *tls.ConnectionState, digest *RequestDigest) (*AuthPayload, error) {
 	ap := &AuthPayload{
 		Method: method,
+		Origin: headers.Get("Origin"),
+		Digest: digest,
+		// Client address is always provided so that other strategies can restrict credentials by IP as well
+		Network: &NetworkPayload{
+			Address:        remoteAddr,
+			ForwardProxies: strings.Split(headers.Get("X-Forwarded-For"), ","),
+		},
 	}
 
-	if token := args.Get("token"); token != "" { // deprecated
+	if sig := headers.Get("X-ERPC-Signature"); sig != "" && digest != nil {
+		ap.Type = common.AuthTypeHmac
+		ap.Hmac = &HmacPayload{
+			KeyId:     headers.Get("X-ERPC-Key-Id"),
+			Timestamp: headers.Get("X-ERPC-Timestamp"),
+			Nonce:     headers.Get("X-ERPC-Nonce"),
+			Signature: sig,
+		}
+	} else if token := args.Get("token"); token != "" { // deprecated
 		ap.Type = common.AuthTypeSecret
 		ap.Secret = &SecretPayload{
 			Value: token,
@@ -69,6 +87,16 @@ func NewPayloadFromHttp(method string, remoteAddr string, headers http.Header, a
 			Signature: signature,
 			Message:   normalizeSiweMessage(args.Get("message")),
 		}
+	} else if session := headers.Get("X-Siwe-Session"); session != "" {
+		ap.Type = common.AuthTypeSiwe
+		ap.Siwe = &SiwePayload{
+			SessionToken: session,
+		}
+	} else if session := args.Get("siweSession"); session != "" {
+		ap.Type = common.AuthTypeSiwe
+		ap.Siwe = &SiwePayload{
+			SessionToken: session,
+		}
 	} else if msg := headers.Get("X-Siwe-Message"); msg != "" {
 		if sig := headers.Get("X-Siwe-Signature"); sig != "" {
 			ap.Type = common.AuthTypeSiwe
@@ -79,14 +107,18 @@ func NewPayloadFromHttp(method string, remoteAddr string, headers http.Header, a
 		}
 	}
 
+	if tlsState != nil && len(tlsState.PeerCertificates) > 0 {
+		ap.Mtls = &MtlsPayload{
+			PeerCertificates: tlsState.PeerCertificates,
+		}
+		if ap.Type == "" {
+			ap.Type = common.AuthTypeMtls
+		}
+	}
+
 	// Add IP-based authentication
 	if ap.Type == "" {
-		xff := headers.Get("X-Forwarded-For")
 		ap.Type = common.AuthTypeNetwork
-		ap.Network = &NetworkPayload{
-			Address:        remoteAddr,
-			ForwardProxies: strings.Split(xff, ","),
-		}
 	}
 
 	return ap, nil
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const jwksMaxResponseSize = 1 << 20
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type jwksKey struct {
+	alg string
+	key interface{}
+}
+
+// jwksKeySet keeps the verification keys published on a JWKS url, indexed by their "kid".
+// Keys are refreshed periodically in the background, and on-demand (throttled) when a token
+// references an unknown "kid", which is what happens right after the issuer rotates its keys.
+type jwksKeySet struct {
+	logger             *zerolog.Logger
+	url                string
+	client             *http.Client
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]*jwksKey
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+func newJwksKeySet(appCtx context.Context, logger *zerolog.Logger, url string, refreshInterval, minRefreshInterval time.Duration) *jwksKeySet {
+	lg := logger.With().Str("jwksUrl", url).Logger()
+	ks := &jwksKeySet{
+		logger:             &lg,
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		minRefreshInterval: minRefreshInterval,
+		keys:               make(map[string]*jwksKey),
+	}
+
+	// Failing to fetch the keys at startup must not prevent the server from starting,
+	// tokens will be rejected until the keys can be fetched.
+	if err := ks.refresh(appCtx); err != nil {
+		ks.logger.Error().Err(err).Msg("failed to fetch initial JWKS keys")
+	}
+
+	if refreshInterval > 0 {
+		go ks.refreshLoop(appCtx, refreshInterval)
+	}
+
+	return ks
+}
+
+func (ks *jwksKeySet) refreshLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.refresh(ctx); err != nil {
+				ks.logger.Warn().Err(err).Msg("failed to refresh JWKS keys, keeping previous keys")
+			}
+		}
+	}
+}
+
+// Key returns the key for the given kid, refreshing the key set once if the kid is unknown and no
+// refresh is already in flight.
+func (ks *jwksKeySet) Key(ctx context.Context, kid string) (*jwksKey, bool) {
+	if key, ok := ks.lookup(kid); ok {
+		return key, true
+	}
+
+	// Requests must not queue up behind a refresh in flight (which can take up to the client timeout),
+	// so the kid is treated as unknown until that refresh completes.
+	if !ks.refreshMu.TryLock() {
+		return nil, false
+	}
+	defer ks.refreshMu.Unlock()
+
+	// Another request might have just refreshed the keys
+	if key, ok := ks.lookup(kid); ok {
+		return key, true
+	}
+	if time.Since(ks.lastRefresh) < ks.minRefreshInterval {
+		return nil, false
+	}
+	if err := ks.fetch(ctx); err != nil {
+		ks.logger.Warn().Err(err).Str("kid", kid).Msg("failed to refresh JWKS keys for unknown kid")
+		return nil, false
+	}
+
+	return ks.lookup(kid)
+}
+
+// Keys returns a snapshot of all currently known keys.
+func (ks *jwksKeySet) Keys() []*jwksKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	keys := make([]*jwksKey, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (ks *jwksKeySet) lookup(kid string) (*jwksKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *jwksKeySet) refresh(ctx context.Context) error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+	return ks.fetch(ctx)
+}
+
+// fetch must be called while holding refreshMu.
+func (ks *jwksKeySet) fetch(ctx context.Context) error {
+	ks.lastRefresh = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from JWKS url", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("failed to parse JWKS document: %w", err)
+	}
+
+	keys := make(map[string]*jwksKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			ks.logger.Warn().Err(err).Str("kid", jwk.Kid).Msg("ignoring unsupported JWKS key")
+			continue
+		}
+		keys[jwk.Kid] = &jwksKey{alg: jwk.Alg, key: key}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS document does not contain any usable signing key")
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	ks.logger.Debug().Int("keys", len(keys)).Msg("refreshed JWKS keys")
+	return nil
+}
+
+func (jwk *jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64Url(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := decodeBase64Url(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve: %s", jwk.Crv)
+		}
+		x, err := decodeBase64Url(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := decodeBase64Url(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", jwk.Crv)
+		}
+		return key, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve: %s", jwk.Crv)
+		}
+		x, err := decodeBase64Url(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := decodeBase64Url(jwk.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid symmetric key: %w", err)
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+}
+
+func decodeBase64Url(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("value is empty")
+	}
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
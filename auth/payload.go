@@ -1,14 +1,28 @@
 package auth
 
-import "github.com/erpc/erpc/common"
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/erpc/erpc/common"
+)
 
 type AuthPayload struct {
 	Method  string
+	Origin  string
 	Type    common.AuthType
 	Secret  *SecretPayload
 	Jwt     *JwtPayload
 	Siwe    *SiwePayload
 	Network *NetworkPayload
+	Mtls    *MtlsPayload
+	Hmac    *HmacPayload
+	// Digest is shared by all JSON-RPC requests of an HTTP request (or SIWE-authenticated websocket connection),
+	// nil when the body is not available
+	Digest *RequestDigest
 }
 
 // This payload is used by both "secret" and "database" strategies
@@ -23,9 +37,82 @@ type JwtPayload struct {
 type SiwePayload struct {
 	Signature string
 	Message   string
+	// SessionToken is used instead of the message and signature once they are exchanged via erpc_siweSession
+	SessionToken string
 }
 
 type NetworkPayload struct {
 	Address        string
 	ForwardProxies []string
 }
+
+type MtlsPayload struct {
+	// PeerCertificates are presented by the client (leaf first) and are not verified by the server yet
+	PeerCertificates []*x509.Certificate
+}
+
+// HmacPayload is the signature material of a signed request, see RequestDigest for the signed content.
+type HmacPayload struct {
+	KeyId     string
+	Timestamp string
+	Nonce     string
+	Signature string
+}
+
+// RequestDigest is the part of an HTTP request covered by its signature besides the headers, i.e.
+// the string to sign is "<http method>\n<path>\n<timestamp>\n<nonce>\n<hex sha256 of body>".
+type RequestDigest struct {
+	HttpMethod string
+	Path       string
+
+	// body is only hashed when a signature is verified, and released afterwards
+	body       []byte
+	bodyOnce   sync.Once
+	bodySha256 [32]byte
+
+	// verifications are shared by all JSON-RPC requests of a batch, so that single-use credentials
+	// (e.g. nonces) are only consumed once per HTTP request
+	verifications sync.Map // map[AuthStrategy]*digestVerification
+}
+
+type digestVerification struct {
+	once sync.Once
+	user *common.User
+	err  error
+}
+
+func NewRequestDigest(httpMethod, path string, body []byte) *RequestDigest {
+	return &RequestDigest{
+		HttpMethod: strings.ToUpper(httpMethod),
+		Path:       path,
+		body:       body,
+	}
+}
+
+// BodySha256 returns the SHA-256 of the body, which is computed on first use.
+func (d *RequestDigest) BodySha256() [32]byte {
+	d.bodyOnce.Do(func() {
+		d.bodySha256 = sha256.Sum256(d.body)
+		d.body = nil
+	})
+	return d.bodySha256
+}
+
+// StringToSign returns the content that clients sign with the secret of their key (HMAC-SHA256, hex-encoded).
+func (d *RequestDigest) StringToSign(timestamp, nonce string) string {
+	bodySha256 := d.BodySha256()
+	return strings.Join([]string{d.HttpMethod, d.Path, timestamp, nonce, hex.EncodeToString(bodySha256[:])}, "\n")
+}
+
+// verifyOnce runs the verification of a strategy only once for all requests sharing the digest.
+func (d *RequestDigest) verifyOnce(strategy AuthStrategy, verify func() (*common.User, error)) (*common.User, error) {
+	if d == nil {
+		return verify()
+	}
+	v, _ := d.verifications.LoadOrStore(strategy, &digestVerification{})
+	dv := v.(*digestVerification)
+	dv.once.Do(func() {
+		dv.user, dv.err = verify()
+	})
+	return dv.user, dv.err
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+)
+
+const (
+	QuotaWindowDaily   = "daily"
+	QuotaWindowMonthly = "monthly"
+
+	QuotaUnitRequests     = "requests"
+	QuotaUnitComputeUnits = "computeUnits"
+
+	quotaKeyPrefix = "erpc_usage"
+	quotaRangeKey  = "value"
+	quotaLockTtl   = 5 * time.Second
+)
+
+// QuotaWindowUsage is the consumption of an API key within a calendar day or month (in UTC).
+type QuotaWindowUsage struct {
+	Period       string    `json:"period"`
+	Requests     int64     `json:"requests"`
+	ComputeUnits int64     `json:"computeUnits"`
+	ResetsAt     time.Time `json:"resetsAt"`
+}
+
+type QuotaUsage struct {
+	Daily   *QuotaWindowUsage `json:"daily"`
+	Monthly *QuotaWindowUsage `json:"monthly"`
+}
+
+type quotaWindow struct {
+	name     string
+	period   string
+	resetsAt time.Time
+}
+
+func quotaWindows(now time.Time) []*quotaWindow {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return []*quotaWindow{
+		{name: QuotaWindowDaily, period: day.Format("2006-01-02"), resetsAt: day.AddDate(0, 0, 1)},
+		{name: QuotaWindowMonthly, period: month.Format("2006-01"), resetsAt: month.AddDate(0, 1, 0)},
+	}
+}
+
+// ttl keeps counters for a while after the window has ended, so that usage can still be looked up.
+func (w *quotaWindow) ttl(now time.Time) time.Duration {
+	return w.resetsAt.Sub(now) + 24*time.Hour
+}
+
+func (w *quotaWindow) limit(quota *common.UserQuota, unit string) int64 {
+	switch {
+	case w.name == QuotaWindowDaily && unit == QuotaUnitRequests:
+		return quota.DailyRequests
+	case w.name == QuotaWindowDaily && unit == QuotaUnitComputeUnits:
+		return quota.DailyComputeUnits
+	case w.name == QuotaWindowMonthly && unit == QuotaUnitRequests:
+		return quota.MonthlyRequests
+	case w.name == QuotaWindowMonthly && unit == QuotaUnitComputeUnits:
+		return quota.MonthlyComputeUnits
+	}
+	return 0
+}
+
+// quotaCounterKey is hash tagged by api key so that all counters of a key land on the same slot in Redis cluster
+// mode, which is required to update them atomically.
+func quotaCounterKey(apiKey string, w *quotaWindow, unit string) string {
+	return fmt.Sprintf("%s:{%s}:%s:%s", quotaKeyPrefix, apiKey, w.period, unit)
+}
+
+// usageCounters persists usage in the auth database connector so that quotas are enforced across all
+// instances. Connectors supporting atomic counters are used as is, others are updated under a lock.
+type usageCounters struct {
+	connector data.Connector
+}
+
+func (u *usageCounters) increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	if cc, ok := u.connector.(data.CounterConnector); ok {
+		return cc.IncrementCounterInt64(ctx, key, delta, ttl)
+	}
+
+	lock, err := u.connector.Lock(ctx, key, quotaLockTtl)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire lock for usage counter: %w", err)
+	}
+	defer func() {
+		_ = lock.Unlock(context.Background())
+	}()
+
+	value, err := u.read(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	value += delta
+	if err := u.connector.Set(ctx, key, quotaRangeKey, []byte(strconv.FormatInt(value, 10)), &ttl); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// incrementWithinLimits applies all increments unless any counter would exceed its limit, in which case none is
+// applied. Connectors without atomic counters are updated under a single lock held for all counters.
+func (u *usageCounters) incrementWithinLimits(ctx context.Context, lockKey string, increments []data.CounterIncrement) (bool, []int64, error) {
+	if cc, ok := u.connector.(data.CounterConnector); ok {
+		return cc.IncrementCountersWithinLimits(ctx, increments)
+	}
+
+	lock, err := u.connector.Lock(ctx, lockKey, quotaLockTtl)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to acquire lock for usage counters: %w", err)
+	}
+	defer func() {
+		_ = lock.Unlock(context.Background())
+	}()
+
+	values := make([]int64, len(increments))
+	exceeded := false
+	for i, inc := range increments {
+		if values[i], err = u.read(ctx, inc.Key); err != nil {
+			return false, nil, err
+		}
+		if inc.Limit > 0 && values[i]+inc.Delta > inc.Limit {
+			exceeded = true
+		}
+	}
+	if exceeded {
+		return false, values, nil
+	}
+	for i, inc := range increments {
+		values[i] += inc.Delta
+		if err := u.connector.Set(ctx, inc.Key, quotaRangeKey, []byte(strconv.FormatInt(values[i], 10)), &inc.TTL); err != nil {
+			return false, nil, err
+		}
+	}
+
+	return true, values, nil
+}
+
+func (u *usageCounters) get(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if cc, ok := u.connector.(data.CounterConnector); ok {
+		return cc.IncrementCounterInt64(ctx, key, 0, ttl)
+	}
+	return u.read(ctx, key)
+}
+
+func (u *usageCounters) reset(ctx context.Context, key string, ttl time.Duration) error {
+	current, err := u.get(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	if current == 0 {
+		return nil
+	}
+	_, err = u.increment(ctx, key, -current, ttl)
+	return err
+}
+
+func (u *usageCounters) read(ctx context.Context, key string) (int64, error) {
+	raw, err := u.connector.Get(ctx, data.ConnectorMainIndex, key, quotaRangeKey, nil)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
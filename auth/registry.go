@@ -70,8 +70,17 @@ func (r *AuthRegistry) Authenticate(ctx context.Context, method string, ap *Auth
 			continue
 		}
 
-		// If authentication is passed then apply and consume the rate limit
-		if err := az.acquireRateLimitPermit(user, method); err != nil {
+		// If authentication is passed then apply the user's own restrictions and consume the rate limit
+		if err := az.authorizeMethod(user, method); err != nil {
+			return user, err
+		}
+		if err := az.acquireUserRateLimitPermit(ctx, user, method); err != nil {
+			return user, err
+		}
+		if err := az.acquireRateLimitPermit(ctx, user, method); err != nil {
+			return user, err
+		}
+		if err := az.consumeQuota(ctx, ap, user, method); err != nil {
 			return user, err
 		}
 
@@ -105,3 +114,25 @@ func (r *AuthRegistry) FindDatabaseConnector(connectorId string) (data.Connector
 	}
 	return nil, fmt.Errorf("database connector with ID '%s' not found", connectorId)
 }
+
+// FindSiweStrategy returns the first siwe strategy that issues nonces, which is what wallets sign in with.
+func (r *AuthRegistry) FindSiweStrategy() (*SiweStrategy, error) {
+	for _, az := range r.strategies {
+		if siweStrategy, ok := az.strategy.(*SiweStrategy); ok && siweStrategy.EnforcesNonces() {
+			return siweStrategy, nil
+		}
+	}
+	return nil, fmt.Errorf("no siwe strategy with a connector is configured")
+}
+
+// FindDatabaseStrategy finds a database strategy by its connector ID
+func (r *AuthRegistry) FindDatabaseStrategy(connectorId string) (*DatabaseStrategy, error) {
+	for _, az := range r.strategies {
+		if az.cfg.Database != nil && az.cfg.Database.Connector != nil && az.cfg.Database.Connector.Id == connectorId {
+			if dbStrategy, ok := az.strategy.(*DatabaseStrategy); ok {
+				return dbStrategy, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("database strategy with connector ID '%s' not found", connectorId)
+}
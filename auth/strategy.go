@@ -10,3 +10,8 @@ type AuthStrategy interface {
 	Supports(ap *AuthPayload) bool
 	Authenticate(ctx context.Context, ap *AuthPayload) (*common.User, error)
 }
+
+// QuotaStrategy is implemented by strategies that enforce long-term usage quotas of authenticated users.
+type QuotaStrategy interface {
+	ConsumeQuota(ctx context.Context, ap *AuthPayload, user *common.User, computeUnits uint) error
+}
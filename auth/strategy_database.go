@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/singleflight"
 )
@@ -17,13 +20,25 @@ type DatabaseStrategy struct {
 	logger    *zerolog.Logger
 	cfg       *common.DatabaseStrategyConfig
 	connector data.Connector
-	cache     *ristretto.Cache[string, *common.User]
+	cache     *ristretto.Cache[string, *apiKeyRecord]
 	negCache  *ristretto.Cache[string, struct{}]
 	negTTL    time.Duration
 	sf        singleflight.Group
+	usage     *usageCounters
+
+	trustedProxies []*net.IPNet
+	// pepper is the secret used to hash API keys at rest, nil when keys are stored as-is
+	pepper []byte
+}
+
+// apiKeyRecord is the authenticated user of an API key along with the scope that each request must satisfy.
+type apiKeyRecord struct {
+	user  *common.User
+	scope *compiledApiKeyScope
 }
 
 var _ AuthStrategy = &DatabaseStrategy{}
+var _ QuotaStrategy = &DatabaseStrategy{}
 
 func NewDatabaseStrategy(appCtx context.Context, logger *zerolog.Logger, cfg *common.DatabaseStrategyConfig) (*DatabaseStrategy, error) {
 	if cfg == nil {
@@ -39,12 +54,22 @@ func NewDatabaseStrategy(appCtx context.Context, logger *zerolog.Logger, cfg *co
 		return nil, fmt.Errorf("failed to create database connector: %w", err)
 	}
 
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	var pepper []byte
+	if cfg.KeyHashing != nil {
+		pepper = []byte(cfg.KeyHashing.Pepper)
+	}
+
 	// Initialize cache(s)
-	var cache *ristretto.Cache[string, *common.User]
+	var cache *ristretto.Cache[string, *apiKeyRecord]
 	var negCache *ristretto.Cache[string, struct{}]
 	negTTL := 5 * time.Second
 	if cfg.Cache != nil {
-		cacheConfig := &ristretto.Config[string, *common.User]{
+		cacheConfig := &ristretto.Config[string, *apiKeyRecord]{
 			NumCounters: *cfg.Cache.NumCounters,
 			MaxCost:     *cfg.Cache.MaxCost,
 			BufferItems: 64, // Default buffer size
@@ -82,6 +107,10 @@ func NewDatabaseStrategy(appCtx context.Context, logger *zerolog.Logger, cfg *co
 		cache:     cache,
 		negCache:  negCache,
 		negTTL:    negTTL,
+		usage:     &usageCounters{connector: connector},
+
+		trustedProxies: trustedProxies,
+		pepper:         pepper,
 	}, nil
 }
 
@@ -94,16 +123,20 @@ func (s *DatabaseStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*
 		return nil, common.NewErrAuthUnauthorized("database", "no secret provided")
 	}
 
-	apiKey := ap.Secret.Value
-	if apiKey == "" {
+	if ap.Secret.Value == "" {
 		return nil, common.NewErrAuthUnauthorized("database", "empty API key")
 	}
+	// Everything below (including caches and logs) only refers to the stored id of the key
+	apiKey := s.storageKey(ap.Secret.Value)
 
 	// Check positive cache first if available
 	if s.cache != nil {
-		if cachedUser, found := s.cache.Get(apiKey); found {
+		if cached, found := s.cache.Get(apiKey); found {
 			s.logger.Debug().Str("apiKey", apiKey).Msg("API key found in cache")
-			return cachedUser, nil
+			if err := s.checkScope(ap, cached); err != nil {
+				return nil, err
+			}
+			return cached.user, nil
 		}
 		s.logger.Debug().Str("apiKey", apiKey).Msg("API key not found in cache")
 	}
@@ -118,33 +151,39 @@ func (s *DatabaseStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*
 
 	// Use singleflight to deduplicate concurrent misses per key
 	type authFetchResult struct {
-		user *common.User
-		err  error
-		neg  bool
+		record *apiKeyRecord
+		err    error
+		neg    bool
 	}
 	v, sfErr, _ := s.sf.Do(apiKey, func() (interface{}, error) {
 		rangeKey := "*"
 		valueBytes, err := s.connector.Get(ctx, data.ConnectorMainIndex, apiKey, rangeKey, nil)
+		// Values looking like hashed ids are never looked up as-is, otherwise hashes could be used as credentials
+		if err != nil && common.HasErrorCode(err, common.ErrCodeRecordNotFound) && s.plaintextFallback() && !strings.HasPrefix(ap.Secret.Value, ApiKeyHashPrefix) {
+			valueBytes, err = s.connector.Get(ctx, data.ConnectorMainIndex, ap.Secret.Value, rangeKey, nil)
+		}
 		if err != nil {
 			if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
-				return &authFetchResult{user: nil, err: common.NewErrAuthUnauthorized("database", "invalid API key"), neg: true}, nil
+				return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", "invalid API key"), neg: true}, nil
 			}
 			s.logger.Error().Err(err).Str("apiKey", apiKey).Msg("database query failed during authentication")
-			return &authFetchResult{user: nil, err: common.NewErrAuthUnauthorized("database", fmt.Sprintf("database query failed: %v", err)), neg: false}, nil
+			return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", fmt.Sprintf("database query failed: %v", err)), neg: false}, nil
 		}
 
 		var userData struct {
-			UserId             string `json:"userId"`
-			PerSecondRateLimit *int64 `json:"perSecondRateLimit,omitempty"`
-			Enabled            *bool  `json:"enabled,omitempty"`
+			UserId             string            `json:"userId"`
+			PerSecondRateLimit *int64            `json:"perSecondRateLimit,omitempty"`
+			Enabled            *bool             `json:"enabled,omitempty"`
+			Quota              *common.UserQuota `json:"quota,omitempty"`
+			ApiKeyScope
 		}
 		if err := json.Unmarshal(valueBytes, &userData); err != nil {
 			s.logger.Error().Err(err).Str("apiKey", apiKey).RawJSON("data", valueBytes).Msg("failed to parse user data from database")
-			return &authFetchResult{user: nil, err: common.NewErrAuthUnauthorized("database", "invalid user data format"), neg: false}, nil
+			return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", "invalid user data format"), neg: false}, nil
 		}
 		if userData.UserId == "" {
 			s.logger.Error().Str("apiKey", apiKey).RawJSON("data", valueBytes).Msg("missing user ID in database record")
-			return &authFetchResult{user: nil, err: common.NewErrAuthUnauthorized("database", "missing user ID in data"), neg: false}, nil
+			return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", "missing user ID in data"), neg: false}, nil
 		}
 		enabled := true
 		if userData.Enabled != nil {
@@ -152,13 +191,28 @@ func (s *DatabaseStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*
 		}
 		if !enabled {
 			s.logger.Warn().Str("apiKey", apiKey).Str("userId", userData.UserId).Msg("authentication attempt with disabled API key")
-			return &authFetchResult{user: nil, err: common.NewErrAuthUnauthorized("database", "API key is disabled"), neg: true}, nil
+			return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", "API key is disabled"), neg: true}, nil
+		}
+		scope, err := compileApiKeyScope(&userData.ApiKeyScope)
+		if err != nil {
+			s.logger.Error().Err(err).Str("apiKey", apiKey).RawJSON("data", valueBytes).Msg("invalid scope in database record")
+			return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", "invalid API key scope"), neg: false}, nil
+		}
+		if scope.isExpired(time.Now()) {
+			return &authFetchResult{record: nil, err: common.NewErrAuthUnauthorized("database", "API key has expired"), neg: true}, nil
+		}
+		user := &common.User{
+			Id:              userData.UserId,
+			AllowedNetworks: normalizeNetworkIds(userData.AllowedNetworks),
+			AllowedMethods:  userData.AllowedMethods,
 		}
-		user := &common.User{Id: userData.UserId}
 		if userData.PerSecondRateLimit != nil {
 			user.PerSecondRateLimit = *userData.PerSecondRateLimit
 		}
-		return &authFetchResult{user: user, err: nil, neg: false}, nil
+		if !userData.Quota.IsEmpty() {
+			user.Quota = userData.Quota
+		}
+		return &authFetchResult{record: &apiKeyRecord{user: user, scope: scope}, err: nil, neg: false}, nil
 	})
 	if sfErr != nil {
 		return nil, sfErr
@@ -170,27 +224,144 @@ func (s *DatabaseStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*
 		}
 		return nil, afr.err
 	}
-	user := afr.user
+	user := afr.record.user
 
-	// Cache the successful result if cache is available
+	// Cache the successful result if cache is available, the scope is still checked for every request
 	if s.cache != nil && s.cfg.Cache != nil && s.cfg.Cache.TTL != nil {
 		ttl := *s.cfg.Cache.TTL
-		s.cache.SetWithTTL(apiKey, user, 1, ttl)
+		s.cache.SetWithTTL(apiKey, afr.record, 1, ttl)
 		s.logger.Debug().Str("apiKey", apiKey).Dur("ttl", ttl).Msg("cached API key data")
 	}
 
+	if err := s.checkScope(ap, afr.record); err != nil {
+		return nil, err
+	}
+
 	s.logger.Debug().Str("apiKey", apiKey).Str("userId", user.Id).Int64("rateLimit", user.PerSecondRateLimit).Msg("user authenticated successfully")
 
 	return user, nil
 }
 
+// plaintextFallback tells whether keys that are not migrated to hashed keys yet can still be used.
+func (s *DatabaseStrategy) plaintextFallback() bool {
+	return s.pepper != nil && s.cfg.KeyHashing.PlaintextFallback != nil && *s.cfg.KeyHashing.PlaintextFallback
+}
+
+func (s *DatabaseStrategy) checkScope(ap *AuthPayload, record *apiKeyRecord) error {
+	if record.scope.isExpired(time.Now()) {
+		return common.NewErrAuthUnauthorized("database", "API key has expired")
+	}
+	if err := record.scope.check(ap, s.trustedProxies); err != nil {
+		return common.NewErrAuthForbidden("database", record.user.Id, err.Error())
+	}
+	return nil
+}
+
+// ConsumeQuota accounts the request towards the daily and monthly usage of the API key and rejects it
+// (without accounting) if any of the quotas of the user would be exceeded. All counters are checked and
+// updated in a single atomic operation.
+func (s *DatabaseStrategy) ConsumeQuota(ctx context.Context, ap *AuthPayload, user *common.User, computeUnits uint) error {
+	if user == nil || user.Quota.IsEmpty() || ap.Secret == nil {
+		return nil
+	}
+	apiKey := s.storageKey(ap.Secret.Value)
+
+	type window struct {
+		*quotaWindow
+		unit string
+	}
+	now := time.Now()
+	var windows []window
+	var increments []data.CounterIncrement
+	for _, w := range quotaWindows(now) {
+		for _, unit := range []string{QuotaUnitRequests, QuotaUnitComputeUnits} {
+			inc := data.CounterIncrement{
+				Key:   quotaCounterKey(apiKey, w, unit),
+				Delta: 1,
+				Limit: w.limit(user.Quota, unit),
+				TTL:   w.ttl(now),
+			}
+			if unit == QuotaUnitComputeUnits {
+				inc.Delta = int64(computeUnits)
+			}
+			windows = append(windows, window{quotaWindow: w, unit: unit})
+			increments = append(increments, inc)
+		}
+	}
+
+	applied, values, err := s.usage.incrementWithinLimits(ctx, fmt.Sprintf("%s:{%s}", quotaKeyPrefix, apiKey), increments)
+	if err != nil {
+		if *s.cfg.QuotaFailOpen {
+			telemetry.MetricAuthQuotaAccountingFailureTotal.WithLabelValues(s.connector.Id(), "allowed").Inc()
+			s.logger.Warn().Err(err).Str("userId", user.Id).Msg("failed to account usage of api key, allowing request")
+			return nil
+		}
+		telemetry.MetricAuthQuotaAccountingFailureTotal.WithLabelValues(s.connector.Id(), "rejected").Inc()
+		s.logger.Warn().Err(err).Str("userId", user.Id).Msg("failed to account usage of api key, rejecting request")
+		return common.NewErrAuthQuotaUnavailable("database", user.Id, err)
+	}
+	if !applied {
+		for i, inc := range increments {
+			if inc.Limit > 0 && values[i]+inc.Delta > inc.Limit {
+				return common.NewErrAuthQuotaExceeded("database", user.Id, windows[i].name, windows[i].unit, inc.Limit, windows[i].resetsAt)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetUsage returns the usage of an API key (or its id) within the current day and month.
+func (s *DatabaseStrategy) GetUsage(ctx context.Context, apiKey string) (*QuotaUsage, error) {
+	apiKey = s.KeyId(apiKey)
+	now := time.Now()
+	usage := &QuotaUsage{}
+	for _, w := range quotaWindows(now) {
+		wu := &QuotaWindowUsage{Period: w.period, ResetsAt: w.resetsAt}
+		var err error
+		if wu.Requests, err = s.usage.get(ctx, quotaCounterKey(apiKey, w, QuotaUnitRequests), w.ttl(now)); err != nil {
+			return nil, err
+		}
+		if wu.ComputeUnits, err = s.usage.get(ctx, quotaCounterKey(apiKey, w, QuotaUnitComputeUnits), w.ttl(now)); err != nil {
+			return nil, err
+		}
+		if w.name == QuotaWindowDaily {
+			usage.Daily = wu
+		} else {
+			usage.Monthly = wu
+		}
+	}
+	return usage, nil
+}
+
+// ResetUsage resets the usage of an API key within the current day and/or month, an empty window resets both.
+func (s *DatabaseStrategy) ResetUsage(ctx context.Context, apiKey string, window string) error {
+	if window != "" && window != QuotaWindowDaily && window != QuotaWindowMonthly {
+		return fmt.Errorf("window must be either '%s' or '%s'", QuotaWindowDaily, QuotaWindowMonthly)
+	}
+	apiKey = s.KeyId(apiKey)
+	now := time.Now()
+	for _, w := range quotaWindows(now) {
+		if window != "" && w.name != window {
+			continue
+		}
+		for _, unit := range []string{QuotaUnitRequests, QuotaUnitComputeUnits} {
+			if err := s.usage.reset(ctx, quotaCounterKey(apiKey, w, unit), w.ttl(now)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // GetConnector returns the database connector for admin operations
 func (s *DatabaseStrategy) GetConnector() data.Connector {
 	return s.connector
 }
 
-// InvalidateCache removes an API key from the cache
+// InvalidateCache removes an API key (or its id) from the cache
 func (s *DatabaseStrategy) InvalidateCache(apiKey string) {
+	apiKey = s.KeyId(apiKey)
 	if s.cache != nil {
 		s.cache.Del(apiKey)
 		s.logger.Debug().Str("apiKey", apiKey).Msg("invalidated API key cache entry")
@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/upstream"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseStrategy_Quotas(t *testing.T) {
+	logger := zerolog.Nop()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.AuthConfig{
+		Strategies: []*common.AuthStrategyConfig{
+			{
+				Type: common.AuthTypeDatabase,
+				Database: &common.DatabaseStrategyConfig{
+					Connector: &common.ConnectorConfig{
+						Id:     "keys",
+						Driver: common.DriverRedis,
+						Redis: &common.RedisConnectorConfig{
+							Addr:        m.Addr(),
+							InitTimeout: common.Duration(2 * time.Second),
+							GetTimeout:  common.Duration(2 * time.Second),
+							SetTimeout:  common.Duration(2 * time.Second),
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	rateLimiters, err := upstream.NewRateLimitersRegistry(nil, &logger)
+	require.NoError(t, err)
+	registry, err := NewAuthRegistry(ctx, &logger, "test", cfg, rateLimiters)
+	require.NoError(t, err)
+	strategy, err := registry.FindDatabaseStrategy("keys")
+	require.NoError(t, err)
+
+	// Redis looks up records by exact keys, so they are stored under the range key used for lookups
+	require.NoError(t, strategy.GetConnector().Set(ctx, "limited-key", "*", []byte(`{"userId":"user-1","quota":{"dailyRequests":3,"monthlyComputeUnits":25}}`), nil))
+	require.NoError(t, strategy.GetConnector().Set(ctx, "unlimited-key", "*", []byte(`{"userId":"user-2"}`), nil))
+
+	authenticate := func(apiKey, method string) error {
+		ap := &AuthPayload{
+			Method: method,
+			Type:   common.AuthTypeDatabase,
+			Secret: &SecretPayload{Value: apiKey},
+		}
+		_, err := registry.Authenticate(ctx, method, ap)
+		return err
+	}
+
+	t.Run("RejectsRequestsOnceDailyRequestsQuotaIsExhausted", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, authenticate("limited-key", "eth_chainId"))
+		}
+		err := authenticate("limited-key", "eth_chainId")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthQuotaExceeded), "expected quota error, got: %v", err)
+
+		jrErr := common.TranslateToJsonRpcException(err)
+		require.True(t, common.HasErrorCode(jrErr, common.ErrCodeJsonRpcExceptionInternal))
+		require.Contains(t, jrErr.Error(), "daily quota of 3 requests is exhausted")
+
+		usage, err := strategy.GetUsage(ctx, "limited-key")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), usage.Daily.Requests, "rejected requests must not be accounted")
+		require.Equal(t, int64(3), usage.Monthly.ComputeUnits)
+	})
+
+	t.Run("CountsComputeUnitsOfMethods", func(t *testing.T) {
+		require.NoError(t, strategy.ResetUsage(ctx, "limited-key", QuotaWindowDaily))
+
+		// eth_getLogs costs 20 compute units, so the monthly total becomes 23 out of 25
+		require.NoError(t, authenticate("limited-key", "eth_getLogs"))
+		err := authenticate("limited-key", "eth_call")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthQuotaExceeded), "expected quota error, got: %v", err)
+
+		usage, err := strategy.GetUsage(ctx, "limited-key")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), usage.Daily.Requests)
+		require.Equal(t, int64(23), usage.Monthly.ComputeUnits)
+	})
+
+	t.Run("ResetsUsage", func(t *testing.T) {
+		require.NoError(t, strategy.ResetUsage(ctx, "limited-key", ""))
+		usage, err := strategy.GetUsage(ctx, "limited-key")
+		require.NoError(t, err)
+		require.Zero(t, usage.Daily.Requests)
+		require.Zero(t, usage.Monthly.ComputeUnits)
+		require.NoError(t, authenticate("limited-key", "eth_call"))
+	})
+
+	t.Run("DoesNotLimitKeysWithoutQuota", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			require.NoError(t, authenticate("unlimited-key", "debug_traceBlockByNumber"))
+		}
+	})
+
+	t.Run("NeverExceedsQuotaUnderConcurrentRequests", func(t *testing.T) {
+		require.NoError(t, strategy.ResetUsage(ctx, "limited-key", ""))
+
+		var wg sync.WaitGroup
+		var allowed atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if authenticate("limited-key", "eth_chainId") == nil {
+					allowed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(3), allowed.Load())
+
+		usage, err := strategy.GetUsage(ctx, "limited-key")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), usage.Daily.Requests)
+	})
+
+	t.Run("AppliesFailOpenPolicyWhenUsageCannotBeAccounted", func(t *testing.T) {
+		failing := data.NewMockConnector("failing")
+		failing.On("Lock", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("database is down"))
+		usage := strategy.usage
+		strategy.usage = &usageCounters{connector: failing}
+		defer func() {
+			strategy.usage = usage
+			strategy.cfg.QuotaFailOpen = util.BoolPtr(true)
+		}()
+
+		require.NoError(t, authenticate("limited-key", "eth_chainId"), "requests must be allowed by default")
+
+		strategy.cfg.QuotaFailOpen = util.BoolPtr(false)
+		err := authenticate("limited-key", "eth_chainId")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthQuotaUnavailable), "expected quota unavailable error, got: %v", err)
+	})
+}
+
+func TestDatabaseStrategy_ScopedApiKeys(t *testing.T) {
+	logger := zerolog.Nop()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.AuthConfig{
+		Strategies: []*common.AuthStrategyConfig{
+			{
+				Type: common.AuthTypeDatabase,
+				Database: &common.DatabaseStrategyConfig{
+					Connector: &common.ConnectorConfig{
+						Id:     "keys",
+						Driver: common.DriverRedis,
+						Redis: &common.RedisConnectorConfig{
+							Addr:        m.Addr(),
+							InitTimeout: common.Duration(2 * time.Second),
+							GetTimeout:  common.Duration(2 * time.Second),
+							SetTimeout:  common.Duration(2 * time.Second),
+						},
+					},
+					TrustedProxies: []string{"10.0.0.0/8"},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	rateLimiters, err := upstream.NewRateLimitersRegistry(nil, &logger)
+	require.NoError(t, err)
+	registry, err := NewAuthRegistry(ctx, &logger, "test", cfg, rateLimiters)
+	require.NoError(t, err)
+	strategy, err := registry.FindDatabaseStrategy("keys")
+	require.NoError(t, err)
+
+	expired := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	records := map[string]string{
+		"frontend-key": `{"userId":"web","allowedNetworks":["1"],"allowedMethods":["eth_call","eth_get*"],"allowedOrigins":["https://*.example.com"]}`,
+		"server-key":   `{"userId":"backend","allowedCIDRs":["203.0.113.0/24"]}`,
+		"expired-key":  `{"userId":"old","expiresAt":"` + expired + `"}`,
+	}
+	for apiKey, record := range records {
+		require.NoError(t, strategy.GetConnector().Set(ctx, apiKey, "*", []byte(record), nil))
+	}
+
+	authenticate := func(apiKey, method, origin, remoteAddr string, xff ...string) (*common.User, error) {
+		ap := &AuthPayload{
+			Method:  method,
+			Origin:  origin,
+			Type:    common.AuthTypeDatabase,
+			Secret:  &SecretPayload{Value: apiKey},
+			Network: &NetworkPayload{Address: remoteAddr, ForwardProxies: xff},
+		}
+		return registry.Authenticate(ctx, method, ap)
+	}
+
+	t.Run("AllowsRequestsWithinScope", func(t *testing.T) {
+		user, err := authenticate("frontend-key", "eth_getBalance", "https://app.example.com", "198.51.100.1:1234")
+		require.NoError(t, err)
+		require.Equal(t, []string{"evm:1"}, user.AllowedNetworks)
+		require.True(t, user.IsNetworkAllowed("evm:1"))
+		require.False(t, user.IsNetworkAllowed("evm:10"))
+	})
+
+	t.Run("RejectsMethodOutsideScope", func(t *testing.T) {
+		_, err := authenticate("frontend-key", "eth_sendRawTransaction", "https://app.example.com", "198.51.100.1:1234")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got: %v", err)
+	})
+
+	t.Run("RejectsOriginOutsideScope", func(t *testing.T) {
+		_, err := authenticate("frontend-key", "eth_call", "https://evil.com", "198.51.100.1:1234")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got: %v", err)
+		_, err = authenticate("frontend-key", "eth_call", "", "198.51.100.1:1234")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got: %v", err)
+	})
+
+	t.Run("ChecksClientIpBehindTrustedProxies", func(t *testing.T) {
+		_, err := authenticate("server-key", "eth_call", "", "10.1.1.1:1234", "203.0.113.7", "10.2.2.2")
+		require.NoError(t, err)
+		_, err = authenticate("server-key", "eth_call", "", "198.51.100.1:1234")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got: %v", err)
+	})
+
+	t.Run("IgnoresForwardedForFromUntrustedPeers", func(t *testing.T) {
+		_, err := authenticate("server-key", "eth_call", "", "198.51.100.1:1234", "203.0.113.7")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got: %v", err)
+		_, err = authenticate("server-key", "eth_call", "", "10.1.1.1:1234", "203.0.113.7", "198.51.100.1")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got: %v", err)
+		_, err = authenticate("server-key", "eth_call", "", "203.0.113.9:1234", "198.51.100.1")
+		require.NoError(t, err)
+	})
+
+	t.Run("RejectsExpiredKeys", func(t *testing.T) {
+		_, err := authenticate("expired-key", "eth_call", "", "198.51.100.1:1234")
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got: %v", err)
+		require.Contains(t, err.Error(), "expired")
+	})
+}
+
+func TestDatabaseStrategy_HashedApiKeys(t *testing.T) {
+	logger := zerolog.Nop()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.AuthConfig{
+		Strategies: []*common.AuthStrategyConfig{
+			{
+				Type: common.AuthTypeDatabase,
+				Database: &common.DatabaseStrategyConfig{
+					Connector: &common.ConnectorConfig{
+						Id:     "keys",
+						Driver: common.DriverRedis,
+						Redis: &common.RedisConnectorConfig{
+							Addr:        m.Addr(),
+							InitTimeout: common.Duration(2 * time.Second),
+							GetTimeout:  common.Duration(2 * time.Second),
+							SetTimeout:  common.Duration(2 * time.Second),
+						},
+					},
+					KeyHashing: &common.DatabaseStrategyKeyHashingConfig{
+						Pepper: "a-very-long-and-random-pepper",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	rateLimiters, err := upstream.NewRateLimitersRegistry(nil, &logger)
+	require.NoError(t, err)
+	registry, err := NewAuthRegistry(ctx, &logger, "test", cfg, rateLimiters)
+	require.NoError(t, err)
+	strategy, err := registry.FindDatabaseStrategy("keys")
+	require.NoError(t, err)
+
+	authenticate := func(apiKey string) (*common.User, error) {
+		ap := &AuthPayload{
+			Method: "eth_call",
+			Type:   common.AuthTypeDatabase,
+			Secret: &SecretPayload{Value: apiKey},
+		}
+		return registry.Authenticate(ctx, "eth_call", ap)
+	}
+
+	const plaintextKey = "legacy-plaintext-key"
+	require.NoError(t, strategy.GetConnector().Set(ctx, plaintextKey, "*", []byte(`{"userId":"legacy"}`), nil))
+	const hmacKeyId = "hmac-key-id"
+	require.NoError(t, strategy.GetConnector().Set(ctx, hmacKeyId, "*", []byte(`{"userId":"signer","secret":"hmac-secret"}`), nil))
+
+	t.Run("AcceptsPlaintextKeysBeforeMigration", func(t *testing.T) {
+		user, err := authenticate(plaintextKey)
+		require.NoError(t, err)
+		require.Equal(t, "legacy", user.Id)
+	})
+
+	t.Run("MigratesPlaintextKeysToHashes", func(t *testing.T) {
+		strategy.ClearCache()
+		result, err := strategy.MigratePlaintextKeys(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Migrated)
+		require.Equal(t, 1, result.Skipped)
+
+		keyId := strategy.KeyId(plaintextKey)
+		require.True(t, strings.HasPrefix(keyId, ApiKeyHashPrefix))
+		for _, key := range m.Keys() {
+			require.NotContains(t, key, plaintextKey, "plaintext key must not be stored anymore")
+		}
+		stored, err := m.Get(keyId + ":*")
+		require.NoError(t, err)
+		require.Contains(t, stored, `"keyPrefix":"legacy-p"`)
+		_, err = m.Get(hmacKeyId + ":*")
+		require.NoError(t, err, "HMAC keys must not be migrated")
+
+		user, err := authenticate(plaintextKey)
+		require.NoError(t, err)
+		require.Equal(t, "legacy", user.Id)
+	})
+
+	t.Run("RejectsHashUsedAsKey", func(t *testing.T) {
+		_, err := authenticate(strategy.KeyId(plaintextKey))
+		require.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got: %v", err)
+	})
+
+	t.Run("PepperChangesHashes", func(t *testing.T) {
+		other := &DatabaseStrategy{pepper: []byte("another-long-and-random-pepper")}
+		require.NotEqual(t, strategy.KeyId(plaintextKey), other.KeyId(plaintextKey))
+	})
+}
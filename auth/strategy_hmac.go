@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	hmacNonceKeyPrefix = "erpc_hmac_nonce"
+	hmacMinNonceLength = 8
+	hmacMaxNonceLength = 128
+)
+
+type HmacStrategy struct {
+	logger    *zerolog.Logger
+	cfg       *common.HmacStrategyConfig
+	connector data.Connector
+	nonces    *usageCounters
+	cache     *ristretto.Cache[string, *hmacKey]
+	sf        singleflight.Group
+}
+
+// hmacKey is the record stored in the connector for each key id.
+type hmacKey struct {
+	UserId             string `json:"userId"`
+	Secret             string `json:"secret"`
+	Enabled            *bool  `json:"enabled,omitempty"`
+	PerSecondRateLimit int64  `json:"perSecondRateLimit,omitempty"`
+}
+
+var _ AuthStrategy = &HmacStrategy{}
+
+func NewHmacStrategy(appCtx context.Context, logger *zerolog.Logger, cfg *common.HmacStrategyConfig) (*HmacStrategy, error) {
+	if cfg.Connector == nil {
+		return nil, fmt.Errorf("hmac strategy connector config is nil")
+	}
+	connector, err := data.NewConnector(appCtx, logger, cfg.Connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hmac keys connector: %w", err)
+	}
+
+	s := &HmacStrategy{
+		logger:    logger,
+		cfg:       cfg,
+		connector: connector,
+		nonces:    &usageCounters{connector: connector},
+	}
+	if cfg.CacheTTL > 0 {
+		s.cache, err = ristretto.NewCache(&ristretto.Config[string, *hmacKey]{
+			NumCounters: 100000,
+			MaxCost:     10000,
+			BufferItems: 64,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cache: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *HmacStrategy) Supports(ap *AuthPayload) bool {
+	return ap.Type == common.AuthTypeHmac
+}
+
+func (s *HmacStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*common.User, error) {
+	if ap.Hmac == nil || ap.Digest == nil {
+		return nil, common.NewErrAuthUnauthorized("hmac", "missing request signature")
+	}
+
+	return ap.Digest.verifyOnce(s, func() (*common.User, error) {
+		return s.verify(ctx, ap.Hmac, ap.Digest)
+	})
+}
+
+func (s *HmacStrategy) verify(ctx context.Context, hp *HmacPayload, digest *RequestDigest) (*common.User, error) {
+	if hp.KeyId == "" || hp.Timestamp == "" || hp.Nonce == "" {
+		return nil, common.NewErrAuthUnauthorized("hmac", "X-ERPC-Key-Id, X-ERPC-Timestamp and X-ERPC-Nonce headers are required for signed requests")
+	}
+	if len(hp.Nonce) < hmacMinNonceLength || len(hp.Nonce) > hmacMaxNonceLength {
+		return nil, common.NewErrAuthUnauthorized("hmac", fmt.Sprintf("nonce must be between %d and %d characters", hmacMinNonceLength, hmacMaxNonceLength))
+	}
+
+	ts, err := strconv.ParseInt(hp.Timestamp, 10, 64)
+	if err != nil {
+		return nil, common.NewErrAuthUnauthorized("hmac", "timestamp must be in unix seconds")
+	}
+	maxSkew := s.cfg.MaxClockSkew.Duration()
+	if drift := time.Since(time.Unix(ts, 0)); drift > maxSkew || drift < -maxSkew {
+		return nil, common.NewErrAuthUnauthorized("hmac", "request timestamp is too far from server time")
+	}
+
+	signature, err := hex.DecodeString(hp.Signature)
+	if err != nil {
+		return nil, common.NewErrAuthUnauthorized("hmac", "signature must be hex-encoded")
+	}
+
+	key, err := s.loadKey(ctx, hp.KeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, []byte(key.Secret))
+	mac.Write([]byte(digest.StringToSign(hp.Timestamp, hp.Nonce)))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, common.NewErrAuthUnauthorized("hmac", "invalid signature")
+	}
+
+	// Nonces are only recorded for valid signatures, and are kept until their timestamp is stale anyway
+	used, err := s.nonces.increment(ctx, fmt.Sprintf("%s:%s:%s", hmacNonceKeyPrefix, hp.KeyId, hp.Nonce), 1, 2*maxSkew)
+	if err != nil {
+		s.logger.Error().Err(err).Str("keyId", hp.KeyId).Msg("failed to record nonce of signed request")
+		return nil, common.NewErrAuthUnauthorized("hmac", fmt.Sprintf("failed to record nonce: %v", err))
+	}
+	if used > 1 {
+		return nil, common.NewErrAuthUnauthorized("hmac", "nonce has already been used")
+	}
+
+	return &common.User{
+		Id:                 key.UserId,
+		PerSecondRateLimit: key.PerSecondRateLimit,
+	}, nil
+}
+
+func (s *HmacStrategy) loadKey(ctx context.Context, keyId string) (*hmacKey, error) {
+	if s.cache != nil {
+		if key, found := s.cache.Get(keyId); found {
+			return key, nil
+		}
+	}
+
+	v, err, _ := s.sf.Do(keyId, func() (interface{}, error) {
+		value, err := s.connector.Get(ctx, data.ConnectorMainIndex, keyId, "*", nil)
+		if err != nil {
+			if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+				return nil, common.NewErrAuthUnauthorized("hmac", "invalid key id")
+			}
+			s.logger.Error().Err(err).Str("keyId", keyId).Msg("database query failed during authentication")
+			return nil, common.NewErrAuthUnauthorized("hmac", fmt.Sprintf("database query failed: %v", err))
+		}
+		key := &hmacKey{}
+		if err := json.Unmarshal(value, key); err != nil || key.UserId == "" || key.Secret == "" {
+			s.logger.Error().Str("keyId", keyId).Msg("invalid hmac key record, it must have userId and secret")
+			return nil, common.NewErrAuthUnauthorized("hmac", "invalid key data format")
+		}
+		if key.Enabled != nil && !*key.Enabled {
+			return nil, common.NewErrAuthUnauthorized("hmac", "key is disabled")
+		}
+		if s.cache != nil {
+			s.cache.SetWithTTL(keyId, key, 1, s.cfg.CacheTTL.Duration())
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*hmacKey), nil
+}
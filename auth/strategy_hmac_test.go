@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedPayload(t *testing.T, keyId, secret, nonce string, ts time.Time, body []byte) *AuthPayload {
+	digest := NewRequestDigest("post", "/main/evm/1", body)
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(digest.StringToSign(timestamp, nonce)))
+
+	headers := http.Header{}
+	headers.Set("X-ERPC-Key-Id", keyId)
+	headers.Set("X-ERPC-Timestamp", timestamp)
+	headers.Set("X-ERPC-Nonce", nonce)
+	headers.Set("X-ERPC-Signature", hex.EncodeToString(mac.Sum(nil)))
+	ap, err := NewPayloadFromHttp("eth_call", "10.0.0.1:1234", headers, url.Values{}, nil, digest)
+	require.NoError(t, err)
+	return ap
+}
+
+func TestHmacStrategy(t *testing.T) {
+	logger := zerolog.Nop()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.HmacStrategyConfig{
+		Connector: &common.ConnectorConfig{
+			Id:     "hmac-keys",
+			Driver: common.DriverRedis,
+			Redis: &common.RedisConnectorConfig{
+				Addr:        m.Addr(),
+				InitTimeout: common.Duration(2 * time.Second),
+				GetTimeout:  common.Duration(2 * time.Second),
+				SetTimeout:  common.Duration(2 * time.Second),
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	strategy, err := NewHmacStrategy(ctx, &logger, cfg)
+	require.NoError(t, err)
+	require.NoError(t, strategy.connector.Set(ctx, "key-1", "*", []byte(`{"userId":"user-1","secret":"s3cr3t","perSecondRateLimit":10}`), nil))
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`)
+
+	t.Run("AcceptsValidSignature", func(t *testing.T) {
+		ap := signedPayload(t, "key-1", "s3cr3t", "nonce-0001", time.Now(), body)
+		require.True(t, strategy.Supports(ap))
+		user, err := strategy.Authenticate(ctx, ap)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", user.Id)
+		assert.Equal(t, int64(10), user.PerSecondRateLimit)
+	})
+
+	t.Run("RejectsReplayedNonce", func(t *testing.T) {
+		_, err := strategy.Authenticate(ctx, signedPayload(t, "key-1", "s3cr3t", "nonce-0002", time.Now(), body))
+		require.NoError(t, err)
+		_, err = strategy.Authenticate(ctx, signedPayload(t, "key-1", "s3cr3t", "nonce-0002", time.Now(), body))
+		assert.ErrorContains(t, err, "nonce has already been used")
+	})
+
+	t.Run("AcceptsAllRequestsOfSignedBatch", func(t *testing.T) {
+		ap := signedPayload(t, "key-1", "s3cr3t", "nonce-0003", time.Now(), body)
+		for i := 0; i < 3; i++ {
+			_, err := strategy.Authenticate(ctx, ap)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("RejectsStaleTimestamp", func(t *testing.T) {
+		_, err := strategy.Authenticate(ctx, signedPayload(t, "key-1", "s3cr3t", "nonce-0004", time.Now().Add(-10*time.Minute), body))
+		assert.ErrorContains(t, err, "timestamp is too far")
+	})
+
+	t.Run("RejectsWrongSecret", func(t *testing.T) {
+		_, err := strategy.Authenticate(ctx, signedPayload(t, "key-1", "wrong", "nonce-0005", time.Now(), body))
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("RejectsTamperedBody", func(t *testing.T) {
+		ap := signedPayload(t, "key-1", "s3cr3t", "nonce-0006", time.Now(), body)
+		ap.Digest = NewRequestDigest("POST", "/main/evm/1", []byte(`{"method":"eth_sendRawTransaction"}`))
+		_, err := strategy.Authenticate(ctx, ap)
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("RejectsUnknownKey", func(t *testing.T) {
+		_, err := strategy.Authenticate(ctx, signedPayload(t, "key-2", "s3cr3t", "nonce-0007", time.Now(), body))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got %v", err)
+	})
+}
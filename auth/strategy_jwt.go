@@ -3,25 +3,30 @@ package auth
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"encoding/pem"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/erpc/erpc/common"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog"
 )
 
 type JwtStrategy struct {
 	cfg    *common.JwtStrategyConfig
 	parser *jwt.Parser
 	keys   map[string]jwt.Keyfunc
+	jwks   *jwksKeySet
 }
 
 var _ AuthStrategy = &JwtStrategy{}
 
-func NewJwtStrategy(cfg *common.JwtStrategyConfig) (*JwtStrategy, error) {
+func NewJwtStrategy(appCtx context.Context, logger *zerolog.Logger, cfg *common.JwtStrategyConfig) (*JwtStrategy, error) {
 	// Parse and store verification keys
 	var keys map[string]jwt.Keyfunc = make(map[string]jwt.Keyfunc)
 	for kid, keyData := range cfg.VerificationKeys {
@@ -34,10 +39,22 @@ func NewJwtStrategy(cfg *common.JwtStrategyConfig) (*JwtStrategy, error) {
 		}
 	}
 
+	var jwks *jwksKeySet
+	if cfg.JwksUrl != "" {
+		jwks = newJwksKeySet(
+			appCtx,
+			logger,
+			cfg.JwksUrl,
+			cfg.JwksRefreshInterval.Duration(),
+			cfg.JwksMinRefreshInterval.Duration(),
+		)
+	}
+
 	return &JwtStrategy{
 		cfg:    cfg,
 		parser: jwt.NewParser(jwt.WithoutClaimsValidation()),
 		keys:   keys,
+		jwks:   jwks,
 	}, nil
 }
 
@@ -57,13 +74,13 @@ func (s *JwtStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*commo
 		}
 	}
 
-	key, err := s.findVerificationKey(token)
+	key, err := s.findVerificationKey(ctx, token)
 	if err != nil {
 		return nil, common.NewErrAuthUnauthorized("jwt", err.Error())
 	}
 
-	// Verify the signature
-	token, err = jwt.Parse(ap.Jwt.Token, key)
+	// Verify the signature, time-based claims are validated separately to allow for clock skew
+	token, err = s.parser.Parse(ap.Jwt.Token, key)
 	if err != nil {
 		return nil, common.NewErrAuthUnauthorized("jwt", "invalid signature")
 	}
@@ -77,22 +94,145 @@ func (s *JwtStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*commo
 		return nil, common.NewErrAuthUnauthorized("jwt", err.Error())
 	}
 
-	id, ok := claims["sub"].(string)
-	if !ok {
-		return nil, common.NewErrAuthUnauthorized("jwt", "missing 'sub' claim to be used as user id")
+	if s.cfg.ClaimsMapping == nil {
+		id, ok := claims["sub"].(string)
+		if !ok {
+			return nil, common.NewErrAuthUnauthorized("jwt", "missing 'sub' claim to be used as user id")
+		}
+		return &common.User{
+			Id: id,
+		}, nil
 	}
 
-	return &common.User{
-		Id: id,
-	}, nil
+	user, err := s.mapClaimsToUser(claims)
+	if err != nil {
+		return nil, common.NewErrAuthUnauthorized("jwt", err.Error())
+	}
+	return user, nil
 }
 
-func (s *JwtStrategy) findVerificationKey(token *jwt.Token) (jwt.Keyfunc, error) {
+func (s *JwtStrategy) mapClaimsToUser(claims jwt.MapClaims) (*common.User, error) {
+	mapping := s.cfg.ClaimsMapping
+	user := &common.User{}
+
+	id, ok := lookupClaim(claims, mapping.UserId).(string)
+	if !ok || id == "" {
+		return nil, fmt.Errorf("missing '%s' claim to be used as user id", mapping.UserId)
+	}
+	user.Id = id
+
+	if mapping.RateLimitBudget != "" {
+		if v := lookupClaim(claims, mapping.RateLimitBudget); v != nil {
+			budget, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("the '%s' claim must be a string to be used as rate limit budget", mapping.RateLimitBudget)
+			}
+			user.RateLimitBudget = budget
+		}
+	}
+
+	if mapping.PerSecondRateLimit != "" {
+		if v := lookupClaim(claims, mapping.PerSecondRateLimit); v != nil {
+			var limit int64
+			var err error
+			switch n := v.(type) {
+			case float64:
+				limit = int64(n)
+			case string:
+				limit, err = strconv.ParseInt(n, 10, 64)
+			default:
+				err = fmt.Errorf("unexpected type %T", v)
+			}
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("the '%s' claim must be a non-negative number to be used as per-second rate limit", mapping.PerSecondRateLimit)
+			}
+			user.PerSecondRateLimit = limit
+		}
+	}
+
+	if mapping.AllowedNetworks != "" {
+		networks, err := claimAsStrings(claims, mapping.AllowedNetworks)
+		if err != nil {
+			return nil, err
+		}
+		user.AllowedNetworks = normalizeNetworkIds(networks)
+	}
+
+	if mapping.AllowedMethods != "" {
+		methods, err := claimAsStrings(claims, mapping.AllowedMethods)
+		if err != nil {
+			return nil, err
+		}
+		user.AllowedMethods = methods
+	}
+
+	return user, nil
+}
+
+// lookupClaim resolves a dot-separated path of (nested) claims, returning nil if it does not exist.
+func lookupClaim(claims jwt.MapClaims, path string) interface{} {
+	if v, ok := claims[path]; ok {
+		return v
+	}
+	var current interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current, ok = obj[part]
+		if !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+// claimAsStrings accepts either an array of values or a space/comma-separated string (e.g. like the "scope" claim).
+// A missing or empty claim is an error, otherwise a token without it would not be restricted at all.
+func claimAsStrings(claims jwt.MapClaims, path string) ([]string, error) {
+	var values []string
+	switch t := lookupClaim(claims, path).(type) {
+	case nil:
+	case string:
+		values = strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == ' ' })
+	case []interface{}:
+		for _, item := range t {
+			switch iv := item.(type) {
+			case string:
+				values = append(values, iv)
+			case float64:
+				values = append(values, strconv.FormatInt(int64(iv), 10))
+			default:
+				return nil, fmt.Errorf("the '%s' claim must only contain strings", path)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("the '%s' claim must be a list or a string", path)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing '%s' claim, use \"*\" to allow everything", path)
+	}
+	return values, nil
+}
+
+func (s *JwtStrategy) findVerificationKey(ctx context.Context, token *jwt.Token) (jwt.Keyfunc, error) {
 	kid, ok := token.Header["kid"].(string)
 	if ok {
 		if key, exists := s.keys[kid]; exists {
 			return key, nil
 		}
+		if s.jwks != nil {
+			if key, exists := s.jwks.Key(ctx, kid); exists {
+				if key.alg != "" && key.alg != token.Method.Alg() {
+					return nil, fmt.Errorf("signing method does not match the algorithm of key %s", kid)
+				}
+				if !isCompatibleKey(key.key, token.Method) {
+					return nil, fmt.Errorf("signing method is not compatible with key %s", kid)
+				}
+				return func(*jwt.Token) (interface{}, error) { return key.key, nil }, nil
+			}
+		}
 	}
 
 	// If no kid is provided or the kid doesn't match, try all keys
@@ -101,13 +241,28 @@ func (s *JwtStrategy) findVerificationKey(token *jwt.Token) (jwt.Keyfunc, error)
 			return key, nil
 		}
 	}
+	if s.jwks != nil && !ok {
+		for _, key := range s.jwks.Keys() {
+			if (key.alg == "" || key.alg == token.Method.Alg()) && isCompatibleKey(key.key, token.Method) {
+				return func(*jwt.Token) (interface{}, error) { return key.key, nil }, nil
+			}
+		}
+	}
 
 	return nil, fmt.Errorf("no suitable verification key found")
 }
 
 func (s *JwtStrategy) validateClaims(claims jwt.MapClaims) error {
-	if err := claims.Valid(); err != nil {
-		return fmt.Errorf("invalid standard claims: %w", err)
+	now := time.Now()
+	skew := s.cfg.ClockSkew.Duration()
+	if !claims.VerifyExpiresAt(now.Add(-skew).Unix(), false) {
+		return fmt.Errorf("invalid standard claims: token is expired")
+	}
+	if !claims.VerifyIssuedAt(now.Add(skew).Unix(), false) {
+		return fmt.Errorf("invalid standard claims: token used before issued")
+	}
+	if !claims.VerifyNotBefore(now.Add(skew).Unix(), false) {
+		return fmt.Errorf("invalid standard claims: token is not valid yet")
 	}
 
 	if len(s.cfg.AllowedIssuers) > 0 {
@@ -174,6 +329,10 @@ func isCompatibleKeyType(keyFn jwt.Keyfunc, method jwt.SigningMethod) bool {
 	if err != nil {
 		return false
 	}
+	return isCompatibleKey(key, method)
+}
+
+func isCompatibleKey(key interface{}, method jwt.SigningMethod) bool {
 	switch method.(type) {
 	case *jwt.SigningMethodRSA:
 		_, ok := key.(*rsa.PublicKey)
@@ -184,6 +343,9 @@ func isCompatibleKeyType(keyFn jwt.Keyfunc, method jwt.SigningMethod) bool {
 	case *jwt.SigningMethodHMAC:
 		_, ok := key.([]byte)
 		return ok
+	case *jwt.SigningMethodEd25519:
+		_, ok := key.(ed25519.PublicKey)
+		return ok
 	default:
 		return false
 	}
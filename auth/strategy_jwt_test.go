@@ -0,0 +1,355 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/upstream"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testJwksServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	requests atomic.Int32
+	// blocked, when set, holds responses until it is closed
+	blocked chan struct{}
+}
+
+func newTestJwksServer(t *testing.T) *testJwksServer {
+	s := &testJwksServer{keys: make(map[string]*rsa.PrivateKey)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.mu.Lock()
+		blocked := s.blocked
+		s.mu.Unlock()
+		if blocked != nil {
+			<-blocked
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		keys := []map[string]string{}
+		for kid, key := range s.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testJwksServer) rotate(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = map[string]*rsa.PrivateKey{kid: key}
+	return key
+}
+
+func signTestJwt(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestJwtStrategy(t *testing.T, cfg *common.JwtStrategyConfig) *JwtStrategy {
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	logger := zerolog.Nop()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	strategy, err := NewJwtStrategy(ctx, &logger, cfg)
+	require.NoError(t, err)
+	return strategy
+}
+
+func jwtPayload(token string) *AuthPayload {
+	return &AuthPayload{Type: common.AuthTypeJwt, Jwt: &JwtPayload{Token: token}}
+}
+
+func TestJwtStrategy_Jwks(t *testing.T) {
+	server := newTestJwksServer(t)
+	key1 := server.rotate(t, "key-1")
+
+	strategy := newTestJwtStrategy(t, &common.JwtStrategyConfig{
+		JwksUrl:                server.URL,
+		JwksRefreshInterval:    common.Duration(time.Hour),
+		JwksMinRefreshInterval: common.Duration(time.Nanosecond),
+	})
+
+	t.Run("AcceptsTokenSignedByPublishedKey", func(t *testing.T) {
+		token := signTestJwt(t, key1, "key-1", jwt.MapClaims{
+			"sub": "user-1",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		})
+		user, err := strategy.Authenticate(context.Background(), jwtPayload(token))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", user.Id)
+	})
+
+	t.Run("RefreshesKeysOnUnknownKid", func(t *testing.T) {
+		key2 := server.rotate(t, "key-2")
+		requestsBefore := server.requests.Load()
+
+		token := signTestJwt(t, key2, "key-2", jwt.MapClaims{"sub": "user-2"})
+		user, err := strategy.Authenticate(context.Background(), jwtPayload(token))
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", user.Id)
+		assert.Equal(t, requestsBefore+1, server.requests.Load())
+
+		// The rotated-out key must no longer be accepted
+		token = signTestJwt(t, key1, "key-1", jwt.MapClaims{"sub": "user-1"})
+		_, err = strategy.Authenticate(context.Background(), jwtPayload(token))
+		assert.Error(t, err)
+	})
+
+	t.Run("RejectsTokenSignedByAnotherKeyWithSameKid", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		token := signTestJwt(t, other, "key-2", jwt.MapClaims{"sub": "attacker"})
+		_, err = strategy.Authenticate(context.Background(), jwtPayload(token))
+		assert.Error(t, err)
+	})
+}
+
+func TestJwtStrategy_ThrottlesRefreshesOnUnknownKid(t *testing.T) {
+	server := newTestJwksServer(t)
+	key := server.rotate(t, "key-1")
+
+	strategy := newTestJwtStrategy(t, &common.JwtStrategyConfig{
+		JwksUrl:                server.URL,
+		JwksRefreshInterval:    common.Duration(time.Hour),
+		JwksMinRefreshInterval: common.Duration(time.Hour),
+	})
+	require.Equal(t, int32(1), server.requests.Load())
+
+	for i := 0; i < 5; i++ {
+		token := signTestJwt(t, key, "unknown", jwt.MapClaims{"sub": "user-1"})
+		_, err := strategy.Authenticate(context.Background(), jwtPayload(token))
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(1), server.requests.Load(), "unknown kids must not be able to flood the JWKS url")
+}
+
+func TestJwtStrategy_DoesNotWaitForRefreshInFlight(t *testing.T) {
+	server := newTestJwksServer(t)
+	key := server.rotate(t, "key-1")
+
+	strategy := newTestJwtStrategy(t, &common.JwtStrategyConfig{
+		JwksUrl:                server.URL,
+		JwksRefreshInterval:    common.Duration(time.Hour),
+		JwksMinRefreshInterval: common.Duration(time.Nanosecond),
+	})
+
+	blocked := make(chan struct{})
+	server.mu.Lock()
+	server.blocked = blocked
+	server.mu.Unlock()
+	defer close(blocked)
+
+	token := signTestJwt(t, key, "unknown", jwt.MapClaims{"sub": "user-1"})
+	go func() {
+		_, _ = strategy.Authenticate(context.Background(), jwtPayload(token))
+	}()
+	require.Eventually(t, func() bool {
+		return server.requests.Load() == 2
+	}, time.Second, 10*time.Millisecond, "refresh for the unknown kid must be in flight")
+
+	start := time.Now()
+	_, err := strategy.Authenticate(context.Background(), jwtPayload(token))
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "unknown kid must be rejected without waiting for the refresh in flight")
+}
+
+func TestJwtStrategy_ClockSkew(t *testing.T) {
+	server := newTestJwksServer(t)
+	key := server.rotate(t, "key-1")
+
+	cases := []struct {
+		name    string
+		skew    time.Duration
+		claims  jwt.MapClaims
+		wantErr bool
+	}{
+		{
+			name:    "ExpiredWithoutSkew",
+			claims:  jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()},
+			wantErr: true,
+		},
+		{
+			name:   "ExpiredWithinSkew",
+			skew:   time.Minute,
+			claims: jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()},
+		},
+		{
+			name:    "ExpiredBeyondSkew",
+			skew:    time.Minute,
+			claims:  jwt.MapClaims{"exp": time.Now().Add(-2 * time.Minute).Unix()},
+			wantErr: true,
+		},
+		{
+			name:    "NotBeforeInFuture",
+			claims:  jwt.MapClaims{"nbf": time.Now().Add(10 * time.Second).Unix()},
+			wantErr: true,
+		},
+		{
+			name:   "NotBeforeWithinSkew",
+			skew:   time.Minute,
+			claims: jwt.MapClaims{"nbf": time.Now().Add(10 * time.Second).Unix()},
+		},
+		{
+			name:    "IssuedInFutureBeyondSkew",
+			skew:    time.Minute,
+			claims:  jwt.MapClaims{"iat": time.Now().Add(2 * time.Minute).Unix()},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategy := newTestJwtStrategy(t, &common.JwtStrategyConfig{
+				JwksUrl:   server.URL,
+				ClockSkew: common.Duration(tc.skew),
+			})
+			tc.claims["sub"] = "user-1"
+			_, err := strategy.Authenticate(context.Background(), jwtPayload(signTestJwt(t, key, "key-1", tc.claims)))
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJwtStrategy_ClaimsMapping(t *testing.T) {
+	server := newTestJwksServer(t)
+	key := server.rotate(t, "key-1")
+
+	logger := zerolog.Nop()
+	rlr, err := upstream.NewRateLimitersRegistry(&common.RateLimiterConfig{
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "free",
+				Rules: []*common.RateLimitRuleConfig{
+					{Method: "*", MaxCount: 1, Period: common.Duration(time.Hour)},
+				},
+			},
+		},
+	}, &logger)
+	require.NoError(t, err)
+
+	cfg := &common.AuthConfig{
+		Strategies: []*common.AuthStrategyConfig{
+			{
+				Type: common.AuthTypeJwt,
+				Jwt: &common.JwtStrategyConfig{
+					JwksUrl: server.URL,
+					ClaimsMapping: &common.JwtClaimsMappingConfig{
+						UserId:             "app.tenant",
+						RateLimitBudget:    "tier",
+						PerSecondRateLimit: "rps",
+						AllowedNetworks:    "networks",
+						AllowedMethods:     "scope",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	registry, err := NewAuthRegistry(context.Background(), &logger, "test", cfg, rlr)
+	require.NoError(t, err)
+
+	authenticate := func(method string, claims jwt.MapClaims) (*common.User, error) {
+		return registry.Authenticate(context.Background(), method, jwtPayload(signTestJwt(t, key, "key-1", claims)))
+	}
+
+	t.Run("MapsClaimsToUser", func(t *testing.T) {
+		user, err := authenticate("eth_call", jwt.MapClaims{
+			"app":      map[string]interface{}{"tenant": "acme"},
+			"networks": []interface{}{1, "evm:137"},
+			"scope":    "eth_call eth_getLogs",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "acme", user.Id)
+		assert.Equal(t, []string{"evm:1", "evm:137"}, user.AllowedNetworks)
+		assert.True(t, user.IsNetworkAllowed("evm:137"))
+		assert.False(t, user.IsNetworkAllowed("evm:10"))
+	})
+
+	t.Run("RejectsMethodNotAllowedByClaims", func(t *testing.T) {
+		_, err := authenticate("eth_sendRawTransaction", jwt.MapClaims{
+			"app":      map[string]interface{}{"tenant": "acme"},
+			"networks": "*",
+			"scope":    "eth_call",
+		})
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got %v", err)
+	})
+
+	t.Run("RejectsTokenWithoutRestrictionClaims", func(t *testing.T) {
+		_, err := authenticate("eth_call", jwt.MapClaims{
+			"app":   map[string]interface{}{"tenant": "acme"},
+			"scope": "*",
+		})
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got %v", err)
+	})
+
+	t.Run("EnforcesPerSecondRateLimitOfUser", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"app":      map[string]interface{}{"tenant": "limited"},
+			"networks": "*",
+			"scope":    "*",
+			"rps":      2,
+		}
+		_, err := authenticate("eth_call", claims)
+		require.NoError(t, err)
+		_, err = authenticate("eth_call", claims)
+		require.NoError(t, err)
+		_, err = authenticate("eth_call", claims)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthRateLimitRuleExceeded), "expected rate limit error, got %v", err)
+	})
+
+	t.Run("UsesBudgetFromClaims", func(t *testing.T) {
+		claims := jwt.MapClaims{
+			"app":      map[string]interface{}{"tenant": "free-user"},
+			"networks": "*",
+			"scope":    "*",
+			"tier":     "free",
+		}
+		_, err := authenticate("eth_call", claims)
+		require.NoError(t, err)
+		_, err = authenticate("eth_call", claims)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthRateLimitRuleExceeded), "expected rate limit error, got %v", err)
+
+		claims["tier"] = "unknown"
+		_, err = authenticate("eth_call", claims)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got %v", err)
+	})
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erpc/erpc/common"
+)
+
+type MtlsStrategy struct {
+	cfg   *common.MtlsStrategyConfig
+	roots *x509.CertPool
+}
+
+var _ AuthStrategy = &MtlsStrategy{}
+
+func NewMtlsStrategy(cfg *common.MtlsStrategyConfig) (*MtlsStrategy, error) {
+	caBundle, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("failed to parse any CA certificate from %s", cfg.CAFile)
+	}
+
+	return &MtlsStrategy{
+		cfg:   cfg,
+		roots: roots,
+	}, nil
+}
+
+func (s *MtlsStrategy) Supports(ap *AuthPayload) bool {
+	return ap.Type == common.AuthTypeMtls
+}
+
+func (s *MtlsStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*common.User, error) {
+	if ap.Mtls == nil || len(ap.Mtls.PeerCertificates) == 0 {
+		return nil, common.NewErrAuthUnauthorized("mtls", "no client certificate presented")
+	}
+
+	// The server only requests client certificates, so the chain is verified here against the CAs of this strategy
+	leaf := ap.Mtls.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range ap.Mtls.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         s.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, common.NewErrAuthUnauthorized("mtls", fmt.Sprintf("invalid client certificate: %v", err))
+	}
+
+	userId := s.identity(leaf)
+	if userId == "" {
+		return nil, common.NewErrAuthUnauthorized("mtls", fmt.Sprintf("client certificate has no %s to identify the user", s.cfg.UserIdFrom))
+	}
+
+	sans := certificateSANs(leaf)
+	if san, denied := matchAnySAN(s.cfg.DeniedSANs, sans); denied {
+		return nil, common.NewErrAuthForbidden("mtls", userId, fmt.Sprintf("client certificate SAN %s is denied", san))
+	}
+	if len(s.cfg.AllowedSANs) > 0 {
+		if _, allowed := matchAnySAN(s.cfg.AllowedSANs, sans); !allowed {
+			return nil, common.NewErrAuthForbidden("mtls", userId, "client certificate has no allowed SAN")
+		}
+	}
+
+	user := &common.User{
+		Id: userId,
+	}
+	for _, budget := range s.cfg.RateLimitBudgets {
+		if match, err := common.WildcardMatch(budget.Identity, userId); err == nil && match {
+			user.RateLimitBudget = budget.RateLimitBudget
+			break
+		}
+	}
+
+	return user, nil
+}
+
+func (s *MtlsStrategy) identity(cert *x509.Certificate) string {
+	switch s.cfg.UserIdFrom {
+	case common.MtlsIdentitySourceSpiffeId:
+		for _, uri := range cert.URIs {
+			if strings.EqualFold(uri.Scheme, "spiffe") {
+				return uri.String()
+			}
+		}
+	case common.MtlsIdentitySourceUriSan:
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	case common.MtlsIdentitySourceDnsSan:
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case common.MtlsIdentitySourceEmailSan:
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+	case common.MtlsIdentitySourceCommonName:
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+func certificateSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.URIs)+len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses))
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+func matchAnySAN(patterns []string, sans []string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, san := range sans {
+			if match, err := common.WildcardMatch(pattern, san); err == nil && match {
+				return san, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) writePEM(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0600))
+	return path
+}
+
+func (ca *testCA) issue(t *testing.T, commonName string, uris []string, usage x509.ExtKeyUsage) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	for _, raw := range uris {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		tmpl.URIs = append(tmpl.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func mtlsPayload(t *testing.T, certs ...*x509.Certificate) *AuthPayload {
+	ap, err := NewPayloadFromHttp("eth_call", "10.0.0.1:1234", http.Header{}, url.Values{}, &tls.ConnectionState{PeerCertificates: certs}, nil)
+	require.NoError(t, err)
+	return ap
+}
+
+func TestMtlsStrategy(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := &common.MtlsStrategyConfig{
+		CAFile:      ca.writePEM(t),
+		AllowedSANs: []string{"spiffe://example.org/*"},
+		DeniedSANs:  []string{"spiffe://example.org/ns/staging/*"},
+		RateLimitBudgets: []*common.MtlsRateLimitBudgetConfig{
+			{Identity: "spiffe://example.org/ns/prod/*", RateLimitBudget: "prod"},
+			{Identity: "*", RateLimitBudget: "default"},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	strategy, err := NewMtlsStrategy(cfg)
+	require.NoError(t, err)
+
+	t.Run("MapsSpiffeIdToUser", func(t *testing.T) {
+		cert := ca.issue(t, "indexer", []string{"spiffe://example.org/ns/prod/sa/indexer"}, x509.ExtKeyUsageClientAuth)
+		ap := mtlsPayload(t, cert)
+		require.True(t, strategy.Supports(ap))
+
+		user, err := strategy.Authenticate(context.Background(), ap)
+		require.NoError(t, err)
+		assert.Equal(t, "spiffe://example.org/ns/prod/sa/indexer", user.Id)
+		assert.Equal(t, "prod", user.RateLimitBudget)
+	})
+
+	t.Run("RejectsCertificateFromUnknownCA", func(t *testing.T) {
+		cert := newTestCA(t).issue(t, "indexer", []string{"spiffe://example.org/ns/prod/sa/indexer"}, x509.ExtKeyUsageClientAuth)
+		_, err := strategy.Authenticate(context.Background(), mtlsPayload(t, cert))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got %v", err)
+	})
+
+	t.Run("RejectsCertificateNotIssuedForClientAuth", func(t *testing.T) {
+		cert := ca.issue(t, "indexer", []string{"spiffe://example.org/ns/prod/sa/indexer"}, x509.ExtKeyUsageServerAuth)
+		_, err := strategy.Authenticate(context.Background(), mtlsPayload(t, cert))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got %v", err)
+	})
+
+	t.Run("RejectsDeniedSAN", func(t *testing.T) {
+		cert := ca.issue(t, "indexer", []string{"spiffe://example.org/ns/staging/sa/indexer"}, x509.ExtKeyUsageClientAuth)
+		_, err := strategy.Authenticate(context.Background(), mtlsPayload(t, cert))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got %v", err)
+	})
+
+	t.Run("RejectsSANNotAllowed", func(t *testing.T) {
+		cert := ca.issue(t, "indexer", []string{"spiffe://other.org/ns/prod/sa/indexer"}, x509.ExtKeyUsageClientAuth)
+		_, err := strategy.Authenticate(context.Background(), mtlsPayload(t, cert))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthForbidden), "expected forbidden error, got %v", err)
+	})
+
+	t.Run("RejectsCertificateWithoutSpiffeId", func(t *testing.T) {
+		cert := ca.issue(t, "indexer", nil, x509.ExtKeyUsageClientAuth)
+		_, err := strategy.Authenticate(context.Background(), mtlsPayload(t, cert))
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeAuthUnauthorized), "expected unauthorized error, got %v", err)
+	})
+
+	t.Run("DoesNotApplyWithoutCertificate", func(t *testing.T) {
+		ap, err := NewPayloadFromHttp("eth_call", "10.0.0.1:1234", http.Header{}, url.Values{}, &tls.ConnectionState{}, nil)
+		require.NoError(t, err)
+		assert.False(t, strategy.Supports(ap))
+	})
+}
@@ -42,7 +42,18 @@ func NewNetworkStrategy(cfg *common.NetworkStrategyConfig) (*NetworkStrategy, er
 	}
 
 	// Parse and store trusted proxies
-	for _, proxyStr := range cfg.TrustedProxies {
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	s.trustedProxies = trustedProxies
+
+	return s, nil
+}
+
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var trustedProxies []*net.IPNet
+	for _, proxyStr := range proxies {
 		_, proxy, err := net.ParseCIDR(proxyStr)
 		if err != nil {
 			ip := net.ParseIP(proxyStr)
@@ -51,10 +62,9 @@ func NewNetworkStrategy(cfg *common.NetworkStrategyConfig) (*NetworkStrategy, er
 			}
 			proxy = &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}
 		}
-		s.trustedProxies = append(s.trustedProxies, proxy)
+		trustedProxies = append(trustedProxies, proxy)
 	}
-
-	return s, nil
+	return trustedProxies, nil
 }
 
 func (s *NetworkStrategy) Supports(ap *AuthPayload) bool {
@@ -66,7 +76,7 @@ func (s *NetworkStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*c
 		return nil, common.NewErrAuthUnauthorized("network", "missing network payload")
 	}
 
-	clientIP := s.determineClientIP(ap.Network)
+	clientIP := resolveClientIP(ap.Network, s.trustedProxies)
 	if clientIP == nil {
 		return nil, common.NewErrAuthUnauthorized("network", "unable to determine client IP")
 	}
@@ -99,13 +109,13 @@ func (s *NetworkStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*c
 	return nil, common.NewErrAuthUnauthorized("network", fmt.Sprintf("IP %s is not allowed", clientIP.String()))
 }
 
-// determineClientIP extracts the actual client IP address from the NetworkPayload
+// resolveClientIP extracts the actual client IP address from the NetworkPayload
 // by checking X-Forwarded-For headers and falling back to RemoteAddr if needed.
 // It uses the following algorithm:
 // 1. Process X-Forwarded-For from right to left (most recent proxy to original client)
 // 2. Return the first non-trusted-proxy IP (which should be the actual client)
 // 3. Fall back to RemoteAddr if no client IP can be determined
-func (s *NetworkStrategy) determineClientIP(np *NetworkPayload) net.IP {
+func resolveClientIP(np *NetworkPayload, trustedProxies []*net.IPNet) net.IP {
 	// First, check the X-Forwarded-For header
 	for i := len(np.ForwardProxies) - 1; i >= 0; i-- {
 		ipStr := strings.TrimSpace(np.ForwardProxies[i])
@@ -116,7 +126,7 @@ func (s *NetworkStrategy) determineClientIP(np *NetworkPayload) net.IP {
 		if ip == nil {
 			continue // Skip invalid IPs
 		}
-		if !s.isTrustedProxy(ip) {
+		if !isTrustedProxy(trustedProxies, ip) {
 			return ip // Found the client IP
 		}
 	}
@@ -130,8 +140,8 @@ func (s *NetworkStrategy) determineClientIP(np *NetworkPayload) net.IP {
 	return net.ParseIP(remoteIP)
 }
 
-func (s *NetworkStrategy) isTrustedProxy(ip net.IP) bool {
-	for _, proxy := range s.trustedProxies {
+func isTrustedProxy(trustedProxies []*net.IPNet, ip net.IP) bool {
+	for _, proxy := range trustedProxies {
 		if proxy.Contains(ip) {
 			return true
 		}
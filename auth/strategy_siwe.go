@@ -2,21 +2,114 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
+	"github.com/dgraph-io/ristretto/v2"
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/rs/zerolog"
 	"github.com/spruceid/siwe-go"
 )
 
+const (
+	siweNonceKeyPrefix     = "erpc_siwe_nonce"
+	siweNonceUsedKeyPrefix = "erpc_siwe_nonce_used"
+	siweSessionKeyPrefix   = "erpc_siwe_session"
+	siweSessionTokenPrefix = "siwe_"
+	siweRangeKey           = "value"
+
+	// SiweMethodNonce issues a nonce for wallets to sign in with
+	SiweMethodNonce = "erpc_siweNonce"
+	// SiweMethodSession exchanges a signed message for a session token
+	SiweMethodSession = "erpc_siweSession"
+)
+
+// IsSiweMethod tells whether the method is handled by eRPC to sign in with SIWE, before any authentication.
+func IsSiweMethod(method string) bool {
+	return method == SiweMethodNonce || method == SiweMethodSession
+}
+
+// NewSiwePayload builds the payload of a message (plain or base64-encoded) and its signature.
+func NewSiwePayload(message, signature string) *SiwePayload {
+	return &SiwePayload{
+		Message:   normalizeSiweMessage(message),
+		Signature: signature,
+	}
+}
+
 type SiweStrategy struct {
-	cfg *common.SiweStrategyConfig
+	logger         *zerolog.Logger
+	cfg            *common.SiweStrategyConfig
+	trustedProxies []*net.IPNet
+
+	// connector is nil when nonces are not enforced
+	connector data.Connector
+	nonces    *usageCounters
+	sessions  *ristretto.Cache[string, *siweSession]
+}
+
+// SiweNonce is issued to wallets to be included in the message they sign, it can only be used once.
+type SiweNonce struct {
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SiweSessionToken is returned in exchange of a signed message, to be sent via "X-Siwe-Session" header
+// instead of the message and signature.
+type SiweSessionToken struct {
+	Token     string    `json:"token"`
+	Address   string    `json:"address"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type siweSession struct {
+	UserId    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 var _ AuthStrategy = &SiweStrategy{}
 
-func NewSiweStrategy(cfg *common.SiweStrategyConfig) *SiweStrategy {
-	return &SiweStrategy{cfg: cfg}
+func NewSiweStrategy(appCtx context.Context, logger *zerolog.Logger, cfg *common.SiweStrategyConfig) (*SiweStrategy, error) {
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	s := &SiweStrategy{
+		logger:         logger,
+		cfg:            cfg,
+		trustedProxies: trustedProxies,
+	}
+	if cfg.Connector == nil {
+		return s, nil
+	}
+
+	connector, err := data.NewConnector(appCtx, logger, cfg.Connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SIWE nonces connector: %w", err)
+	}
+	s.connector = connector
+	s.nonces = &usageCounters{connector: connector}
+
+	if cfg.SessionTTL > 0 {
+		s.sessions, err = ristretto.NewCache(&ristretto.Config[string, *siweSession]{
+			NumCounters: 100000,
+			MaxCost:     10000,
+			BufferItems: 64,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SIWE sessions cache: %w", err)
+		}
+	}
+
+	return s, nil
 }
 
 func (s *SiweStrategy) Supports(ap *AuthPayload) bool {
@@ -28,30 +121,63 @@ func (s *SiweStrategy) Authenticate(ctx context.Context, ap *AuthPayload) (*comm
 		return nil, common.NewErrAuthUnauthorized("siwe", "missing SIWE payload")
 	}
 
-	// Parse the SIWE message
+	if ap.Siwe.SessionToken != "" {
+		return s.authenticateSession(ctx, ap.Siwe.SessionToken)
+	}
+
+	// All requests of a batch (or websocket connection) are authenticated by the same message, which consumes
+	// its nonce only once
+	user, err := ap.Digest.verifyOnce(s, func() (*common.User, error) {
+		user, _, err := s.authenticateMessage(ctx, ap.Siwe)
+		return user, err
+	})
+	if err != nil || ap.Digest == nil {
+		return user, err
+	}
+
+	// A websocket connection might outlive the message it was authenticated with
 	message, err := siwe.ParseMessage(ap.Siwe.Message)
 	if err != nil {
 		return nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to parse SIWE message: %s", err))
 	}
+	if ok, err := message.ValidNow(); !ok {
+		return nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("SIWE message expired: %s", err))
+	}
+	return user, nil
+}
+
+func (s *SiweStrategy) authenticateMessage(ctx context.Context, sp *SiwePayload) (*common.User, *siwe.Message, error) {
+	// Parse the SIWE message
+	message, err := siwe.ParseMessage(sp.Message)
+	if err != nil {
+		return nil, nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to parse SIWE message: %s", err))
+	}
 
 	// Verify the signature
-	if _, err := message.VerifyEIP191(ap.Siwe.Signature); err != nil {
-		return nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to verify SIWE signature: %s", err))
+	if _, err := message.VerifyEIP191(sp.Signature); err != nil {
+		return nil, nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to verify SIWE signature: %s", err))
 	}
 
 	// Check if the domain is allowed
 	if !s.isDomainAllowed(message.GetDomain()) {
-		return nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("domain %s is not allowed", message.GetDomain()))
+		return nil, nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("domain %s is not allowed", message.GetDomain()))
 	}
 
 	// Verify the message is not expired
 	if ok, err := message.ValidNow(); !ok {
-		return nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("SIWE message expired: %s", err))
+		return nil, nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("SIWE message expired: %s", err))
+	}
+
+	// Nonces are only consumed for valid signatures, otherwise anyone could burn issued nonces
+	if s.connector != nil {
+		if err := s.consumeNonce(ctx, message.GetNonce()); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	return &common.User{
 		Id: strings.ToLower(message.GetAddress().String()),
-	}, nil
+	}, message, nil
 }
 
 func (s *SiweStrategy) isDomainAllowed(domain string) bool {
@@ -63,3 +189,152 @@ func (s *SiweStrategy) isDomainAllowed(domain string) bool {
 
 	return false
 }
+
+// EnforcesNonces tells whether messages must use a nonce issued via IssueNonce.
+func (s *SiweStrategy) EnforcesNonces() bool {
+	return s.connector != nil
+}
+
+// NonceRateLimitPerIp is how many nonces a client IP can be issued per second, 0 means unlimited.
+func (s *SiweStrategy) NonceRateLimitPerIp() uint {
+	return s.cfg.NonceRateLimitPerIp
+}
+
+// NonceClientIP resolves the client IP that nonces are limited by, X-Forwarded-For is only honoured when
+// sent by a trusted proxy so that clients cannot spoof it to get more nonces.
+func (s *SiweStrategy) NonceClientIP(ap *AuthPayload) string {
+	if ap == nil || ap.Network == nil {
+		return ""
+	}
+	if ip := resolvePeerClientIP(ap.Network, s.trustedProxies); ip != nil {
+		return ip.String()
+	}
+	return ap.Network.Address
+}
+
+// IssueNonce generates a nonce and stores it until it is used or expires.
+func (s *SiweStrategy) IssueNonce(ctx context.Context) (*SiweNonce, error) {
+	if s.connector == nil {
+		return nil, fmt.Errorf("SIWE strategy has no connector to store nonces")
+	}
+	ttl := s.cfg.NonceTTL.Duration()
+	nonce := &SiweNonce{
+		Nonce:     siwe.GenerateNonce(),
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	if err := s.connector.Set(ctx, siweNonceKey(nonce.Nonce), siweRangeKey, []byte("1"), &ttl); err != nil {
+		return nil, fmt.Errorf("failed to store SIWE nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+func (s *SiweStrategy) consumeNonce(ctx context.Context, nonce string) error {
+	if _, err := s.connector.Get(ctx, data.ConnectorMainIndex, siweNonceKey(nonce), siweRangeKey, nil); err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return common.NewErrAuthUnauthorized("siwe", "SIWE nonce was not issued by this server or has expired")
+		}
+		s.logger.Error().Err(err).Msg("failed to look up SIWE nonce")
+		return common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to look up SIWE nonce: %v", err))
+	}
+
+	// Incrementing is atomic (or done under a lock), so only the first use of a nonce gets 1 across all instances
+	used, err := s.nonces.increment(ctx, fmt.Sprintf("%s:%s", siweNonceUsedKeyPrefix, nonce), 1, s.cfg.NonceTTL.Duration())
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to record use of SIWE nonce")
+		return common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to record use of SIWE nonce: %v", err))
+	}
+	if used > 1 {
+		return common.NewErrAuthUnauthorized("siwe", "SIWE nonce has already been used")
+	}
+	return nil
+}
+
+// SupportsSessions tells whether signed messages can be exchanged for session tokens.
+func (s *SiweStrategy) SupportsSessions() bool {
+	return s.sessions != nil
+}
+
+// CreateSession verifies a signed message (consuming its nonce) and returns a session token of the signer,
+// which does not outlive the expiration time of the message.
+func (s *SiweStrategy) CreateSession(ctx context.Context, sp *SiwePayload) (*SiweSessionToken, error) {
+	if !s.SupportsSessions() {
+		return nil, fmt.Errorf("SIWE sessions are not enabled, set sessionTtl on the siwe strategy")
+	}
+
+	user, message, err := s.authenticateMessage(ctx, sp)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.SessionTTL.Duration())
+	if exp := message.GetExpirationTime(); exp != nil {
+		if messageExpiresAt, err := time.Parse(time.RFC3339, *exp); err == nil && messageExpiresAt.Before(expiresAt) {
+			expiresAt = messageExpiresAt
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate SIWE session token: %w", err)
+	}
+	token := siweSessionTokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	session := &siweSession{UserId: user.Id, ExpiresAt: expiresAt.UTC()}
+	value, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	ttl := expiresAt.Sub(now)
+	if err := s.connector.Set(ctx, siweSessionKey(token), siweRangeKey, value, &ttl); err != nil {
+		return nil, fmt.Errorf("failed to store SIWE session: %w", err)
+	}
+
+	return &SiweSessionToken{
+		Token:     token,
+		Address:   user.Id,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *SiweStrategy) authenticateSession(ctx context.Context, token string) (*common.User, error) {
+	if !s.SupportsSessions() {
+		return nil, common.NewErrAuthUnauthorized("siwe", "SIWE sessions are not enabled")
+	}
+
+	// Only a hash of the token is stored and used as cache key, so that the database does not hold usable tokens
+	key := siweSessionKey(token)
+	session, found := s.sessions.Get(key)
+	if !found {
+		value, err := s.connector.Get(ctx, data.ConnectorMainIndex, key, siweRangeKey, nil)
+		if err != nil {
+			if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+				return nil, common.NewErrAuthUnauthorized("siwe", "invalid or expired SIWE session")
+			}
+			return nil, common.NewErrAuthUnauthorized("siwe", fmt.Sprintf("failed to look up SIWE session: %v", err))
+		}
+		session = &siweSession{}
+		if err := json.Unmarshal(value, session); err != nil || session.UserId == "" {
+			return nil, common.NewErrAuthUnauthorized("siwe", "invalid SIWE session data")
+		}
+		if ttl := time.Until(session.ExpiresAt); ttl > 0 {
+			s.sessions.SetWithTTL(key, session, 1, ttl)
+		}
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		return nil, common.NewErrAuthUnauthorized("siwe", "invalid or expired SIWE session")
+	}
+
+	return &common.User{
+		Id: session.UserId,
+	}, nil
+}
+
+func siweNonceKey(nonce string) string {
+	return fmt.Sprintf("%s:%s", siweNonceKeyPrefix, nonce)
+}
+
+func siweSessionKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%s:%s", siweSessionKeyPrefix, hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog"
+	"github.com/spruceid/siwe-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signTestSiweMessage(t *testing.T, key *ecdsa.PrivateKey, nonce string) *SiwePayload {
+	return signTestSiweMessageExpiringAt(t, key, nonce, time.Now().Add(time.Hour))
+}
+
+func signTestSiweMessageExpiringAt(t *testing.T, key *ecdsa.PrivateKey, nonce string, expiresAt time.Time) *SiwePayload {
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	message, err := siwe.InitMessage("app.example.com", address, "https://app.example.com", nonce, map[string]interface{}{
+		"expirationTime": expiresAt.UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	signature, err := crypto.Sign(accounts.TextHash([]byte(message.String())), key)
+	require.NoError(t, err)
+	signature[64] += 27
+	return NewSiwePayload(message.String(), hexutil.Encode(signature))
+}
+
+func newTestSiweStrategy(t *testing.T, sessionTTL time.Duration) *SiweStrategy {
+	logger := zerolog.Nop()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(m.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cfg := &common.SiweStrategyConfig{
+		AllowedDomains: []string{"app.example.com"},
+		Connector: &common.ConnectorConfig{
+			Id:     "siwe",
+			Driver: common.DriverRedis,
+			Redis: &common.RedisConnectorConfig{
+				Addr:        m.Addr(),
+				InitTimeout: common.Duration(2 * time.Second),
+				GetTimeout:  common.Duration(2 * time.Second),
+				SetTimeout:  common.Duration(2 * time.Second),
+			},
+		},
+		SessionTTL: common.Duration(sessionTTL),
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	strategy, err := NewSiweStrategy(ctx, &logger, cfg)
+	require.NoError(t, err)
+	return strategy
+}
+
+func siwePayload(sp *SiwePayload) *AuthPayload {
+	return &AuthPayload{Type: common.AuthTypeSiwe, Siwe: sp, Digest: NewRequestDigest("POST", "/main/evm/1", nil)}
+}
+
+func TestSiweStrategy_Nonces(t *testing.T) {
+	strategy := newTestSiweStrategy(t, 0)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("AcceptsIssuedNonceOnlyOnce", func(t *testing.T) {
+		nonce, err := strategy.IssueNonce(ctx)
+		require.NoError(t, err)
+		sp := signTestSiweMessage(t, key, nonce.Nonce)
+
+		user, err := strategy.Authenticate(ctx, siwePayload(sp))
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex()), user.Id)
+
+		// A captured message and signature cannot be replayed
+		_, err = strategy.Authenticate(ctx, siwePayload(sp))
+		assert.ErrorContains(t, err, "nonce has already been used")
+	})
+
+	t.Run("AcceptsAllRequestsOfBatch", func(t *testing.T) {
+		nonce, err := strategy.IssueNonce(ctx)
+		require.NoError(t, err)
+		ap := siwePayload(signTestSiweMessage(t, key, nonce.Nonce))
+		for i := 0; i < 3; i++ {
+			_, err := strategy.Authenticate(ctx, ap)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("RejectsSharedMessageOnceExpired", func(t *testing.T) {
+		nonce, err := strategy.IssueNonce(ctx)
+		require.NoError(t, err)
+		expiresAt := time.Now().Add(2 * time.Second).Truncate(time.Second)
+		ap := siwePayload(signTestSiweMessageExpiringAt(t, key, nonce.Nonce, expiresAt))
+		_, err = strategy.Authenticate(ctx, ap)
+		require.NoError(t, err)
+
+		// e.g. a websocket connection authenticated with the message is still open
+		time.Sleep(time.Until(expiresAt) + 100*time.Millisecond)
+		_, err = strategy.Authenticate(ctx, ap)
+		assert.ErrorContains(t, err, "expired")
+	})
+
+	t.Run("RejectsNonceNotIssued", func(t *testing.T) {
+		_, err := strategy.Authenticate(ctx, siwePayload(signTestSiweMessage(t, key, siwe.GenerateNonce())))
+		assert.ErrorContains(t, err, "was not issued")
+	})
+
+	t.Run("DoesNotConsumeNonceOnInvalidSignature", func(t *testing.T) {
+		nonce, err := strategy.IssueNonce(ctx)
+		require.NoError(t, err)
+		other, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		forged := signTestSiweMessage(t, other, nonce.Nonce)
+		forged.Message = signTestSiweMessage(t, key, nonce.Nonce).Message
+		_, err = strategy.Authenticate(ctx, siwePayload(forged))
+		require.Error(t, err)
+
+		_, err = strategy.Authenticate(ctx, siwePayload(signTestSiweMessage(t, key, nonce.Nonce)))
+		assert.NoError(t, err)
+	})
+
+	t.Run("RejectsSessionsWhenDisabled", func(t *testing.T) {
+		nonce, err := strategy.IssueNonce(ctx)
+		require.NoError(t, err)
+		_, err = strategy.CreateSession(ctx, signTestSiweMessage(t, key, nonce.Nonce))
+		assert.Error(t, err)
+	})
+}
+
+func TestSiweStrategy_Sessions(t *testing.T) {
+	strategy := newTestSiweStrategy(t, 15*time.Minute)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	nonce, err := strategy.IssueNonce(ctx)
+	require.NoError(t, err)
+	session, err := strategy.CreateSession(ctx, signTestSiweMessage(t, key, nonce.Nonce))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), session.ExpiresAt, time.Minute)
+
+	t.Run("AuthenticatesWithSessionToken", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("X-Siwe-Session", session.Token)
+		ap, err := NewPayloadFromHttp("eth_call", "10.0.0.1:1234", headers, url.Values{}, nil, nil)
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			user, err := strategy.Authenticate(ctx, ap)
+			require.NoError(t, err)
+			assert.Equal(t, session.Address, user.Id)
+		}
+	})
+
+	t.Run("RejectsUnknownSessionToken", func(t *testing.T) {
+		_, err := strategy.Authenticate(ctx, siwePayload(&SiwePayload{SessionToken: "siwe_unknown"}))
+		assert.ErrorContains(t, err, "invalid or expired SIWE session")
+	})
+
+	t.Run("CannotReuseMessageForAnotherSession", func(t *testing.T) {
+		_, err := strategy.CreateSession(ctx, signTestSiweMessage(t, key, nonce.Nonce))
+		assert.ErrorContains(t, err, "nonce has already been used")
+	})
+}
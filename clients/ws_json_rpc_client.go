@@ -0,0 +1,708 @@
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bytedance/sonic/ast"
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/websocket"
+)
+
+type WsJsonRpcClient interface {
+	GetType() ClientType
+	SendRequest(ctx context.Context, req *common.NormalizedRequest) (*common.NormalizedResponse, error)
+}
+
+// WsSubscriptionHandler receives the "result" of each "eth_subscription" notification of a single subscription.
+// It is called from the read loop of the connection and must never block.
+type WsSubscriptionHandler func(result []byte)
+
+type wsSubscriptionHandlerKey struct{}
+
+// WithWsSubscriptionHandler attaches a handler to the context of an eth_subscribe request. The handler is bound
+// to the resulting subscription id before the response is returned, so that no notification is missed.
+// Sending eth_unsubscribe for that id (routed to the connection owning the subscription) removes the handler.
+func WithWsSubscriptionHandler(ctx context.Context, h WsSubscriptionHandler) context.Context {
+	return context.WithValue(ctx, wsSubscriptionHandlerKey{}, h)
+}
+
+// GenericWsJsonRpcClient multiplexes JSON-RPC requests over a small pool of persistent
+// websocket connections. Each outgoing request gets a client-unique wire id so that
+// concurrent requests with the same user-provided id can share the same connection,
+// and the original id is restored on the response before handing it back to the upstream.
+type GenericWsJsonRpcClient struct {
+	Url     *url.URL
+	headers map[string]string
+
+	projectId       string
+	upstream        common.Upstream
+	appCtx          context.Context
+	logger          *zerolog.Logger
+	isLogLevelTrace bool
+
+	wsCfg  *common.WebSocketUpstreamConfig
+	conns  []*wsConnection
+	nextRr atomic.Uint32
+	nextId atomic.Int64
+
+	// Extractor for architecture-specific error normalization
+	errorExtractor common.JsonRpcErrorExtractor
+}
+
+type wsConnection struct {
+	client *GenericWsJsonRpcClient
+	index  int
+
+	mu      sync.RWMutex
+	conn    *websocket.Conn
+	ready   chan struct{}
+	lastErr error
+	pending map[int64]*wsPendingRequest
+	// subscriptions only live as long as the socket they were created on
+	subscriptions map[string]WsSubscriptionHandler
+
+	writeMu sync.Mutex
+}
+
+type wsPendingRequest struct {
+	ctx        context.Context
+	request    *common.NormalizedRequest
+	originalId interface{}
+	response   chan *common.NormalizedResponse
+	err        chan error
+	// subscriptionHandler is bound to the subscription id returned by a successful eth_subscribe
+	subscriptionHandler WsSubscriptionHandler
+}
+
+// wsSyntheticHttpResponse is handed to the error extractor, which was designed around http responses.
+// A message received over an open websocket is equivalent to a 200 OK in http terms.
+var wsSyntheticHttpResponse = &http.Response{
+	StatusCode: http.StatusOK,
+	Header:     http.Header{},
+}
+
+func NewGenericWsJsonRpcClient(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	projectId string,
+	upstream common.Upstream,
+	parsedUrl *url.URL,
+	jsonRpcCfg *common.JsonRpcUpstreamConfig,
+	extractor common.JsonRpcErrorExtractor,
+) (WsJsonRpcClient, error) {
+	client := &GenericWsJsonRpcClient{
+		Url:             parsedUrl,
+		appCtx:          appCtx,
+		logger:          logger,
+		projectId:       projectId,
+		upstream:        upstream,
+		isLogLevelTrace: logger.GetLevel() == zerolog.TraceLevel,
+		errorExtractor:  extractor,
+	}
+
+	if jsonRpcCfg != nil {
+		if jsonRpcCfg.Headers != nil {
+			client.headers = jsonRpcCfg.Headers
+		}
+		if jsonRpcCfg.WebSocket != nil {
+			client.wsCfg = jsonRpcCfg.WebSocket
+		}
+	}
+	if client.wsCfg == nil {
+		client.wsCfg = &common.WebSocketUpstreamConfig{}
+		if err := client.wsCfg.SetDefaults(); err != nil {
+			return nil, err
+		}
+	}
+
+	client.conns = make([]*wsConnection, client.wsCfg.MaxConnections)
+	for i := range client.conns {
+		wc := &wsConnection{
+			client:        client,
+			index:         i,
+			ready:         make(chan struct{}),
+			pending:       make(map[int64]*wsPendingRequest),
+			subscriptions: make(map[string]WsSubscriptionHandler),
+		}
+		client.conns[i] = wc
+		go wc.run()
+	}
+
+	return client, nil
+}
+
+func (c *GenericWsJsonRpcClient) GetType() ClientType {
+	return ClientTypeWsJsonRpc
+}
+
+func (c *GenericWsJsonRpcClient) SendRequest(ctx context.Context, req *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	ctx, span := common.StartSpan(ctx, "WsJsonRpcClient.SendRequest",
+		trace.WithAttributes(
+			attribute.String("network.id", req.NetworkId()),
+			attribute.String("upstream.id", c.upstream.Id()),
+		),
+	)
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("request.id", fmt.Sprintf("%v", req.ID())),
+		)
+	}
+
+	startedAt := time.Now()
+	jrReq, err := req.JsonRpcRequest()
+	if err != nil || jrReq == nil {
+		method, _ := req.Method()
+		common.SetTraceSpanError(span, err)
+		return nil, common.NewErrUpstreamRequest(
+			err,
+			c.upstream,
+			req.NetworkId(),
+			method,
+			0, 0, 0, 0,
+		)
+	}
+
+	var wc *wsConnection
+	var subscriptionHandler WsSubscriptionHandler
+	jrReq.RLock()
+	switch jrReq.Method {
+	case "eth_subscribe":
+		subscriptionHandler, _ = ctx.Value(wsSubscriptionHandlerKey{}).(WsSubscriptionHandler)
+	case "eth_unsubscribe":
+		// Subscription ids are only known by the socket which created them
+		if len(jrReq.Params) > 0 {
+			if subId, ok := jrReq.Params[0].(string); ok {
+				wc = c.releaseSubscription(subId)
+			}
+		}
+	}
+	jrReq.RUnlock()
+
+	if wc == nil || !wc.isConnected() {
+		wc, err = c.pickConnection(ctx)
+		if err != nil {
+			common.SetTraceSpanError(span, err)
+			return nil, c.normalizeContextError(ctx, err, startedAt)
+		}
+	}
+
+	wireId := c.nextId.Add(1)
+	jrReq.RLock()
+	span.SetAttributes(attribute.String("request.method", jrReq.Method))
+	originalId := jrReq.ID
+	requestBody, err := common.SonicCfg.Marshal(common.JsonRpcRequest{
+		JSONRPC: jrReq.JSONRPC,
+		Method:  jrReq.Method,
+		Params:  jrReq.Params,
+		ID:      wireId,
+	})
+	jrReq.RUnlock()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	pr := &wsPendingRequest{
+		ctx:        ctx,
+		request:    req,
+		originalId: originalId,
+		response:   make(chan *common.NormalizedResponse, 1),
+		err:        make(chan error, 1),
+
+		subscriptionHandler: subscriptionHandler,
+	}
+
+	if err := wc.send(wireId, pr, requestBody); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	select {
+	case nr := <-pr.response:
+		return nr, nil
+	case err := <-pr.err:
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	case <-ctx.Done():
+		wc.forget(wireId)
+		err := c.normalizeContextError(ctx, ctx.Err(), startedAt)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	case <-c.appCtx.Done():
+		wc.forget(wireId)
+		return nil, common.NewErrEndpointRequestCanceled(c.appCtx.Err())
+	}
+}
+
+// pickConnection returns the next connected socket in round-robin order. When none
+// of the connections are ready (e.g. right after startup or during reconnection) it
+// waits up to the dial timeout for any of them to become available.
+func (c *GenericWsJsonRpcClient) pickConnection(ctx context.Context) (*wsConnection, error) {
+	ln := len(c.conns)
+	start := int(c.nextRr.Add(1))
+	for i := 0; i < ln; i++ {
+		wc := c.conns[(start+i)%ln]
+		if wc.isConnected() {
+			return wc, nil
+		}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.wsCfg.DialTimeout.Duration())
+	defer cancel()
+
+	readyCh := make(chan *wsConnection, ln)
+	for _, wc := range c.conns {
+		go func(wc *wsConnection) {
+			wc.mu.RLock()
+			ready := wc.ready
+			wc.mu.RUnlock()
+			select {
+			case <-ready:
+				readyCh <- wc
+			case <-waitCtx.Done():
+			}
+		}(wc)
+	}
+
+	select {
+	case wc := <-readyCh:
+		return wc, nil
+	case <-waitCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, wc := range c.conns {
+			wc.mu.RLock()
+			if wc.lastErr != nil {
+				lastErr = wc.lastErr
+			}
+			wc.mu.RUnlock()
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no websocket connection became available within %s", c.wsCfg.DialTimeout.String())
+		}
+		return nil, common.NewErrEndpointTransportFailure(c.Url, lastErr)
+	}
+}
+
+func (c *GenericWsJsonRpcClient) normalizeContextError(ctx context.Context, err error, startedAt time.Time) error {
+	if cause := context.Cause(ctx); cause != nil {
+		err = cause
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return common.NewErrEndpointRequestTimeout(time.Since(startedAt), err)
+	} else if errors.Is(err, context.Canceled) {
+		return common.NewErrEndpointRequestCanceled(err)
+	}
+	return err
+}
+
+func (c *GenericWsJsonRpcClient) dial() (*websocket.Conn, error) {
+	origin := &url.URL{Scheme: "http", Host: c.Url.Host}
+	if c.Url.Scheme == "wss" {
+		origin.Scheme = "https"
+	}
+	cfg, err := websocket.NewConfig(c.Url.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	cfg.Header = http.Header{}
+	cfg.Header.Set("User-Agent", fmt.Sprintf("erpc (%s/%s; Project/%s)", common.ErpcVersion, common.ErpcCommitSha, c.projectId))
+	for k, v := range c.headers {
+		cfg.Header.Set(k, v)
+	}
+	cfg.Dialer = &net.Dialer{
+		Timeout:   c.wsCfg.DialTimeout.Duration(),
+		KeepAlive: 30 * time.Second,
+	}
+	if c.Url.Scheme == "wss" {
+		cfg.TlsConfig = &tls.Config{
+			ServerName: c.Url.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.appCtx, c.wsCfg.DialTimeout.Duration())
+	defer cancel()
+	conn, err := cfg.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn.MaxPayloadBytes = c.wsCfg.MaxMessageSize
+	conn.PayloadType = websocket.TextFrame
+
+	return conn, nil
+}
+
+func (c *GenericWsJsonRpcClient) handleMessage(wc *wsConnection, msg []byte) {
+	if c.isLogLevelTrace {
+		if len(msg) > 20*1024 {
+			c.logger.Trace().Int("conn", wc.index).Str("head", string(msg[:20*1024])).Msgf("received websocket message from upstream (trimmed to first 20k)")
+		} else {
+			c.logger.Trace().Int("conn", wc.index).RawJSON("message", msg).Msgf("received websocket message from upstream")
+		}
+	}
+
+	searcher := ast.NewSearcher(string(msg))
+	searcher.CopyReturn = true
+	searcher.ConcurrentRead = false
+	searcher.ValidateJSON = false
+
+	rootNode, err := searcher.GetByPath()
+	if err != nil {
+		c.logger.Warn().Err(err).Int("conn", wc.index).Msgf("received unparsable websocket message from upstream")
+		return
+	}
+
+	if rootNode.TypeSafe() != ast.V_OBJECT {
+		c.logger.Warn().Int("conn", wc.index).Msgf("unexpected websocket message type (not an object): %s", string(msg))
+		return
+	}
+
+	// Server-initiated notifications (e.g. eth_subscription) carry a "method" and no "id"
+	if methodNode := rootNode.Get("method"); methodNode != nil && methodNode.Exists() {
+		c.handleNotification(wc, methodNode, rootNode.Get("params"))
+		return
+	}
+
+	jrResp, err := getJsonRpcResponseFromNode(rootNode)
+	if err != nil || jrResp == nil {
+		c.logger.Warn().Err(err).Int("conn", wc.index).Msgf("could not parse json-rpc response from websocket message: %s", string(msg))
+		return
+	}
+
+	var wireId int64
+	switch v := jrResp.ID().(type) {
+	case int64:
+		wireId = v
+	case nil:
+		if jrResp.Error != nil {
+			c.failPendingOnError(wc, jrResp)
+			return
+		}
+		c.logger.Warn().Int("conn", wc.index).Msgf("unexpected response received over websocket without an ID: %s", string(msg))
+		return
+	default:
+		c.logger.Warn().Int("conn", wc.index).Interface("id", v).Msgf("unexpected response received over websocket without a matching ID: %s", string(msg))
+		return
+	}
+
+	pr := wc.forget(wireId)
+	if pr == nil {
+		c.logger.Debug().Int64("wireId", wireId).Int("conn", wc.index).Msg("received websocket response for an unknown or already-finished request")
+		return
+	}
+
+	if err := jrResp.SetID(pr.originalId); err != nil {
+		pr.err <- err
+		return
+	}
+
+	if pr.subscriptionHandler != nil && jrResp.Error == nil {
+		var subId string
+		if err := common.SonicCfg.Unmarshal(jrResp.GetResultBytes(), &subId); err == nil && subId != "" {
+			wc.mu.Lock()
+			wc.subscriptions[subId] = pr.subscriptionHandler
+			wc.mu.Unlock()
+		}
+	}
+
+	nr := common.NewNormalizedResponse().
+		WithRequest(pr.request).
+		WithJsonRpcResponse(jrResp)
+
+	if cause := context.Cause(pr.ctx); cause != nil {
+		nr.Release()
+		pr.err <- cause
+		return
+	}
+
+	if err := c.normalizeJsonRpcError(nr); err != nil {
+		nr.Release()
+		pr.err <- err
+		return
+	}
+
+	pr.response <- nr
+}
+
+func (c *GenericWsJsonRpcClient) handleNotification(wc *wsConnection, methodNode *ast.Node, paramsNode *ast.Node) {
+	method, _ := methodNode.String()
+	if method != "eth_subscription" || paramsNode == nil || !paramsNode.Exists() {
+		c.logger.Debug().Int("conn", wc.index).Str("method", method).Msg("ignoring unexpected websocket notification")
+		return
+	}
+	subId, _ := paramsNode.Get("subscription").String()
+
+	wc.mu.RLock()
+	h := wc.subscriptions[subId]
+	wc.mu.RUnlock()
+	if h == nil {
+		c.logger.Debug().Int("conn", wc.index).Str("subscriptionId", subId).Msg("ignoring websocket notification of an unknown subscription")
+		return
+	}
+
+	resultNode := paramsNode.Get("result")
+	if resultNode == nil || !resultNode.Exists() {
+		return
+	}
+	raw, err := resultNode.Raw()
+	if err != nil {
+		return
+	}
+	h([]byte(raw))
+}
+
+// releaseSubscription removes the handler of a subscription and returns the connection that owns it, if any.
+func (c *GenericWsJsonRpcClient) releaseSubscription(subId string) *wsConnection {
+	for _, wc := range c.conns {
+		wc.mu.Lock()
+		_, ok := wc.subscriptions[subId]
+		delete(wc.subscriptions, subId)
+		wc.mu.Unlock()
+		if ok {
+			return wc
+		}
+	}
+	return nil
+}
+
+// failPendingOnError fails all requests in flight on the connection when an error arrives with a null id
+// (e.g. a parse error), since it cannot be matched to its request and the caller would otherwise wait until timeout.
+func (c *GenericWsJsonRpcClient) failPendingOnError(wc *wsConnection, jrResp *common.JsonRpcResponse) {
+	wc.mu.Lock()
+	pending := wc.pending
+	wc.pending = make(map[int64]*wsPendingRequest)
+	wc.mu.Unlock()
+
+	c.logger.Warn().Int("conn", wc.index).Int("pending", len(pending)).Interface("error", jrResp.Error).Msg("received json-rpc error without an ID over websocket, failing pending requests")
+
+	// The error is normalized once and shared by all pending requests, as it cannot be attributed to any of them
+	nr := common.NewNormalizedResponse().WithJsonRpcResponse(jrResp)
+	err := c.normalizeJsonRpcError(nr)
+	if err == nil {
+		err = common.NewErrJsonRpcExceptionInternal(
+			0,
+			common.JsonRpcErrorServerSideException,
+			"json-rpc error without an id received from upstream",
+			jrResp.Error,
+			map[string]interface{}{
+				"upstreamId": c.upstream.Id(),
+				"transport":  "websocket",
+			},
+		)
+	}
+	nr.Release()
+
+	for _, pr := range pending {
+		pr.err <- err
+	}
+}
+
+func (c *GenericWsJsonRpcClient) normalizeJsonRpcError(nr *common.NormalizedResponse) error {
+	jr, err := nr.JsonRpcResponse()
+	if err != nil {
+		return common.NewErrJsonRpcExceptionInternal(
+			0,
+			common.JsonRpcErrorParseException,
+			"could not parse json rpc response from upstream",
+			err,
+			map[string]interface{}{
+				"upstreamId": c.upstream.Id(),
+				"transport":  "websocket",
+			},
+		)
+	}
+
+	if e := c.errorExtractor.Extract(wsSyntheticHttpResponse, nr, jr, c.upstream); e != nil {
+		return e
+	}
+
+	if jr == nil || jr.Error == nil {
+		return nil
+	}
+
+	return common.NewErrJsonRpcExceptionInternal(
+		0,
+		common.JsonRpcErrorServerSideException,
+		"unknown json-rpc error",
+		jr.Error,
+		map[string]interface{}{
+			"upstreamId": c.upstream.Id(),
+			"transport":  "websocket",
+		},
+	)
+}
+
+// run keeps the connection alive for the lifetime of the app context, reconnecting
+// with exponential backoff (and jitter) whenever the socket drops.
+func (wc *wsConnection) run() {
+	c := wc.client
+	attempt := 0
+	for {
+		if c.appCtx.Err() != nil {
+			wc.failPending(common.NewErrEndpointRequestCanceled(c.appCtx.Err()))
+			return
+		}
+
+		conn, err := c.dial()
+		if err != nil {
+			wc.mu.Lock()
+			wc.lastErr = err
+			wc.mu.Unlock()
+			delay := wc.backoff(attempt)
+			attempt++
+			c.logger.Warn().Err(err).Int("conn", wc.index).Int("attempt", attempt).Dur("retryIn", delay).Msg("failed to connect to websocket upstream")
+			select {
+			case <-time.After(delay):
+				continue
+			case <-c.appCtx.Done():
+				continue
+			}
+		}
+
+		attempt = 0
+		wc.mu.Lock()
+		wc.conn = conn
+		wc.lastErr = nil
+		close(wc.ready)
+		wc.mu.Unlock()
+		c.logger.Debug().Int("conn", wc.index).Str("host", c.Url.Host).Msg("websocket connection established")
+
+		stop := make(chan struct{})
+		go func() {
+			select {
+			case <-c.appCtx.Done():
+				_ = conn.Close()
+			case <-stop:
+			}
+		}()
+
+		err = wc.readLoop(conn)
+		close(stop)
+		_ = conn.Close()
+
+		wc.mu.Lock()
+		wc.conn = nil
+		wc.lastErr = err
+		wc.ready = make(chan struct{})
+		// Subscriptions die with the socket, subscribers are expected to notice and subscribe again
+		wc.subscriptions = make(map[string]WsSubscriptionHandler)
+		wc.mu.Unlock()
+
+		if c.appCtx.Err() == nil {
+			c.logger.Warn().Err(err).Int("conn", wc.index).Msg("websocket connection to upstream dropped, reconnecting")
+		}
+		wc.failPending(common.NewErrEndpointTransportFailure(c.Url, fmt.Errorf("websocket connection closed: %w", err)))
+	}
+}
+
+func (wc *wsConnection) readLoop(conn *websocket.Conn) error {
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			return err
+		}
+		if len(msg) == 0 {
+			continue
+		}
+		// Batch responses are never expected since requests are sent one by one
+		if trimmed := strings.TrimSpace(string(msg[:min(len(msg), 16)])); strings.HasPrefix(trimmed, "[") {
+			wc.client.logger.Warn().Int("conn", wc.index).Msg("ignoring unexpected batch message received over websocket")
+			continue
+		}
+		wc.client.handleMessage(wc, msg)
+	}
+}
+
+func (wc *wsConnection) backoff(attempt int) time.Duration {
+	minDelay := wc.client.wsCfg.ReconnectMinDelay.Duration()
+	maxDelay := wc.client.wsCfg.ReconnectMaxDelay.Duration()
+	delay := minDelay
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	// Add up to 20% jitter so that many connections don't reconnect in lockstep
+	// #nosec G404
+	jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	return delay + jitter
+}
+
+func (wc *wsConnection) isConnected() bool {
+	wc.mu.RLock()
+	defer wc.mu.RUnlock()
+	return wc.conn != nil
+}
+
+func (wc *wsConnection) send(wireId int64, pr *wsPendingRequest, body []byte) error {
+	wc.mu.Lock()
+	conn := wc.conn
+	if conn == nil {
+		wc.mu.Unlock()
+		return common.NewErrEndpointTransportFailure(wc.client.Url, errors.New("websocket connection is not established"))
+	}
+	wc.pending[wireId] = pr
+	wc.mu.Unlock()
+
+	if wc.client.isLogLevelTrace {
+		wc.client.logger.Trace().Int("conn", wc.index).Int64("wireId", wireId).RawJSON("request", body).Msg("sending json rpc request over websocket")
+	}
+
+	wc.writeMu.Lock()
+	if dl, ok := pr.ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(dl)
+	} else {
+		_ = conn.SetWriteDeadline(time.Time{})
+	}
+	err := websocket.Message.Send(conn, string(body))
+	wc.writeMu.Unlock()
+
+	if err != nil {
+		wc.forget(wireId)
+		return common.NewErrEndpointTransportFailure(wc.client.Url, err)
+	}
+
+	return nil
+}
+
+func (wc *wsConnection) forget(wireId int64) *wsPendingRequest {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	pr, ok := wc.pending[wireId]
+	if !ok {
+		return nil
+	}
+	delete(wc.pending, wireId)
+	return pr
+}
+
+func (wc *wsConnection) failPending(err error) {
+	wc.mu.Lock()
+	pending := wc.pending
+	wc.pending = make(map[int64]*wsPendingRequest)
+	wc.mu.Unlock()
+
+	for _, pr := range pending {
+		pr.err <- err
+	}
+}
@@ -0,0 +1,242 @@
+package clients
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// newFakeWsServer starts a websocket json-rpc server that answers every request with
+// the given handler result, echoing back the wire id it received.
+func newFakeWsServer(t *testing.T, handler func(conn *websocket.Conn, req map[string]interface{})) (*httptest.Server, *url.URL) {
+	srv := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		for {
+			var msg string
+			if err := websocket.Message.Receive(conn, &msg); err != nil {
+				return
+			}
+			var req map[string]interface{}
+			if err := sonic.UnmarshalString(msg, &req); err != nil {
+				t.Errorf("fake ws server received invalid json: %v", err)
+				return
+			}
+			handler(conn, req)
+		}
+	}))
+	u, err := url.Parse("ws" + strings.TrimPrefix(srv.URL, "http"))
+	require.NoError(t, err)
+	return srv, u
+}
+
+func newTestWsClient(t *testing.T, ctx context.Context, u *url.URL) WsJsonRpcClient {
+	logger := log.Logger
+	ups := common.NewFakeUpstream("rpc1")
+	ups.Config().Type = common.UpstreamTypeEvm
+	ups.Config().Endpoint = u.String()
+	wsCfg := &common.WebSocketUpstreamConfig{
+		MaxConnections:    1,
+		ReconnectMinDelay: common.Duration(10 * time.Millisecond),
+		ReconnectMaxDelay: common.Duration(50 * time.Millisecond),
+	}
+	require.NoError(t, wsCfg.SetDefaults())
+	client, err := NewGenericWsJsonRpcClient(ctx, &logger, "prj1", ups, u, &common.JsonRpcUpstreamConfig{WebSocket: wsCfg}, &noopErrorExtractor{})
+	require.NoError(t, err)
+	return client
+}
+
+func TestWsJsonRpcClient(t *testing.T) {
+	t.Run("RestoresOriginalIdOnResponse", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		srv, u := newFakeWsServer(t, func(conn *websocket.Conn, req map[string]interface{}) {
+			resp, _ := sonic.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x1"})
+			_ = websocket.Message.Send(conn, string(resp))
+		})
+		defer srv.Close()
+
+		client := newTestWsClient(t, ctx, u)
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":"abc","method":"eth_blockNumber","params":[]}`))
+		resp, err := client.SendRequest(ctx, req)
+		require.NoError(t, err)
+
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		assert.Equal(t, "abc", jrr.ID())
+		assert.Equal(t, `"0x1"`, jrr.GetResultString())
+	})
+
+	t.Run("MultiplexesConcurrentRequestsWithSameId", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		srv, u := newFakeWsServer(t, func(conn *websocket.Conn, req map[string]interface{}) {
+			params := req["params"].([]interface{})
+			// Answer asynchronously and out-of-order to prove correlation is by id
+			go func() {
+				time.Sleep(time.Duration(len(params[0].(string))%3) * time.Millisecond)
+				resp, _ := sonic.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": params[0]})
+				_ = websocket.Message.Send(conn, string(resp))
+			}()
+		})
+		defer srv.Close()
+
+		client := newTestWsClient(t, ctx, u)
+
+		var wg sync.WaitGroup
+		var failures atomic.Int32
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				val := strings.Repeat("a", i+1)
+				req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["` + val + `"]}`))
+				resp, err := client.SendRequest(ctx, req)
+				if err != nil {
+					failures.Add(1)
+					return
+				}
+				jrr, _ := resp.JsonRpcResponse()
+				if jrr.GetResultString() != `"`+val+`"` || jrr.ID() != int64(1) {
+					failures.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, int32(0), failures.Load())
+	})
+
+	t.Run("TimeoutWhenUpstreamDoesNotAnswer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		srv, u := newFakeWsServer(t, func(conn *websocket.Conn, req map[string]interface{}) {})
+		defer srv.Close()
+
+		client := newTestWsClient(t, ctx, u)
+		reqCtx, reqCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer reqCancel()
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+		_, err := client.SendRequest(reqCtx, req)
+		require.Error(t, err)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeEndpointRequestTimeout), "expected timeout error, got: %v", err)
+	})
+
+	t.Run("FailsPendingRequestOnErrorWithNullId", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		srv, u := newFakeWsServer(t, func(conn *websocket.Conn, req map[string]interface{}) {
+			_ = websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`)
+		})
+		defer srv.Close()
+
+		client := newTestWsClient(t, ctx, u)
+		reqCtx, reqCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer reqCancel()
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+		startedAt := time.Now()
+		_, err := client.SendRequest(reqCtx, req)
+		require.Error(t, err)
+		assert.False(t, common.HasErrorCode(err, common.ErrCodeEndpointRequestTimeout), "expected json-rpc error, got: %v", err)
+		assert.Less(t, time.Since(startedAt), 2*time.Second)
+		assert.ErrorContains(t, err, "parse error")
+	})
+
+	t.Run("ReconnectsAfterConnectionDrop", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls atomic.Int32
+		srv, u := newFakeWsServer(t, func(conn *websocket.Conn, req map[string]interface{}) {
+			if calls.Add(1) == 1 {
+				// Drop the first connection without answering
+				_ = conn.Close()
+				return
+			}
+			resp, _ := sonic.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x2"})
+			_ = websocket.Message.Send(conn, string(resp))
+		})
+		defer srv.Close()
+
+		client := newTestWsClient(t, ctx, u)
+
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+		_, err := client.SendRequest(ctx, req)
+		require.Error(t, err)
+		assert.True(t, common.HasErrorCode(err, common.ErrCodeEndpointTransportFailure), "expected transport failure, got: %v", err)
+
+		req = common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}`))
+		resp, err := client.SendRequest(ctx, req)
+		require.NoError(t, err)
+		jrr, _ := resp.JsonRpcResponse()
+		assert.Equal(t, `"0x2"`, jrr.GetResultString())
+	})
+
+	t.Run("RoutesNotificationsPerSubscription", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		srv, u := newFakeWsServer(t, func(conn *websocket.Conn, req map[string]interface{}) {
+			params := req["params"].([]interface{})
+			switch req["method"] {
+			case "eth_subscribe":
+				subId := "0x" + params[0].(string)
+				resp, _ := sonic.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": subId})
+				_ = websocket.Message.Send(conn, string(resp))
+				_ = websocket.Message.Send(conn, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"`+subId+`","result":{"kind":"`+params[0].(string)+`"}}}`)
+			case "eth_unsubscribe":
+				resp, _ := sonic.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": true})
+				_ = websocket.Message.Send(conn, string(resp))
+				// Late notification of the removed subscription, followed by one of the remaining subscription
+				_ = websocket.Message.Send(conn, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xnewHeads","result":{"kind":"late"}}}`)
+				_ = websocket.Message.Send(conn, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xlogs","result":{"kind":"after"}}}`)
+			}
+		})
+		defer srv.Close()
+
+		client := newTestWsClient(t, ctx, u)
+		heads := make(chan string, 4)
+		logs := make(chan string, 4)
+		subscribe := func(kind string, ch chan string) {
+			subCtx := WithWsSubscriptionHandler(ctx, func(result []byte) {
+				ch <- string(result)
+			})
+			req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["` + kind + `"]}`))
+			_, err := client.SendRequest(subCtx, req)
+			require.NoError(t, err)
+		}
+		receive := func(ch chan string) string {
+			select {
+			case msg := <-ch:
+				return msg
+			case <-time.After(2 * time.Second):
+				t.Fatal("notification was not dispatched")
+				return ""
+			}
+		}
+
+		subscribe("newHeads", heads)
+		subscribe("logs", logs)
+		assert.Contains(t, receive(heads), `"newHeads"`)
+		assert.Contains(t, receive(logs), `"logs"`)
+
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","id":2,"method":"eth_unsubscribe","params":["0xnewHeads"]}`))
+		_, err := client.SendRequest(ctx, req)
+		require.NoError(t, err)
+		assert.Contains(t, receive(logs), `"after"`)
+		assert.Empty(t, heads)
+	})
+}
@@ -87,22 +87,34 @@ func LoadConfig(fs afero.Fs, filename string, opts *DefaultOptions) (*Config, er
 }
 
 type ServerConfig struct {
-	ListenV4            *bool           `yaml:"listenV4,omitempty" json:"listenV4"`
-	HttpHostV4          *string         `yaml:"httpHostV4,omitempty" json:"httpHostV4"`
-	ListenV6            *bool           `yaml:"listenV6,omitempty" json:"listenV6"`
-	HttpHostV6          *string         `yaml:"httpHostV6,omitempty" json:"httpHostV6"`
-	HttpPort            *int            `yaml:"httpPort,omitempty" json:"httpPort"` // Deprecated: use HttpPortV4
-	HttpPortV4          *int            `yaml:"httpPortV4,omitempty" json:"httpPortV4"`
-	HttpPortV6          *int            `yaml:"httpPortV6,omitempty" json:"httpPortV6"`
-	MaxTimeout          *Duration       `yaml:"maxTimeout,omitempty" json:"maxTimeout" tstype:"Duration"`
-	ReadTimeout         *Duration       `yaml:"readTimeout,omitempty" json:"readTimeout" tstype:"Duration"`
-	WriteTimeout        *Duration       `yaml:"writeTimeout,omitempty" json:"writeTimeout" tstype:"Duration"`
-	EnableGzip          *bool           `yaml:"enableGzip,omitempty" json:"enableGzip"`
-	TLS                 *TLSConfig      `yaml:"tls,omitempty" json:"tls"`
-	Aliasing            *AliasingConfig `yaml:"aliasing" json:"aliasing"`
-	WaitBeforeShutdown  *Duration       `yaml:"waitBeforeShutdown,omitempty" json:"waitBeforeShutdown" tstype:"Duration"`
-	WaitAfterShutdown   *Duration       `yaml:"waitAfterShutdown,omitempty" json:"waitAfterShutdown" tstype:"Duration"`
-	IncludeErrorDetails *bool           `yaml:"includeErrorDetails,omitempty" json:"includeErrorDetails"`
+	ListenV4            *bool                  `yaml:"listenV4,omitempty" json:"listenV4"`
+	HttpHostV4          *string                `yaml:"httpHostV4,omitempty" json:"httpHostV4"`
+	ListenV6            *bool                  `yaml:"listenV6,omitempty" json:"listenV6"`
+	HttpHostV6          *string                `yaml:"httpHostV6,omitempty" json:"httpHostV6"`
+	HttpPort            *int                   `yaml:"httpPort,omitempty" json:"httpPort"` // Deprecated: use HttpPortV4
+	HttpPortV4          *int                   `yaml:"httpPortV4,omitempty" json:"httpPortV4"`
+	HttpPortV6          *int                   `yaml:"httpPortV6,omitempty" json:"httpPortV6"`
+	MaxTimeout          *Duration              `yaml:"maxTimeout,omitempty" json:"maxTimeout" tstype:"Duration"`
+	ReadTimeout         *Duration              `yaml:"readTimeout,omitempty" json:"readTimeout" tstype:"Duration"`
+	WriteTimeout        *Duration              `yaml:"writeTimeout,omitempty" json:"writeTimeout" tstype:"Duration"`
+	EnableGzip          *bool                  `yaml:"enableGzip,omitempty" json:"enableGzip"`
+	TLS                 *TLSConfig             `yaml:"tls,omitempty" json:"tls"`
+	Aliasing            *AliasingConfig        `yaml:"aliasing" json:"aliasing"`
+	WaitBeforeShutdown  *Duration              `yaml:"waitBeforeShutdown,omitempty" json:"waitBeforeShutdown" tstype:"Duration"`
+	WaitAfterShutdown   *Duration              `yaml:"waitAfterShutdown,omitempty" json:"waitAfterShutdown" tstype:"Duration"`
+	IncludeErrorDetails *bool                  `yaml:"includeErrorDetails,omitempty" json:"includeErrorDetails"`
+	WebSocket           *WebSocketServerConfig `yaml:"webSocket,omitempty" json:"webSocket"`
+}
+
+// WebSocketServerConfig controls the websocket listener served on the same
+// /<project>/<architecture>/<chainId> paths as http, including eth_subscribe support.
+type WebSocketServerConfig struct {
+	Enabled                       *bool    `yaml:"enabled,omitempty" json:"enabled"`
+	MaxMessageSize                int      `yaml:"maxMessageSize,omitempty" json:"maxMessageSize"`
+	MaxSubscriptionsPerConnection int      `yaml:"maxSubscriptionsPerConnection,omitempty" json:"maxSubscriptionsPerConnection"`
+	MaxInFlightPerConnection      int      `yaml:"maxInFlightPerConnection,omitempty" json:"maxInFlightPerConnection"`
+	MaxUpstreamSubscriptions      int      `yaml:"maxUpstreamSubscriptions,omitempty" json:"maxUpstreamSubscriptions"`
+	HeadsPollInterval             Duration `yaml:"headsPollInterval,omitempty" json:"headsPollInterval" tstype:"Duration"`
 }
 
 type HealthCheckConfig struct {
@@ -178,6 +190,15 @@ type CacheConfig struct {
 	Connectors  []*ConnectorConfig   `yaml:"connectors,omitempty" json:"connectors" tstype:"TsConnectorConfig[]"`
 	Policies    []*CachePolicyConfig `yaml:"policies,omitempty" json:"policies"`
 	Compression *CompressionConfig   `yaml:"compression,omitempty" json:"compression"`
+	Reorgs      *CacheReorgsConfig   `yaml:"reorgs,omitempty" json:"reorgs"`
+}
+
+// CacheReorgsConfig controls invalidation of unfinalized cache entries when a block they reference is reorged.
+// Block hashes of the most recent TrackedBlocks heights are remembered (from state pollers and block responses)
+// along with the cache entries written for those heights.
+type CacheReorgsConfig struct {
+	Enabled       *bool `yaml:"enabled,omitempty" json:"enabled"`
+	TrackedBlocks int64 `yaml:"trackedBlocks,omitempty" json:"trackedBlocks"`
 }
 
 type CompressionConfig struct {
@@ -216,6 +237,9 @@ const (
 	DriverPostgreSQL ConnectorDriverType = "postgresql"
 	DriverDynamoDB   ConnectorDriverType = "dynamodb"
 	DriverGrpc       ConnectorDriverType = "grpc"
+	DriverTiered     ConnectorDriverType = "tiered"
+	DriverBadger     ConnectorDriverType = "badger"
+	DriverScylla     ConnectorDriverType = "scylla"
 )
 
 type ConnectorConfig struct {
@@ -226,15 +250,36 @@ type ConnectorConfig struct {
 	DynamoDB   *DynamoDBConnectorConfig   `yaml:"dynamodb,omitempty" json:"dynamodb"`
 	PostgreSQL *PostgreSQLConnectorConfig `yaml:"postgresql,omitempty" json:"postgresql"`
 	Grpc       *GrpcConnectorConfig       `yaml:"grpc,omitempty" json:"grpc"`
+	Tiered     *TieredConnectorConfig     `yaml:"tiered,omitempty" json:"tiered"`
+	Badger     *BadgerConnectorConfig     `yaml:"badger,omitempty" json:"badger"`
+	Scylla     *ScyllaConnectorConfig     `yaml:"scylla,omitempty" json:"scylla"`
 	Mock       *MockConnectorConfig       `yaml:"-" json:"-"`
 }
 
+// TieredConnectorConfig layers an in-memory L1 over any remote L2 connector. Reads are served from L1 when possible,
+// L2 hits are promoted into L1, and writes go through to both tiers.
+type TieredConnectorConfig struct {
+	L1    *MemoryConnectorConfig `yaml:"l1,omitempty" json:"l1"`
+	L2    *ConnectorConfig       `yaml:"l2,omitempty" json:"l2"`
+	L1TTL Duration               `yaml:"l1Ttl,omitempty" json:"l1Ttl" tstype:"Duration"`
+	// When enabled deletes are broadcast via L2 pub/sub so that other instances evict them from their L1
+	Invalidation *bool `yaml:"invalidation,omitempty" json:"invalidation"`
+}
+
 type GrpcConnectorConfig struct {
 	Bootstrap string            `yaml:"bootstrap,omitempty" json:"bootstrap"`
 	Servers   []string          `yaml:"servers,omitempty" json:"servers"`
 	Headers   map[string]string `yaml:"headers,omitempty" json:"headers"`
 }
 
+// BadgerConnectorConfig configures an embedded on-disk key-value store, useful for single-node
+// deployments that need a persistent cache without an external database.
+type BadgerConnectorConfig struct {
+	Dir        string   `yaml:"dir" json:"dir"`
+	SyncWrites *bool    `yaml:"syncWrites,omitempty" json:"syncWrites"`
+	GCInterval Duration `yaml:"gcInterval,omitempty" json:"gcInterval" tstype:"Duration"`
+}
+
 type MemoryConnectorConfig struct {
 	MaxItems     int    `yaml:"maxItems" json:"maxItems"`
 	MaxTotalSize string `yaml:"maxTotalSize" json:"maxTotalSize"`
@@ -257,32 +302,53 @@ type TLSConfig struct {
 	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty" json:"insecureSkipVerify"`
 }
 
+type RedisConnectorMode string
+
+const (
+	// RedisModeStandalone connects to a single Redis server via "uri" (or "addr").
+	RedisModeStandalone RedisConnectorMode = "standalone"
+	// RedisModeCluster connects to a sharded Redis Cluster using "addrs" as seed nodes.
+	RedisModeCluster RedisConnectorMode = "cluster"
+	// RedisModeSentinel discovers the master named "masterName" via the sentinels in "addrs" and follows failovers.
+	RedisModeSentinel RedisConnectorMode = "sentinel"
+)
+
 type RedisConnectorConfig struct {
-	Addr              string     `yaml:"addr,omitempty" json:"addr"`
-	Username          string     `yaml:"username,omitempty" json:"username"`
-	Password          string     `yaml:"password,omitempty" json:"-"`
-	DB                int        `yaml:"db,omitempty" json:"db"`
-	TLS               *TLSConfig `yaml:"tls,omitempty" json:"tls"`
-	ConnPoolSize      int        `yaml:"connPoolSize,omitempty" json:"connPoolSize"`
-	URI               string     `yaml:"uri" json:"uri"`
-	InitTimeout       Duration   `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
-	GetTimeout        Duration   `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
-	SetTimeout        Duration   `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
-	LockRetryInterval Duration   `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
+	Mode              RedisConnectorMode `yaml:"mode,omitempty" json:"mode"`
+	Addr              string             `yaml:"addr,omitempty" json:"addr"`
+	Addrs             []string           `yaml:"addrs,omitempty" json:"addrs"`
+	MasterName        string             `yaml:"masterName,omitempty" json:"masterName"`
+	SentinelUsername  string             `yaml:"sentinelUsername,omitempty" json:"sentinelUsername"`
+	SentinelPassword  string             `yaml:"sentinelPassword,omitempty" json:"-"`
+	Username          string             `yaml:"username,omitempty" json:"username"`
+	Password          string             `yaml:"password,omitempty" json:"-"`
+	DB                int                `yaml:"db,omitempty" json:"db"`
+	TLS               *TLSConfig         `yaml:"tls,omitempty" json:"tls"`
+	ConnPoolSize      int                `yaml:"connPoolSize,omitempty" json:"connPoolSize"`
+	URI               string             `yaml:"uri" json:"uri"`
+	InitTimeout       Duration           `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout        Duration           `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	SetTimeout        Duration           `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+	LockRetryInterval Duration           `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
 }
 
 func (r *RedisConnectorConfig) MarshalJSON() ([]byte, error) {
 	return sonic.Marshal(map[string]interface{}{
-		"addr":         r.Addr,
-		"username":     r.Username,
-		"password":     "REDACTED",
-		"db":           r.DB,
-		"connPoolSize": r.ConnPoolSize,
-		"uri":          util.RedactEndpoint(r.URI),
-		"tls":          r.TLS,
-		"initTimeout":  r.InitTimeout.String(),
-		"getTimeout":   r.GetTimeout.String(),
-		"setTimeout":   r.SetTimeout.String(),
+		"mode":             r.Mode,
+		"addr":             r.Addr,
+		"addrs":            r.Addrs,
+		"masterName":       r.MasterName,
+		"sentinelUsername": r.SentinelUsername,
+		"sentinelPassword": "REDACTED",
+		"username":         r.Username,
+		"password":         "REDACTED",
+		"db":               r.DB,
+		"connPoolSize":     r.ConnPoolSize,
+		"uri":              util.RedactEndpoint(r.URI),
+		"tls":              r.TLS,
+		"initTimeout":      r.InitTimeout.String(),
+		"getTimeout":       r.GetTimeout.String(),
+		"setTimeout":       r.SetTimeout.String(),
 	})
 }
 
@@ -303,6 +369,42 @@ type DynamoDBConnectorConfig struct {
 	LockRetryInterval Duration       `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
 }
 
+// ScyllaConnectorConfig configures a ScyllaDB (or any Cassandra-compatible) connector. The keyspace
+// and tables are created if they do not exist.
+type ScyllaConnectorConfig struct {
+	Hosts             []string   `yaml:"hosts,omitempty" json:"hosts"`
+	Keyspace          string     `yaml:"keyspace,omitempty" json:"keyspace"`
+	Table             string     `yaml:"table,omitempty" json:"table"`
+	Username          string     `yaml:"username,omitempty" json:"username"`
+	Password          string     `yaml:"password,omitempty" json:"-"`
+	TLS               *TLSConfig `yaml:"tls,omitempty" json:"tls"`
+	Consistency       string     `yaml:"consistency,omitempty" json:"consistency"`
+	ReplicationFactor int        `yaml:"replicationFactor,omitempty" json:"replicationFactor"`
+	InitTimeout       Duration   `yaml:"initTimeout,omitempty" json:"initTimeout" tstype:"Duration"`
+	GetTimeout        Duration   `yaml:"getTimeout,omitempty" json:"getTimeout" tstype:"Duration"`
+	SetTimeout        Duration   `yaml:"setTimeout,omitempty" json:"setTimeout" tstype:"Duration"`
+	LockRetryInterval Duration   `yaml:"lockRetryInterval,omitempty" json:"lockRetryInterval" tstype:"Duration"`
+	StatePollInterval Duration   `yaml:"statePollInterval,omitempty" json:"statePollInterval" tstype:"Duration"`
+}
+
+func (s *ScyllaConnectorConfig) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(map[string]interface{}{
+		"hosts":             s.Hosts,
+		"keyspace":          s.Keyspace,
+		"table":             s.Table,
+		"username":          s.Username,
+		"password":          "REDACTED",
+		"tls":               s.TLS,
+		"consistency":       s.Consistency,
+		"replicationFactor": s.ReplicationFactor,
+		"initTimeout":       s.InitTimeout.String(),
+		"getTimeout":        s.GetTimeout.String(),
+		"setTimeout":        s.SetTimeout.String(),
+		"lockRetryInterval": s.LockRetryInterval.String(),
+		"statePollInterval": s.StatePollInterval.String(),
+	})
+}
+
 type PostgreSQLConnectorConfig struct {
 	ConnectionUri string   `yaml:"connectionUri" json:"connectionUri"`
 	Table         string   `yaml:"table" json:"table"`
@@ -356,6 +458,21 @@ type ProjectConfig struct {
 	ScoreMetricsWindowSize Duration                            `yaml:"scoreMetricsWindowSize,omitempty" json:"scoreMetricsWindowSize" tstype:"Duration"`
 	ScoreRefreshInterval   Duration                            `yaml:"scoreRefreshInterval,omitempty" json:"scoreRefreshInterval" tstype:"Duration"`
 	DeprecatedHealthCheck  *DeprecatedProjectHealthCheckConfig `yaml:"healthCheck,omitempty" json:"healthCheck"`
+	Disputes               *DisputesConfig                     `yaml:"disputes,omitempty" json:"disputes"`
+}
+
+// DisputesConfig persists consensus disputes and shadow upstream mismatches (request, each participant's
+// response and the chosen outcome) so that data-quality issues of providers can be investigated after the fact.
+type DisputesConfig struct {
+	Connector *ConnectorConfig `yaml:"connector" json:"connector"`
+	// Retention is how long records are kept in the connector.
+	Retention Duration `yaml:"retention,omitempty" json:"retention" tstype:"Duration"`
+	// MaxBodySize caps the number of bytes kept of the request and of each response body.
+	MaxBodySize int `yaml:"maxBodySize,omitempty" json:"maxBodySize"`
+	// Consensus records requests where consensus participants disagreed.
+	Consensus *bool `yaml:"consensus,omitempty" json:"consensus"`
+	// Shadow records responses of shadow upstreams that differ from the primary response.
+	Shadow *bool `yaml:"shadow,omitempty" json:"shadow"`
 }
 
 type NetworkDefaults struct {
@@ -470,6 +587,8 @@ type UpstreamConfig struct {
 	Failsafe                     []*FailsafeConfig        `yaml:"failsafe,omitempty" json:"failsafe"`
 	RateLimitBudget              string                   `yaml:"rateLimitBudget,omitempty" json:"rateLimitBudget"`
 	RateLimitAutoTune            *RateLimitAutoTuneConfig `yaml:"rateLimitAutoTune,omitempty" json:"rateLimitAutoTune"`
+	RateLimitCooldown            *RateLimitCooldownConfig `yaml:"rateLimitCooldown,omitempty" json:"rateLimitCooldown"`
+	ConcurrencyLimit             *ConcurrencyLimitConfig  `yaml:"concurrencyLimit,omitempty" json:"concurrencyLimit"`
 	Routing                      *RoutingConfig           `yaml:"routing,omitempty" json:"routing"`
 	Shadow                       *ShadowUpstreamConfig    `yaml:"shadow,omitempty" json:"shadow"`
 }
@@ -512,6 +631,8 @@ func (u *UpstreamConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 		Failsafe                     *FailsafeConfig          `yaml:"failsafe,omitempty"`
 		RateLimitBudget              string                   `yaml:"rateLimitBudget,omitempty"`
 		RateLimitAutoTune            *RateLimitAutoTuneConfig `yaml:"rateLimitAutoTune,omitempty"`
+		RateLimitCooldown            *RateLimitCooldownConfig `yaml:"rateLimitCooldown,omitempty"`
+		ConcurrencyLimit             *ConcurrencyLimitConfig  `yaml:"concurrencyLimit,omitempty"`
 		Routing                      *RoutingConfig           `yaml:"routing,omitempty"`
 		Shadow                       *ShadowUpstreamConfig    `yaml:"shadow,omitempty"`
 	}
@@ -536,6 +657,8 @@ func (u *UpstreamConfig) UnmarshalYAML(unmarshal func(interface{}) error) error
 	u.AutoIgnoreUnsupportedMethods = old.AutoIgnoreUnsupportedMethods
 	u.RateLimitBudget = old.RateLimitBudget
 	u.RateLimitAutoTune = old.RateLimitAutoTune
+	u.RateLimitCooldown = old.RateLimitCooldown
+	u.ConcurrencyLimit = old.ConcurrencyLimit
 	u.Routing = old.Routing
 	u.Shadow = old.Shadow
 
@@ -576,6 +699,12 @@ func (c *UpstreamConfig) Copy() *UpstreamConfig {
 	if c.RateLimitAutoTune != nil {
 		copied.RateLimitAutoTune = c.RateLimitAutoTune.Copy()
 	}
+	if c.RateLimitCooldown != nil {
+		copied.RateLimitCooldown = c.RateLimitCooldown.Copy()
+	}
+	if c.ConcurrencyLimit != nil {
+		copied.ConcurrencyLimit = c.ConcurrencyLimit.Copy()
+	}
 
 	if c.IgnoreMethods != nil {
 		copied.IgnoreMethods = make([]string, len(c.IgnoreMethods))
@@ -747,13 +876,88 @@ func (c *RateLimitAutoTuneConfig) Copy() *RateLimitAutoTuneConfig {
 	return copied
 }
 
+type RateLimitCooldownScope string
+
+const (
+	RateLimitCooldownScopeMethod   RateLimitCooldownScope = "method"
+	RateLimitCooldownScopeUpstream RateLimitCooldownScope = "upstream"
+)
+
+// RateLimitCooldownConfig controls how long an upstream is excluded from routing after it responds
+// with a rate limit error, based on the backoff window the upstream asked for (e.g. Retry-After).
+type RateLimitCooldownConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled"`
+	// Scope is either "method" (only the rate-limited method is skipped) or "upstream" (all methods).
+	Scope RateLimitCooldownScope `yaml:"scope,omitempty" json:"scope"`
+	// DefaultDuration is used when the upstream does not provide a backoff hint, 0 means no cooldown.
+	DefaultDuration Duration `yaml:"defaultDuration,omitempty" json:"defaultDuration" tstype:"Duration"`
+	MaxDuration     Duration `yaml:"maxDuration,omitempty" json:"maxDuration" tstype:"Duration"`
+}
+
+func (c *RateLimitCooldownConfig) Copy() *RateLimitCooldownConfig {
+	if c == nil {
+		return nil
+	}
+
+	copied := &RateLimitCooldownConfig{}
+	*copied = *c
+
+	return copied
+}
+
+type ConcurrencyLimitAlgorithm string
+
+const (
+	ConcurrencyLimitAlgorithmGradient ConcurrencyLimitAlgorithm = "gradient"
+	ConcurrencyLimitAlgorithmAimd     ConcurrencyLimitAlgorithm = "aimd"
+)
+
+// ConcurrencyLimitConfig caps the number of in-flight requests to an upstream, adapting the cap to the
+// upstream's latency so that overloaded nodes get fewer concurrent requests. Requests beyond the cap are
+// not queued but overflow to the next upstream.
+type ConcurrencyLimitConfig struct {
+	Enabled          *bool                     `yaml:"enabled,omitempty" json:"enabled"`
+	Algorithm        ConcurrencyLimitAlgorithm `yaml:"algorithm,omitempty" json:"algorithm"`
+	InitialLimit     int                       `yaml:"initialLimit,omitempty" json:"initialLimit"`
+	MinLimit         int                       `yaml:"minLimit,omitempty" json:"minLimit"`
+	MaxLimit         int                       `yaml:"maxLimit,omitempty" json:"maxLimit"`
+	AdjustmentPeriod Duration                  `yaml:"adjustmentPeriod,omitempty" json:"adjustmentPeriod" tstype:"Duration"`
+	// LatencyQuantile of the upstream response times (as tracked for scoring) compared against the baseline.
+	LatencyQuantile float64 `yaml:"latencyQuantile,omitempty" json:"latencyQuantile"`
+	// Tolerance is how much slower than the baseline the upstream can get before the limit is decreased.
+	Tolerance float64 `yaml:"tolerance,omitempty" json:"tolerance"`
+	// BackoffRatio is the multiplicative decrease applied when the upstream is overloaded (aimd only).
+	BackoffRatio float64 `yaml:"backoffRatio,omitempty" json:"backoffRatio"`
+}
+
+func (c *ConcurrencyLimitConfig) Copy() *ConcurrencyLimitConfig {
+	if c == nil {
+		return nil
+	}
+
+	copied := &ConcurrencyLimitConfig{}
+	*copied = *c
+
+	return copied
+}
+
 type JsonRpcUpstreamConfig struct {
-	SupportsBatch *bool             `yaml:"supportsBatch,omitempty" json:"supportsBatch"`
-	BatchMaxSize  int               `yaml:"batchMaxSize,omitempty" json:"batchMaxSize"`
-	BatchMaxWait  Duration          `yaml:"batchMaxWait,omitempty" json:"batchMaxWait" tstype:"Duration"`
-	EnableGzip    *bool             `yaml:"enableGzip,omitempty" json:"enableGzip"`
-	Headers       map[string]string `yaml:"headers,omitempty" json:"headers"`
-	ProxyPool     string            `yaml:"proxyPool,omitempty" json:"proxyPool"`
+	SupportsBatch *bool                    `yaml:"supportsBatch,omitempty" json:"supportsBatch"`
+	BatchMaxSize  int                      `yaml:"batchMaxSize,omitempty" json:"batchMaxSize"`
+	BatchMaxWait  Duration                 `yaml:"batchMaxWait,omitempty" json:"batchMaxWait" tstype:"Duration"`
+	EnableGzip    *bool                    `yaml:"enableGzip,omitempty" json:"enableGzip"`
+	Headers       map[string]string        `yaml:"headers,omitempty" json:"headers"`
+	ProxyPool     string                   `yaml:"proxyPool,omitempty" json:"proxyPool"`
+	WebSocket     *WebSocketUpstreamConfig `yaml:"webSocket,omitempty" json:"webSocket"`
+}
+
+// WebSocketUpstreamConfig is only used when upstream endpoint is ws:// or wss://
+type WebSocketUpstreamConfig struct {
+	MaxConnections    int      `yaml:"maxConnections,omitempty" json:"maxConnections"`
+	DialTimeout       Duration `yaml:"dialTimeout,omitempty" json:"dialTimeout" tstype:"Duration"`
+	ReconnectMinDelay Duration `yaml:"reconnectMinDelay,omitempty" json:"reconnectMinDelay" tstype:"Duration"`
+	ReconnectMaxDelay Duration `yaml:"reconnectMaxDelay,omitempty" json:"reconnectMaxDelay" tstype:"Duration"`
+	MaxMessageSize    int      `yaml:"maxMessageSize,omitempty" json:"maxMessageSize"`
 }
 
 func (c *JsonRpcUpstreamConfig) Copy() *JsonRpcUpstreamConfig {
@@ -768,6 +972,11 @@ func (c *JsonRpcUpstreamConfig) Copy() *JsonRpcUpstreamConfig {
 		maps.Copy(copied.Headers, c.Headers)
 	}
 
+	if c.WebSocket != nil {
+		ws := *c.WebSocket
+		copied.WebSocket = &ws
+	}
+
 	return copied
 }
 
@@ -958,6 +1167,32 @@ type ConsensusPolicyConfig struct {
 	IgnoreFields            map[string][]string              `yaml:"ignoreFields,omitempty" json:"ignoreFields"`
 	PreferNonEmpty          *bool                            `yaml:"preferNonEmpty,omitempty" json:"preferNonEmpty"`
 	PreferLargerResponses   *bool                            `yaml:"preferLargerResponses,omitempty" json:"preferLargerResponses"`
+	// Weights give the votes of some upstreams more power (e.g. own nodes over third-party providers).
+	// When set, AgreementThreshold is compared with the total weight of agreeing participants instead of their count.
+	Weights []*ConsensusWeightConfig `yaml:"weights,omitempty" json:"weights" tstype:"ConsensusWeightConfig[]"`
+	// WeightByAgreementRate scales the weight of each upstream by its historical rate of agreement with
+	// consensus for the method, so that upstreams which often misbehave have less say when picking the winner.
+	// AgreementThreshold is still compared with the configured weights, so unanimous responses always reach it.
+	WeightByAgreementRate bool `yaml:"weightByAgreementRate,omitempty" json:"weightByAgreementRate"`
+}
+
+// ConsensusWeightConfig sets the vote weight of upstreams matching an id and/or group (wildcards supported),
+// the first matching entry is used and upstreams not matching any entry have a weight of 1.
+type ConsensusWeightConfig struct {
+	Upstream string  `yaml:"upstream,omitempty" json:"upstream"`
+	Group    string  `yaml:"group,omitempty" json:"group"`
+	Weight   float64 `yaml:"weight" json:"weight"`
+}
+
+// MaxConsensusWeight returns the highest weight a participant can have, including the default weight of 1.
+func MaxConsensusWeight(weights []*ConsensusWeightConfig) float64 {
+	maxWeight := 1.0
+	for _, w := range weights {
+		if w != nil && w.Weight > maxWeight {
+			maxWeight = w.Weight
+		}
+	}
+	return maxWeight
 }
 
 func (c *ConsensusPolicyConfig) Copy() *ConsensusPolicyConfig {
@@ -979,6 +1214,16 @@ func (c *ConsensusPolicyConfig) Copy() *ConsensusPolicyConfig {
 		}
 	}
 
+	if c.Weights != nil {
+		copied.Weights = make([]*ConsensusWeightConfig, len(c.Weights))
+		for i, w := range c.Weights {
+			if w != nil {
+				wc := *w
+				copied.Weights[i] = &wc
+			}
+		}
+	}
+
 	return copied
 }
 
@@ -998,7 +1243,19 @@ func (c *PunishMisbehaviorConfig) Copy() *PunishMisbehaviorConfig {
 }
 
 type RateLimiterConfig struct {
+	Store   *RateLimitStoreConfig    `yaml:"store,omitempty" json:"store"`
 	Budgets []*RateLimitBudgetConfig `yaml:"budgets" json:"budgets" tstype:"RateLimitBudgetConfig[]"`
+	// ComputeUnits is the cost of each method (or wildcard pattern of methods) used by rules counting
+	// compute units. User-defined values are merged on top of DefaultMethodComputeUnits.
+	ComputeUnits map[string]uint `yaml:"computeUnits,omitempty" json:"computeUnits" tstype:"Record<string, number>"`
+}
+
+// RateLimitStoreConfig defines a backend shared by all eRPC instances so that budgets are enforced
+// cluster-wide instead of per instance. When the backend is unreachable budgets are enforced locally.
+type RateLimitStoreConfig struct {
+	Connector *ConnectorConfig `yaml:"connector" json:"connector" tstype:"TsConnectorConfig"`
+	KeyPrefix string           `yaml:"keyPrefix,omitempty" json:"keyPrefix"`
+	Timeout   Duration         `yaml:"timeout,omitempty" json:"timeout" tstype:"Duration"`
 }
 
 type RateLimitBudgetConfig struct {
@@ -1006,11 +1263,21 @@ type RateLimitBudgetConfig struct {
 	Rules []*RateLimitRuleConfig `yaml:"rules" json:"rules" tstype:"RateLimitRuleConfig[]"`
 }
 
+type RateLimitUnit string
+
+const (
+	// RateLimitUnitRequest counts every call as 1 towards the rule's maxCount
+	RateLimitUnitRequest RateLimitUnit = "request"
+	// RateLimitUnitComputeUnit counts the compute units of the method towards the rule's maxCount
+	RateLimitUnitComputeUnit RateLimitUnit = "computeUnit"
+)
+
 type RateLimitRuleConfig struct {
-	Method   string   `yaml:"method" json:"method"`
-	MaxCount uint     `yaml:"maxCount" json:"maxCount"`
-	Period   Duration `yaml:"period" json:"period" tstype:"Duration"`
-	WaitTime Duration `yaml:"waitTime" json:"waitTime" tstype:"Duration"`
+	Method   string        `yaml:"method" json:"method"`
+	MaxCount uint          `yaml:"maxCount" json:"maxCount"`
+	Period   Duration      `yaml:"period" json:"period" tstype:"Duration"`
+	WaitTime Duration      `yaml:"waitTime" json:"waitTime" tstype:"Duration"`
+	Unit     RateLimitUnit `yaml:"unit,omitempty" json:"unit"`
 }
 
 func (c *Config) HasRateLimiterBudget(id string) bool {
@@ -1120,15 +1387,40 @@ type DirectiveDefaultsConfig struct {
 }
 
 type EvmNetworkConfig struct {
-	ChainId                     int64               `yaml:"chainId" json:"chainId"`
-	FallbackFinalityDepth       int64               `yaml:"fallbackFinalityDepth,omitempty" json:"fallbackFinalityDepth"`
-	FallbackStatePollerDebounce Duration            `yaml:"fallbackStatePollerDebounce,omitempty" json:"fallbackStatePollerDebounce" tstype:"Duration"`
-	Integrity                   *EvmIntegrityConfig `yaml:"integrity,omitempty" json:"integrity"`
-	GetLogsMaxAllowedRange      int64               `yaml:"getLogsMaxAllowedRange,omitempty" json:"getLogsMaxAllowedRange"`
-	GetLogsMaxAllowedAddresses  int64               `yaml:"getLogsMaxAllowedAddresses,omitempty" json:"getLogsMaxAllowedAddresses"`
-	GetLogsMaxAllowedTopics     int64               `yaml:"getLogsMaxAllowedTopics,omitempty" json:"getLogsMaxAllowedTopics"`
-	GetLogsSplitOnError         *bool               `yaml:"getLogsSplitOnError,omitempty" json:"getLogsSplitOnError"`
-	GetLogsSplitConcurrency     int                 `yaml:"getLogsSplitConcurrency,omitempty" json:"getLogsSplitConcurrency"`
+	ChainId                     int64                 `yaml:"chainId" json:"chainId"`
+	FallbackFinalityDepth       int64                 `yaml:"fallbackFinalityDepth,omitempty" json:"fallbackFinalityDepth"`
+	FallbackStatePollerDebounce Duration              `yaml:"fallbackStatePollerDebounce,omitempty" json:"fallbackStatePollerDebounce" tstype:"Duration"`
+	Integrity                   *EvmIntegrityConfig   `yaml:"integrity,omitempty" json:"integrity"`
+	GetLogsMaxAllowedRange      int64                 `yaml:"getLogsMaxAllowedRange,omitempty" json:"getLogsMaxAllowedRange"`
+	GetLogsMaxAllowedAddresses  int64                 `yaml:"getLogsMaxAllowedAddresses,omitempty" json:"getLogsMaxAllowedAddresses"`
+	GetLogsMaxAllowedTopics     int64                 `yaml:"getLogsMaxAllowedTopics,omitempty" json:"getLogsMaxAllowedTopics"`
+	GetLogsSplitOnError         *bool                 `yaml:"getLogsSplitOnError,omitempty" json:"getLogsSplitOnError"`
+	GetLogsSplitConcurrency     int                   `yaml:"getLogsSplitConcurrency,omitempty" json:"getLogsSplitConcurrency"`
+	Filters                     *EvmFiltersConfig     `yaml:"filters,omitempty" json:"filters"`
+	TxBroadcast                 *EvmTxBroadcastConfig `yaml:"txBroadcast,omitempty" json:"txBroadcast"`
+}
+
+// EvmFiltersConfig controls emulation of eth_newFilter, eth_newBlockFilter and related methods,
+// where filter state is owned by eRPC (and persisted in shared state) instead of a single upstream.
+type EvmFiltersConfig struct {
+	Enabled          *bool    `yaml:"enabled,omitempty" json:"enabled"`
+	Ttl              Duration `yaml:"ttl,omitempty" json:"ttl" tstype:"Duration"`
+	MaxBlocksPerPoll int64    `yaml:"maxBlocksPerPoll,omitempty" json:"maxBlocksPerPoll"`
+}
+
+// EvmTxBroadcastConfig sends eth_sendRawTransaction to several upstreams in parallel, so that a
+// transaction is not lost when the mempool of a single node drops it.
+type EvmTxBroadcastConfig struct {
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled"`
+
+	// Upstreams limits broadcasting to upstreams matching any of these id patterns (e.g. "alchemy-*"),
+	// when empty all upstreams selected for the request are used.
+	Upstreams    []string `yaml:"upstreams,omitempty" json:"upstreams"`
+	MaxUpstreams int      `yaml:"maxUpstreams,omitempty" json:"maxUpstreams"`
+
+	// DedupWindow is how long a successfully broadcasted payload is remembered, so that repeated
+	// submissions of the same signed transaction are answered without sending it again.
+	DedupWindow Duration `yaml:"dedupWindow,omitempty" json:"dedupWindow" tstype:"Duration"`
 }
 
 type EvmIntegrityConfig struct {
@@ -1208,6 +1500,8 @@ const (
 	AuthTypeJwt      AuthType = "jwt"
 	AuthTypeSiwe     AuthType = "siwe"
 	AuthTypeNetwork  AuthType = "network"
+	AuthTypeMtls     AuthType = "mtls"
+	AuthTypeHmac     AuthType = "hmac"
 )
 
 type AuthConfig struct {
@@ -1225,6 +1519,8 @@ type AuthStrategyConfig struct {
 	Database *DatabaseStrategyConfig `yaml:"database,omitempty" json:"database,omitempty"`
 	Jwt      *JwtStrategyConfig      `yaml:"jwt,omitempty" json:"jwt,omitempty"`
 	Siwe     *SiweStrategyConfig     `yaml:"siwe,omitempty" json:"siwe,omitempty"`
+	Mtls     *MtlsStrategyConfig     `yaml:"mtls,omitempty" json:"mtls,omitempty"`
+	Hmac     *HmacStrategyConfig     `yaml:"hmac,omitempty" json:"hmac,omitempty"`
 }
 
 type SecretStrategyConfig struct {
@@ -1242,6 +1538,28 @@ func (s *SecretStrategyConfig) MarshalJSON() ([]byte, error) {
 type DatabaseStrategyConfig struct {
 	Connector *ConnectorConfig             `yaml:"connector" json:"connector"`
 	Cache     *DatabaseStrategyCacheConfig `yaml:"cache,omitempty" json:"cache,omitempty"`
+	// TrustedProxies are the only peers whose X-Forwarded-For is honoured when resolving the client IP for keys
+	// restricted to "allowedCIDRs", otherwise the IP of the connection is used
+	TrustedProxies []string                          `yaml:"trustedProxies,omitempty" json:"trustedProxies,omitempty"`
+	KeyHashing     *DatabaseStrategyKeyHashingConfig `yaml:"keyHashing,omitempty" json:"keyHashing,omitempty"`
+	// QuotaFailOpen allows requests of api keys with quotas when their usage cannot be accounted (e.g. database is down)
+	QuotaFailOpen *bool `yaml:"quotaFailOpen,omitempty" json:"quotaFailOpen,omitempty"`
+}
+
+// DatabaseStrategyKeyHashingConfig stores API keys as HMAC-SHA256 hashes (keyed by the pepper) instead of
+// their raw value, so that read access to the database does not reveal any usable credential.
+type DatabaseStrategyKeyHashingConfig struct {
+	Pepper string `yaml:"pepper" json:"pepper"`
+	// PlaintextFallback also looks up keys by their raw value until existing keys are migrated via erpc_migrateApiKeys
+	PlaintextFallback *bool `yaml:"plaintextFallback,omitempty" json:"plaintextFallback,omitempty"`
+}
+
+// custom json marshaller to redact the pepper
+func (k *DatabaseStrategyKeyHashingConfig) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(map[string]interface{}{
+		"pepper":            "REDACTED",
+		"plaintextFallback": k.PlaintextFallback,
+	})
 }
 
 type DatabaseStrategyCacheConfig struct {
@@ -1257,10 +1575,81 @@ type JwtStrategyConfig struct {
 	AllowedAlgorithms []string          `yaml:"allowedAlgorithms" json:"allowedAlgorithms"`
 	RequiredClaims    []string          `yaml:"requiredClaims" json:"requiredClaims"`
 	VerificationKeys  map[string]string `yaml:"verificationKeys" json:"verificationKeys"`
+	// JwksUrl is fetched for verification keys (by "kid"), which are refreshed periodically and whenever
+	// a token is signed by an unknown key, so that keys can be rotated without a redeploy.
+	JwksUrl                string   `yaml:"jwksUrl,omitempty" json:"jwksUrl"`
+	JwksRefreshInterval    Duration `yaml:"jwksRefreshInterval,omitempty" json:"jwksRefreshInterval" tstype:"Duration"`
+	JwksMinRefreshInterval Duration `yaml:"jwksMinRefreshInterval,omitempty" json:"jwksMinRefreshInterval" tstype:"Duration"`
+	// ClockSkew is tolerated when validating "exp", "nbf" and "iat" claims.
+	ClockSkew     Duration                `yaml:"clockSkew,omitempty" json:"clockSkew" tstype:"Duration"`
+	ClaimsMapping *JwtClaimsMappingConfig `yaml:"claimsMapping,omitempty" json:"claimsMapping"`
+}
+
+// JwtClaimsMappingConfig names the claims (dot-separated for nested claims, e.g. "app_metadata.tier")
+// from which the identity and limits of the user are derived, so that a single gateway can serve
+// multiple tenants based on token contents alone.
+type JwtClaimsMappingConfig struct {
+	UserId             string `yaml:"userId,omitempty" json:"userId"`
+	RateLimitBudget    string `yaml:"rateLimitBudget,omitempty" json:"rateLimitBudget"`
+	PerSecondRateLimit string `yaml:"perSecondRateLimit,omitempty" json:"perSecondRateLimit"`
+	AllowedNetworks    string `yaml:"allowedNetworks,omitempty" json:"allowedNetworks"`
+	AllowedMethods     string `yaml:"allowedMethods,omitempty" json:"allowedMethods"`
 }
 
 type SiweStrategyConfig struct {
 	AllowedDomains []string `yaml:"allowedDomains" json:"allowedDomains"`
+	// Connector stores nonces issued via erpc_siweNonce (and sessions), when set messages must use such a
+	// nonce and each nonce can only be used once, so that captured signatures cannot be replayed.
+	Connector *ConnectorConfig `yaml:"connector,omitempty" json:"connector,omitempty" tstype:"TsConnectorConfig"`
+	NonceTTL  Duration         `yaml:"nonceTtl,omitempty" json:"nonceTtl" tstype:"Duration"`
+	// SessionTTL enables erpc_siweSession which exchanges a signed message for a session token valid this long
+	SessionTTL Duration `yaml:"sessionTtl,omitempty" json:"sessionTtl" tstype:"Duration"`
+	// NonceRateLimitPerIp limits how many nonces are issued per second to a client IP, nonces are issued
+	// before authentication so the project rate limit budget applies as well.
+	NonceRateLimitPerIp uint `yaml:"nonceRateLimitPerIp,omitempty" json:"nonceRateLimitPerIp,omitempty"`
+	// TrustedProxies are the only peers whose X-Forwarded-For is honoured when resolving the client IP for
+	// nonceRateLimitPerIp, otherwise the IP of the connection is used
+	TrustedProxies []string `yaml:"trustedProxies,omitempty" json:"trustedProxies,omitempty"`
+}
+
+// HmacStrategyConfig authenticates requests signed with a per-key secret (stored in the connector), so that
+// no reusable credential is sent along with requests and leaked into proxy logs.
+type HmacStrategyConfig struct {
+	Connector *ConnectorConfig `yaml:"connector" json:"connector" tstype:"TsConnectorConfig"`
+	// MaxClockSkew is how far the signed timestamp can be from the server time, nonces are remembered for twice as long
+	MaxClockSkew Duration `yaml:"maxClockSkew,omitempty" json:"maxClockSkew" tstype:"Duration"`
+	// CacheTTL is how long keys are kept in memory after being loaded from the connector
+	CacheTTL Duration `yaml:"cacheTtl,omitempty" json:"cacheTtl" tstype:"Duration"`
+}
+
+type MtlsIdentitySource string
+
+const (
+	// MtlsIdentitySourceSpiffeId uses the URI SAN with "spiffe://" scheme, e.g. spiffe://example.org/ns/prod/sa/indexer
+	MtlsIdentitySourceSpiffeId   MtlsIdentitySource = "spiffeId"
+	MtlsIdentitySourceUriSan     MtlsIdentitySource = "uriSan"
+	MtlsIdentitySourceDnsSan     MtlsIdentitySource = "dnsSan"
+	MtlsIdentitySourceEmailSan   MtlsIdentitySource = "emailSan"
+	MtlsIdentitySourceCommonName MtlsIdentitySource = "commonName"
+)
+
+// MtlsStrategyConfig authenticates clients by the certificate they present during the TLS handshake
+// (requires server.tls to be enabled), e.g. services of a mesh identified by their SPIFFE ID.
+type MtlsStrategyConfig struct {
+	// CAFile is a PEM bundle of the CAs that client certificates must chain to
+	CAFile     string             `yaml:"caFile" json:"caFile"`
+	UserIdFrom MtlsIdentitySource `yaml:"userIdFrom,omitempty" json:"userIdFrom"`
+	// AllowedSANs and DeniedSANs are patterns matched against all SANs (URIs, DNS names, emails and IPs)
+	// of the certificate, deny rules take precedence.
+	AllowedSANs []string `yaml:"allowedSANs,omitempty" json:"allowedSANs,omitempty"`
+	DeniedSANs  []string `yaml:"deniedSANs,omitempty" json:"deniedSANs,omitempty"`
+	// RateLimitBudgets assigns a budget to users whose id matches the identity pattern, first match wins
+	RateLimitBudgets []*MtlsRateLimitBudgetConfig `yaml:"rateLimitBudgets,omitempty" json:"rateLimitBudgets,omitempty"`
+}
+
+type MtlsRateLimitBudgetConfig struct {
+	Identity        string `yaml:"identity" json:"identity"`
+	RateLimitBudget string `yaml:"rateLimitBudget" json:"rateLimitBudget"`
 }
 
 type NetworkStrategyConfig struct {
@@ -1288,6 +1677,31 @@ type MetricsConfig struct {
 	HistogramBuckets string    `yaml:"histogramBuckets,omitempty" json:"histogramBuckets"`
 }
 
+// HasAuthStrategy tells whether any project, the admin or the healthcheck endpoint uses the given auth strategy.
+func (c *Config) HasAuthStrategy(authType AuthType) bool {
+	var authConfigs []*AuthConfig
+	for _, project := range c.Projects {
+		authConfigs = append(authConfigs, project.Auth)
+	}
+	if c.Admin != nil {
+		authConfigs = append(authConfigs, c.Admin.Auth)
+	}
+	if c.HealthCheck != nil {
+		authConfigs = append(authConfigs, c.HealthCheck.Auth)
+	}
+	for _, ac := range authConfigs {
+		if ac == nil {
+			continue
+		}
+		for _, strategy := range ac.Strategies {
+			if strategy != nil && strategy.Type == authType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetProjectConfig returns the project configuration by the specified project ID.
 func (c *Config) GetProjectConfig(projectId string) *ProjectConfig {
 	for _, project := range c.Projects {
@@ -1302,6 +1716,7 @@ func (c *Config) GetProjectConfig(projectId string) *ProjectConfig {
 func (c *RateLimitRuleConfig) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("method", c.Method).
 		Uint("maxCount", c.MaxCount).
+		Str("unit", string(c.Unit)).
 		Str("periodMs", fmt.Sprintf("%d", c.Period)).
 		Str("waitTimeMs", fmt.Sprintf("%d", c.WaitTime))
 }
@@ -39,6 +39,8 @@ const (
 	connectorScopeSharedState connectorScope = "shared-state"
 	connectorScopeCache       connectorScope = "cache"
 	connectorScopeAuth        connectorScope = "auth"
+	connectorScopeRateLimiter connectorScope = "rate-limiter"
+	connectorScopeDisputes    connectorScope = "disputes"
 )
 
 // DefaultOptions is used to pass env-provided or args-provided options to the config defaults initializer
@@ -424,6 +426,43 @@ var DefaultSpecialCacheMethods = map[string]*CacheMethodConfig{
 	},
 }
 
+// DefaultMethodComputeUnits reflects the relative cost of methods for providers, used by rate limit rules
+// that count compute units instead of requests. Keys might be wildcard patterns, in which case the most
+// specific (longest) matching pattern wins. Methods not matching any key cost 1 compute unit.
+var DefaultMethodComputeUnits = map[string]uint{
+	"eth_chainId":                   1,
+	"net_version":                   1,
+	"eth_blockNumber":               1,
+	"eth_gasPrice":                  1,
+	"eth_maxPriorityFeePerGas":      1,
+	"eth_feeHistory":                2,
+	"eth_getBalance":                2,
+	"eth_getCode":                   2,
+	"eth_getStorageAt":              2,
+	"eth_getTransactionCount":       2,
+	"eth_getBlockByNumber":          2,
+	"eth_getBlockByHash":            2,
+	"eth_getTransactionByHash":      2,
+	"eth_getTransactionReceipt":     2,
+	"eth_call":                      5,
+	"eth_getProof":                  10,
+	"eth_estimateGas":               10,
+	"eth_createAccessList":          10,
+	"eth_sendRawTransaction":        10,
+	"eth_getBlockReceipts":          20,
+	"eth_getLogs":                   20,
+	"trace_*":                       50,
+	"arbtrace_*":                    50,
+	"trace_call":                    100,
+	"trace_filter":                  100,
+	"trace_replayTransaction":       100,
+	"trace_replayBlockTransactions": 300,
+	"debug_*":                       100,
+	"debug_traceBlock":              300,
+	"debug_traceBlockByNumber":      300,
+	"debug_traceBlockByHash":        300,
+}
+
 func (c *CacheConfig) SetDefaults() error {
 	if len(c.Policies) > 0 {
 		for _, policy := range c.Policies {
@@ -448,6 +487,13 @@ func (c *CacheConfig) SetDefaults() error {
 		return fmt.Errorf("failed to set defaults for compression: %w", err)
 	}
 
+	if c.Reorgs == nil {
+		c.Reorgs = &CacheReorgsConfig{}
+	}
+	if err := c.Reorgs.SetDefaults(); err != nil {
+		return fmt.Errorf("failed to set defaults for cache reorgs: %w", err)
+	}
+
 	return nil
 }
 
@@ -536,6 +582,17 @@ func (m *MethodsConfig) SetDefaults() error {
 	return nil
 }
 
+func (c *CacheReorgsConfig) SetDefaults() error {
+	if c.Enabled == nil {
+		c.Enabled = util.BoolPtr(true)
+	}
+	if c.TrackedBlocks == 0 {
+		// Deep enough for reorgs seen in practice on most chains, finalized data is never invalidated anyway
+		c.TrackedBlocks = 128
+	}
+	return nil
+}
+
 func (c *CompressionConfig) SetDefaults() error {
 	// Enable compression by default
 	if c.Enabled == nil {
@@ -650,6 +707,35 @@ func (s *ServerConfig) SetDefaults() error {
 	if s.IncludeErrorDetails == nil {
 		s.IncludeErrorDetails = util.BoolPtr(true)
 	}
+	if s.WebSocket == nil {
+		s.WebSocket = &WebSocketServerConfig{}
+	}
+	if err := s.WebSocket.SetDefaults(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WebSocketServerConfig) SetDefaults() error {
+	if w.Enabled == nil {
+		w.Enabled = util.BoolPtr(true)
+	}
+	if w.MaxMessageSize == 0 {
+		w.MaxMessageSize = 10 * 1024 * 1024 // 10MB
+	}
+	if w.MaxSubscriptionsPerConnection == 0 {
+		w.MaxSubscriptionsPerConnection = 100
+	}
+	if w.MaxInFlightPerConnection == 0 {
+		w.MaxInFlightPerConnection = 100
+	}
+	if w.MaxUpstreamSubscriptions == 0 {
+		w.MaxUpstreamSubscriptions = 2
+	}
+	if w.HeadsPollInterval == 0 {
+		w.HeadsPollInterval = Duration(2 * time.Second)
+	}
 
 	return nil
 }
@@ -799,6 +885,118 @@ func (c *ConnectorConfig) SetDefaults(scope connectorScope) error {
 			return fmt.Errorf("failed to set defaults for dynamo db connector: %w", err)
 		}
 	}
+	if c.Scylla != nil {
+		c.Driver = DriverScylla
+	}
+	if c.Driver == DriverScylla {
+		if c.Scylla == nil {
+			c.Scylla = &ScyllaConnectorConfig{}
+		}
+		if err := c.Scylla.SetDefaults(scope); err != nil {
+			return fmt.Errorf("failed to set defaults for scylla connector: %w", err)
+		}
+	}
+	if c.Badger != nil {
+		c.Driver = DriverBadger
+	}
+	if c.Driver == DriverBadger {
+		if c.Badger == nil {
+			c.Badger = &BadgerConnectorConfig{}
+		}
+		if err := c.Badger.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for badger connector: %w", err)
+		}
+	}
+	if c.Tiered != nil {
+		c.Driver = DriverTiered
+	}
+	if c.Driver == DriverTiered {
+		if c.Tiered == nil {
+			c.Tiered = &TieredConnectorConfig{}
+		}
+		if err := c.Tiered.SetDefaults(c.Id, scope); err != nil {
+			return fmt.Errorf("failed to set defaults for tiered connector: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ScyllaConnectorConfig) SetDefaults(scope connectorScope) error {
+	if s.Keyspace == "" {
+		s.Keyspace = "erpc"
+	}
+	if s.Table == "" {
+		switch scope {
+		case connectorScopeSharedState:
+			s.Table = "erpc_shared_state"
+		case connectorScopeCache:
+			s.Table = "erpc_json_rpc_cache"
+		case connectorScopeAuth:
+			s.Table = "erpc_auth"
+		case connectorScopeDisputes:
+			s.Table = "erpc_disputes"
+		default:
+			return fmt.Errorf("invalid connector scope: %s", scope)
+		}
+	}
+	if s.Consistency == "" {
+		s.Consistency = "LOCAL_QUORUM"
+	}
+	if s.ReplicationFactor == 0 {
+		s.ReplicationFactor = 1
+	}
+	if s.InitTimeout == 0 {
+		s.InitTimeout = Duration(10 * time.Second)
+	}
+	if s.GetTimeout == 0 {
+		s.GetTimeout = Duration(1 * time.Second)
+	}
+	if s.SetTimeout == 0 {
+		s.SetTimeout = Duration(2 * time.Second)
+	}
+	if s.LockRetryInterval == 0 {
+		s.LockRetryInterval = Duration(300 * time.Millisecond)
+	}
+	if s.StatePollInterval == 0 {
+		s.StatePollInterval = Duration(5 * time.Second)
+	}
+
+	return nil
+}
+
+func (b *BadgerConnectorConfig) SetDefaults() error {
+	if b.SyncWrites == nil {
+		b.SyncWrites = util.BoolPtr(false)
+	}
+	if b.GCInterval == 0 {
+		b.GCInterval = Duration(5 * time.Minute)
+	}
+
+	return nil
+}
+
+func (t *TieredConnectorConfig) SetDefaults(parentId string, scope connectorScope) error {
+	if t.L1 == nil {
+		t.L1 = &MemoryConnectorConfig{}
+	}
+	if err := t.L1.SetDefaults(); err != nil {
+		return err
+	}
+	if t.L1TTL == 0 {
+		t.L1TTL = Duration(1 * time.Minute)
+	}
+	if t.Invalidation == nil {
+		t.Invalidation = util.BoolPtr(false)
+	}
+	if t.L2 != nil {
+		if t.L2.Id == "" {
+			t.L2.Id = parentId + "-l2"
+		}
+		if err := t.L2.SetDefaults(scope); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -815,6 +1013,14 @@ func (m *MemoryConnectorConfig) SetDefaults() error {
 }
 
 func (r *RedisConnectorConfig) SetDefaults() error {
+	if r.Mode == "" {
+		if r.MasterName != "" {
+			r.Mode = RedisModeSentinel
+		} else {
+			r.Mode = RedisModeStandalone
+		}
+	}
+
 	if r.URI != "" && r.Addr != "" {
 		return fmt.Errorf(
 			"redis connector: provide either 'uri' or 'addr/username/password/db', not both",
@@ -839,6 +1045,11 @@ func (r *RedisConnectorConfig) SetDefaults() error {
 		r.LockRetryInterval = Duration(500 * time.Millisecond)
 	}
 
+	// Cluster and sentinel clients are built from discrete fields, as a single URI cannot describe multiple nodes
+	if r.Mode == RedisModeCluster || r.Mode == RedisModeSentinel {
+		return nil
+	}
+
 	// URI is provided directly
 	if r.URI != "" {
 		return nil
@@ -898,6 +1109,8 @@ func (p *PostgreSQLConnectorConfig) SetDefaults(scope connectorScope) error {
 			p.Table = "erpc_json_rpc_cache"
 		case connectorScopeAuth:
 			p.Table = "erpc_auth"
+		case connectorScopeDisputes:
+			p.Table = "erpc_disputes"
 		default:
 			return fmt.Errorf("invalid connector scope: %s", scope)
 		}
@@ -938,6 +1151,8 @@ func (d *DynamoDBConnectorConfig) SetDefaults(scope connectorScope) error {
 			d.Table = "erpc_json_rpc_cache"
 		case connectorScopeAuth:
 			d.Table = "erpc_auth"
+		case connectorScopeDisputes:
+			d.Table = "erpc_disputes"
 		default:
 			return fmt.Errorf("invalid connector scope: %s", scope)
 		}
@@ -1066,13 +1281,41 @@ func (p *ProjectConfig) SetDefaults(opts *DefaultOptions) error {
 			p.ScoreMetricsWindowSize = Duration(10 * time.Minute)
 		}
 	}
+	if p.Disputes != nil {
+		if err := p.Disputes.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for disputes: %w", err)
+		}
+	}
 
 	return nil
 }
 
+func (d *DisputesConfig) SetDefaults() error {
+	if d.Connector != nil {
+		if err := d.Connector.SetDefaults(connectorScopeDisputes); err != nil {
+			return err
+		}
+	}
+	if d.Retention == 0 {
+		d.Retention = Duration(7 * 24 * time.Hour)
+	}
+	if d.MaxBodySize == 0 {
+		d.MaxBodySize = 16 * 1024
+	}
+	if d.Consensus == nil {
+		d.Consensus = util.BoolPtr(true)
+	}
+	if d.Shadow == nil {
+		d.Shadow = util.BoolPtr(true)
+	}
+	return nil
+}
+
 func convertUpstreamToProvider(upstream *UpstreamConfig) (*ProviderConfig, error) {
 	if strings.HasPrefix(upstream.Endpoint, "http://") ||
 		strings.HasPrefix(upstream.Endpoint, "https://") ||
+		strings.HasPrefix(upstream.Endpoint, "ws://") ||
+		strings.HasPrefix(upstream.Endpoint, "wss://") ||
 		strings.HasPrefix(upstream.Endpoint, "grpc://") ||
 		strings.HasPrefix(upstream.Endpoint, "grpc+bds://") {
 		return nil, nil
@@ -1315,6 +1558,12 @@ func (u *UpstreamConfig) ApplyDefaults(defaults *UpstreamConfig) error {
 	if u.RateLimitAutoTune == nil {
 		u.RateLimitAutoTune = defaults.RateLimitAutoTune
 	}
+	if u.RateLimitCooldown == nil && defaults.RateLimitCooldown != nil {
+		u.RateLimitCooldown = defaults.RateLimitCooldown.Copy()
+	}
+	if u.ConcurrencyLimit == nil && defaults.ConcurrencyLimit != nil {
+		u.ConcurrencyLimit = defaults.ConcurrencyLimit.Copy()
+	}
 	// IMPORTANT: Some of the configs must be copied vs referenced, because the object might be updated in runtime only for this specific upstream
 	// TODO Should we refactor so this won't happen?
 	if u.Evm == nil && defaults.Evm != nil {
@@ -1350,6 +1599,7 @@ func (u *UpstreamConfig) ApplyDefaults(defaults *UpstreamConfig) error {
 			EnableGzip:    defaults.JsonRpc.EnableGzip,
 			ProxyPool:     defaults.JsonRpc.ProxyPool,
 			Headers:       defaults.JsonRpc.Headers,
+			WebSocket:     defaults.JsonRpc.WebSocket,
 		}
 	}
 	// Integrity moved under Evm.Integrity
@@ -1461,6 +1711,18 @@ func (u *UpstreamConfig) SetDefaults(defaults *UpstreamConfig) error {
 		}
 	}
 
+	if u.RateLimitCooldown == nil {
+		u.RateLimitCooldown = &RateLimitCooldownConfig{}
+	}
+	if err := u.RateLimitCooldown.SetDefaults(); err != nil {
+		return fmt.Errorf("failed to set defaults for rate limit cooldown: %w", err)
+	}
+	if u.ConcurrencyLimit != nil {
+		if err := u.ConcurrencyLimit.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for concurrency limit: %w", err)
+		}
+	}
+
 	if u.Evm == nil {
 		if strings.HasPrefix(string(u.Type), "evm") {
 			u.Evm = &EvmUpstreamConfig{}
@@ -1548,6 +1810,30 @@ func (e *EvmUpstreamConfig) SetDefaults(defaults *EvmUpstreamConfig) error {
 }
 
 func (j *JsonRpcUpstreamConfig) SetDefaults() error {
+	if j.WebSocket != nil {
+		if err := j.WebSocket.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for websocket: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *WebSocketUpstreamConfig) SetDefaults() error {
+	if w.MaxConnections == 0 {
+		w.MaxConnections = 2
+	}
+	if w.DialTimeout == 0 {
+		w.DialTimeout = Duration(10 * time.Second)
+	}
+	if w.ReconnectMinDelay == 0 {
+		w.ReconnectMinDelay = Duration(500 * time.Millisecond)
+	}
+	if w.ReconnectMaxDelay == 0 {
+		w.ReconnectMaxDelay = Duration(30 * time.Second)
+	}
+	if w.MaxMessageSize == 0 {
+		w.MaxMessageSize = 100 * 1024 * 1024
+	}
 	return nil
 }
 
@@ -1726,6 +2012,47 @@ func (e *EvmNetworkConfig) SetDefaults() error {
 		e.GetLogsSplitConcurrency = 10
 	}
 
+	if e.Filters == nil {
+		e.Filters = &EvmFiltersConfig{}
+	}
+	if err := e.Filters.SetDefaults(); err != nil {
+		return err
+	}
+
+	if e.TxBroadcast == nil {
+		e.TxBroadcast = &EvmTxBroadcastConfig{}
+	}
+	if err := e.TxBroadcast.SetDefaults(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *EvmFiltersConfig) SetDefaults() error {
+	if f.Enabled == nil {
+		f.Enabled = util.BoolPtr(false)
+	}
+	if f.Ttl == 0 {
+		// Same as geth's default deadline for filters that are not polled
+		f.Ttl = Duration(5 * time.Minute)
+	}
+	if f.MaxBlocksPerPoll == 0 {
+		f.MaxBlocksPerPoll = 100
+	}
+	return nil
+}
+
+func (b *EvmTxBroadcastConfig) SetDefaults() error {
+	if b.Enabled == nil {
+		b.Enabled = util.BoolPtr(false)
+	}
+	if b.MaxUpstreams == 0 {
+		b.MaxUpstreams = 3
+	}
+	if b.DedupWindow == 0 {
+		b.DedupWindow = Duration(1 * time.Minute)
+	}
 	return nil
 }
 
@@ -2039,6 +2366,58 @@ func (r *RateLimitAutoTuneConfig) SetDefaults() error {
 	return nil
 }
 
+func (r *RateLimitCooldownConfig) SetDefaults() error {
+	if r.Enabled == nil {
+		r.Enabled = util.BoolPtr(true)
+	}
+	if r.Scope == "" {
+		r.Scope = RateLimitCooldownScopeMethod
+	}
+	if r.MaxDuration == 0 {
+		r.MaxDuration = Duration(1 * time.Minute)
+	}
+
+	return nil
+}
+
+func (c *ConcurrencyLimitConfig) SetDefaults() error {
+	if c.Enabled == nil {
+		c.Enabled = util.BoolPtr(true)
+	}
+	if c.Algorithm == "" {
+		c.Algorithm = ConcurrencyLimitAlgorithmGradient
+	}
+	if c.MinLimit == 0 {
+		c.MinLimit = 1
+	}
+	if c.MaxLimit == 0 {
+		c.MaxLimit = 200
+	}
+	if c.InitialLimit == 0 {
+		c.InitialLimit = 20
+		if c.InitialLimit < c.MinLimit {
+			c.InitialLimit = c.MinLimit
+		}
+		if c.InitialLimit > c.MaxLimit {
+			c.InitialLimit = c.MaxLimit
+		}
+	}
+	if c.AdjustmentPeriod == 0 {
+		c.AdjustmentPeriod = Duration(1 * time.Second)
+	}
+	if c.LatencyQuantile == 0 {
+		c.LatencyQuantile = 0.9
+	}
+	if c.Tolerance == 0 {
+		c.Tolerance = 1.5
+	}
+	if c.BackoffRatio == 0 {
+		c.BackoffRatio = 0.9
+	}
+
+	return nil
+}
+
 func (r *RoutingConfig) SetDefaults() error {
 	if r.ScoreMultipliers != nil {
 		for _, multiplier := range r.ScoreMultipliers {
@@ -2212,6 +2591,26 @@ func (s *AuthStrategyConfig) SetDefaults() error {
 		}
 	}
 
+	if s.Type == AuthTypeHmac && s.Hmac == nil {
+		s.Hmac = &HmacStrategyConfig{}
+	}
+	if s.Hmac != nil {
+		s.Type = AuthTypeHmac
+		if err := s.Hmac.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for hmac strategy: %w", err)
+		}
+	}
+
+	if s.Type == AuthTypeMtls && s.Mtls == nil {
+		s.Mtls = &MtlsStrategyConfig{}
+	}
+	if s.Mtls != nil {
+		s.Type = AuthTypeMtls
+		if err := s.Mtls.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for mtls strategy: %w", err)
+		}
+	}
+
 	if s.Type == AuthTypeSiwe && s.Siwe == nil {
 		s.Siwe = &SiweStrategyConfig{}
 	}
@@ -2254,18 +2653,66 @@ func (s *DatabaseStrategyConfig) SetDefaults() error {
 		s.Cache.NumCounters = &defaultNumCounters
 	}
 
+	if s.KeyHashing != nil && s.KeyHashing.PlaintextFallback == nil {
+		s.KeyHashing.PlaintextFallback = util.BoolPtr(true)
+	}
+
+	if s.QuotaFailOpen == nil {
+		s.QuotaFailOpen = util.BoolPtr(true)
+	}
+
 	return s.Connector.SetDefaults(connectorScopeAuth)
 }
 
+func (h *HmacStrategyConfig) SetDefaults() error {
+	if h.Connector == nil {
+		h.Connector = &ConnectorConfig{}
+	}
+	if h.MaxClockSkew == 0 {
+		h.MaxClockSkew = Duration(5 * time.Minute)
+	}
+	if h.CacheTTL == 0 {
+		h.CacheTTL = Duration(time.Minute)
+	}
+	return h.Connector.SetDefaults(connectorScopeAuth)
+}
+
+func (m *MtlsStrategyConfig) SetDefaults() error {
+	if m.UserIdFrom == "" {
+		m.UserIdFrom = MtlsIdentitySourceSpiffeId
+	}
+	return nil
+}
+
 func (s *SecretStrategyConfig) SetDefaults() error {
 	return nil
 }
 
 func (j *JwtStrategyConfig) SetDefaults() error {
+	if j.ClaimsMapping != nil && j.ClaimsMapping.UserId == "" {
+		j.ClaimsMapping.UserId = "sub"
+	}
+	if j.JwksUrl != "" {
+		if j.JwksRefreshInterval == 0 {
+			j.JwksRefreshInterval = Duration(10 * time.Minute)
+		}
+		if j.JwksMinRefreshInterval == 0 {
+			j.JwksMinRefreshInterval = Duration(30 * time.Second)
+		}
+	}
 	return nil
 }
 
 func (s *SiweStrategyConfig) SetDefaults() error {
+	if s.Connector != nil {
+		if s.NonceTTL == 0 {
+			s.NonceTTL = Duration(10 * time.Minute)
+		}
+		if s.NonceRateLimitPerIp == 0 {
+			s.NonceRateLimitPerIp = 5
+		}
+		return s.Connector.SetDefaults(connectorScopeAuth)
+	}
 	return nil
 }
 
@@ -2274,6 +2721,20 @@ func (n *NetworkStrategyConfig) SetDefaults() error {
 }
 
 func (r *RateLimiterConfig) SetDefaults() error {
+	computeUnits := make(map[string]uint, len(DefaultMethodComputeUnits)+len(r.ComputeUnits))
+	for method, cu := range DefaultMethodComputeUnits {
+		computeUnits[method] = cu
+	}
+	for method, cu := range r.ComputeUnits {
+		computeUnits[method] = cu
+	}
+	r.ComputeUnits = computeUnits
+
+	if r.Store != nil {
+		if err := r.Store.SetDefaults(); err != nil {
+			return fmt.Errorf("failed to set defaults for rate limiter store: %w", err)
+		}
+	}
 	if len(r.Budgets) > 0 {
 		for _, budget := range r.Budgets {
 			if err := budget.SetDefaults(); err != nil {
@@ -2285,6 +2746,23 @@ func (r *RateLimiterConfig) SetDefaults() error {
 	return nil
 }
 
+func (s *RateLimitStoreConfig) SetDefaults() error {
+	if s.Connector != nil {
+		if err := s.Connector.SetDefaults(connectorScopeRateLimiter); err != nil {
+			return err
+		}
+	}
+	if s.KeyPrefix == "" {
+		s.KeyPrefix = "erpc_rate_limiter"
+	}
+	if s.Timeout == 0 {
+		// Rate limiting is on the hot path of every request, so fall back to the local limiter quickly
+		s.Timeout = Duration(100 * time.Millisecond)
+	}
+
+	return nil
+}
+
 func (b *RateLimitBudgetConfig) SetDefaults() error {
 	if len(b.Rules) > 0 {
 		for _, rule := range b.Rules {
@@ -2307,6 +2785,9 @@ func (r *RateLimitRuleConfig) SetDefaults() error {
 	if r.Method == "" {
 		r.Method = "*"
 	}
+	if r.Unit == "" {
+		r.Unit = RateLimitUnitRequest
+	}
 
 	return nil
 }
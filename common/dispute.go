@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"time"
+)
+
+type DisputeKind string
+
+const (
+	// DisputeKindConsensus is recorded when consensus participants disagree with each other
+	DisputeKindConsensus DisputeKind = "consensus"
+	// DisputeKindShadow is recorded when a shadow upstream responds differently than the primary upstream
+	DisputeKindShadow DisputeKind = "shadow"
+)
+
+// DisputeRecord captures a request on which upstreams disagreed, for offline review of data-quality issues.
+type DisputeRecord struct {
+	Id        string      `json:"id"`
+	Kind      DisputeKind `json:"kind"`
+	ProjectId string      `json:"projectId"`
+	NetworkId string      `json:"networkId"`
+	Method    string      `json:"method"`
+	Timestamp time.Time   `json:"timestamp"`
+
+	Request          string `json:"request"`
+	RequestTruncated bool   `json:"requestTruncated,omitempty"`
+
+	// Outcome is what was returned to the client, either "result" (with its OutcomeHash) or "error"
+	Outcome     string `json:"outcome"`
+	OutcomeHash string `json:"outcomeHash,omitempty"`
+	Error       string `json:"error,omitempty"`
+
+	Participants []*DisputeParticipant `json:"participants"`
+}
+
+type DisputeParticipant struct {
+	Upstream          string  `json:"upstream"`
+	Vendor            string  `json:"vendor,omitempty"`
+	ResponseType      string  `json:"responseType,omitempty"`
+	ResponseHash      string  `json:"responseHash,omitempty"`
+	ResponseSize      int     `json:"responseSize"`
+	Response          string  `json:"response,omitempty"`
+	ResponseTruncated bool    `json:"responseTruncated,omitempty"`
+	Error             string  `json:"error,omitempty"`
+	AgreesWithOutcome bool    `json:"agreesWithOutcome"`
+	Weight            float64 `json:"weight,omitempty"`
+}
+
+// DisputeRecorder persists dispute records, implementations must not block the caller.
+type DisputeRecorder interface {
+	RecordDispute(ctx context.Context, record *DisputeRecord)
+}
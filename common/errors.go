@@ -535,7 +535,40 @@ type ErrAuthRateLimitRuleExceeded struct{ BaseError }
 
 const ErrCodeAuthRateLimitRuleExceeded ErrorCode = "ErrAuthRateLimitRuleExceeded"
 
-var NewErrAuthRateLimitRuleExceeded = func(projectId, strategy, budget, rule string) error {
+// RateLimitRuleInfo describes the rule of a self-imposed rate limiter budget that rejected a request,
+// so that clients can back off until the rule is replenished.
+type RateLimitRuleInfo struct {
+	Budget   string        `json:"budget"`
+	Method   string        `json:"method"`
+	MaxCount uint          `json:"maxCount"`
+	Period   Duration      `json:"period"`
+	Unit     RateLimitUnit `json:"unit"`
+	ResetIn  Duration      `json:"resetIn"`
+}
+
+// RateLimitedError is implemented by errors of self-imposed rate limit rules rejecting a request.
+type RateLimitedError interface {
+	RateLimitRule() *RateLimitRuleInfo
+}
+
+// LookupRateLimitRule returns the rule that rejected a request if the error (or any of its causes) is
+// due to a self-imposed rate limit rule, otherwise nil.
+func LookupRateLimitRule(err error) *RateLimitRuleInfo {
+	var rle RateLimitedError
+	if errors.As(err, &rle) {
+		return rle.RateLimitRule()
+	}
+	return nil
+}
+
+func rateLimitRuleFromDetails(details map[string]interface{}) *RateLimitRuleInfo {
+	if rule, ok := details["rule"].(*RateLimitRuleInfo); ok {
+		return rule
+	}
+	return nil
+}
+
+var NewErrAuthRateLimitRuleExceeded = func(projectId, strategy string, rule *RateLimitRuleInfo) error {
 	return &ErrAuthRateLimitRuleExceeded{
 		BaseError{
 			Code:    ErrCodeAuthRateLimitRuleExceeded,
@@ -543,7 +576,7 @@ var NewErrAuthRateLimitRuleExceeded = func(projectId, strategy, budget, rule str
 			Details: map[string]interface{}{
 				"projectId": projectId,
 				"strategy":  strategy,
-				"budget":    budget,
+				"budget":    rule.Budget,
 				"rule":      rule,
 			},
 		},
@@ -554,6 +587,78 @@ func (e *ErrAuthRateLimitRuleExceeded) ErrorStatusCode() int {
 	return http.StatusTooManyRequests
 }
 
+func (e *ErrAuthRateLimitRuleExceeded) RateLimitRule() *RateLimitRuleInfo {
+	return rateLimitRuleFromDetails(e.Details)
+}
+
+type ErrAuthQuotaExceeded struct{ BaseError }
+
+const ErrCodeAuthQuotaExceeded ErrorCode = "ErrAuthQuotaExceeded"
+
+var NewErrAuthQuotaExceeded = func(strategy, userId, window, unit string, limit int64, resetsAt time.Time) error {
+	return &ErrAuthQuotaExceeded{
+		BaseError{
+			Code:    ErrCodeAuthQuotaExceeded,
+			Message: fmt.Sprintf("%s quota of %d %s is exhausted for this api key, it resets at %s", window, limit, unit, resetsAt.UTC().Format(time.RFC3339)),
+			Details: map[string]interface{}{
+				"strategy": strategy,
+				"userId":   userId,
+				"window":   window,
+				"unit":     unit,
+				"limit":    limit,
+				"resetsAt": resetsAt.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+func (e *ErrAuthQuotaExceeded) ErrorStatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+type ErrAuthQuotaUnavailable struct{ BaseError }
+
+const ErrCodeAuthQuotaUnavailable ErrorCode = "ErrAuthQuotaUnavailable"
+
+var NewErrAuthQuotaUnavailable = func(strategy, userId string, cause error) error {
+	return &ErrAuthQuotaUnavailable{
+		BaseError{
+			Code:    ErrCodeAuthQuotaUnavailable,
+			Message: "usage of this api key could not be accounted, so the request is rejected",
+			Cause:   cause,
+			Details: map[string]interface{}{
+				"strategy": strategy,
+				"userId":   userId,
+			},
+		},
+	}
+}
+
+func (e *ErrAuthQuotaUnavailable) ErrorStatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+type ErrAuthForbidden struct{ BaseError }
+
+const ErrCodeAuthForbidden ErrorCode = "ErrAuthForbidden"
+
+var NewErrAuthForbidden = func(strategy, userId, message string) error {
+	return &ErrAuthForbidden{
+		BaseError{
+			Code:    ErrCodeAuthForbidden,
+			Message: message,
+			Details: map[string]interface{}{
+				"strategy": strategy,
+				"userId":   userId,
+			},
+		},
+	}
+}
+
+func (e *ErrAuthForbidden) ErrorStatusCode() int {
+	return http.StatusForbidden
+}
+
 //
 // Projects
 //
@@ -985,7 +1090,8 @@ func (e *ErrUpstreamsExhausted) SummarizeCauses() string {
 				missing++
 				continue
 			} else if HasErrorCode(e, ErrCodeEndpointCapacityExceeded) ||
-				HasErrorCode(e, ErrCodeUpstreamRateLimitRuleExceeded) {
+				HasErrorCode(e, ErrCodeUpstreamRateLimitRuleExceeded) ||
+				HasErrorCode(e, ErrCodeUpstreamConcurrencyLimitExceeded) {
 				rateLimit++
 				continue
 			} else if HasErrorCode(e, ErrCodeEndpointBillingIssue) {
@@ -1713,14 +1819,14 @@ type ErrProjectRateLimitRuleExceeded struct{ BaseError }
 
 const ErrCodeProjectRateLimitRuleExceeded ErrorCode = "ErrProjectRateLimitRuleExceeded"
 
-var NewErrProjectRateLimitRuleExceeded = func(project string, budget string, rule string) error {
+var NewErrProjectRateLimitRuleExceeded = func(project string, rule *RateLimitRuleInfo) error {
 	return &ErrProjectRateLimitRuleExceeded{
 		BaseError{
 			Code:    ErrCodeProjectRateLimitRuleExceeded,
 			Message: "project-level rate limit rule exceeded",
 			Details: map[string]interface{}{
 				"project": project,
-				"budget":  budget,
+				"budget":  rule.Budget,
 				"rule":    rule,
 			},
 		},
@@ -1731,11 +1837,15 @@ func (e *ErrProjectRateLimitRuleExceeded) ErrorStatusCode() int {
 	return http.StatusTooManyRequests
 }
 
+func (e *ErrProjectRateLimitRuleExceeded) RateLimitRule() *RateLimitRuleInfo {
+	return rateLimitRuleFromDetails(e.Details)
+}
+
 type ErrNetworkRateLimitRuleExceeded struct{ BaseError }
 
 const ErrCodeNetworkRateLimitRuleExceeded ErrorCode = "ErrNetworkRateLimitRuleExceeded"
 
-var NewErrNetworkRateLimitRuleExceeded = func(project string, network string, budget string, rule string) error {
+var NewErrNetworkRateLimitRuleExceeded = func(project string, network string, rule *RateLimitRuleInfo) error {
 	return &ErrNetworkRateLimitRuleExceeded{
 		BaseError{
 			Code:    ErrCodeNetworkRateLimitRuleExceeded,
@@ -1743,7 +1853,7 @@ var NewErrNetworkRateLimitRuleExceeded = func(project string, network string, bu
 			Details: map[string]interface{}{
 				"project": project,
 				"network": network,
-				"budget":  budget,
+				"budget":  rule.Budget,
 				"rule":    rule,
 			},
 		},
@@ -1754,6 +1864,10 @@ func (e *ErrNetworkRateLimitRuleExceeded) ErrorStatusCode() int {
 	return http.StatusTooManyRequests
 }
 
+func (e *ErrNetworkRateLimitRuleExceeded) RateLimitRule() *RateLimitRuleInfo {
+	return rateLimitRuleFromDetails(e.Details)
+}
+
 type ErrNetworkRequestTimeout struct{ BaseError }
 
 const ErrCodeNetworkRequestTimeout ErrorCode = "ErrNetworkRequestTimeout"
@@ -1794,6 +1908,27 @@ func (e *ErrUpstreamRateLimitRuleExceeded) ErrorStatusCode() int {
 	return http.StatusTooManyRequests
 }
 
+type ErrUpstreamConcurrencyLimitExceeded struct{ BaseError }
+
+const ErrCodeUpstreamConcurrencyLimitExceeded ErrorCode = "ErrUpstreamConcurrencyLimitExceeded"
+
+var NewErrUpstreamConcurrencyLimitExceeded = func(upstreamId string, limit int64) error {
+	return &ErrUpstreamConcurrencyLimitExceeded{
+		BaseError{
+			Code:    ErrCodeUpstreamConcurrencyLimitExceeded,
+			Message: "upstream-level concurrency limit exceeded",
+			Details: map[string]interface{}{
+				"upstreamId": upstreamId,
+				"limit":      limit,
+			},
+		},
+	}
+}
+
+func (e *ErrUpstreamConcurrencyLimitExceeded) ErrorStatusCode() int {
+	return http.StatusTooManyRequests
+}
+
 type ErrUpstreamExcludedByPolicy struct{ BaseError }
 
 const ErrCodeUpstreamExcludedByPolicy ErrorCode = "ErrUpstreamExcludedByPolicy"
@@ -2005,6 +2140,25 @@ func (e *ErrEndpointCapacityExceeded) ErrorStatusCode() int {
 	return http.StatusTooManyRequests
 }
 
+// WithRetryAfter records how long the remote endpoint asked clients to back off for (e.g. via Retry-After).
+func (e *ErrEndpointCapacityExceeded) WithRetryAfter(d time.Duration) *ErrEndpointCapacityExceeded {
+	if d <= 0 {
+		return e
+	}
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details["retryAfter"] = Duration(d)
+	return e
+}
+
+func (e *ErrEndpointCapacityExceeded) RetryAfter() time.Duration {
+	if d, ok := e.Details["retryAfter"].(Duration); ok {
+		return d.Duration()
+	}
+	return 0
+}
+
 type ErrEndpointBillingIssue struct{ BaseError }
 
 const ErrCodeEndpointBillingIssue = "ErrEndpointBillingIssue"
@@ -2417,7 +2571,9 @@ func IsCapacityIssue(err error) bool {
 		ErrCodeProjectRateLimitRuleExceeded,
 		ErrCodeNetworkRateLimitRuleExceeded,
 		ErrCodeUpstreamRateLimitRuleExceeded,
+		ErrCodeUpstreamConcurrencyLimitExceeded,
 		ErrCodeAuthRateLimitRuleExceeded,
+		ErrCodeAuthQuotaExceeded,
 		ErrCodeEndpointCapacityExceeded,
 	)
 }
@@ -2466,6 +2622,8 @@ type ParticipantInfo struct {
 	Upstream   string `json:"upstream"`
 	ResultHash string `json:"resultHash,omitempty"`
 	ErrSummary string `json:"errorSummary,omitempty"`
+	// Weight is the vote weight of the upstream, omitted when consensus weights are not used
+	Weight float64 `json:"weight,omitempty"`
 }
 
 type ErrConsensusDispute struct{ BaseError }
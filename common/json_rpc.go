@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"sort"
@@ -1448,12 +1449,37 @@ func TranslateToJsonRpcException(err error) error {
 		ErrCodeProjectRateLimitRuleExceeded,
 		ErrCodeNetworkRateLimitRuleExceeded,
 		ErrCodeUpstreamRateLimitRuleExceeded,
+		ErrCodeUpstreamConcurrencyLimitExceeded,
 	) {
+		var details map[string]interface{}
+		// Name the budget and rule that was hit so that clients can throttle themselves accordingly
+		if rule := LookupRateLimitRule(err); rule != nil {
+			details = map[string]interface{}{
+				"data": rule,
+			}
+		}
 		return NewErrJsonRpcExceptionInternal(
 			0,
 			JsonRpcErrorCapacityExceeded,
 			"rate-limit exceeded",
 			err,
+			details,
+		)
+	}
+	if HasErrorCode(
+		err,
+		ErrCodeAuthQuotaExceeded,
+	) {
+		msg := "quota exceeded"
+		var qe *ErrAuthQuotaExceeded
+		if errors.As(err, &qe) {
+			msg = qe.Message
+		}
+		return NewErrJsonRpcExceptionInternal(
+			0,
+			JsonRpcErrorCapacityExceeded,
+			msg,
+			err,
 			nil,
 		)
 	}
@@ -1469,6 +1495,23 @@ func TranslateToJsonRpcException(err error) error {
 			nil,
 		)
 	}
+	if HasErrorCode(
+		err,
+		ErrCodeAuthForbidden,
+	) {
+		msg := "forbidden"
+		var fe *ErrAuthForbidden
+		if errors.As(err, &fe) {
+			msg = fe.Message
+		}
+		return NewErrJsonRpcExceptionInternal(
+			0,
+			JsonRpcErrorUnauthorized,
+			msg,
+			err,
+			nil,
+		)
+	}
 	if HasErrorCode(err, ErrCodeUpstreamMethodIgnored) {
 		return NewErrJsonRpcExceptionInternal(
 			0,
@@ -25,6 +25,8 @@ type Network interface {
 	GetMethodMetrics(method string) TrackedMetrics
 	Forward(ctx context.Context, nq *NormalizedRequest) (*NormalizedResponse, error)
 	GetFinality(ctx context.Context, req *NormalizedRequest, resp *NormalizedResponse) DataFinalityState
+	// DisputeRecorder returns nil when disputes are not recorded for the project
+	DisputeRecorder() DisputeRecorder
 
 	// TODO Move to EvmNetwork interface?
 	EvmHighestLatestBlockNumber(ctx context.Context) int64
@@ -3,4 +3,43 @@ package common
 type User struct {
 	Id                 string
 	PerSecondRateLimit int64
+	Quota              *UserQuota
+	// RateLimitBudget overrides the rate limit budget of the auth strategy for this user.
+	RateLimitBudget string
+	// AllowedNetworks (e.g. "evm:1") and AllowedMethods restrict what this user can access,
+	// wildcard patterns are supported and an empty list means no restriction.
+	AllowedNetworks []string
+	AllowedMethods  []string
+}
+
+func (u *User) IsNetworkAllowed(networkId string) bool {
+	return u == nil || matchesAnyPattern(u.AllowedNetworks, networkId)
+}
+
+func (u *User) IsMethodAllowed(method string) bool {
+	return u == nil || matchesAnyPattern(u.AllowedMethods, method)
+}
+
+func matchesAnyPattern(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if match, err := WildcardMatch(pattern, value); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// UserQuota limits the usage of an API key per calendar day and month (in UTC), zero means unlimited.
+type UserQuota struct {
+	DailyRequests       int64 `json:"dailyRequests,omitempty"`
+	MonthlyRequests     int64 `json:"monthlyRequests,omitempty"`
+	DailyComputeUnits   int64 `json:"dailyComputeUnits,omitempty"`
+	MonthlyComputeUnits int64 `json:"monthlyComputeUnits,omitempty"`
+}
+
+func (q *UserQuota) IsEmpty() bool {
+	return q == nil || (q.DailyRequests <= 0 && q.MonthlyRequests <= 0 && q.DailyComputeUnits <= 0 && q.MonthlyComputeUnits <= 0)
 }
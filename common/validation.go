@@ -2,6 +2,8 @@ package common
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
@@ -50,6 +52,9 @@ func (c *Config) Validate() error {
 	} else {
 		return fmt.Errorf("projects config is required")
 	}
+	if c.HasAuthStrategy(AuthTypeMtls) && (c.Server.TLS == nil || !c.Server.TLS.Enabled) {
+		return fmt.Errorf("auth.*.mtls strategy requires server.tls.enabled to be true, as client certificates are only presented over TLS")
+	}
 	if c.RateLimiters != nil {
 		if err := c.RateLimiters.Validate(); err != nil {
 			return err
@@ -89,6 +94,30 @@ func (s *ServerConfig) Validate() error {
 	if s.MaxTimeout == nil || *s.MaxTimeout == 0 {
 		return fmt.Errorf("server.maxTimeout is required")
 	}
+	if s.WebSocket != nil {
+		if err := s.WebSocket.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *WebSocketServerConfig) Validate() error {
+	if w.MaxMessageSize < 0 {
+		return fmt.Errorf("server.webSocket.maxMessageSize must be greater than or equal to 0")
+	}
+	if w.MaxSubscriptionsPerConnection < 0 {
+		return fmt.Errorf("server.webSocket.maxSubscriptionsPerConnection must be greater than or equal to 0")
+	}
+	if w.MaxInFlightPerConnection < 0 {
+		return fmt.Errorf("server.webSocket.maxInFlightPerConnection must be greater than or equal to 0")
+	}
+	if w.MaxUpstreamSubscriptions < 0 {
+		return fmt.Errorf("server.webSocket.maxUpstreamSubscriptions must be greater than or equal to 0")
+	}
+	if w.HeadsPollInterval < 0 {
+		return fmt.Errorf("server.webSocket.headsPollInterval must be greater than or equal to 0")
+	}
 	return nil
 }
 
@@ -142,6 +171,22 @@ func (m *MetricsConfig) Validate() error {
 }
 
 func (r *RateLimiterConfig) Validate() error {
+	if r.Store != nil {
+		if err := r.Store.Validate(); err != nil {
+			return err
+		}
+	}
+	for method, cu := range r.ComputeUnits {
+		if method == "" {
+			return fmt.Errorf("rateLimiters.computeUnits keys must not be empty")
+		}
+		if err := ValidatePattern(method); err != nil {
+			return fmt.Errorf("rateLimiters.computeUnits key '%s' is not a valid pattern: %w", method, err)
+		}
+		if cu == 0 {
+			return fmt.Errorf("rateLimiters.computeUnits.%s must be greater than 0", method)
+		}
+	}
 	if len(r.Budgets) > 0 {
 		for _, budget := range r.Budgets {
 			if err := budget.Validate(); err != nil {
@@ -152,6 +197,23 @@ func (r *RateLimiterConfig) Validate() error {
 	return nil
 }
 
+func (s *RateLimitStoreConfig) Validate() error {
+	if s.Connector == nil {
+		return fmt.Errorf("rateLimiters.store.connector is required")
+	}
+	// Only connectors that can atomically increment counters are supported
+	if s.Connector.Driver != DriverRedis {
+		return fmt.Errorf("rateLimiters.store.connector.driver must be %s, got %s", DriverRedis, s.Connector.Driver)
+	}
+	if err := s.Connector.Validate(); err != nil {
+		return err
+	}
+	if s.Timeout <= 0 {
+		return fmt.Errorf("rateLimiters.store.timeout must be greater than 0")
+	}
+	return nil
+}
+
 func (b *RateLimitBudgetConfig) Validate() error {
 	if len(b.Rules) == 0 {
 		return fmt.Errorf("rateLimiter.*.budget.rules is required, add at least one rule")
@@ -171,6 +233,9 @@ func (r *RateLimitRuleConfig) Validate() error {
 	if r.WaitTime == 0 {
 		return fmt.Errorf("rateLimiter.*.budget.rules.*.waitTime is required")
 	}
+	if r.Unit != RateLimitUnitRequest && r.Unit != RateLimitUnitComputeUnit {
+		return fmt.Errorf("rateLimiter.*.budget.rules.*.unit must be either '%s' or '%s', got '%s'", RateLimitUnitRequest, RateLimitUnitComputeUnit, r.Unit)
+	}
 	return nil
 }
 
@@ -270,6 +335,9 @@ func (c *CacheConfig) Validate() error {
 			return err
 		}
 	}
+	if c.Reorgs != nil && c.Reorgs.TrackedBlocks < 0 {
+		return fmt.Errorf("cache.reorgs.trackedBlocks must be greater than or equal to 0")
+	}
 	return nil
 }
 
@@ -359,7 +427,7 @@ func (c *ConnectorConfig) Validate() error {
 	if c.Driver == "" {
 		return fmt.Errorf("database.*.connector.driver is required")
 	}
-	drivers := []ConnectorDriverType{DriverMemory, DriverRedis, DriverPostgreSQL, DriverDynamoDB, DriverGrpc}
+	drivers := []ConnectorDriverType{DriverMemory, DriverRedis, DriverPostgreSQL, DriverDynamoDB, DriverGrpc, DriverTiered, DriverBadger, DriverScylla}
 	if !slices.Contains(drivers, c.Driver) {
 		return fmt.Errorf("database.*.connector.driver '%s' is invalid must be one of: %v", c.Driver, drivers)
 	}
@@ -375,6 +443,24 @@ func (c *ConnectorConfig) Validate() error {
 	if c.Driver == DriverDynamoDB && c.DynamoDB == nil {
 		return fmt.Errorf("database.*.connector.dynamodb is required when driver is dynamodb")
 	}
+	if c.Driver == DriverScylla && c.Scylla == nil {
+		return fmt.Errorf("database.*.connector.scylla is required when driver is scylla")
+	}
+	if c.Driver == DriverBadger && c.Badger == nil {
+		return fmt.Errorf("database.*.connector.badger is required when driver is badger")
+	}
+	if c.Driver == DriverTiered && c.Tiered == nil {
+		return fmt.Errorf("database.*.connector.tiered is required when driver is tiered")
+	}
+	if c.Tiered != nil && (c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil || c.Badger != nil || c.Scylla != nil) {
+		return fmt.Errorf("database.*.connector.tiered is mutually exclusive with database.*.connector.memory, database.*.connector.redis, database.*.connector.postgresql, database.*.connector.dynamodb, database.*.connector.badger, and database.*.connector.scylla (use tiered.l2 instead)")
+	}
+	if c.Badger != nil && (c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil || c.Scylla != nil) {
+		return fmt.Errorf("database.*.connector.badger is mutually exclusive with database.*.connector.memory, database.*.connector.redis, database.*.connector.postgresql, database.*.connector.dynamodb, and database.*.connector.scylla")
+	}
+	if c.Scylla != nil && (c.Memory != nil || c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil) {
+		return fmt.Errorf("database.*.connector.scylla is mutually exclusive with database.*.connector.memory, database.*.connector.redis, database.*.connector.postgresql, and database.*.connector.dynamodb")
+	}
 
 	// TODO switch to go-validator library :D
 	if c.Memory != nil && (c.Redis != nil || c.PostgreSQL != nil || c.DynamoDB != nil) {
@@ -410,10 +496,76 @@ func (c *ConnectorConfig) Validate() error {
 			return err
 		}
 	}
+	if c.Scylla != nil {
+		if err := c.Scylla.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Badger != nil {
+		if err := c.Badger.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Tiered != nil {
+		if err := c.Tiered.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ScyllaConnectorConfig) Validate() error {
+	if len(s.Hosts) == 0 {
+		return fmt.Errorf("database.*.connector.scylla.hosts is required")
+	}
+	if s.Keyspace == "" {
+		return fmt.Errorf("database.*.connector.scylla.keyspace is required")
+	}
+	if s.Table == "" {
+		return fmt.Errorf("database.*.connector.scylla.table is required")
+	}
+	levels := []string{"ANY", "ONE", "TWO", "THREE", "QUORUM", "ALL", "LOCAL_QUORUM", "EACH_QUORUM", "LOCAL_ONE"}
+	if !slices.Contains(levels, strings.ToUpper(s.Consistency)) {
+		return fmt.Errorf("database.*.connector.scylla.consistency '%s' is invalid must be one of: %v", s.Consistency, levels)
+	}
+	if s.ReplicationFactor < 1 {
+		return fmt.Errorf("database.*.connector.scylla.replicationFactor must be greater than 0")
+	}
+	if s.InitTimeout <= 0 || s.GetTimeout <= 0 || s.SetTimeout <= 0 {
+		return fmt.Errorf("database.*.connector.scylla.initTimeout, getTimeout and setTimeout must be greater than 0")
+	}
+	return nil
+}
 
+func (b *BadgerConnectorConfig) Validate() error {
+	if b.Dir == "" {
+		return fmt.Errorf("database.*.connector.badger.dir is required")
+	}
+	if b.GCInterval < 0 {
+		return fmt.Errorf("database.*.connector.badger.gcInterval must be greater than or equal to 0")
+	}
 	return nil
 }
 
+func (t *TieredConnectorConfig) Validate() error {
+	if t.L2 == nil {
+		return fmt.Errorf("database.*.connector.tiered.l2 is required")
+	}
+	if t.L2.Driver == DriverTiered || t.L2.Driver == DriverMemory {
+		return fmt.Errorf("database.*.connector.tiered.l2.driver must be a persistent connector (redis, postgresql, dynamodb, scylla, badger or grpc), got '%s'", t.L2.Driver)
+	}
+	if t.L1TTL < 0 {
+		return fmt.Errorf("database.*.connector.tiered.l1Ttl must be greater than or equal to 0")
+	}
+	if t.L1 != nil {
+		if err := t.L1.Validate(); err != nil {
+			return err
+		}
+	}
+	return t.L2.Validate()
+}
+
 func (p *DynamoDBConnectorConfig) Validate() error {
 	if p.Table == "" {
 		return fmt.Errorf("database.*.connector.dynamodb.table is required")
@@ -471,6 +623,14 @@ func (p *PostgreSQLConnectorConfig) Validate() error {
 }
 
 func (c *RedisConnectorConfig) Validate() error {
+	switch c.Mode {
+	case RedisModeCluster, RedisModeSentinel:
+		return c.validateMultiNode()
+	case "", RedisModeStandalone:
+	default:
+		return fmt.Errorf("database.*.connector.redis.mode must be one of: %s, %s, %s", RedisModeStandalone, RedisModeCluster, RedisModeSentinel)
+	}
+
 	uri := strings.TrimSpace(c.URI)
 	if uri == "" {
 		return fmt.Errorf("database.*.connector.redis.uri is required")
@@ -489,6 +649,36 @@ func (c *RedisConnectorConfig) Validate() error {
 	return nil
 }
 
+func (c *RedisConnectorConfig) validateMultiNode() error {
+	if len(c.Addrs) == 0 {
+		return fmt.Errorf("database.*.connector.redis.addrs is required when mode is %s", c.Mode)
+	}
+	for _, addr := range c.Addrs {
+		if strings.TrimSpace(addr) == "" {
+			return fmt.Errorf("database.*.connector.redis.addrs must not contain empty addresses")
+		}
+	}
+	if c.URI != "" || c.Addr != "" {
+		return fmt.Errorf("database.*.connector.redis.uri and addr are not supported when mode is %s, use addrs instead", c.Mode)
+	}
+	if c.Mode == RedisModeCluster {
+		if c.DB != 0 {
+			return fmt.Errorf("database.*.connector.redis.db must be 0 when mode is cluster, as Redis Cluster only supports database 0")
+		}
+		if c.MasterName != "" {
+			return fmt.Errorf("database.*.connector.redis.masterName is only supported when mode is sentinel")
+		}
+	}
+	if c.Mode == RedisModeSentinel && c.MasterName == "" {
+		return fmt.Errorf("database.*.connector.redis.masterName is required when mode is sentinel")
+	}
+	if c.LockRetryInterval.Duration() < 100*time.Millisecond && c.LockRetryInterval.Duration() > 0 {
+		return fmt.Errorf("redis.lockRetryInterval should be at least 100ms to avoid excessive Redis load")
+	}
+
+	return nil
+}
+
 func (p *MemoryConnectorConfig) Validate() error {
 	return nil
 }
@@ -561,6 +751,30 @@ func (p *ProjectConfig) Validate(c *Config) error {
 	if p.ScoreMetricsWindowSize == 0 {
 		return fmt.Errorf("project.*.scoreMetricsWindowSize is required")
 	}
+	if p.Disputes != nil {
+		if err := p.Disputes.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DisputesConfig) Validate() error {
+	if d.Connector == nil {
+		return fmt.Errorf("project.*.disputes.connector is required")
+	}
+	if d.Connector.Driver == DriverMemory || d.Connector.Driver == DriverGrpc {
+		return fmt.Errorf("project.*.disputes.connector cannot use %s driver because records must be listed, use redis, postgresql, dynamodb, scylla or badger", d.Connector.Driver)
+	}
+	if err := d.Connector.Validate(); err != nil {
+		return err
+	}
+	if d.Retention <= 0 {
+		return fmt.Errorf("project.*.disputes.retention must be greater than 0")
+	}
+	if d.MaxBodySize <= 0 {
+		return fmt.Errorf("project.*.disputes.maxBodySize must be greater than 0")
+	}
 	return nil
 }
 
@@ -631,6 +845,20 @@ func (s *AuthStrategyConfig) Validate() error {
 		if err := s.Database.Validate(); err != nil {
 			return err
 		}
+	case AuthTypeHmac:
+		if s.Hmac == nil {
+			return fmt.Errorf("auth.*.hmac is required for hmac strategy")
+		}
+		if err := s.Hmac.Validate(); err != nil {
+			return err
+		}
+	case AuthTypeMtls:
+		if s.Mtls == nil {
+			return fmt.Errorf("auth.*.mtls is required for mtls strategy")
+		}
+		if err := s.Mtls.Validate(); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("auth.*.type '%s' is invalid must be one of: %v", s.Type, []AuthType{
 			AuthTypeNetwork,
@@ -638,6 +866,8 @@ func (s *AuthStrategyConfig) Validate() error {
 			AuthTypeJwt,
 			AuthTypeSiwe,
 			AuthTypeDatabase,
+			AuthTypeMtls,
+			AuthTypeHmac,
 		})
 	}
 	return nil
@@ -663,6 +893,16 @@ func (s *DatabaseStrategyConfig) Validate() error {
 		}
 	}
 
+	if s.KeyHashing != nil && len(s.KeyHashing.Pepper) < 16 {
+		return fmt.Errorf("auth.*.database.keyHashing.pepper must be at least 16 characters, use a long random secret (e.g. from an env variable)")
+	}
+
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("auth.*.database.trustedProxies must only contain IPs or CIDRs, invalid value: %s", proxy)
+		}
+	}
+
 	return s.Connector.Validate()
 }
 
@@ -678,16 +918,92 @@ func (s *SecretStrategyConfig) Validate() error {
 }
 
 func (j *JwtStrategyConfig) Validate() error {
-	if len(j.VerificationKeys) == 0 {
-		return fmt.Errorf("auth.*.jwt.verificationKeys is required, add at least one verification key")
+	if len(j.VerificationKeys) == 0 && j.JwksUrl == "" {
+		return fmt.Errorf("auth.*.jwt.verificationKeys or auth.*.jwt.jwksUrl is required, add at least one verification key")
+	}
+	if j.JwksUrl != "" {
+		u, err := url.Parse(j.JwksUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("auth.*.jwt.jwksUrl must be a valid http(s) url")
+		}
+		if j.JwksRefreshInterval <= 0 {
+			return fmt.Errorf("auth.*.jwt.jwksRefreshInterval must be greater than 0")
+		}
+		if j.JwksMinRefreshInterval < 0 {
+			return fmt.Errorf("auth.*.jwt.jwksMinRefreshInterval must be greater than or equal to 0")
+		}
+	}
+	if j.ClockSkew < 0 {
+		return fmt.Errorf("auth.*.jwt.clockSkew must be greater than or equal to 0")
 	}
 	return nil
 }
 
-func (s *SiweStrategyConfig) Validate() error {
+func (h *HmacStrategyConfig) Validate() error {
+	if h.Connector == nil {
+		return fmt.Errorf("auth.*.hmac.connector is required")
+	}
+	if h.MaxClockSkew <= 0 {
+		return fmt.Errorf("auth.*.hmac.maxClockSkew must be greater than 0")
+	}
+	if h.CacheTTL < 0 {
+		return fmt.Errorf("auth.*.hmac.cacheTtl must be greater than or equal to 0")
+	}
+	return h.Connector.Validate()
+}
+
+func (m *MtlsStrategyConfig) Validate() error {
+	if m.CAFile == "" {
+		return fmt.Errorf("auth.*.mtls.caFile is required, it must contain the CAs that client certificates are issued by")
+	}
+	switch m.UserIdFrom {
+	case MtlsIdentitySourceSpiffeId, MtlsIdentitySourceUriSan, MtlsIdentitySourceDnsSan, MtlsIdentitySourceEmailSan, MtlsIdentitySourceCommonName:
+	default:
+		return fmt.Errorf("auth.*.mtls.userIdFrom '%s' is invalid must be one of: %v", m.UserIdFrom, []MtlsIdentitySource{
+			MtlsIdentitySourceSpiffeId,
+			MtlsIdentitySourceUriSan,
+			MtlsIdentitySourceDnsSan,
+			MtlsIdentitySourceEmailSan,
+			MtlsIdentitySourceCommonName,
+		})
+	}
+	for _, pattern := range append(append([]string{}, m.AllowedSANs...), m.DeniedSANs...) {
+		if err := ValidatePattern(pattern); err != nil {
+			return fmt.Errorf("auth.*.mtls.allowedSANs/deniedSANs pattern '%s' is invalid: %w", pattern, err)
+		}
+	}
+	for _, budget := range m.RateLimitBudgets {
+		if budget == nil || budget.Identity == "" || budget.RateLimitBudget == "" {
+			return fmt.Errorf("auth.*.mtls.rateLimitBudgets.* requires both identity and rateLimitBudget")
+		}
+		if err := ValidatePattern(budget.Identity); err != nil {
+			return fmt.Errorf("auth.*.mtls.rateLimitBudgets.*.identity '%s' is invalid: %w", budget.Identity, err)
+		}
+	}
 	return nil
 }
 
+func (s *SiweStrategyConfig) Validate() error {
+	if s.Connector == nil {
+		if s.SessionTTL != 0 {
+			return fmt.Errorf("auth.*.siwe.connector is required when auth.*.siwe.sessionTtl is set, as sessions are stored in the connector")
+		}
+		return nil
+	}
+	if s.NonceTTL <= 0 {
+		return fmt.Errorf("auth.*.siwe.nonceTtl must be greater than 0")
+	}
+	if s.SessionTTL < 0 {
+		return fmt.Errorf("auth.*.siwe.sessionTtl must be greater than or equal to 0")
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("auth.*.siwe.trustedProxies must only contain IPs or CIDRs, invalid value: %s", proxy)
+		}
+	}
+	return s.Connector.Validate()
+}
+
 func (c *CORSConfig) Validate() error {
 	if len(c.AllowedOrigins) == 0 {
 		return fmt.Errorf("*.cors.allowedOrigins is required, add at least one allowed origin")
@@ -765,6 +1081,16 @@ func (u *UpstreamConfig) Validate(c *Config, skipEndpointCheck bool) error {
 			return err
 		}
 	}
+	if u.RateLimitCooldown != nil {
+		if err := u.RateLimitCooldown.Validate(); err != nil {
+			return err
+		}
+	}
+	if u.ConcurrencyLimit != nil {
+		if err := u.ConcurrencyLimit.Validate(); err != nil {
+			return err
+		}
+	}
 	if u.Routing != nil {
 		if err := u.Routing.Validate(); err != nil {
 			return err
@@ -890,7 +1216,21 @@ func (c *ConsensusPolicyConfig) Validate() error {
 	if c.AgreementThreshold <= 0 {
 		return fmt.Errorf("consensus.agreementThreshold must be greater than 0")
 	}
-	if c.MaxParticipants < c.AgreementThreshold {
+	for i, w := range c.Weights {
+		if w == nil {
+			return fmt.Errorf("consensus.weights.%d must not be null", i)
+		}
+		if w.Upstream == "" && w.Group == "" {
+			return fmt.Errorf("consensus.weights.%d must have an upstream or group", i)
+		}
+		if w.Weight <= 0 {
+			return fmt.Errorf("consensus.weights.%d.weight must be greater than 0", i)
+		}
+	}
+	if float64(c.MaxParticipants)*MaxConsensusWeight(c.Weights) < float64(c.AgreementThreshold) {
+		if len(c.Weights) > 0 {
+			return fmt.Errorf("consensus.agreementThreshold must be reachable by the total weight of maxParticipants")
+		}
 		return fmt.Errorf("consensus.maxParticipants must be greater than or equal to agreementThreshold")
 	}
 	if c.PunishMisbehavior != nil {
@@ -938,6 +1278,24 @@ func (j *JsonRpcUpstreamConfig) Validate(c *Config) error {
 			return fmt.Errorf("jsonRpc.proxyPool '%s' does not exist in configured proxyPools, must be one of: %v", j.ProxyPool, allIds)
 		}
 	}
+	if j.WebSocket != nil {
+		if err := j.WebSocket.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *WebSocketUpstreamConfig) Validate() error {
+	if w.MaxConnections < 0 {
+		return fmt.Errorf("jsonRpc.webSocket.maxConnections must be greater than or equal to 0")
+	}
+	if w.ReconnectMaxDelay > 0 && w.ReconnectMinDelay > w.ReconnectMaxDelay {
+		return fmt.Errorf("jsonRpc.webSocket.reconnectMinDelay must be less than or equal to reconnectMaxDelay")
+	}
+	if w.MaxMessageSize < 0 {
+		return fmt.Errorf("jsonRpc.webSocket.maxMessageSize must be greater than or equal to 0")
+	}
 	return nil
 }
 
@@ -966,6 +1324,50 @@ func (r *RateLimitAutoTuneConfig) Validate() error {
 	return nil
 }
 
+func (r *RateLimitCooldownConfig) Validate() error {
+	if r.Scope != "" && r.Scope != RateLimitCooldownScopeMethod && r.Scope != RateLimitCooldownScopeUpstream {
+		return fmt.Errorf("upstream.*.rateLimitCooldown.scope must be either 'method' or 'upstream'")
+	}
+	if r.DefaultDuration < 0 {
+		return fmt.Errorf("upstream.*.rateLimitCooldown.defaultDuration must be greater than or equal to 0")
+	}
+	if r.MaxDuration < 0 {
+		return fmt.Errorf("upstream.*.rateLimitCooldown.maxDuration must be greater than or equal to 0")
+	}
+	return nil
+}
+
+func (c *ConcurrencyLimitConfig) Validate() error {
+	if c.Enabled == nil || !*c.Enabled {
+		return nil
+	}
+	if c.Algorithm != ConcurrencyLimitAlgorithmGradient && c.Algorithm != ConcurrencyLimitAlgorithmAimd {
+		return fmt.Errorf("upstream.*.concurrencyLimit.algorithm must be either 'gradient' or 'aimd'")
+	}
+	if c.MinLimit < 1 {
+		return fmt.Errorf("upstream.*.concurrencyLimit.minLimit must be greater than or equal to 1")
+	}
+	if c.MaxLimit < c.MinLimit {
+		return fmt.Errorf("upstream.*.concurrencyLimit.maxLimit must be greater than or equal to minLimit")
+	}
+	if c.InitialLimit < c.MinLimit || c.InitialLimit > c.MaxLimit {
+		return fmt.Errorf("upstream.*.concurrencyLimit.initialLimit must be between minLimit and maxLimit")
+	}
+	if c.AdjustmentPeriod <= 0 {
+		return fmt.Errorf("upstream.*.concurrencyLimit.adjustmentPeriod must be greater than 0")
+	}
+	if c.LatencyQuantile <= 0 || c.LatencyQuantile > 1 {
+		return fmt.Errorf("upstream.*.concurrencyLimit.latencyQuantile must be greater than 0 and less than or equal to 1")
+	}
+	if c.Tolerance < 1 {
+		return fmt.Errorf("upstream.*.concurrencyLimit.tolerance must be greater than or equal to 1")
+	}
+	if c.BackoffRatio <= 0 || c.BackoffRatio >= 1 {
+		return fmt.Errorf("upstream.*.concurrencyLimit.backoffRatio must be greater than 0 and less than 1")
+	}
+	return nil
+}
+
 func (r *RoutingConfig) Validate() error {
 	if len(r.ScoreMultipliers) > 0 {
 		for _, multiplier := range r.ScoreMultipliers {
@@ -1028,6 +1430,41 @@ func (e *EvmNetworkConfig) Validate() error {
 	if e.GetLogsMaxAllowedRange == 0 {
 		return fmt.Errorf("network.*.evm.getLogsMaxAllowedRange must be greater than 0")
 	}
+	if e.Filters != nil {
+		if err := e.Filters.Validate(); err != nil {
+			return err
+		}
+	}
+	if e.TxBroadcast != nil {
+		if err := e.TxBroadcast.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *EvmFiltersConfig) Validate() error {
+	if f.Ttl < 0 {
+		return fmt.Errorf("network.*.evm.filters.ttl must be greater than or equal to 0")
+	}
+	if f.MaxBlocksPerPoll < 0 {
+		return fmt.Errorf("network.*.evm.filters.maxBlocksPerPoll must be greater than or equal to 0")
+	}
+	return nil
+}
+
+func (b *EvmTxBroadcastConfig) Validate() error {
+	if b.MaxUpstreams < 0 {
+		return fmt.Errorf("network.*.evm.txBroadcast.maxUpstreams must be greater than or equal to 0")
+	}
+	if b.DedupWindow < 0 {
+		return fmt.Errorf("network.*.evm.txBroadcast.dedupWindow must be greater than or equal to 0")
+	}
+	for _, pattern := range b.Upstreams {
+		if pattern == "" {
+			return fmt.Errorf("network.*.evm.txBroadcast.upstreams must not contain empty patterns")
+		}
+	}
 	return nil
 }
 
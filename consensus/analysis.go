@@ -11,10 +11,12 @@ import (
 
 // responseGroup holds all responses that share the same hash.
 type responseGroup struct {
-	Hash    string
-	Results []*execResult
-	Count   int
-	IsTie   bool // Flag to indicate a tie with another group
+	Hash      string
+	Results   []*execResult
+	Count     int
+	Votes     float64 // Total weight of the results, equal to Count unless consensus weights are configured
+	BaseVotes float64 // Total configured weight of the results (not scaled by agreement rate), compared with the threshold
+	IsTie     bool    // Flag to indicate a tie with another group
 
 	ResponseType ResponseType
 	ResponseSize int // Size of the largest result in the group
@@ -25,18 +27,22 @@ type responseGroup struct {
 	HasResult     bool
 }
 
-func (g *responseGroup) participants() []common.ParticipantInfo {
+func (g *responseGroup) participants(withWeights bool) []common.ParticipantInfo {
 	participants := make([]common.ParticipantInfo, 0, len(g.Results))
 	for _, r := range g.Results {
 		upsId := "n/a"
 		if r.Upstream != nil {
 			upsId = r.Upstream.Id()
 		}
-		participants = append(participants, common.ParticipantInfo{
+		info := common.ParticipantInfo{
 			Upstream:   upsId,
 			ResultHash: r.CachedHash,
 			ErrSummary: common.ErrorSummary(r.Err),
-		})
+		}
+		if withWeights {
+			info.Weight = r.Weight
+		}
+		participants = append(participants, info)
 	}
 	return participants
 }
@@ -47,6 +53,8 @@ type consensusAnalysis struct {
 	groups            map[string]*responseGroup
 	totalParticipants int
 	validParticipants int
+	validVotes        float64
+	validBaseVotes    float64
 	originalRequest   *common.NormalizedRequest
 	leaderUpstream    common.Upstream
 
@@ -81,6 +89,8 @@ func newConsensusAnalysis(lg *zerolog.Logger, exec failsafe.Execution[*common.No
 
 		if r.CachedResponseType != ResponseTypeInfrastructureError {
 			analysis.validParticipants++
+			analysis.validVotes += r.Weight
+			analysis.validBaseVotes += r.BaseWeight
 		}
 
 		group, exists := analysis.groups[r.CachedHash]
@@ -94,6 +104,8 @@ func newConsensusAnalysis(lg *zerolog.Logger, exec failsafe.Execution[*common.No
 		}
 
 		group.Count++
+		group.Votes += r.Weight
+		group.BaseVotes += r.BaseWeight
 		group.Results = append(group.Results, r)
 		// Track the largest successful response in the group
 		if r.Err == nil {
@@ -113,24 +125,20 @@ func newConsensusAnalysis(lg *zerolog.Logger, exec failsafe.Execution[*common.No
 		}
 	}
 
-	// After grouping, compute tie flags among valid groups by response type (exclude infrastructure errors)
+	// After grouping, compute tie flags among valid groups by response type (exclude infrastructure errors).
+	// Votes might be fractional sums, so they are compared within an epsilon rather than used as map keys.
 	if len(analysis.groups) > 1 {
-		countsByType := make(map[ResponseType]map[int]int)
 		for _, g := range analysis.groups {
+			g.IsTie = false
 			if g.ResponseType == ResponseTypeInfrastructureError {
 				continue
 			}
-			if _, ok := countsByType[g.ResponseType]; !ok {
-				countsByType[g.ResponseType] = make(map[int]int)
-			}
-			countsByType[g.ResponseType][g.Count]++
-		}
-		for _, g := range analysis.groups {
-			if g.ResponseType == ResponseTypeInfrastructureError {
-				g.IsTie = false
-				continue
+			for _, other := range analysis.groups {
+				if other != g && other.ResponseType == g.ResponseType && compareVotes(other.Votes, g.Votes) == 0 {
+					g.IsTie = true
+					break
+				}
 			}
-			g.IsTie = countsByType[g.ResponseType][g.Count] > 1
 		}
 	}
 
@@ -141,10 +149,24 @@ func (a *consensusAnalysis) hasRemaining() bool {
 	return a.config.maxParticipants > a.totalParticipants
 }
 
+// remainingVotes is the most votes that participants yet to respond could add to a group.
+func (a *consensusAnalysis) remainingVotes() float64 {
+	remaining := a.config.maxParticipants - a.totalParticipants
+	if remaining <= 0 {
+		return 0
+	}
+	return float64(remaining) * a.config.maxWeight()
+}
+
+// meetsThreshold checks if the configured weights of a group reach the agreement threshold.
+func (a *consensusAnalysis) meetsThreshold(g *responseGroup) bool {
+	return compareVotes(g.BaseVotes, float64(a.config.agreementThreshold)) >= 0
+}
+
 func (a *consensusAnalysis) participants() []common.ParticipantInfo {
 	participants := make([]common.ParticipantInfo, 0, len(a.groups))
 	for _, group := range a.groups {
-		participants = append(participants, group.participants()...)
+		participants = append(participants, group.participants(a.config.hasWeights())...)
 	}
 	return participants
 }
@@ -170,7 +192,7 @@ func (a *consensusAnalysis) getBestNonEmpty() *responseGroup {
 			if group.ResponseType != ResponseTypeNonEmpty {
 				continue
 			}
-			if best == nil || group.Count > best.Count || (group.Count == best.Count && group.ResponseSize > best.ResponseSize) {
+			if best == nil || compareVotes(group.Votes, best.Votes) > 0 || (compareVotes(group.Votes, best.Votes) == 0 && group.ResponseSize > best.ResponseSize) {
 				best = group
 			}
 		}
@@ -189,7 +211,7 @@ func (a *consensusAnalysis) getBestEmpty() *responseGroup {
 					best = group
 					continue
 				}
-				if group.Count > best.Count {
+				if compareVotes(group.Votes, best.Votes) > 0 {
 					best = group
 				}
 			}
@@ -209,7 +231,7 @@ func (a *consensusAnalysis) getBestError() *responseGroup {
 					best = group
 					continue
 				}
-				if group.Count > best.Count {
+				if compareVotes(group.Votes, best.Votes) > 0 {
 					best = group
 				}
 			}
@@ -224,7 +246,7 @@ func (a *consensusAnalysis) getBestByCount() *responseGroup {
 	if a.cachedBestByCount == nil && len(a.groups) > 0 {
 		var best *responseGroup
 		for _, group := range a.groups {
-			if best == nil || group.Count > best.Count {
+			if best == nil || compareVotes(group.Votes, best.Votes) > 0 {
 				best = group
 			}
 		}
@@ -247,9 +269,9 @@ func (a *consensusAnalysis) getBestBySize() *responseGroup {
 	return a.cachedBestBySize
 }
 
-// isLowParticipants checks if we have insufficient participants
+// isLowParticipants checks if valid participants do not have enough configured weight to reach the threshold
 func (a *consensusAnalysis) isLowParticipants(threshold int) bool {
-	return a.validParticipants < threshold
+	return compareVotes(a.validBaseVotes, float64(threshold)) < 0
 }
 
 // getLeaderGroupNonError returns the response group that contains the leader upstream with a non-error response (non-empty or empty).
@@ -75,6 +75,10 @@ type execResult struct {
 	Result   *common.NormalizedResponse
 	Err      error
 	Upstream common.Upstream
+	// BaseWeight is the configured weight of the upstream, which counts toward the agreement threshold
+	BaseWeight float64
+	// Weight is the vote of this result when ranking results, i.e. BaseWeight scaled by the agreement rate
+	Weight float64
 
 	// Cached values to avoid re-computation
 	CachedHash         string
@@ -118,7 +122,7 @@ func (e *executor) Apply(innerFn func(failsafe.Execution[*common.NormalizedRespo
 		)
 
 		// Track misbehaviors while responses are still available
-		e.trackAndPunishMisbehavingUpstreams(&lg, originalReq, labels, winner, analysis)
+		e.trackAndPunishMisbehavingUpstreams(ctx, &lg, originalReq, labels, winner, analysis)
 
 		// Now release non-winning responses to free memory
 		if analysis != nil {
@@ -303,7 +307,7 @@ func (e *executor) executeParticipant(
 				Str("stack", string(debug.Stack())).
 				Msg("Panic in consensus participant")
 			telemetry.MetricConsensusPanics.WithLabelValues(labels.projectId, labels.networkId, labels.category, labels.finalityStr).Inc()
-			responseChan <- &execResult{Err: errPanicInConsensus}
+			responseChan <- &execResult{Err: errPanicInConsensus, BaseWeight: defaultWeight, Weight: defaultWeight}
 		}
 	}()
 
@@ -360,11 +364,14 @@ func (e *executor) executeParticipant(
 	if rr, ok := any(result.Result).(*common.NormalizedResponse); ok {
 		nr = rr
 	}
+	baseWeight, weight := e.upstreamWeights(upstream, labels.method)
 	responseChan <- &execResult{
-		Result:   nr,
-		Err:      result.Error,
-		Upstream: upstream,
-		Index:    index,
+		Result:     nr,
+		Err:        result.Error,
+		Upstream:   upstream,
+		BaseWeight: baseWeight,
+		Weight:     weight,
+		Index:      index,
 	}
 }
 
@@ -405,7 +412,7 @@ func (e *executor) determineWinner(lg *zerolog.Logger, analysis *consensusAnalys
 
 // --- Tracing, Metrics, and Punishment ---
 
-func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *common.NormalizedRequest, labels metricsLabels, winner *failsafeCommon.PolicyResult[*common.NormalizedResponse], analysis *consensusAnalysis) {
+func (e *executor) trackAndPunishMisbehavingUpstreams(ctx context.Context, lg *zerolog.Logger, req *common.NormalizedRequest, labels metricsLabels, winner *failsafeCommon.PolicyResult[*common.NormalizedResponse], analysis *consensusAnalysis) {
 	// Skip tracking when there are no valid participants (all infra errors)
 	if analysis.validParticipants == 0 {
 		return
@@ -436,7 +443,7 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 	// If no consensus group found from winner result, use the best group by count
 	if consensusGroup == nil {
 		for _, g := range analysis.getValidGroups() {
-			if consensusGroup == nil || g.Count > consensusGroup.Count {
+			if consensusGroup == nil || compareVotes(g.Votes, consensusGroup.Votes) > 0 {
 				consensusGroup = g
 			}
 		}
@@ -452,7 +459,14 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 	// Determine if the dispute log level is enabled; if not, avoid heavy preparation
 	shouldLog := e.logger.GetLevel() <= e.disputeLogLevel
 
-	// Only define participant tracking structures if we'll actually log
+	// Disputes are also persisted when the project has a disputes store
+	var recorder common.DisputeRecorder
+	if ntw := req.Network(); ntw != nil {
+		recorder = ntw.DisputeRecorder()
+	}
+	shouldCollect := shouldLog || recorder != nil
+
+	// Only define participant tracking structures if we'll actually log or record
 	type participantInfo struct {
 		upstreamId          string
 		upstream            common.Upstream
@@ -462,10 +476,11 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 		responseBody        []byte // Full response content for debugging disputes
 		errorMessage        string
 		agreesWithConsensus bool
+		weight              float64
 	}
 	var allParticipants []participantInfo
 	misbehavingParticipants := ""
-	if shouldLog {
+	if shouldCollect {
 		allParticipants = make([]participantInfo, 0, analysis.totalParticipants)
 	}
 	var misbehavingCount int
@@ -482,8 +497,8 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 
 			upstreamId := result.Upstream.Id()
 
-			// Only collect participant details if we'll actually log them
-			if shouldLog {
+			// Only collect participant details if we'll actually log or record them
+			if shouldCollect {
 				var responseHash string
 				var responseSize int
 				var responseBody []byte
@@ -531,6 +546,7 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 					responseBody:        responseBody,
 					errorMessage:        errorMessage,
 					agreesWithConsensus: agreesWithConsensus,
+					weight:              result.Weight,
 				})
 
 				if !agreesWithConsensus {
@@ -649,6 +665,7 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 			Str("category", labels.category).
 			Str("finality", labels.finalityStr).
 			Int("consensusCount", consensusGroup.Count).
+			Float64("consensusVotes", consensusGroup.Votes).
 			Int("totalParticipants", analysis.totalParticipants).
 			Int("validParticipants", analysis.validParticipants).
 			Int("misbehavingCount", misbehavingCount).
@@ -673,10 +690,57 @@ func (e *executor) trackAndPunishMisbehavingUpstreams(lg *zerolog.Logger, req *c
 			if participant.errorMessage != "" {
 				logEvent = logEvent.Str("error"+idx, participant.errorMessage)
 			}
+			if e.hasWeights() {
+				logEvent = logEvent.Float64("weight"+idx, participant.weight)
+			}
 		}
 
 		logEvent.Msg("consensus misbehavior detected - upstreams differ from consensus")
 	}
+
+	if misbehavingCount > 0 && recorder != nil {
+		record := &common.DisputeRecord{
+			Kind:         common.DisputeKindConsensus,
+			NetworkId:    labels.networkId,
+			Method:       labels.method,
+			Participants: make([]*common.DisputeParticipant, 0, len(allParticipants)),
+		}
+		if body := req.Body(); len(body) > 0 {
+			record.Request = string(body)
+		} else if jrq, err := req.JsonRpcRequest(ctx); err == nil {
+			if body, err := common.SonicCfg.Marshal(jrq); err == nil {
+				record.Request = string(body)
+			}
+		}
+		if winner != nil && winner.Error == nil && winner.Result != nil {
+			record.Outcome = "result"
+			record.OutcomeHash = consensusGroup.Hash
+		} else {
+			record.Outcome = "error"
+			if winner != nil && winner.Error != nil {
+				record.Error = common.ErrorSummary(winner.Error)
+			}
+		}
+		for _, participant := range allParticipants {
+			dp := &common.DisputeParticipant{
+				Upstream:          participant.upstreamId,
+				ResponseType:      participant.responseType.String(),
+				ResponseHash:      participant.responseHash,
+				ResponseSize:      participant.responseSize,
+				Response:          string(participant.responseBody),
+				Error:             participant.errorMessage,
+				AgreesWithOutcome: participant.agreesWithConsensus,
+			}
+			if participant.upstream != nil {
+				dp.Vendor = participant.upstream.VendorName()
+			}
+			if e.hasWeights() {
+				dp.Weight = participant.weight
+			}
+			record.Participants = append(record.Participants, dp)
+		}
+		recorder.RecordDispute(ctx, record)
+	}
 }
 
 // shouldPunishUpstream determines if punishment should be applied based on configuration and consensus strength
@@ -692,8 +756,8 @@ func (e *executor) shouldPunishUpstream(lg *zerolog.Logger, consensusGroup *resp
 		return false
 	}
 
-	// Only punish if we have a clear majority (>50% of valid participants)
-	return consensusGroup.Count > analysis.validParticipants/2
+	// Only punish if we have a clear majority (>50% of the votes of valid participants)
+	return consensusGroup.Votes > analysis.validVotes/2
 }
 
 func (e *executor) handleMisbehavingUpstream(logger *zerolog.Logger, upstream common.Upstream, upstreamId, projectId, networkId string) {
@@ -780,7 +844,7 @@ func (e *executor) startConsensusSpan(ctx context.Context, labels metricsLabels,
 func (e *executor) recordMetricsAndTracing(req *common.NormalizedRequest, startTime time.Time, result *failsafeCommon.PolicyResult[*common.NormalizedResponse], analysis *consensusAnalysis, labels metricsLabels, span trace.Span) {
 	// Determine if consensus was achieved based on the highest count group
 	best := analysis.getBestByCount()
-	hasConsensus := best != nil && best.Count >= e.agreementThreshold
+	hasConsensus := best != nil && analysis.meetsThreshold(best)
 	isLowParticipants := analysis.isLowParticipants(e.agreementThreshold)
 	isDispute := !hasConsensus && !isLowParticipants
 
@@ -33,6 +33,8 @@ type ConsensusPolicyBuilder interface {
 	WithIgnoreFields(ignoreFields map[string][]string) ConsensusPolicyBuilder
 	WithPreferNonEmpty(preferNonEmpty bool) ConsensusPolicyBuilder
 	WithPreferLargerResponses(preferLargerResponses bool) ConsensusPolicyBuilder
+	WithWeights(weights []*common.ConsensusWeightConfig) ConsensusPolicyBuilder
+	WithWeightByAgreementRate(weightByAgreementRate bool) ConsensusPolicyBuilder
 
 	// Build returns a new ConsensusPolicy using the builder's configuration.
 	Build() ConsensusPolicy
@@ -52,6 +54,8 @@ type config struct {
 	ignoreFields            map[string][]string
 	preferNonEmpty          bool
 	preferLargerResponses   bool
+	weights                 []*common.ConsensusWeightConfig
+	weightByAgreementRate   bool
 
 	onAgreement       func(event failsafe.ExecutionEvent[*common.NormalizedResponse])
 	onDispute         func(event failsafe.ExecutionEvent[*common.NormalizedResponse])
@@ -141,6 +145,16 @@ func (c *config) WithPreferLargerResponses(preferLargerResponses bool) Consensus
 	return c
 }
 
+func (c *config) WithWeights(weights []*common.ConsensusWeightConfig) ConsensusPolicyBuilder {
+	c.weights = weights
+	return c
+}
+
+func (c *config) WithWeightByAgreementRate(weightByAgreementRate bool) ConsensusPolicyBuilder {
+	c.weightByAgreementRate = weightByAgreementRate
+	return c
+}
+
 func (c *config) Build() ConsensusPolicy {
 	hCopy := *c
 	if !c.BaseAbortablePolicy.IsConfigured() {
@@ -32,11 +32,11 @@ var consensusRules = []consensusRule{
 			// Trigger only when we do not already have a valid group meeting threshold (i.e., dispute path)
 			var bestValid *responseGroup
 			for _, g := range a.getValidGroups() {
-				if bestValid == nil || g.Count > bestValid.Count {
+				if bestValid == nil || compareVotes(g.Votes, bestValid.Votes) > 0 {
 					bestValid = g
 				}
 			}
-			if bestValid != nil && bestValid.Count >= a.config.agreementThreshold {
+			if bestValid != nil && a.meetsThreshold(bestValid) {
 				return false
 			}
 			// Always handle in dispute mode; action decides whether to return leader result or leader error
@@ -94,11 +94,11 @@ var consensusRules = []consensusRule{
 			// Apply only when there is no valid group meeting threshold
 			var bestValid *responseGroup
 			for _, g := range a.getValidGroups() {
-				if bestValid == nil || g.Count > bestValid.Count {
+				if bestValid == nil || compareVotes(g.Votes, bestValid.Votes) > 0 {
 					bestValid = g
 				}
 			}
-			if bestValid != nil && bestValid.Count >= a.config.agreementThreshold {
+			if bestValid != nil && a.meetsThreshold(bestValid) {
 				return false
 			}
 			// Prefer leader group if available; otherwise let subsequent rules (accept-most-common) handle
@@ -127,7 +127,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			return best == nil || best.Count < a.config.agreementThreshold
+			return best == nil || !a.meetsThreshold(best)
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			largest := a.getBestBySize()
@@ -157,7 +157,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			// Apply when the leading group meets threshold and is empty OR consensus error, while any non-empty exists
-			if best.Count >= a.config.agreementThreshold && (best.ResponseType == ResponseTypeEmpty || best.ResponseType == ResponseTypeConsensusError) {
+			if a.meetsThreshold(best) && (best.ResponseType == ResponseTypeEmpty || best.ResponseType == ResponseTypeConsensusError) {
 				for _, g := range a.groups {
 					if g.ResponseType == ResponseTypeNonEmpty {
 						return true
@@ -169,7 +169,7 @@ var consensusRules = []consensusRule{
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			var bestNonEmpty *responseGroup
 			for _, g := range a.groups {
-				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || g.Count > bestNonEmpty.Count || (g.Count == bestNonEmpty.Count && g.ResponseSize > bestNonEmpty.ResponseSize)) {
+				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || compareVotes(g.Votes, bestNonEmpty.Votes) > 0 || (compareVotes(g.Votes, bestNonEmpty.Votes) == 0 && g.ResponseSize > bestNonEmpty.ResponseSize)) {
 					bestNonEmpty = g
 				}
 			}
@@ -194,7 +194,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count < a.config.agreementThreshold {
+			if best == nil || !a.meetsThreshold(best) {
 				return false
 			}
 			// If the best is an agreed error, allow generic threshold rule to return it
@@ -207,7 +207,7 @@ var consensusRules = []consensusRule{
 				if g.ResponseType == ResponseTypeConsensusError {
 					continue
 				}
-				if g.Count == best.Count {
+				if compareVotes(g.Votes, best.Votes) == 0 {
 					countWithBest++
 					if countWithBest > 1 {
 						return true
@@ -239,7 +239,7 @@ var consensusRules = []consensusRule{
 			}
 			// Apply only when below threshold, there exists at least one non-empty group and at least one empty group
 			best := a.getBestByCount()
-			if best == nil || best.Count >= a.config.agreementThreshold {
+			if best == nil || a.meetsThreshold(best) {
 				return false
 			}
 			nonEmptyGroups := 0
@@ -257,7 +257,7 @@ var consensusRules = []consensusRule{
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			var bestNonEmpty *responseGroup
 			for _, g := range a.groups {
-				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || g.Count > bestNonEmpty.Count || (g.Count == bestNonEmpty.Count && g.ResponseSize > bestNonEmpty.ResponseSize)) {
+				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || compareVotes(g.Votes, bestNonEmpty.Votes) > 0 || (compareVotes(g.Votes, bestNonEmpty.Votes) == 0 && g.ResponseSize > bestNonEmpty.ResponseSize)) {
 					bestNonEmpty = g
 				}
 			}
@@ -282,7 +282,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count >= a.config.agreementThreshold {
+			if best == nil || a.meetsThreshold(best) {
 				return false
 			}
 			if best.ResponseType != ResponseTypeConsensusError {
@@ -298,7 +298,7 @@ var consensusRules = []consensusRule{
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			var bestNonEmpty *responseGroup
 			for _, g := range a.groups {
-				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || g.Count > bestNonEmpty.Count || (g.Count == bestNonEmpty.Count && g.ResponseSize > bestNonEmpty.ResponseSize)) {
+				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || compareVotes(g.Votes, bestNonEmpty.Votes) > 0 || (compareVotes(g.Votes, bestNonEmpty.Votes) == 0 && g.ResponseSize > bestNonEmpty.ResponseSize)) {
 					bestNonEmpty = g
 				}
 			}
@@ -323,7 +323,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.ResponseType != ResponseTypeEmpty || best.Count < a.config.agreementThreshold {
+			if best == nil || best.ResponseType != ResponseTypeEmpty || !a.meetsThreshold(best) {
 				return false
 			}
 			// any non-empty group present?
@@ -353,14 +353,14 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count < a.config.agreementThreshold {
+			if best == nil || !a.meetsThreshold(best) {
 				return false
 			}
 			// Check presence of at least one non-empty group meeting threshold
 			hasNonEmptyAbove := false
 			hasConsensusErrAbove := false
 			for _, g := range a.getValidGroups() {
-				if g.Count >= a.config.agreementThreshold {
+				if a.meetsThreshold(g) {
 					switch g.ResponseType {
 					case ResponseTypeNonEmpty:
 						hasNonEmptyAbove = true
@@ -374,7 +374,7 @@ var consensusRules = []consensusRule{
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			var bestNonEmpty *responseGroup
 			for _, g := range a.getValidGroups() {
-				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || g.Count > bestNonEmpty.Count || (g.Count == bestNonEmpty.Count && g.ResponseSize > bestNonEmpty.ResponseSize)) {
+				if g.ResponseType == ResponseTypeNonEmpty && (bestNonEmpty == nil || compareVotes(g.Votes, bestNonEmpty.Votes) > 0 || (compareVotes(g.Votes, bestNonEmpty.Votes) == 0 && g.ResponseSize > bestNonEmpty.ResponseSize)) {
 					bestNonEmpty = g
 				}
 			}
@@ -395,13 +395,13 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count < a.config.agreementThreshold {
+			if best == nil || !a.meetsThreshold(best) {
 				return false
 			}
 			// Count how many groups share the best count
 			countWithBest := 0
 			for _, g := range a.getValidGroups() {
-				if g.Count == best.Count {
+				if compareVotes(g.Votes, best.Votes) == 0 {
 					countWithBest++
 					if countWithBest > 1 {
 						return true
@@ -424,13 +424,13 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count < a.config.agreementThreshold {
+			if best == nil || !a.meetsThreshold(best) {
 				return false
 			}
 			// Activate when there is more than one valid group above threshold (could have different counts)
 			numValidAbove := 0
 			for _, g := range a.getValidGroups() {
-				if g.Count >= a.config.agreementThreshold {
+				if a.meetsThreshold(g) {
 					numValidAbove++
 					if numValidAbove > 1 {
 						return true
@@ -464,7 +464,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count < a.config.agreementThreshold {
+			if best == nil || !a.meetsThreshold(best) {
 				return false
 			}
 			if best.ResponseType != ResponseTypeNonEmpty {
@@ -497,12 +497,12 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			best := a.getBestByCount()
-			if best == nil || best.Count < a.config.agreementThreshold || best.ResponseType != ResponseTypeNonEmpty {
+			if best == nil || !a.meetsThreshold(best) || best.ResponseType != ResponseTypeNonEmpty {
 				return false
 			}
 			// Trigger only when the larger non-empty exists but is below threshold (single or minority)
 			for _, g := range a.getValidGroups() {
-				if g.ResponseType == ResponseTypeNonEmpty && g.ResponseSize > best.ResponseSize && g.Count < a.config.agreementThreshold {
+				if g.ResponseType == ResponseTypeNonEmpty && g.ResponseSize > best.ResponseSize && !a.meetsThreshold(g) {
 					return true
 				}
 			}
@@ -523,42 +523,42 @@ var consensusRules = []consensusRule{
 			}
 			// Determine best and second best among valid groups by count
 			var best *responseGroup
-			second := 0
+			second := 0.0
 			for _, g := range a.getValidGroups() {
-				if best == nil || g.Count > best.Count {
+				if best == nil || compareVotes(g.Votes, best.Votes) > 0 {
 					if best != nil {
-						if best.Count > second {
-							second = best.Count
+						if compareVotes(best.Votes, second) > 0 {
+							second = best.Votes
 						}
 					}
 					best = g
-				} else if g.Count > second {
-					second = g.Count
+				} else if compareVotes(g.Votes, second) > 0 {
+					second = g.Votes
 				}
 			}
 			if best == nil {
 				return false
 			}
 			// Below threshold and strictly unique leader
-			return best.Count < a.config.agreementThreshold && best.Count > second
+			return !a.meetsThreshold(best) && compareVotes(best.Votes, second) > 0
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			// Pick the unique best-by-count valid group; resolve by response type
 			var best *responseGroup
-			second := 0
+			second := 0.0
 			for _, g := range a.getValidGroups() {
-				if best == nil || g.Count > best.Count {
+				if best == nil || compareVotes(g.Votes, best.Votes) > 0 {
 					if best != nil {
-						if best.Count > second {
-							second = best.Count
+						if compareVotes(best.Votes, second) > 0 {
+							second = best.Votes
 						}
 					}
 					best = g
-				} else if g.Count > second {
-					second = g.Count
+				} else if compareVotes(g.Votes, second) > 0 {
+					second = g.Votes
 				}
 			}
-			if best == nil || best.Count <= second {
+			if best == nil || compareVotes(best.Votes, second) <= 0 {
 				return &failsafeCommon.PolicyResult[*common.NormalizedResponse]{
 					Error: common.NewErrConsensusDispute("not enough agreement among responses", a.participants(), nil),
 				}
@@ -575,7 +575,7 @@ var consensusRules = []consensusRule{
 			if a.config.lowParticipantsBehavior != common.ConsensusLowParticipantsBehaviorAcceptMostCommonValidResult {
 				return false
 			}
-			return a.validParticipants < a.config.agreementThreshold && len(a.getValidGroups()) > 0
+			return a.isLowParticipants(a.config.agreementThreshold) && len(a.getValidGroups()) > 0
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			if bestNonEmpty := a.getBestNonEmpty(); bestNonEmpty != nil {
@@ -603,17 +603,17 @@ var consensusRules = []consensusRule{
 			// Consider only valid groups (non-empty, empty, and consensus-valid errors)
 			var bestValid *responseGroup
 			for _, g := range a.getValidGroups() {
-				if bestValid == nil || g.Count > bestValid.Count {
+				if bestValid == nil || compareVotes(g.Votes, bestValid.Votes) > 0 {
 					bestValid = g
 				}
 			}
-			return bestValid != nil && bestValid.Count >= a.config.agreementThreshold
+			return bestValid != nil && a.meetsThreshold(bestValid)
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			// Pick winner among valid groups only
 			var bestValid *responseGroup
 			for _, g := range a.getValidGroups() {
-				if bestValid == nil || g.Count > bestValid.Count {
+				if bestValid == nil || compareVotes(g.Votes, bestValid.Votes) > 0 {
 					bestValid = g
 				}
 			}
@@ -635,7 +635,7 @@ var consensusRules = []consensusRule{
 			if best == nil {
 				return false
 			}
-			return best.Count < a.config.agreementThreshold && len(a.getValidGroups()) > 1
+			return !a.meetsThreshold(best) && len(a.getValidGroups()) > 1
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			return &failsafeCommon.PolicyResult[*common.NormalizedResponse]{
@@ -655,7 +655,7 @@ var consensusRules = []consensusRule{
 			if best == nil || best.ResponseType != ResponseTypeInfrastructureError {
 				return false
 			}
-			return best.Count >= a.config.agreementThreshold
+			return a.meetsThreshold(best)
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			best := a.getBestByCount()
@@ -675,7 +675,7 @@ var consensusRules = []consensusRule{
 				return false
 			}
 			// Low participants when valid (non-infra-error) responses are fewer than threshold
-			return a.validParticipants < a.config.agreementThreshold
+			return a.isLowParticipants(a.config.agreementThreshold)
 		},
 		Action: func(a *consensusAnalysis) *failsafeCommon.PolicyResult[*common.NormalizedResponse] {
 			return &failsafeCommon.PolicyResult[*common.NormalizedResponse]{
@@ -719,7 +719,7 @@ var shortCircuitRules = []shortCircuitRule{
 			if best.ResponseType != ResponseTypeConsensusError {
 				return false
 			}
-			if best.Count < a.config.agreementThreshold {
+			if !a.meetsThreshold(best) {
 				return false
 			}
 			// Don't short-circuit to an error under AcceptMostCommon when a preference
@@ -755,24 +755,24 @@ var shortCircuitRules = []shortCircuitRule{
 					return false
 				}
 			}
-			if best == nil || best.ResponseType != ResponseTypeNonEmpty || best.Count < a.config.agreementThreshold {
+			if best == nil || best.ResponseType != ResponseTypeNonEmpty || !a.meetsThreshold(best) {
 				return false
 			}
 			// Compute second best among valid groups (non-infrastructure)
-			secondCount := 0
+			secondCount := 0.0
 			for _, g := range a.getValidGroups() {
 				if g.Hash == best.Hash {
 					continue
 				}
-				if g.Count > secondCount {
-					secondCount = g.Count
+				if compareVotes(g.Votes, secondCount) > 0 {
+					secondCount = g.Votes
 				}
 			}
-			remaining := a.config.maxParticipants - a.totalParticipants
+			remaining := a.remainingVotes()
 			// Without size preference, allow potential ties to short-circuit once threshold is met
 			// and the lead is unassailable by the number of remaining responses.
-			leadOverSecond := best.Count - secondCount
-			return leadOverSecond >= remaining
+			leadOverSecond := best.Votes - secondCount
+			return compareVotes(leadOverSecond, remaining) >= 0
 		},
 	},
 }
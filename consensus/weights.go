@@ -0,0 +1,86 @@
+package consensus
+
+import (
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+)
+
+const (
+	// defaultWeight is the vote of participants not matching any configured weight.
+	defaultWeight = 1.0
+	// votesEpsilon absorbs rounding errors of summed fractional weights when comparing votes.
+	votesEpsilon = 1e-9
+)
+
+// compareVotes returns 0 when votes are equal within votesEpsilon, otherwise -1 or 1 like cmp.Compare.
+func compareVotes(a, b float64) int {
+	switch {
+	case a-b > votesEpsilon:
+		return 1
+	case b-a > votesEpsilon:
+		return -1
+	}
+	return 0
+}
+
+// hasWeights tells whether votes may differ from one participant to another.
+func (c *config) hasWeights() bool {
+	return len(c.weights) > 0 || c.weightByAgreementRate
+}
+
+// maxWeight is the highest vote a single participant can have, used to bound what remaining participants can add.
+func (c *config) maxWeight() float64 {
+	return common.MaxConsensusWeight(c.weights)
+}
+
+// upstreamWeights returns the configured weight of an upstream, which counts toward the agreement threshold, and
+// its vote for a method, which ranks results and is scaled by the agreement rate when enabled. Results without a
+// known upstream have the default weight.
+func (c *config) upstreamWeights(ups common.Upstream, method string) (float64, float64) {
+	if ups == nil {
+		return defaultWeight, defaultWeight
+	}
+
+	weight := defaultWeight
+	var group string
+	if cfg := ups.Config(); cfg != nil {
+		group = cfg.Group
+	}
+	for _, w := range c.weights {
+		if w == nil || !matchesWeight(w, ups.Id(), group) {
+			continue
+		}
+		weight = w.Weight
+		break
+	}
+
+	vote := weight
+	if c.weightByAgreementRate {
+		// Misbehaviors are recorded by the consensus policy itself whenever an upstream disagrees with the winner
+		if tracker, ok := ups.Tracker().(*health.Tracker); ok && tracker != nil {
+			if metrics := tracker.GetUpstreamMethodMetrics(ups, method); metrics != nil {
+				agreementRate := 1 - metrics.MisbehaviorRate()
+				if agreementRate < 0 {
+					agreementRate = 0
+				}
+				vote *= agreementRate
+			}
+		}
+	}
+
+	return weight, vote
+}
+
+func matchesWeight(w *common.ConsensusWeightConfig, upstreamId, group string) bool {
+	if w.Upstream != "" {
+		if match, err := common.WildcardMatch(w.Upstream, upstreamId); err != nil || !match {
+			return false
+		}
+	}
+	if w.Group != "" {
+		if match, err := common.WildcardMatch(w.Group, group); err != nil || !match {
+			return false
+		}
+	}
+	return true
+}
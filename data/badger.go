@@ -0,0 +1,389 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+)
+
+const (
+	BadgerDriverName = "badger"
+
+	badgerMainPrefix    = "k"
+	badgerReversePrefix = "r"
+	badgerKeySeparator  = "\x00"
+	badgerGCDiscard     = 0.5
+)
+
+var _ Connector = (*BadgerConnector)(nil)
+
+// BadgerConnector stores entries in an embedded on-disk key-value store. Every entry is stored under
+// "k<partitionKey>\x00<rangeKey>" and indexed under "r<rangeKey>\x00<partitionKey>" so that lookups by
+// range key and partition key prefix (i.e. ConnectorReverseIndex) are answered with a prefix scan.
+type BadgerConnector struct {
+	id     string
+	logger *zerolog.Logger
+	db     *badger.DB
+	locks  sync.Map // map[string]*sync.Mutex
+}
+
+type badgerLogger struct {
+	logger *zerolog.Logger
+}
+
+func (l *badgerLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Error().Msgf(strings.TrimSuffix(format, "\n"), args...)
+}
+
+func (l *badgerLogger) Warningf(format string, args ...interface{}) {
+	l.logger.Warn().Msgf(strings.TrimSuffix(format, "\n"), args...)
+}
+
+func (l *badgerLogger) Infof(format string, args ...interface{}) {
+	l.logger.Debug().Msgf(strings.TrimSuffix(format, "\n"), args...)
+}
+
+func (l *badgerLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Trace().Msgf(strings.TrimSuffix(format, "\n"), args...)
+}
+
+func NewBadgerConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.BadgerConnectorConfig,
+) (*BadgerConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating badger connector")
+
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("dir is required for badger connector")
+	}
+
+	opts := badger.DefaultOptions(cfg.Dir).
+		WithLogger(&badgerLogger{logger: &lg}).
+		WithSyncWrites(cfg.SyncWrites != nil && *cfg.SyncWrites)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open badger database at '%s': %w", cfg.Dir, err)
+	}
+
+	c := &BadgerConnector{
+		id:     id,
+		logger: &lg,
+		db:     db,
+	}
+
+	go c.run(ctx, cfg.GCInterval.Duration())
+
+	return c, nil
+}
+
+func (b *BadgerConnector) Id() string {
+	return b.id
+}
+
+func (b *BadgerConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	b.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Msg("writing to badger")
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		main := badger.NewEntry(badgerMainKey(partitionKey, rangeKey), value)
+		rev := badger.NewEntry(badgerReverseKey(partitionKey, rangeKey), nil)
+		if ttl != nil && *ttl > 0 {
+			main = main.WithTTL(*ttl)
+			rev = rev.WithTTL(*ttl)
+		}
+		if err := txn.SetEntry(main); err != nil {
+			return err
+		}
+		return txn.SetEntry(rev)
+	})
+}
+
+func (b *BadgerConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	b.logger.Debug().Str("index", index).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("getting item from badger")
+
+	var value []byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		var key []byte
+		switch {
+		case index == ConnectorReverseIndex && (strings.HasSuffix(partitionKey, "*") || strings.HasSuffix(rangeKey, "*")):
+			key = b.findByReverseIndex(txn, partitionKey, rangeKey)
+		case strings.HasSuffix(rangeKey, "*"):
+			key = b.findFirstWithPrefix(txn, []byte(badgerMainPrefix+partitionKey+badgerKeySeparator+strings.TrimSuffix(rangeKey, "*")))
+		}
+		if key == nil {
+			key = badgerMainKey(partitionKey, rangeKey)
+		}
+
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, common.NewErrRecordNotFound(partitionKey, rangeKey, BadgerDriverName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// findByReverseIndex resolves the main key of the first entry whose range key and partition key match
+// the given values, where either might end with a "*" wildcard.
+func (b *BadgerConnector) findByReverseIndex(txn *badger.Txn, partitionKey, rangeKey string) []byte {
+	pkPrefix := strings.TrimSuffix(partitionKey, "*")
+	var prefix string
+	if strings.HasSuffix(rangeKey, "*") {
+		prefix = badgerReversePrefix + strings.TrimSuffix(rangeKey, "*")
+	} else {
+		prefix = badgerReversePrefix + rangeKey + badgerKeySeparator + pkPrefix
+	}
+
+	it := txn.NewIterator(badger.IteratorOptions{Prefix: []byte(prefix)})
+	defer it.Close()
+	for it.Rewind(); it.Valid(); it.Next() {
+		rk, pk, ok := strings.Cut(string(it.Item().Key()[len(badgerReversePrefix):]), badgerKeySeparator)
+		if !ok || !strings.HasPrefix(pk, pkPrefix) {
+			continue
+		}
+		return badgerMainKey(pk, rk)
+	}
+
+	return nil
+}
+
+func (b *BadgerConnector) findFirstWithPrefix(txn *badger.Txn, prefix []byte) []byte {
+	it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+	defer it.Close()
+	it.Rewind()
+	if !it.Valid() {
+		return nil
+	}
+	return it.Item().KeyCopy(nil)
+}
+
+func (b *BadgerConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	b.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting from badger")
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete(badgerMainKey(partitionKey, rangeKey)); err != nil {
+			return err
+		}
+		return txn.Delete(badgerReverseKey(partitionKey, rangeKey))
+	})
+}
+
+func (b *BadgerConnector) DeletePartition(ctx context.Context, partitionKey string) error {
+	b.logger.Debug().Str("partitionKey", partitionKey).Msg("deleting partition from badger")
+
+	prefix := []byte(badgerMainPrefix + partitionKey + badgerKeySeparator)
+	var rangeKeys []string
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			rangeKeys = append(rangeKeys, string(it.Item().Key()[len(prefix):]))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, rangeKey := range rangeKeys {
+		if err := wb.Delete(badgerMainKey(partitionKey, rangeKey)); err != nil {
+			return err
+		}
+		if err := wb.Delete(badgerReverseKey(partitionKey, rangeKey)); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+func (b *BadgerConnector) ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	prefix := []byte(badgerMainPrefix + partitionKey + badgerKeySeparator)
+	results := make([]KeyValuePair, 0, limit)
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix, Reverse: true, PrefetchValues: true, PrefetchSize: limit})
+		defer it.Close()
+
+		// Reverse iteration seeks to the last key lower than or equal to the given one
+		if beforeRangeKey == "" {
+			it.Seek(append(append([]byte{}, prefix...), 0xFF))
+		} else {
+			it.Seek(badgerMainKey(partitionKey, beforeRangeKey))
+			if it.Valid() && string(it.Item().Key()[len(prefix):]) == beforeRangeKey {
+				it.Next()
+			}
+		}
+
+		for ; it.Valid() && len(results) < limit; it.Next() {
+			item := it.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			results = append(results, KeyValuePair{
+				PartitionKey: partitionKey,
+				RangeKey:     string(item.Key()[len(prefix):]),
+				Value:        value,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// List iterates entries ordered by partition key and range key. The pagination token is the
+// (base64 encoded) key of the last returned entry.
+func (b *BadgerConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be greater than 0")
+	}
+
+	var after []byte
+	if paginationToken != "" {
+		decoded, err := base64.StdEncoding.DecodeString(paginationToken)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+		}
+		after = decoded
+	}
+
+	results := make([]KeyValuePair, 0, limit)
+	nextToken := ""
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: []byte(badgerMainPrefix), PrefetchValues: true, PrefetchSize: limit})
+		defer it.Close()
+
+		if after != nil {
+			it.Seek(after)
+			if it.Valid() && bytes.Equal(it.Item().Key(), after) {
+				it.Next()
+			}
+		} else {
+			it.Rewind()
+		}
+
+		for ; it.Valid(); it.Next() {
+			if len(results) >= limit {
+				nextToken = base64.StdEncoding.EncodeToString(badgerMainKeyOf(results[len(results)-1]))
+				return nil
+			}
+			item := it.Item()
+			pk, rk, ok := strings.Cut(string(item.Key()[len(badgerMainPrefix):]), badgerKeySeparator)
+			if !ok {
+				continue
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			results = append(results, KeyValuePair{
+				PartitionKey: pk,
+				RangeKey:     rk,
+				Value:        value,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return results, nextToken, nil
+}
+
+// Lock is local to this process as the database can only be opened by a single process.
+func (b *BadgerConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	value, _ := b.locks.LoadOrStore(key, &sync.Mutex{})
+	mutex := value.(*sync.Mutex)
+
+	mutex.Lock()
+	return &memoryLock{
+		mutex: mutex,
+	}, nil
+}
+
+// WatchCounterInt64 is a no-op for badger connector since the database is local to this process.
+func (b *BadgerConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan int64, func(), error) {
+	ch := make(chan int64)
+	return ch, func() {}, nil
+}
+
+// PublishCounterInt64 is a no-op for badger connector since the database is local to this process.
+func (b *BadgerConnector) PublishCounterInt64(ctx context.Context, key string, value int64) error {
+	return nil
+}
+
+// run periodically reclaims space of the value log taken by deleted and expired entries,
+// and closes the database when the app context is done.
+func (b *BadgerConnector) run(ctx context.Context, gcInterval time.Duration) {
+	defer func() {
+		if err := b.db.Close(); err != nil {
+			b.logger.Warn().Err(err).Msg("failed to close badger database")
+		}
+	}()
+
+	var tick <-chan time.Time
+	if gcInterval > 0 {
+		ticker := time.NewTicker(gcInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			// Each run rewrites at most one file, so keep going until there's nothing left to collect
+			for {
+				err := b.db.RunValueLogGC(badgerGCDiscard)
+				if err != nil {
+					if !errors.Is(err, badger.ErrNoRewrite) && !errors.Is(err, badger.ErrRejected) {
+						b.logger.Warn().Err(err).Msg("failed to run badger value log garbage collection")
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+func badgerMainKey(partitionKey, rangeKey string) []byte {
+	return []byte(badgerMainPrefix + partitionKey + badgerKeySeparator + rangeKey)
+}
+
+func badgerMainKeyOf(kv KeyValuePair) []byte {
+	return badgerMainKey(kv.PartitionKey, kv.RangeKey)
+}
+
+func badgerReverseKey(partitionKey, rangeKey string) []byte {
+	return []byte(badgerReversePrefix + rangeKey + badgerKeySeparator + partitionKey)
+}
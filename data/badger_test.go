@@ -0,0 +1,145 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newBadgerTestConnector(t *testing.T, ctx context.Context, dir string) *BadgerConnector {
+	logger := zerolog.New(io.Discard)
+	cfg := &common.ConnectorConfig{
+		Id: "badger-test",
+		Badger: &common.BadgerConnectorConfig{
+			Dir: dir,
+		},
+	}
+	require.NoError(t, cfg.SetDefaults("cache"))
+	require.NoError(t, cfg.Validate())
+
+	connector, err := NewConnector(ctx, &logger, cfg)
+	require.NoError(t, err)
+	return connector.(*BadgerConnector)
+}
+
+func TestBadgerConnector(t *testing.T) {
+	t.Run("PersistsEntriesAcrossRestarts", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithCancel(context.Background())
+		connector := newBadgerTestConnector(t, ctx, dir)
+		err := connector.Set(ctx, "evm:1:100", "rk", []byte("value"), nil)
+		require.NoError(t, err)
+
+		// Closing happens in the background when the context is done, so the directory lock is released eventually
+		cancel()
+		ctx2, cancel2 := context.WithCancel(context.Background())
+		defer cancel2()
+		logger := zerolog.New(io.Discard)
+		require.Eventually(t, func() bool {
+			connector, err = NewBadgerConnector(ctx2, &logger, "badger-test", &common.BadgerConnectorConfig{Dir: dir})
+			return err == nil
+		}, 5*time.Second, 50*time.Millisecond)
+		val, err := connector.Get(ctx2, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+	})
+
+	t.Run("ExpiresEntriesAfterTTL", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		connector := newBadgerTestConnector(t, ctx, t.TempDir())
+
+		ttl := 1 * time.Second
+		require.NoError(t, connector.Set(ctx, "evm:1:100", "rk", []byte("value"), &ttl))
+		_, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			return common.HasErrorCode(err, common.ErrCodeRecordNotFound)
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("ResolvesWildcardPartitionKeyViaReverseIndex", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		connector := newBadgerTestConnector(t, ctx, t.TempDir())
+
+		require.NoError(t, connector.Set(ctx, "evm:1:100", "eth_getBlockByHash:0xabc", []byte("block"), nil))
+		require.NoError(t, connector.Set(ctx, "evm:2:100", "eth_getBlockByHash:0xdef", []byte("other"), nil))
+
+		val, err := connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByHash:0xabc", nil)
+		require.NoError(t, err)
+		require.Equal(t, "block", string(val))
+
+		_, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByHash:0xdef", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+
+		require.NoError(t, connector.Delete(ctx, "evm:1:100", "eth_getBlockByHash:0xabc"))
+		_, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByHash:0xabc", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("ListsEntriesWithPagination", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		connector := newBadgerTestConnector(t, ctx, t.TempDir())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, connector.Set(ctx, fmt.Sprintf("pk%d", i), "rk", []byte(fmt.Sprintf("v%d", i)), nil))
+		}
+
+		var all []KeyValuePair
+		token := ""
+		pages := 0
+		for {
+			items, next, err := connector.List(ctx, ConnectorMainIndex, 2, token)
+			require.NoError(t, err)
+			all = append(all, items...)
+			pages++
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		require.Equal(t, 3, pages)
+		require.Len(t, all, 5)
+		for i, kv := range all {
+			require.Equal(t, fmt.Sprintf("pk%d", i), kv.PartitionKey)
+			require.Equal(t, "rk", kv.RangeKey)
+			require.Equal(t, fmt.Sprintf("v%d", i), string(kv.Value))
+		}
+	})
+
+	t.Run("ListsPartitionFromHighestRangeKey", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		connector := newBadgerTestConnector(t, ctx, t.TempDir())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, connector.Set(ctx, "pk", fmt.Sprintf("rk%d", i), []byte(fmt.Sprintf("v%d", i)), nil))
+		}
+		// Neighbouring partitions must not be listed
+		require.NoError(t, connector.Set(ctx, "pk1", "rk9", []byte("other"), nil))
+		require.NoError(t, connector.Set(ctx, "pj", "rk9", []byte("other"), nil))
+
+		items, err := connector.ListPartition(ctx, "pk", "", 2)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, "rk4", items[0].RangeKey)
+		require.Equal(t, "v4", string(items[0].Value))
+		require.Equal(t, "rk3", items[1].RangeKey)
+
+		items, err = connector.ListPartition(ctx, "pk", items[1].RangeKey, 10)
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		require.Equal(t, "rk2", items[0].RangeKey)
+		require.Equal(t, "rk0", items[2].RangeKey)
+	})
+}
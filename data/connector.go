@@ -38,6 +38,70 @@ type Connector interface {
 	PublishCounterInt64(ctx context.Context, key string, value int64) error
 }
 
+// CounterConnector is implemented by connectors that can atomically increment counters, which is
+// required to enforce budgets shared by multiple eRPC instances (e.g. distributed rate limiting).
+type CounterConnector interface {
+	Connector
+	// IncrementCounterInt64 atomically adds "delta" to the counter and returns the new value.
+	// A counter that does not exist yet is created with a value of 0 and expires after "ttl".
+	IncrementCounterInt64(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	// AcquireSlidingWindowPermits atomically adds "permits" to the counter of the current window unless the count
+	// of the previous window weighted by "overlap" plus the current count would exceed "limit". It returns whether
+	// the permits were acquired along with the counts of the previous and current windows.
+	AcquireSlidingWindowPermits(ctx context.Context, previousKey, currentKey string, overlap float64, permits, limit int64, ttl time.Duration) (bool, int64, int64, error)
+	// IncrementCountersWithinLimits atomically applies all increments unless any counter would exceed its limit, in
+	// which case none is applied. It returns whether they were applied along with the resulting (or, when rejected,
+	// the current) value of each counter.
+	IncrementCountersWithinLimits(ctx context.Context, increments []CounterIncrement) (bool, []int64, error)
+}
+
+// CounterIncrement is an increment of a counter that must not exceed "Limit" (0 means unlimited).
+type CounterIncrement struct {
+	Key   string
+	Delta int64
+	Limit int64
+	TTL   time.Duration
+}
+
+// PartitionDeleter is implemented by connectors that can remove every entry of a partition key at once,
+// which is used to invalidate entries written by any eRPC instance (e.g. blocks replaced by a chain reorg).
+type PartitionDeleter interface {
+	Connector
+	DeletePartition(ctx context.Context, partitionKey string) error
+}
+
+// WriteWaiter is implemented by connectors that apply writes asynchronously (e.g. memory), WaitForWrites blocks
+// until the writes issued before the call are visible to reads.
+type WriteWaiter interface {
+	Connector
+	WaitForWrites()
+}
+
+// PartitionBatchDeleter is implemented by connectors that delete several partitions more efficiently at once
+// than one by one (e.g. tiered, which notifies other instances once per call).
+type PartitionBatchDeleter interface {
+	PartitionDeleter
+	DeletePartitions(ctx context.Context, partitionKeys []string) error
+}
+
+// PartitionIndexer is implemented by connectors that cannot find the entries of a partition key on their own without
+// walking the keyspace. Entries written with SetIndexed are added to an index of their partition, which DeletePartition
+// relies on, so entries that must be found by partition have to be written with SetIndexed on such connectors.
+type PartitionIndexer interface {
+	Connector
+	SetIndexed(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error
+}
+
+// PartitionLister is implemented by connectors that can page through the entries of a partition key ordered by
+// range key, which is used to query records indexed by a time-ordered range key (e.g. disputes of a project).
+type PartitionLister interface {
+	Connector
+	// ListPartition returns up to "limit" entries of the partition with a range key lower than "beforeRangeKey"
+	// (no bound when empty), ordered by range key from the highest to the lowest. On connectors that are also
+	// PartitionIndexer, only entries written with SetIndexed are listed.
+	ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error)
+}
+
 func NewConnector(
 	ctx context.Context,
 	logger *zerolog.Logger,
@@ -54,6 +118,12 @@ func NewConnector(
 		return NewPostgreSQLConnector(ctx, logger, cfg.Id, cfg.PostgreSQL)
 	case common.DriverGrpc:
 		return NewGrpcConnector(ctx, logger, cfg.Id, cfg.Grpc)
+	case common.DriverBadger:
+		return NewBadgerConnector(ctx, logger, cfg.Id, cfg.Badger)
+	case common.DriverScylla:
+		return NewScyllaConnector(ctx, logger, cfg.Id, cfg.Scylla)
+	case common.DriverTiered:
+		return NewTieredConnector(ctx, logger, cfg.Id, cfg.Tiered)
 	}
 
 	if util.IsTest() && cfg.Driver == "mock" {
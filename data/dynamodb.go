@@ -878,6 +878,159 @@ func (d *DynamoDBConnector) Delete(ctx context.Context, partitionKey, rangeKey s
 	return err
 }
 
+// DeletePartition queries the range keys of a partition and deletes them in batches of 25 items,
+// which is the maximum accepted by BatchWriteItem.
+func (d *DynamoDBConnector) DeletePartition(ctx context.Context, partitionKey string) error {
+	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.DeletePartition")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.String("partition_key", partitionKey))
+	}
+
+	if d.readClient == nil || d.writeClient == nil {
+		err := fmt.Errorf("DynamoDB client not initialized yet")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	d.logger.Debug().Str("partitionKey", partitionKey).Msg("deleting partition from dynamodb")
+
+	ctx, cancel := context.WithTimeout(ctx, d.setTimeout)
+	defer cancel()
+
+	var rangeKeys []string
+	err := d.readClient.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
+		KeyConditionExpression: aws.String("#pkey = :pkey"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pkey": aws.String(d.partitionKeyName),
+			"#rkey": aws.String(d.rangeKeyName),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pkey": {S: aws.String(partitionKey)},
+		},
+		ProjectionExpression: aws.String("#rkey"),
+	}, func(page *dynamodb.QueryOutput, _ bool) bool {
+		for _, item := range page.Items {
+			if rk, ok := item[d.rangeKeyName]; ok && rk.S != nil {
+				rangeKeys = append(rangeKeys, *rk.S)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	for start := 0; start < len(rangeKeys); start += 25 {
+		end := min(start+25, len(rangeKeys))
+		requests := make([]*dynamodb.WriteRequest, 0, end-start)
+		for _, rangeKey := range rangeKeys[start:end] {
+			requests = append(requests, &dynamodb.WriteRequest{
+				DeleteRequest: &dynamodb.DeleteRequest{
+					Key: map[string]*dynamodb.AttributeValue{
+						d.partitionKeyName: {S: aws.String(partitionKey)},
+						d.rangeKeyName:     {S: aws.String(rangeKey)},
+					},
+				},
+			})
+		}
+		pending := map[string][]*dynamodb.WriteRequest{d.table: requests}
+		for len(pending) > 0 {
+			result, err := d.writeClient.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				common.SetTraceSpanError(span, err)
+				return err
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+func (d *DynamoDBConnector) ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error) {
+	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.ListPartition")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	if d.readClient == nil {
+		err := fmt.Errorf("DynamoDB client not initialized yet")
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.getTimeout)
+	defer cancel()
+
+	keyCondition := "#pkey = :pkey"
+	exprAttrValues := map[string]*dynamodb.AttributeValue{
+		":pkey": {S: aws.String(partitionKey)},
+	}
+	if beforeRangeKey != "" {
+		keyCondition += " AND #rkey < :rkey"
+		exprAttrValues[":rkey"] = &dynamodb.AttributeValue{S: aws.String(beforeRangeKey)}
+	}
+
+	results := make([]KeyValuePair, 0, limit)
+	now := time.Now().Unix()
+	err := d.readClient.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.table),
+		KeyConditionExpression: aws.String(keyCondition),
+		ExpressionAttributeNames: map[string]*string{
+			"#pkey": aws.String(d.partitionKeyName),
+			"#rkey": aws.String(d.rangeKeyName),
+		},
+		ExpressionAttributeValues: exprAttrValues,
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int64(int64(limit)),
+	}, func(page *dynamodb.QueryOutput, _ bool) bool {
+		for _, item := range page.Items {
+			// Expired items are only evaluated against the limit of the query, so pages continue until enough are collected
+			if ttl, exists := item[d.ttlAttributeName]; exists && ttl.N != nil && *ttl.N != "" && *ttl.N != "0" {
+				expirationTime, err := strconv.ParseInt(*ttl.N, 10, 64)
+				if err == nil && now > expirationTime {
+					continue
+				}
+			}
+			kv := KeyValuePair{PartitionKey: partitionKey}
+			if item[d.rangeKeyName] != nil && item[d.rangeKeyName].S != nil {
+				kv.RangeKey = *item[d.rangeKeyName].S
+			}
+			if item["value"] != nil {
+				if item["value"].B != nil {
+					kv.Value = item["value"].B
+				} else if item["value"].S != nil {
+					kv.Value = []byte(*item["value"].S)
+				}
+			}
+			results = append(results, kv)
+			if len(results) >= limit {
+				return false
+			}
+		}
+		return true
+	}, request.WithResponseReadTimeout(d.getTimeout))
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (d *DynamoDBConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	ctx, span := common.StartSpan(ctx, "DynamoDBConnector.List")
 	defer span.End()
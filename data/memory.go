@@ -6,6 +6,7 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
@@ -20,15 +21,21 @@ const (
 	memoryReverseIndexPrefix = "rvi"
 )
 
-var _ Connector = (*MemoryConnector)(nil)
+var _ CounterConnector = (*MemoryConnector)(nil)
 
 type MemoryConnector struct {
 	id          string
 	logger      *zerolog.Logger
 	cache       *ristretto.Cache[string, []byte]
 	locks       sync.Map // map[string]*sync.Mutex
+	counters    sync.Map // map[string]*memoryCounter
 	emitMetrics bool
 
+	// Serializes operations spanning multiple counters, so that they are applied atomically
+	multiCountersMu sync.Mutex
+
+	lastCountersSweep atomic.Int64
+
 	// Previous metric values for calculating deltas
 	prevMetrics struct {
 		setsDropped  uint64
@@ -132,6 +139,11 @@ func (m *MemoryConnector) Set(ctx context.Context, partitionKey, rangeKey string
 	return nil
 }
 
+// WaitForWrites blocks until buffered ristretto writes are applied.
+func (m *MemoryConnector) WaitForWrites() {
+	m.cache.Wait()
+}
+
 func (m *MemoryConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
 	if index == ConnectorReverseIndex && strings.HasSuffix(partitionKey, "*") {
 		fullKey, found := m.cache.Get(memoryReverseIndexPrefix + "#" + partitionKey + "#" + rangeKey)
@@ -193,6 +205,122 @@ func (m *MemoryConnector) PublishCounterInt64(ctx context.Context, key string, v
 	return nil
 }
 
+const memoryCountersSweepInterval = time.Minute
+
+type memoryCounter struct {
+	mu        sync.Mutex
+	value     int64
+	expiresAt time.Time
+	removed   bool
+}
+
+// IncrementCounterInt64 keeps counters outside of the ristretto cache, as its writes are applied
+// asynchronously and might be dropped, which is not acceptable for counters.
+func (m *MemoryConnector) IncrementCounterInt64(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	now := time.Now()
+	m.sweepExpiredCounters(now)
+
+	for {
+		value, _ := m.counters.LoadOrStore(key, &memoryCounter{})
+		counter := value.(*memoryCounter)
+
+		counter.mu.Lock()
+		if counter.removed {
+			// Removed by a concurrent sweep after being loaded, so a new counter must be created
+			counter.mu.Unlock()
+			continue
+		}
+		if !counter.expiresAt.IsZero() && now.After(counter.expiresAt) {
+			counter.value = 0
+			counter.expiresAt = time.Time{}
+		}
+		counter.value += delta
+		if counter.expiresAt.IsZero() && ttl > 0 {
+			counter.expiresAt = now.Add(ttl)
+		}
+		result := counter.value
+		counter.mu.Unlock()
+
+		return result, nil
+	}
+}
+
+// AcquireSlidingWindowPermits checks and increments the counters while holding multiCountersMu, so it is
+// atomic with respect to other multi-counter operations on the same keys.
+func (m *MemoryConnector) AcquireSlidingWindowPermits(ctx context.Context, previousKey, currentKey string, overlap float64, permits, limit int64, ttl time.Duration) (bool, int64, int64, error) {
+	m.multiCountersMu.Lock()
+	defer m.multiCountersMu.Unlock()
+
+	now := time.Now()
+	previous := m.peekCounter(previousKey, now)
+	current := m.peekCounter(currentKey, now)
+	if float64(previous)*overlap+float64(current+permits) > float64(limit) {
+		return false, previous, current, nil
+	}
+	current, err := m.IncrementCounterInt64(ctx, currentKey, permits, ttl)
+	return true, previous, current, err
+}
+
+// IncrementCountersWithinLimits checks and increments the counters while holding multiCountersMu, so it is
+// atomic with respect to other multi-counter operations on the same keys.
+func (m *MemoryConnector) IncrementCountersWithinLimits(ctx context.Context, increments []CounterIncrement) (bool, []int64, error) {
+	m.multiCountersMu.Lock()
+	defer m.multiCountersMu.Unlock()
+
+	now := time.Now()
+	values := make([]int64, len(increments))
+	exceeded := false
+	for i, inc := range increments {
+		values[i] = m.peekCounter(inc.Key, now)
+		if inc.Limit > 0 && values[i]+inc.Delta > inc.Limit {
+			exceeded = true
+		}
+	}
+	if exceeded {
+		return false, values, nil
+	}
+	for i, inc := range increments {
+		value, err := m.IncrementCounterInt64(ctx, inc.Key, inc.Delta, inc.TTL)
+		if err != nil {
+			return false, nil, err
+		}
+		values[i] = value
+	}
+	return true, values, nil
+}
+
+// peekCounter returns the value of a counter, or 0 when it does not exist or has expired.
+func (m *MemoryConnector) peekCounter(key string, now time.Time) int64 {
+	value, ok := m.counters.Load(key)
+	if !ok {
+		return 0
+	}
+	counter := value.(*memoryCounter)
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	if counter.removed || (!counter.expiresAt.IsZero() && now.After(counter.expiresAt)) {
+		return 0
+	}
+	return counter.value
+}
+
+func (m *MemoryConnector) sweepExpiredCounters(now time.Time) {
+	last := m.lastCountersSweep.Load()
+	if now.UnixNano()-last < int64(memoryCountersSweepInterval) || !m.lastCountersSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	m.counters.Range(func(key, value any) bool {
+		counter := value.(*memoryCounter)
+		counter.mu.Lock()
+		if !counter.expiresAt.IsZero() && now.After(counter.expiresAt) {
+			counter.removed = true
+			m.counters.Delete(key)
+		}
+		counter.mu.Unlock()
+		return true
+	})
+}
+
 // metricsCollectionLoop runs in a background goroutine to periodically collect
 // and emit Ristretto cache metrics to Prometheus.
 func (m *MemoryConnector) metricsCollectionLoop(ctx context.Context) {
@@ -307,6 +435,11 @@ func (m *MemoryConnector) List(ctx context.Context, index string, limit int, pag
 	return nil, "", fmt.Errorf("List operation not supported by MemoryConnector - Ristretto cache doesn't provide efficient iteration")
 }
 
+// Clear removes all entries (including reverse index entries) from the cache.
+func (m *MemoryConnector) Clear() {
+	m.cache.Clear()
+}
+
 // Close cleans up resources including stopping the metrics collection goroutine
 func (m *MemoryConnector) Close() error {
 	if m.stopMetrics != nil {
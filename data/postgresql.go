@@ -877,6 +877,101 @@ func (p *PostgreSQLConnector) Delete(ctx context.Context, partitionKey, rangeKey
 	return err
 }
 
+func (p *PostgreSQLConnector) DeletePartition(ctx context.Context, partitionKey string) error {
+	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.DeletePartition")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.String("partition_key", partitionKey))
+	}
+
+	p.connMu.RLock()
+	defer p.connMu.RUnlock()
+
+	if p.conn == nil {
+		err := fmt.Errorf("PostgreSQLConnector not connected yet")
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	p.logger.Debug().Str("partitionKey", partitionKey).Msg("deleting partition from postgres")
+
+	ctx, cancel := context.WithTimeout(ctx, p.setTimeout)
+	defer cancel()
+
+	_, err := p.conn.Exec(ctx, fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE partition_key = $1
+	`, p.table), partitionKey)
+
+	if err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+	}
+
+	return err
+}
+
+// ListPartition compares range keys byte-wise (C collation) so that the order does not depend on the database locale.
+func (p *PostgreSQLConnector) ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error) {
+	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.ListPartition")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	p.connMu.RLock()
+	defer p.connMu.RUnlock()
+
+	if p.conn == nil {
+		err := fmt.Errorf("PostgreSQLConnector not connected yet")
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.getTimeout)
+	defer cancel()
+
+	rows, err := p.conn.Query(ctx, fmt.Sprintf(`
+		SELECT range_key, value FROM %s
+		WHERE partition_key = $1 AND ($2 = '' OR range_key COLLATE "C" < $2)
+		AND (expires_at IS NULL OR expires_at > NOW() AT TIME ZONE 'UTC')
+		ORDER BY range_key COLLATE "C" DESC
+		LIMIT $3
+	`, p.table), partitionKey, beforeRangeKey, limit)
+	if err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]KeyValuePair, 0, limit)
+	for rows.Next() {
+		kv := KeyValuePair{PartitionKey: partitionKey}
+		if err := rows.Scan(&kv.RangeKey, &kv.Value); err != nil {
+			common.SetTraceSpanError(span, err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		results = append(results, kv)
+	}
+	if err := rows.Err(); err != nil {
+		p.handleConnectionFailure(err)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (p *PostgreSQLConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
 	ctx, span := common.StartSpan(ctx, "PostgreSQLConnector.List")
 	defer span.End()
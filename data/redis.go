@@ -2,10 +2,13 @@ package data
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/erpc/erpc/common"
@@ -20,11 +23,12 @@ import (
 )
 
 const (
-	RedisDriverName         = "redis"
-	redisReverseIndexPrefix = "rvi"
+	RedisDriverName           = "redis"
+	redisReverseIndexPrefix   = "rvi"
+	redisPartitionIndexPrefix = "pidx"
 )
 
-var _ Connector = &RedisConnector{}
+var _ CounterConnector = &RedisConnector{}
 
 // zerologAdapter adapts zerolog to work with go-redis internal logger
 type zerologAdapter struct {
@@ -54,10 +58,11 @@ func (z *zerologAdapter) Printf(ctx context.Context, format string, v ...interfa
 type RedisConnector struct {
 	id            string
 	logger        *zerolog.Logger
-	client        *redis.Client
+	client        redis.UniversalClient
 	initializer   *util.Initializer
 	cfg           *common.RedisConnectorConfig
 	redsync       *redsync.Redsync
+	tlsConfig     *tls.Config
 	pubsubManager *RedisPubSubManager
 	appCtx        context.Context
 
@@ -125,14 +130,76 @@ func (r *RedisConnector) connectTask(ctx context.Context) error {
 		r.logger.Debug().Err(err).Msg("existing Redis connection unhealthy, proceeding with reconnection")
 	}
 
-	var options *redis.Options
+	var client redis.UniversalClient
+	var tlsConfig *tls.Config
+	var addr string
 	var err error
+	switch r.cfg.Mode {
+	case common.RedisModeCluster, common.RedisModeSentinel:
+		client, tlsConfig, err = r.newMultiNodeClient()
+		addr = strings.Join(r.cfg.Addrs, ",")
+	default:
+		var standalone *redis.Client
+		standalone, tlsConfig, err = r.newStandaloneClient()
+		if err == nil {
+			client = standalone
+			addr = standalone.Options().Addr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	r.logger.Debug().Str("addr", addr).Str("mode", string(r.cfg.Mode)).Msg("attempting to connect to Redis")
 
+	// Test the connection with Ping.
+	ctx, cancel := context.WithTimeout(ctx, r.initTimeout)
+	defer cancel()
+	_, err = client.Ping(ctx).Result()
+	if err != nil {
+		_ = client.Close()
+		if tlsConfig != nil && strings.Contains(err.Error(), "certificate") {
+			errMsg := fmt.Sprintf("failed to connect to Redis with TLS enabled: %v.", err)
+			if !tlsConfig.InsecureSkipVerify && tlsConfig.RootCAs == nil {
+				errMsg += " Ensure the server certificate is valid and trusted by the system CAs, or provide a custom CA using 'tls.caFile'."
+			} else if !tlsConfig.InsecureSkipVerify && tlsConfig.RootCAs != nil {
+				errMsg += " Ensure the server certificate is valid and signed by the provided 'tls.caFile'."
+			}
+			if len(tlsConfig.Certificates) > 0 {
+				errMsg += " Also verify the client certificate and key ('tls.certFile', 'tls.keyFile') if used."
+			}
+			return common.NewTaskFatal(fmt.Errorf(errMsg))
+		}
+		return err
+	}
+
+	if r.client != nil {
+		_ = r.client.Close()
+	}
+	r.client = client
+	r.tlsConfig = tlsConfig
+
+	pool := goredis.NewPool(client)
+	r.redsync = redsync.New(pool)
+
+	// Handle pubsub manager - create once and let it handle reconnections
+	if r.pubsubManager == nil {
+		// First time initialization
+		r.pubsubManager = NewRedisPubSubManager(r.appCtx, r.logger, r)
+	}
+	// Manager will detect the client change and reconnect internally
+
+	r.logger.Info().Str("addr", addr).Str("mode", string(r.cfg.Mode)).Msg("successfully connected to Redis")
+	return nil
+}
+
+// newStandaloneClient creates a client for a single Redis server from the configured URI.
+func (r *RedisConnector) newStandaloneClient() (*redis.Client, *tls.Config, error) {
 	redisURI := strings.TrimSpace(r.cfg.URI)
 	r.logger.Debug().Str("uri", util.RedactEndpoint(redisURI)).Msg("attempting to connect to Redis using provided URI")
-	options, err = redis.ParseURL(redisURI)
+	options, err := redis.ParseURL(redisURI)
 	if err != nil {
-		return common.NewTaskFatal(fmt.Errorf("failed to parse Redis URI: %w", err))
+		return nil, nil, common.NewTaskFatal(fmt.Errorf("failed to parse Redis URI: %w", err))
 	}
 
 	if r.initTimeout == 0 && options.DialTimeout > 0 {
@@ -156,7 +223,7 @@ func (r *RedisConnector) connectTask(ctx context.Context) error {
 	if cfgTLS := r.cfg.TLS; cfgTLS != nil && cfgTLS.Enabled {
 		tlsConfig, err := common.CreateTLSConfig(cfgTLS)
 		if err != nil {
-			return common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
+			return nil, nil, common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
 		}
 
 		if options.TLSConfig == nil {
@@ -179,46 +246,95 @@ func (r *RedisConnector) connectTask(ctx context.Context) error {
 		r.logger.Debug().Msg("using TLS configuration implied by rediss:// URI (verify against system CAs or InsecureSkipVerify)")
 	}
 
-	r.logger.Debug().Str("addr", options.Addr).Msg("attempting to connect to Redis")
-	client := redis.NewClient(options)
+	return redis.NewClient(options), options.TLSConfig, nil
+}
 
-	// Test the connection with Ping.
-	ctx, cancel := context.WithTimeout(ctx, r.initTimeout)
-	defer cancel()
-	_, err = client.Ping(ctx).Result()
-	if err != nil {
-		if options.TLSConfig != nil && strings.Contains(err.Error(), "certificate") {
-			errMsg := fmt.Sprintf("failed to connect to Redis with TLS enabled: %v.", err)
-			if !options.TLSConfig.InsecureSkipVerify && options.TLSConfig.RootCAs == nil {
-				errMsg += " Ensure the server certificate is valid and trusted by the system CAs, or provide a custom CA using 'tls.caFile'."
-			} else if !options.TLSConfig.InsecureSkipVerify && options.TLSConfig.RootCAs != nil {
-				errMsg += " Ensure the server certificate is valid and signed by the provided 'tls.caFile'."
-			}
-			if len(options.TLSConfig.Certificates) > 0 {
-				errMsg += " Also verify the client certificate and key ('tls.certFile', 'tls.keyFile') if used."
-			}
-			return common.NewTaskFatal(fmt.Errorf(errMsg))
+// newMultiNodeClient creates a slot-aware client in cluster mode, or a client that discovers
+// the current master via sentinels (and follows failovers) in sentinel mode.
+func (r *RedisConnector) newMultiNodeClient() (redis.UniversalClient, *tls.Config, error) {
+	var tlsConfig *tls.Config
+	if cfgTLS := r.cfg.TLS; cfgTLS != nil && cfgTLS.Enabled {
+		var err error
+		tlsConfig, err = common.CreateTLSConfig(cfgTLS)
+		if err != nil {
+			return nil, nil, common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
 		}
-		return err
 	}
 
-	if r.client != nil {
-		_ = r.client.Close()
+	if r.cfg.Mode == common.RedisModeCluster {
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        r.cfg.Addrs,
+			Username:     r.cfg.Username,
+			Password:     r.cfg.Password,
+			TLSConfig:    tlsConfig,
+			PoolSize:     r.cfg.ConnPoolSize,
+			DialTimeout:  r.initTimeout,
+			ReadTimeout:  r.getTimeout,
+			WriteTimeout: r.setTimeout,
+		}), tlsConfig, nil
 	}
-	r.client = client
 
-	pool := goredis.NewPool(client)
-	r.redsync = redsync.New(pool)
+	return redis.NewFailoverClient(&redis.FailoverOptions{
+		MasterName:       r.cfg.MasterName,
+		SentinelAddrs:    r.cfg.Addrs,
+		SentinelUsername: r.cfg.SentinelUsername,
+		SentinelPassword: r.cfg.SentinelPassword,
+		Username:         r.cfg.Username,
+		Password:         r.cfg.Password,
+		DB:               r.cfg.DB,
+		TLSConfig:        tlsConfig,
+		PoolSize:         r.cfg.ConnPoolSize,
+		DialTimeout:      r.initTimeout,
+		ReadTimeout:      r.getTimeout,
+		WriteTimeout:     r.setTimeout,
+	}), tlsConfig, nil
+}
 
-	// Handle pubsub manager - create once and let it handle reconnections
-	if r.pubsubManager == nil {
-		// First time initialization
-		r.pubsubManager = NewRedisPubSubManager(r.appCtx, r.logger, r)
+// entryKey returns the key of an entry. In cluster mode the partition key is hash-tagged, so that all
+// entries of a partition (e.g. a shared state counter value and its lock) are stored in the same slot.
+func (r *RedisConnector) entryKey(partitionKey, rangeKey string) string {
+	if r.cfg.Mode == common.RedisModeCluster {
+		return fmt.Sprintf("{%s}:%s", partitionKey, rangeKey)
 	}
-	// Manager will detect the client change and reconnect internally
+	return fmt.Sprintf("%s:%s", partitionKey, rangeKey)
+}
 
-	r.logger.Info().Str("addr", options.Addr).Msg("successfully connected to Redis") // Use options.Addr for logging consistency
-	return nil
+// reverseIndexKey returns the key of the reverse index entry for wildcard lookups, or an empty string
+// for partition keys that are not indexed (only EVM partition keys that are not already wildcarded).
+func (r *RedisConnector) reverseIndexKey(partitionKey, rangeKey string) string {
+	if !strings.HasPrefix(partitionKey, "evm:") || strings.HasSuffix(partitionKey, "*") {
+		return ""
+	}
+	parts := strings.SplitAfterN(partitionKey, ":", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, parts[0]+parts[1]+"*", rangeKey)
+}
+
+// partitionIndexKey returns the key of the sorted set holding the range keys of a partition written with SetIndexed,
+// hash-tagged in cluster mode to land on the same slot as the entries of the partition.
+func (r *RedisConnector) partitionIndexKey(partitionKey string) string {
+	if r.cfg.Mode == common.RedisModeCluster {
+		return fmt.Sprintf("%s#{%s}", redisPartitionIndexPrefix, partitionKey)
+	}
+	return fmt.Sprintf("%s#%s", redisPartitionIndexPrefix, partitionKey)
+}
+
+// lockKey returns the key of a lock, hash-tagged in cluster mode to land on the same slot as entries of the same partition key.
+func (r *RedisConnector) lockKey(key string) string {
+	if r.cfg.Mode == common.RedisModeCluster {
+		return fmt.Sprintf("lock:{%s}", key)
+	}
+	return fmt.Sprintf("lock:%s", key)
+}
+
+// parseEntryKey is the inverse of entryKey.
+func (r *RedisConnector) parseEntryKey(key string) (partitionKey, rangeKey string, ok bool) {
+	if r.cfg.Mode == common.RedisModeCluster && strings.HasPrefix(key, "{") {
+		return strings.Cut(key[1:], "}:")
+	}
+	return strings.Cut(key, ":")
 }
 
 // markConnectionAsLostIfNecessary sets the connection task's state to "failed" so that the Initializer triggers a retry.
@@ -294,7 +410,7 @@ func (r *RedisConnector) Set(ctx context.Context, partitionKey, rangeKey string,
 		return err
 	}
 
-	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
+	key := r.entryKey(partitionKey, rangeKey)
 	if len(value) < 1024 {
 		r.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Int("len", len(value)).Msg("writing value to Redis")
 	} else {
@@ -319,23 +435,48 @@ func (r *RedisConnector) Set(ctx context.Context, partitionKey, rangeKey string,
 	/**
 	 * TODO Find a better way to store a reverse index for cache entries with unknown block ref (*):
 	 */
-	if strings.HasPrefix(partitionKey, "evm:") && !strings.HasSuffix(partitionKey, "*") {
-		// Maintain a reverse index for fast wildcard lookups (idx_reverse) similar to Memory connector.
-		// Only index EVM partition keys that are not already wildcarded.
-		parts := strings.SplitAfterN(partitionKey, ":", 3)
-		if len(parts) >= 2 {
-			wildcardPartitionKey := parts[0] + parts[1] + "*"
-			reverseKey := fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, wildcardPartitionKey, rangeKey)
-			// Best-effort: log on error but do not fail the primary SET.
-			if err := r.client.Set(ctx, reverseKey, partitionKey, duration).Err(); err != nil {
-				r.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to SET reverse index in Redis")
-			}
+	// Maintain a reverse index for fast wildcard lookups (idx_reverse) similar to Memory connector.
+	if reverseKey := r.reverseIndexKey(partitionKey, rangeKey); reverseKey != "" {
+		// Best-effort: log on error but do not fail the primary SET.
+		if err := r.client.Set(ctx, reverseKey, partitionKey, duration).Err(); err != nil {
+			r.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to SET reverse index in Redis")
 		}
 	}
 
 	return nil
 }
 
+// SetIndexed stores the entry like Set and adds its range key to the index of the partition. The index expires
+// along with the longest-lived entry of the partition.
+func (r *RedisConnector) SetIndexed(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	if err := r.Set(ctx, partitionKey, rangeKey, value, ttl); err != nil {
+		return err
+	}
+
+	ctx, span := common.StartSpan(ctx, "RedisConnector.SetIndexed")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	defer cancel()
+
+	indexKey := r.partitionIndexKey(partitionKey)
+	pipe := r.client.Pipeline()
+	pipe.ZAdd(ctx, indexKey, redis.Z{Member: rangeKey})
+	if ttl != nil && *ttl > 0 {
+		// NX covers an index that was just created, GT extends it for entries outliving the previous ones
+		pipe.ExpireNX(ctx, indexKey, *ttl)
+		pipe.ExpireGT(ctx, indexKey, *ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Warn().Err(err).Str("key", indexKey).Msg("failed to update partition index in Redis")
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	return nil
+}
+
 // Get retrieves a value from Redis. If wildcard, retrieves the first matching key. Returns early if not ready.
 func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
 	ctx, span := common.StartSpan(ctx, "RedisConnector.Get",
@@ -372,7 +513,7 @@ func (r *RedisConnector) Get(ctx context.Context, index, partitionKey, rangeKey
 	}
 
 	// Construct the final key and continue with the regular retrieval path.
-	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
+	key := r.entryKey(partitionKey, rangeKey)
 
 	ctx, cancel := context.WithTimeout(ctx, r.getTimeout)
 	defer cancel()
@@ -480,7 +621,7 @@ func (r *RedisConnector) Lock(ctx context.Context, lockKey string, ttl time.Dura
 		Msg("calculated lock acquisition strategy")
 
 	mutex := r.redsync.NewMutex(
-		fmt.Sprintf("lock:%s", lockKey),
+		r.lockKey(lockKey),
 		redsync.WithExpiry(ttl),               // Lock key in Redis expires after ttl
 		redsync.WithRetryDelay(retryInterval), // Wait this long between retries
 		redsync.WithTries(maxRetries),         // Attempt this many times (or until context is done)
@@ -558,6 +699,153 @@ func (r *RedisConnector) PublishCounterInt64(ctx context.Context, key string, va
 	return err
 }
 
+// incrementCounterScript sets the expiry only when the counter is created, so that it is not extended by every increment.
+var incrementCounterScript = redis.NewScript(`
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// IncrementCounterInt64 atomically increments a counter in Redis.
+func (r *RedisConnector) IncrementCounterInt64(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.IncrementCounterInt64",
+		trace.WithAttributes(
+			attribute.String("key", key),
+		),
+	)
+	defer span.End()
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	defer cancel()
+
+	value, err := incrementCounterScript.Run(ctx, r.client, []string{key}, delta, ttl.Milliseconds()).Int64()
+	if err != nil {
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// acquireSlidingWindowScript increments the counter of the current window (KEYS[2]) unless the count of the previous
+// window (KEYS[1]) weighted by the overlap plus the current count would exceed the limit.
+var acquireSlidingWindowScript = redis.NewScript(`
+local previous = tonumber(redis.call("GET", KEYS[1]) or "0")
+local current = tonumber(redis.call("GET", KEYS[2]) or "0")
+local permits = tonumber(ARGV[1])
+if previous * tonumber(ARGV[2]) + current + permits > tonumber(ARGV[3]) then
+	return {0, previous, current}
+end
+current = redis.call("INCRBY", KEYS[2], permits)
+if redis.call("PTTL", KEYS[2]) == -1 then
+	redis.call("PEXPIRE", KEYS[2], ARGV[4])
+end
+return {1, previous, current}
+`)
+
+// AcquireSlidingWindowPermits checks and increments the counters in a single script. In cluster mode both keys must
+// hash to the same slot (e.g. by sharing a hash tag).
+func (r *RedisConnector) AcquireSlidingWindowPermits(ctx context.Context, previousKey, currentKey string, overlap float64, permits, limit int64, ttl time.Duration) (bool, int64, int64, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.AcquireSlidingWindowPermits",
+		trace.WithAttributes(
+			attribute.String("key", currentKey),
+		),
+	)
+	defer span.End()
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, 0, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	defer cancel()
+
+	result, err := acquireSlidingWindowScript.Run(
+		ctx, r.client, []string{previousKey, currentKey},
+		permits, strconv.FormatFloat(overlap, 'f', -1, 64), limit, ttl.Milliseconds(),
+	).Int64Slice()
+	if err == nil && len(result) != 3 {
+		err = fmt.Errorf("unexpected sliding window script result: %v", result)
+	}
+	if err != nil {
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return false, 0, 0, err
+	}
+
+	return result[0] == 1, result[1], result[2], nil
+}
+
+// incrementCountersWithinLimitsScript applies all increments (ARGV holds delta, limit and ttl of each key) unless any
+// counter would exceed its limit. The first element of the result tells whether they were applied.
+var incrementCountersWithinLimitsScript = redis.NewScript(`
+local values = {}
+local exceeded = false
+for i, key in ipairs(KEYS) do
+	local value = tonumber(redis.call("GET", key) or "0")
+	local delta = tonumber(ARGV[i * 3 - 2])
+	local limit = tonumber(ARGV[i * 3 - 1])
+	if limit > 0 and value + delta > limit then
+		exceeded = true
+	end
+	values[i] = value
+end
+if exceeded then
+	table.insert(values, 1, 0)
+	return values
+end
+for i, key in ipairs(KEYS) do
+	values[i] = redis.call("INCRBY", key, ARGV[i * 3 - 2])
+	if redis.call("PTTL", key) == -1 then
+		redis.call("PEXPIRE", key, ARGV[i * 3])
+	end
+end
+table.insert(values, 1, 1)
+return values
+`)
+
+// IncrementCountersWithinLimits checks and increments the counters in a single script. In cluster mode all keys must
+// hash to the same slot (e.g. by sharing a hash tag).
+func (r *RedisConnector) IncrementCountersWithinLimits(ctx context.Context, increments []CounterIncrement) (bool, []int64, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.IncrementCountersWithinLimits")
+	defer span.End()
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return false, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	defer cancel()
+
+	keys := make([]string, 0, len(increments))
+	args := make([]interface{}, 0, len(increments)*3)
+	for _, inc := range increments {
+		keys = append(keys, inc.Key)
+		args = append(args, inc.Delta, inc.Limit, inc.TTL.Milliseconds())
+	}
+	result, err := incrementCountersWithinLimitsScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err == nil && len(result) != len(increments)+1 {
+		err = fmt.Errorf("unexpected counters script result: %v", result)
+	}
+	if err != nil {
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return false, nil, err
+	}
+
+	return result[0] == 1, result[1:], nil
+}
+
 var _ DistributedLock = &redisLock{}
 
 type redisLock struct {
@@ -610,7 +898,7 @@ func (r *RedisConnector) Delete(ctx context.Context, partitionKey, rangeKey stri
 		return err
 	}
 
-	key := fmt.Sprintf("%s:%s", partitionKey, rangeKey)
+	key := r.entryKey(partitionKey, rangeKey)
 	r.logger.Debug().Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("deleting from Redis")
 
 	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
@@ -625,57 +913,183 @@ func (r *RedisConnector) Delete(ctx context.Context, partitionKey, rangeKey stri
 	}
 
 	// Clean up reverse index if it exists
-	if strings.HasPrefix(partitionKey, "evm:") && !strings.HasSuffix(partitionKey, "*") {
-		parts := strings.SplitAfterN(partitionKey, ":", 3)
-		if len(parts) >= 2 {
-			wildcardPartitionKey := parts[0] + parts[1] + "*"
-			reverseKey := fmt.Sprintf("%s#%s#%s", redisReverseIndexPrefix, wildcardPartitionKey, rangeKey)
-			// Best-effort: log on error but do not fail the primary DELETE
-			if err := r.client.Del(ctx, reverseKey).Err(); err != nil {
-				r.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to DELETE reverse index in Redis")
+	if reverseKey := r.reverseIndexKey(partitionKey, rangeKey); reverseKey != "" {
+		// Best-effort: log on error but do not fail the primary DELETE
+		if err := r.client.Del(ctx, reverseKey).Err(); err != nil {
+			r.logger.Warn().Err(err).Str("key", reverseKey).Msg("failed to DELETE reverse index in Redis")
+		}
+	}
+
+	return nil
+}
+
+// DeletePartition deletes the entries of a partition written with SetIndexed, along with their reverse index keys
+// and the partition index itself.
+func (r *RedisConnector) DeletePartition(ctx context.Context, partitionKey string) error {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.DeletePartition")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.String("partition_key", partitionKey))
+	}
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	r.logger.Debug().Str("partitionKey", partitionKey).Msg("deleting partition from Redis")
+
+	ctx, cancel := context.WithTimeout(ctx, r.setTimeout)
+	defer cancel()
+
+	indexKey := r.partitionIndexKey(partitionKey)
+	rangeKeys, err := r.client.ZRange(ctx, indexKey, 0, -1).Result()
+	if err == nil {
+		pipe := r.client.Pipeline()
+		for _, rk := range rangeKeys {
+			pipe.Del(ctx, r.entryKey(partitionKey, rk))
+			if reverseKey := r.reverseIndexKey(partitionKey, rk); reverseKey != "" {
+				pipe.Del(ctx, reverseKey)
 			}
 		}
+		pipe.Del(ctx, indexKey)
+		_, err = pipe.Exec(ctx)
+	}
+	if err != nil {
+		r.logger.Warn().Err(err).Str("partitionKey", partitionKey).Msg("failed to delete partition in Redis")
+		r.markConnectionAsLostIfNecessary(err)
+		common.SetTraceSpanError(span, err)
+		return err
 	}
 
 	return nil
 }
 
-func (r *RedisConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
-	ctx, span := common.StartSpan(ctx, "RedisConnector.List")
+// ListPartition pages through the index of the partition in reverse lexicographical order, so only entries written
+// with SetIndexed are listed, and fetches the values of each page at once. Members of entries that expired since
+// they were indexed are removed from the index along the way.
+func (r *RedisConnector) ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.ListPartition")
 	defer span.End()
 
 	if common.IsTracingDetailed {
 		span.SetAttributes(
-			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
 			attribute.Int("limit", limit),
 		)
 	}
 
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
 	if err := r.checkReady(); err != nil {
 		common.SetTraceSpanError(span, err)
-		return nil, "", err
+		return nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, r.getTimeout)
 	defer cancel()
 
-	// Use SCAN for efficient pagination
-	cursor := uint64(0)
-	if paginationToken != "" {
-		var err error
-		cursor, err = strconv.ParseUint(paginationToken, 10, 64)
+	indexKey := r.partitionIndexKey(partitionKey)
+	maxMember := "+"
+	if beforeRangeKey != "" {
+		maxMember = "(" + beforeRangeKey
+	}
+
+	results := make([]KeyValuePair, 0, limit)
+	for len(results) < limit {
+		count := limit - len(results)
+		rangeKeys, err := r.client.ZRevRangeByLex(ctx, indexKey, &redis.ZRangeBy{
+			Min:   "-",
+			Max:   maxMember,
+			Count: int64(count),
+		}).Result()
 		if err != nil {
-			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+			r.logger.Warn().Err(err).Str("partitionKey", partitionKey).Msg("failed to list partition index in Redis")
+			r.markConnectionAsLostIfNecessary(err)
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+		if len(rangeKeys) == 0 {
+			break
+		}
+
+		// Entries of a partition share the same slot in cluster mode, so they can be fetched with a single MGET
+		keys := make([]string, len(rangeKeys))
+		for i, rk := range rangeKeys {
+			keys[i] = r.entryKey(partitionKey, rk)
+		}
+		values, err := r.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			r.markConnectionAsLostIfNecessary(err)
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+
+		var stale []interface{}
+		for i, value := range values {
+			str, ok := value.(string)
+			if !ok {
+				stale = append(stale, rangeKeys[i])
+				continue
+			}
+			results = append(results, KeyValuePair{
+				PartitionKey: partitionKey,
+				RangeKey:     rangeKeys[i],
+				Value:        []byte(str),
+			})
+		}
+		if len(stale) > 0 {
+			if err := r.client.ZRem(ctx, indexKey, stale...).Err(); err != nil {
+				r.logger.Warn().Err(err).Str("key", indexKey).Msg("failed to remove expired entries from partition index in Redis")
+			}
+		}
+
+		if len(rangeKeys) < count {
+			break
 		}
+		maxMember = "(" + rangeKeys[len(rangeKeys)-1]
 	}
 
+	return results, nil
+}
+
+func (r *RedisConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "RedisConnector.List")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.Int("limit", limit),
+		)
+	}
+
+	if err := r.checkReady(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.getTimeout)
+	defer cancel()
+
 	// Determine the pattern to scan for
 	pattern := "*"
 	if index == ConnectorReverseIndex {
 		pattern = redisReverseIndexPrefix + "#*"
 	}
 
-	keys, nextCursor, err := r.client.Scan(ctx, cursor, pattern, int64(limit)).Result()
+	// Use SCAN for efficient pagination
+	var keys []string
+	var nextToken string
+	var err error
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		keys, nextToken, err = r.scanCluster(ctx, cluster, paginationToken, pattern, limit)
+	} else {
+		keys, nextToken, err = r.scan(ctx, paginationToken, pattern, limit)
+	}
 	if err != nil {
 		r.logger.Warn().Err(err).Msg("failed to SCAN in Redis")
 		r.markConnectionAsLostIfNecessary(err)
@@ -683,6 +1097,17 @@ func (r *RedisConnector) List(ctx context.Context, index string, limit int, pagi
 		return nil, "", err
 	}
 
+	// Partition indexes are sorted sets, not entries
+	if index != ConnectorReverseIndex {
+		entryKeys := keys[:0]
+		for _, key := range keys {
+			if !strings.HasPrefix(key, redisPartitionIndexPrefix+"#") {
+				entryKeys = append(entryKeys, key)
+			}
+		}
+		keys = entryKeys
+	}
+
 	results := make([]KeyValuePair, 0, len(keys))
 
 	// Get values for all keys in a pipeline for efficiency
@@ -721,11 +1146,7 @@ func (r *RedisConnector) List(ctx context.Context, index string, limit int, pagi
 			}
 		} else {
 			// For regular keys: partitionKey:rangeKey
-			parts := strings.SplitN(keys[i], ":", 2)
-			if len(parts) == 2 {
-				partitionKey = parts[0]
-				rangeKey = parts[1]
-			}
+			partitionKey, rangeKey, _ = r.parseEntryKey(keys[i])
 		}
 
 		if partitionKey != "" && rangeKey != "" {
@@ -737,10 +1158,79 @@ func (r *RedisConnector) List(ctx context.Context, index string, limit int, pagi
 		}
 	}
 
+	return results, nextToken, nil
+}
+
+func (r *RedisConnector) scan(ctx context.Context, paginationToken, pattern string, limit int) ([]string, string, error) {
+	cursor := uint64(0)
+	if paginationToken != "" {
+		var err error
+		cursor, err = strconv.ParseUint(paginationToken, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+		}
+	}
+
+	keys, nextCursor, err := r.client.Scan(ctx, cursor, pattern, int64(limit)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
 	nextToken := ""
 	if nextCursor != 0 {
 		nextToken = strconv.FormatUint(nextCursor, 10)
 	}
 
-	return results, nextToken, nil
+	return keys, nextToken, nil
+}
+
+// scanCluster scans masters one after another (ordered by address), as SCAN only covers the keys of
+// a single node. The pagination token is "<master index>:<cursor>".
+func (r *RedisConnector) scanCluster(ctx context.Context, cluster *redis.ClusterClient, paginationToken, pattern string, limit int) ([]string, string, error) {
+	nodeIdx, cursor := 0, uint64(0)
+	if paginationToken != "" {
+		idx, cur, ok := strings.Cut(paginationToken, ":")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid pagination token: %s", paginationToken)
+		}
+		var err error
+		if nodeIdx, err = strconv.Atoi(idx); err != nil {
+			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+		}
+		if cursor, err = strconv.ParseUint(cur, 10, 64); err != nil {
+			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+		}
+	}
+
+	var mu sync.Mutex
+	masters := make([]*redis.Client, 0)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		mu.Lock()
+		masters = append(masters, client)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(masters, func(i, j int) bool {
+		return masters[i].Options().Addr < masters[j].Options().Addr
+	})
+	if nodeIdx >= len(masters) {
+		return []string{}, "", nil
+	}
+
+	keys, nextCursor, err := masters[nodeIdx].Scan(ctx, cursor, pattern, int64(limit)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextToken := ""
+	if nextCursor != 0 {
+		nextToken = fmt.Sprintf("%d:%d", nodeIdx, nextCursor)
+	} else if nodeIdx+1 < len(masters) {
+		nextToken = fmt.Sprintf("%d:0", nodeIdx+1)
+	}
+
+	return keys, nextToken, nil
 }
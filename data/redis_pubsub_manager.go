@@ -16,6 +16,9 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// failoverPollDelay gives the client time to follow a master switch before counters are polled.
+const failoverPollDelay = 1 * time.Second
+
 // subscriberChannel wraps a channel with metadata to prevent double-close
 type subscriberChannel struct {
 	ch     chan int64
@@ -88,6 +91,11 @@ func (m *RedisPubSubManager) start() error {
 	// Start the goroutines
 	go m.messageLoop()
 	go m.pollingLoop()
+	if m.connector.cfg != nil && m.connector.cfg.Mode == common.RedisModeSentinel {
+		for _, addr := range m.connector.cfg.Addrs {
+			go m.failoverLoop(addr)
+		}
+	}
 
 	return nil
 }
@@ -352,6 +360,62 @@ func (m *RedisPubSubManager) pollingLoop() {
 	}
 }
 
+// failoverLoop listens for master switches announced by a sentinel. The client moves all connections
+// (including the pubsub one) to the new master on its own, but updates published during the switch are
+// lost, so all subscribed keys are polled once the new master has been promoted.
+func (m *RedisPubSubManager) failoverLoop(sentinelAddr string) {
+	defer func() {
+		if r := recover(); r != nil {
+			telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+				"redis-pubsub-failover-loop",
+				"redis-pubsub-manager",
+				common.ErrorFingerprint(r),
+			).Inc()
+			m.logger.Error().
+				Interface("panic", r).
+				Msg("unexpected panic in Redis pubsub failover loop")
+		}
+	}()
+
+	cfg := m.connector.cfg
+	sentinel := redis.NewSentinelClient(&redis.Options{
+		Addr:      sentinelAddr,
+		Username:  cfg.SentinelUsername,
+		Password:  cfg.SentinelPassword,
+		TLSConfig: m.connector.tlsConfig,
+	})
+	defer sentinel.Close()
+
+	// Subscription is re-established by the client if the sentinel connection is lost
+	pubsub := sentinel.Subscribe(m.appCtx, "+switch-master")
+	defer pubsub.Close()
+	ch := pubsub.Channel()
+
+	for {
+		select {
+		case <-m.appCtx.Done():
+			return
+
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			// Payload is "<master name> <old ip> <old port> <new ip> <new port>"
+			if !strings.HasPrefix(msg.Payload, cfg.MasterName+" ") {
+				continue
+			}
+			m.logger.Warn().Str("sentinel", sentinelAddr).Str("payload", msg.Payload).Msg("redis master switched, polling counter values to catch up on missed updates")
+			telemetry.MetricRedisFailoversTotal.WithLabelValues(m.connector.id).Inc()
+			select {
+			case <-time.After(failoverPollDelay):
+			case <-m.appCtx.Done():
+				return
+			}
+			m.pollAllKeys()
+		}
+	}
+}
+
 // pollAllKeys polls current values for all subscribed keys
 func (m *RedisPubSubManager) pollAllKeys() {
 	keys := make([]string, 0)
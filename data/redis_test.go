@@ -315,6 +315,73 @@ func TestRedisConnectorConfigurationMethods(t *testing.T) {
 			expectSuccess: false,
 			checkConnect:  false,
 		},
+		{
+			name: "cluster mode with seed addrs - valid",
+			conn: common.RedisConnectorConfig{
+				Mode:     common.RedisModeCluster,
+				Addrs:    []string{"127.0.0.1:7000", "127.0.0.1:7001"},
+				Username: redisUser,
+				Password: redisPass,
+			},
+			expectSuccess: true,
+			checkConnect:  false,
+		},
+		{
+			name: "cluster mode without addrs - invalid",
+			conn: common.RedisConnectorConfig{
+				Mode: common.RedisModeCluster,
+			},
+			expectSuccess: false,
+			checkConnect:  false,
+		},
+		{
+			name: "cluster mode with non-zero db - invalid",
+			conn: common.RedisConnectorConfig{
+				Mode:  common.RedisModeCluster,
+				Addrs: []string{"127.0.0.1:7000"},
+				DB:    1,
+			},
+			expectSuccess: false,
+			checkConnect:  false,
+		},
+		{
+			name: "cluster mode with uri - invalid",
+			conn: common.RedisConnectorConfig{
+				Mode:  common.RedisModeCluster,
+				Addrs: []string{"127.0.0.1:7000"},
+				URI:   "redis://<addr>/0",
+			},
+			expectSuccess: false,
+			checkConnect:  false,
+		},
+		{
+			name: "sentinel mode inferred from master name - valid",
+			conn: common.RedisConnectorConfig{
+				Addrs:      []string{"127.0.0.1:26379", "127.0.0.1:26380"},
+				MasterName: "mymaster",
+				DB:         2,
+			},
+			expectSuccess: true,
+			checkConnect:  false,
+		},
+		{
+			name: "sentinel mode without master name - invalid",
+			conn: common.RedisConnectorConfig{
+				Mode:  common.RedisModeSentinel,
+				Addrs: []string{"127.0.0.1:26379"},
+			},
+			expectSuccess: false,
+			checkConnect:  false,
+		},
+		{
+			name: "unknown mode - invalid",
+			conn: common.RedisConnectorConfig{
+				Mode: "replicated",
+				URI:  "redis://<addr>/0",
+			},
+			expectSuccess: false,
+			checkConnect:  false,
+		},
 		{
 			name: "discrete fields but missing Addr - invalid",
 			conn: common.RedisConnectorConfig{
@@ -822,3 +889,158 @@ func TestRedisConnector_ChainIsolation(t *testing.T) {
 		require.Equal(t, blockNumberB, valueWildcardB, "wildcard lookup for chain B should return chain B's data")
 	}
 }
+
+func TestRedisClusterMode(t *testing.T) {
+	// miniredis answers CLUSTER SLOTS as a single master owning all slots
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+
+	logger := zerolog.New(io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &common.RedisConnectorConfig{
+		Mode:        common.RedisModeCluster,
+		Addrs:       []string{m.Addr()},
+		InitTimeout: common.Duration(2 * time.Second),
+		GetTimeout:  common.Duration(2 * time.Second),
+		SetTimeout:  common.Duration(2 * time.Second),
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+
+	connector, err := NewRedisConnector(ctx, &logger, "test-cluster", cfg)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return connector.initializer.State() == util.StateReady
+	}, 3*time.Second, 100*time.Millisecond, "connector did not become ready")
+	_, ok := connector.client.(*redis.ClusterClient)
+	require.True(t, ok, "cluster mode must use a slot-aware cluster client")
+
+	t.Run("HashTagsPartitionKeys", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:100", "eth_getBlockByNumber:abc", []byte("block"), nil))
+		require.True(t, m.Exists("{evm:1:100}:eth_getBlockByNumber:abc"))
+
+		val, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		require.Equal(t, "block", string(val))
+
+		val, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		require.Equal(t, "block", string(val))
+
+		require.NoError(t, connector.Delete(ctx, "evm:1:100", "eth_getBlockByNumber:abc"))
+		require.False(t, m.Exists("{evm:1:100}:eth_getBlockByNumber:abc"))
+	})
+
+	t.Run("LockAndCounterValueShareSlot", func(t *testing.T) {
+		lock, err := connector.Lock(ctx, "counter-key", 5*time.Second)
+		require.NoError(t, err)
+		require.True(t, m.Exists("lock:{counter-key}"))
+		require.NoError(t, connector.Set(ctx, "counter-key", "value", []byte("42"), nil))
+		require.NoError(t, lock.Unlock(ctx))
+
+		lockSlot, err := connector.client.ClusterKeySlot(ctx, connector.lockKey("counter-key")).Result()
+		require.NoError(t, err)
+		valueSlot, err := connector.client.ClusterKeySlot(ctx, connector.entryKey("counter-key", "value")).Result()
+		require.NoError(t, err)
+		require.Equal(t, lockSlot, valueSlot)
+	})
+
+	t.Run("ListsAndParsesHashTaggedKeys", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:200", "rk", []byte("listed"), nil))
+
+		found := false
+		token := ""
+		for {
+			items, next, err := connector.List(ctx, ConnectorMainIndex, 10, token)
+			require.NoError(t, err)
+			for _, item := range items {
+				if item.PartitionKey == "evm:1:200" && item.RangeKey == "rk" {
+					found = true
+					require.Equal(t, "listed", string(item.Value))
+				}
+			}
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		require.True(t, found)
+	})
+
+	t.Run("DeletesIndexedPartitionWithoutScanning", func(t *testing.T) {
+		ttl := time.Minute
+		require.NoError(t, connector.SetIndexed(ctx, "evm:1:300", "rk1", []byte("a"), &ttl))
+		require.NoError(t, connector.SetIndexed(ctx, "evm:1:300", "rk2", []byte("b"), &ttl))
+		require.NoError(t, connector.Set(ctx, "evm:1:301", "rk1", []byte("kept"), &ttl))
+		require.True(t, m.Exists("pidx#{evm:1:300}"))
+
+		indexSlot, err := connector.client.ClusterKeySlot(ctx, connector.partitionIndexKey("evm:1:300")).Result()
+		require.NoError(t, err)
+		entrySlot, err := connector.client.ClusterKeySlot(ctx, connector.entryKey("evm:1:300", "rk1")).Result()
+		require.NoError(t, err)
+		require.Equal(t, indexSlot, entrySlot)
+
+		// Index keys are not listed as entries
+		items, _, err := connector.List(ctx, ConnectorMainIndex, 100, "")
+		require.NoError(t, err)
+		for _, item := range items {
+			require.NotContains(t, item.PartitionKey, redisPartitionIndexPrefix)
+		}
+
+		require.NoError(t, connector.DeletePartition(ctx, "evm:1:300"))
+		require.False(t, m.Exists("{evm:1:300}:rk1"))
+		require.False(t, m.Exists("{evm:1:300}:rk2"))
+		require.False(t, m.Exists("pidx#{evm:1:300}"))
+		_, err = connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "rk2", nil)
+		require.Error(t, err)
+		val, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:301", "rk1", nil)
+		require.NoError(t, err)
+		require.Equal(t, "kept", string(val))
+	})
+
+	t.Run("ListsIndexedPartitionWithoutScanning", func(t *testing.T) {
+		ttl := time.Minute
+		for _, rk := range []string{"rk1", "rk2", "rk3", "rk4"} {
+			require.NoError(t, connector.SetIndexed(ctx, "disputes:main", rk, []byte("v-"+rk), &ttl))
+		}
+		// Entries that are not indexed are not listed
+		require.NoError(t, connector.Set(ctx, "disputes:main", "rk5", []byte("v-rk5"), &ttl))
+		// Expired entries are skipped and removed from the index
+		m.Del("{disputes:main}:rk3")
+
+		items, err := connector.ListPartition(ctx, "disputes:main", "", 2)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, "rk4", items[0].RangeKey)
+		require.Equal(t, "v-rk4", string(items[0].Value))
+		require.Equal(t, "rk2", items[1].RangeKey)
+
+		items, err = connector.ListPartition(ctx, "disputes:main", items[1].RangeKey, 10)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, "rk1", items[0].RangeKey)
+
+		members, err := m.ZMembers("pidx#{disputes:main}")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"rk1", "rk2", "rk4"}, members)
+	})
+
+	t.Run("PublishesCounterUpdatesToWatchers", func(t *testing.T) {
+		updates, cleanup, err := connector.WatchCounterInt64(ctx, "watched-key")
+		require.NoError(t, err)
+		defer cleanup()
+
+		require.Eventually(t, func() bool {
+			require.NoError(t, connector.PublishCounterInt64(ctx, "watched-key", 7))
+			select {
+			case val := <-updates:
+				return val == 7
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}, 3*time.Second, 50*time.Millisecond)
+	})
+}
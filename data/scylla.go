@@ -0,0 +1,678 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ScyllaDriverName = "scylla"
+
+	// Highest code point, used as the exclusive upper bound of prefix (wildcard) range queries
+	scyllaPrefixUpperBound = "\U0010FFFF"
+)
+
+var _ Connector = (*ScyllaConnector)(nil)
+
+// ScyllaConnector stores entries in a ScyllaDB (or Cassandra) table partitioned by partition key and
+// clustered by range key. A second table clustered by partition key within each range key serves
+// ConnectorReverseIndex lookups (i.e. wildcard partition keys) with a single range query.
+type ScyllaConnector struct {
+	id                string
+	logger            *zerolog.Logger
+	session           *gocql.Session
+	sessionMu         sync.RWMutex
+	initializer       *util.Initializer
+	keyspace          string
+	table             string
+	initTimeout       time.Duration
+	getTimeout        time.Duration
+	setTimeout        time.Duration
+	lockRetryInterval time.Duration
+	statePollInterval time.Duration
+}
+
+var _ DistributedLock = &scyllaLock{}
+
+type scyllaLock struct {
+	connector *ScyllaConnector
+	lockKey   string
+	owner     string
+}
+
+func (l *scyllaLock) IsNil() bool {
+	return l == nil || l.connector == nil
+}
+
+func NewScyllaConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.ScyllaConnectorConfig,
+) (*ScyllaConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating scylla connector")
+
+	connector := &ScyllaConnector{
+		id:                id,
+		logger:            &lg,
+		keyspace:          cfg.Keyspace,
+		table:             cfg.Table,
+		initTimeout:       cfg.InitTimeout.Duration(),
+		getTimeout:        cfg.GetTimeout.Duration(),
+		setTimeout:        cfg.SetTimeout.Duration(),
+		lockRetryInterval: cfg.LockRetryInterval.Duration(),
+		statePollInterval: cfg.StatePollInterval.Duration(),
+	}
+
+	connector.initializer = util.NewInitializer(ctx, &lg, nil)
+	connectTask := util.NewBootstrapTask(fmt.Sprintf("scylla-connect/%s", id), func(ctx context.Context) error {
+		return connector.connectTask(ctx, cfg)
+	})
+	if err := connector.initializer.ExecuteTasks(ctx, connectTask); err != nil {
+		lg.Error().Err(err).Msg("failed to initialize scylla on first attempt (will retry in background)")
+		return connector, nil
+	}
+
+	go func() {
+		<-ctx.Done()
+		connector.sessionMu.Lock()
+		defer connector.sessionMu.Unlock()
+		if connector.session != nil {
+			connector.session.Close()
+			connector.session = nil
+		}
+	}()
+
+	return connector, nil
+}
+
+func (s *ScyllaConnector) connectTask(ctx context.Context, cfg *common.ScyllaConnectorConfig) error {
+	consistency, err := gocql.ParseConsistencyWrapper(strings.ToUpper(cfg.Consistency))
+	if err != nil {
+		return common.NewTaskFatal(fmt.Errorf("invalid consistency: %w", err))
+	}
+
+	cluster := gocql.NewCluster(cfg.Hosts...)
+	cluster.Consistency = consistency
+	cluster.ConnectTimeout = s.initTimeout
+	cluster.Timeout = max(s.getTimeout, s.setTimeout)
+	if cfg.Username != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: cfg.Username,
+			Password: cfg.Password,
+		}
+	}
+	if cfg.TLS != nil && cfg.TLS.Enabled {
+		tlsConfig, err := common.CreateTLSConfig(cfg.TLS)
+		if err != nil {
+			return common.NewTaskFatal(fmt.Errorf("failed to create TLS config: %w", err))
+		}
+		cluster.SslOpts = &gocql.SslOptions{
+			Config:                 tlsConfig,
+			EnableHostVerification: !cfg.TLS.InsecureSkipVerify,
+		}
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.initTimeout)
+	defer cancel()
+
+	statements := []string{
+		fmt.Sprintf(
+			`CREATE KEYSPACE IF NOT EXISTS %s WITH REPLICATION = {'class': 'SimpleStrategy', 'replication_factor': %d}`,
+			cfg.Keyspace, cfg.ReplicationFactor,
+		),
+		fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				partition_key text,
+				range_key text,
+				value blob,
+				PRIMARY KEY ((partition_key), range_key)
+			)`, s.mainTable()),
+		fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %s (
+				range_key text,
+				partition_key text,
+				PRIMARY KEY ((range_key), partition_key)
+			)`, s.reverseTable()),
+	}
+	for _, stmt := range statements {
+		if err := session.Query(stmt).WithContext(ctx).Exec(); err != nil {
+			session.Close()
+			return fmt.Errorf("failed to prepare scylla schema: %w", err)
+		}
+	}
+
+	s.sessionMu.Lock()
+	s.session = session
+	s.sessionMu.Unlock()
+
+	s.logger.Info().Str("keyspace", cfg.Keyspace).Str("table", cfg.Table).Msg("successfully connected to scylla")
+	return nil
+}
+
+func (s *ScyllaConnector) Id() string {
+	return s.id
+}
+
+func (s *ScyllaConnector) getSession() (*gocql.Session, error) {
+	s.sessionMu.RLock()
+	defer s.sessionMu.RUnlock()
+	if s.session == nil {
+		return nil, fmt.Errorf("ScyllaConnector not connected yet")
+	}
+	return s.session, nil
+}
+
+func (s *ScyllaConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.Set")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+			attribute.Int("value_size", len(value)),
+		)
+	}
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	s.logger.Debug().Int("length", len(value)).Str("partitionKey", partitionKey).Str("rangeKey", rangeKey).Msg("writing to scylla")
+
+	ctx, cancel := context.WithTimeout(ctx, s.setTimeout)
+	defer cancel()
+
+	ttlSeconds := scyllaTTLSeconds(ttl)
+	batch := session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(
+		fmt.Sprintf(`INSERT INTO %s (partition_key, range_key, value) VALUES (?, ?, ?) USING TTL ?`, s.mainTable()),
+		partitionKey, rangeKey, value, ttlSeconds,
+	)
+	batch.Query(
+		fmt.Sprintf(`INSERT INTO %s (range_key, partition_key) VALUES (?, ?) USING TTL ?`, s.reverseTable()),
+		rangeKey, partitionKey, ttlSeconds,
+	)
+	if err := session.ExecuteBatch(batch); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *ScyllaConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, _ interface{}) ([]byte, error) {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.Get")
+	defer span.End()
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(
+			attribute.String("index", index),
+			attribute.String("partition_key", partitionKey),
+			attribute.String("range_key", rangeKey),
+		)
+	}
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.getTimeout)
+	defer cancel()
+
+	if index == ConnectorReverseIndex && strings.HasSuffix(partitionKey, "*") && !strings.HasSuffix(rangeKey, "*") {
+		// Resolve the concrete partition key, otherwise continue with the original (wildcard) partition key
+		prefix := strings.TrimSuffix(partitionKey, "*")
+		var resolved string
+		err := session.Query(
+			fmt.Sprintf(`SELECT partition_key FROM %s WHERE range_key = ? AND partition_key >= ? AND partition_key < ? LIMIT 1`, s.reverseTable()),
+			rangeKey, prefix, prefix+scyllaPrefixUpperBound,
+		).WithContext(ctx).Scan(&resolved)
+		if err == nil {
+			partitionKey = resolved
+		} else if !errors.Is(err, gocql.ErrNotFound) {
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+	}
+
+	var value []byte
+	if strings.HasSuffix(rangeKey, "*") {
+		prefix := strings.TrimSuffix(rangeKey, "*")
+		err = session.Query(
+			fmt.Sprintf(`SELECT value FROM %s WHERE partition_key = ? AND range_key >= ? AND range_key < ? LIMIT 1`, s.mainTable()),
+			partitionKey, prefix, prefix+scyllaPrefixUpperBound,
+		).WithContext(ctx).Scan(&value)
+	} else {
+		err = session.Query(
+			fmt.Sprintf(`SELECT value FROM %s WHERE partition_key = ? AND range_key = ?`, s.mainTable()),
+			partitionKey, rangeKey,
+		).WithContext(ctx).Scan(&value)
+	}
+
+	if errors.Is(err, gocql.ErrNotFound) {
+		err := common.NewErrRecordNotFound(partitionKey, rangeKey, ScyllaDriverName)
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	if common.IsTracingDetailed {
+		span.SetAttributes(attribute.Int("value_size", len(value)))
+	}
+
+	return value, nil
+}
+
+func (s *ScyllaConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.Delete")
+	defer span.End()
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.setTimeout)
+	defer cancel()
+
+	batch := session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(fmt.Sprintf(`DELETE FROM %s WHERE partition_key = ? AND range_key = ?`, s.mainTable()), partitionKey, rangeKey)
+	batch.Query(fmt.Sprintf(`DELETE FROM %s WHERE range_key = ? AND partition_key = ?`, s.reverseTable()), rangeKey, partitionKey)
+	if err := session.ExecuteBatch(batch); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// DeletePartition removes all entries of a partition along with their reverse index rows, which are
+// keyed by range key and therefore have to be deleted one by one.
+func (s *ScyllaConnector) DeletePartition(ctx context.Context, partitionKey string) error {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.DeletePartition")
+	defer span.End()
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.setTimeout)
+	defer cancel()
+
+	iter := session.Query(fmt.Sprintf(`SELECT range_key FROM %s WHERE partition_key = ?`, s.mainTable()), partitionKey).
+		WithContext(ctx).
+		Iter()
+	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	var rangeKey string
+	for iter.Scan(&rangeKey) {
+		batch.Query(fmt.Sprintf(`DELETE FROM %s WHERE range_key = ? AND partition_key = ?`, s.reverseTable()), rangeKey, partitionKey)
+	}
+	if err := iter.Close(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+	batch.Query(fmt.Sprintf(`DELETE FROM %s WHERE partition_key = ?`, s.mainTable()), partitionKey)
+	if err := session.ExecuteBatch(batch); err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+// ListPartition relies on the range key being the clustering column of the main table.
+func (s *ScyllaConnector) ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error) {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.ListPartition")
+	defer span.End()
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.getTimeout)
+	defer cancel()
+
+	var query *gocql.Query
+	if beforeRangeKey == "" {
+		query = session.Query(fmt.Sprintf(`SELECT range_key, value FROM %s WHERE partition_key = ? ORDER BY range_key DESC LIMIT ?`, s.mainTable()), partitionKey, limit)
+	} else {
+		query = session.Query(fmt.Sprintf(`SELECT range_key, value FROM %s WHERE partition_key = ? AND range_key < ? ORDER BY range_key DESC LIMIT ?`, s.mainTable()), partitionKey, beforeRangeKey, limit)
+	}
+	iter := query.WithContext(ctx).Iter()
+
+	results := make([]KeyValuePair, 0, limit)
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		kv := KeyValuePair{PartitionKey: partitionKey}
+		if err := scanner.Scan(&kv.RangeKey, &kv.Value); err != nil {
+			_ = iter.Close()
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+		results = append(results, kv)
+	}
+	if err := iter.Close(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// List iterates over all entries of the main table. The pagination token is the (base64 encoded)
+// paging state of the driver, so the order is the token order of partitions.
+func (s *ScyllaConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.List")
+	defer span.End()
+
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be greater than 0")
+	}
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	var pageState []byte
+	if paginationToken != "" {
+		pageState, err = base64.StdEncoding.DecodeString(paginationToken)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid pagination token: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.getTimeout)
+	defer cancel()
+
+	iter := session.Query(fmt.Sprintf(`SELECT partition_key, range_key, value FROM %s`, s.mainTable())).
+		WithContext(ctx).
+		PageSize(limit).
+		PageState(pageState).
+		Iter()
+
+	// Only consume the first page, reading further would make the driver fetch the next one
+	results := make([]KeyValuePair, 0, limit)
+	scanner := iter.Scanner()
+	for len(results) < iter.NumRows() && scanner.Next() {
+		var kv KeyValuePair
+		if err := scanner.Scan(&kv.PartitionKey, &kv.RangeKey, &kv.Value); err != nil {
+			_ = iter.Close()
+			common.SetTraceSpanError(span, err)
+			return nil, "", err
+		}
+		results = append(results, kv)
+	}
+	nextState := iter.PageState()
+	if err := iter.Close(); err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, "", err
+	}
+
+	nextToken := ""
+	if len(nextState) > 0 {
+		nextToken = base64.StdEncoding.EncodeToString(nextState)
+	}
+
+	return results, nextToken, nil
+}
+
+// Lock uses a lightweight transaction to insert a lock row only if it does not exist. The row
+// expires after "ttl" so that locks of crashed instances are eventually released.
+func (s *ScyllaConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.Lock",
+		trace.WithAttributes(
+			attribute.String("lock_key", key),
+			attribute.Int64("ttl_ms", ttl.Milliseconds()),
+		),
+	)
+	defer span.End()
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
+	lockKey := fmt.Sprintf("%s:lock", key)
+	owner := strconv.FormatInt(util.RandomID(), 16)
+
+	for {
+		attemptCtx, attemptCancel := context.WithTimeout(ctx, s.setTimeout)
+		applied, err := session.Query(
+			fmt.Sprintf(`INSERT INTO %s (partition_key, range_key, value) VALUES (?, 'lock', ?) IF NOT EXISTS USING TTL ?`, s.mainTable()),
+			lockKey, []byte(owner), scyllaTTLSeconds(&ttl),
+		).WithContext(attemptCtx).SerialConsistency(gocql.LocalSerial).MapScanCAS(map[string]interface{}{})
+		attemptCancel()
+
+		if err == nil && applied {
+			s.logger.Debug().Str("lockKey", lockKey).Dur("ttl", ttl).Msg("distributed lock acquired")
+			return &scyllaLock{
+				connector: s,
+				lockKey:   lockKey,
+				owner:     owner,
+			}, nil
+		}
+		if err != nil {
+			s.logger.Debug().Err(err).Str("lockKey", lockKey).Msg("failed to acquire lock, will retry")
+		} else {
+			s.logger.Debug().Str("lockKey", lockKey).Dur("retryInterval", s.lockRetryInterval).Msg("lock currently held, will retry")
+		}
+
+		select {
+		case <-time.After(s.lockRetryInterval):
+		case <-ctx.Done():
+			err := fmt.Errorf("lock acquisition cancelled or timed out for key '%s': %w", key, ctx.Err())
+			common.SetTraceSpanError(span, err)
+			return nil, err
+		}
+	}
+}
+
+func (l *scyllaLock) Unlock(ctx context.Context) error {
+	session, err := l.connector.getSession()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.connector.setTimeout)
+	defer cancel()
+
+	// Only release the lock if it is still held by this owner (it might have expired and been taken by someone else)
+	applied, err := session.Query(
+		fmt.Sprintf(`DELETE FROM %s WHERE partition_key = ? AND range_key = 'lock' IF value = ?`, l.connector.mainTable()),
+		l.lockKey, []byte(l.owner),
+	).WithContext(ctx).SerialConsistency(gocql.LocalSerial).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return fmt.Errorf("failed to release lock '%s': %w", l.lockKey, err)
+	}
+	if !applied {
+		l.connector.logger.Warn().Str("lockKey", l.lockKey).Msg("lock was not held by this owner anymore when releasing (probably expired)")
+	}
+
+	return nil
+}
+
+// WatchCounterInt64 polls the counter value as Scylla has no native pub/sub, similar to DynamoDB connector.
+func (s *ScyllaConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan int64, func(), error) {
+	if _, err := s.getSession(); err != nil {
+		return nil, nil, err
+	}
+
+	updates := make(chan int64, 1)
+	ticker := time.NewTicker(s.statePollInterval)
+	done := make(chan struct{})
+
+	var lastValue int64
+	if val, err := s.getCounterValue(ctx, key); err == nil {
+		lastValue = val
+		updates <- val
+	}
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				value, err := s.getCounterValue(ctx, key)
+				if err != nil {
+					s.logger.Warn().Err(err).Str("key", key).Msg("failed to poll counter value")
+					continue
+				}
+				if value > lastValue {
+					lastValue = value
+					select {
+					case updates <- value:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	cleanup := func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+
+	return updates, cleanup, nil
+}
+
+// PublishCounterInt64 stores the value only if it is greater than the current one, using a
+// lightweight transaction conditioned on the current value.
+func (s *ScyllaConnector) PublishCounterInt64(ctx context.Context, key string, value int64) error {
+	ctx, span := common.StartSpan(ctx, "ScyllaConnector.PublishCounterInt64",
+		trace.WithAttributes(
+			attribute.String("key", key),
+		),
+	)
+	defer span.End()
+
+	session, err := s.getSession()
+	if err != nil {
+		common.SetTraceSpanError(span, err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.setTimeout)
+	defer cancel()
+
+	newValue := []byte(strconv.FormatInt(value, 10))
+	for {
+		var current []byte
+		err := session.Query(
+			fmt.Sprintf(`SELECT value FROM %s WHERE partition_key = ? AND range_key = 'value'`, s.mainTable()),
+			key,
+		).WithContext(ctx).Scan(&current)
+
+		var applied bool
+		if errors.Is(err, gocql.ErrNotFound) {
+			applied, err = session.Query(
+				fmt.Sprintf(`INSERT INTO %s (partition_key, range_key, value) VALUES (?, 'value', ?) IF NOT EXISTS`, s.mainTable()),
+				key, newValue,
+			).WithContext(ctx).SerialConsistency(gocql.LocalSerial).MapScanCAS(map[string]interface{}{})
+		} else if err == nil {
+			currentValue, _ := strconv.ParseInt(string(current), 10, 64)
+			if currentValue >= value {
+				return nil
+			}
+			applied, err = session.Query(
+				fmt.Sprintf(`UPDATE %s SET value = ? WHERE partition_key = ? AND range_key = 'value' IF value = ?`, s.mainTable()),
+				newValue, key, current,
+			).WithContext(ctx).SerialConsistency(gocql.LocalSerial).MapScanCAS(map[string]interface{}{})
+		}
+		if err != nil {
+			common.SetTraceSpanError(span, err)
+			return err
+		}
+		if applied {
+			return nil
+		}
+		// Someone else updated the value concurrently, re-evaluate against the new value
+	}
+}
+
+func (s *ScyllaConnector) getCounterValue(ctx context.Context, key string) (int64, error) {
+	session, err := s.getSession()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.getTimeout)
+	defer cancel()
+
+	var value []byte
+	err = session.Query(
+		fmt.Sprintf(`SELECT value FROM %s WHERE partition_key = ? AND range_key = 'value'`, s.mainTable()),
+		key,
+	).WithContext(ctx).Scan(&value)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+func (s *ScyllaConnector) mainTable() string {
+	return s.keyspace + "." + s.table
+}
+
+func (s *ScyllaConnector) reverseTable() string {
+	return s.keyspace + "." + s.table + "_reverse"
+}
+
+// scyllaTTLSeconds converts a TTL to seconds as expected by "USING TTL" (0 means no expiry).
+func scyllaTTLSeconds(ttl *time.Duration) int {
+	if ttl == nil || *ttl <= 0 {
+		return 0
+	}
+	return int(min(math.Ceil(ttl.Seconds()), math.MaxInt32))
+}
@@ -0,0 +1,171 @@
+package data
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func startScyllaContainer(t *testing.T, ctx context.Context) string {
+	// Resolving the docker host panics (instead of failing the health check) when docker is not installed
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Skipf("docker is not available: %v", r)
+			}
+		}()
+		testcontainers.SkipIfProviderIsNotHealthy(t)
+	}()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "scylladb/scylla:6.2",
+		Cmd:          []string{"--smp", "1", "--memory", "512M", "--overprovisioned", "1", "--developer-mode", "1"},
+		ExposedPorts: []string{"9042/tcp"},
+		WaitingFor:   wait.ForListeningPort("9042/tcp").WithStartupTimeout(2 * time.Minute),
+	}
+	scyllaC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err, "failed to start scylla container")
+	t.Cleanup(func() {
+		_ = scyllaC.Terminate(context.Background())
+	})
+
+	host, err := scyllaC.Host(ctx)
+	require.NoError(t, err)
+	port, err := scyllaC.MappedPort(ctx, "9042")
+	require.NoError(t, err)
+
+	return net.JoinHostPort(host, port.Port())
+}
+
+func newScyllaTestConnector(t *testing.T, ctx context.Context, addr string) *ScyllaConnector {
+	logger := zerolog.New(io.Discard)
+	cfg := &common.ScyllaConnectorConfig{
+		Hosts:       []string{addr},
+		InitTimeout: common.Duration(30 * time.Second),
+	}
+	require.NoError(t, cfg.SetDefaults("cache"))
+	require.NoError(t, cfg.Validate())
+
+	connector, err := NewScyllaConnector(ctx, &logger, "scylla-test", cfg)
+	require.NoError(t, err)
+	// Scylla might still be bootstrapping after its port is open, so the connection is retried in the background
+	require.Eventually(t, func() bool {
+		return connector.initializer.State() == util.StateReady
+	}, 2*time.Minute, time.Second, "scylla connector must be ready")
+
+	return connector
+}
+
+func TestScyllaConnector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr := startScyllaContainer(t, ctx)
+	connector := newScyllaTestConnector(t, ctx, addr)
+
+	t.Run("SetGetDeleteOnMainIndex", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:100", "eth_getBlockByNumber:abc", []byte("value"), nil))
+
+		val, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+
+		val, err = connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:*", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+
+		require.NoError(t, connector.Delete(ctx, "evm:1:100", "eth_getBlockByNumber:abc"))
+		_, err = connector.Get(ctx, ConnectorMainIndex, "evm:1:100", "eth_getBlockByNumber:abc", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("GetsByReverseIndexWithWildcardPartitionKey", func(t *testing.T) {
+		require.NoError(t, connector.Set(ctx, "evm:1:200", "eth_getLogs:def", []byte("logs"), nil))
+
+		val, err := connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_getLogs:def", nil)
+		require.NoError(t, err)
+		require.Equal(t, "logs", string(val))
+
+		_, err = connector.Get(ctx, ConnectorReverseIndex, "evm:2:*", "eth_getLogs:def", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("ExpiresEntriesAfterTTL", func(t *testing.T) {
+		ttl := 1 * time.Second
+		require.NoError(t, connector.Set(ctx, "evm:1:300", "eth_call:ghi", []byte("short-lived"), &ttl))
+
+		val, err := connector.Get(ctx, ConnectorMainIndex, "evm:1:300", "eth_call:ghi", nil)
+		require.NoError(t, err)
+		require.Equal(t, "short-lived", string(val))
+
+		require.Eventually(t, func() bool {
+			_, err := connector.Get(ctx, ConnectorReverseIndex, "evm:1:*", "eth_call:ghi", nil)
+			return common.HasErrorCode(err, common.ErrCodeRecordNotFound)
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("ListsAllEntriesWithPagination", func(t *testing.T) {
+		for _, rk := range []string{"a", "b", "c", "d", "e"} {
+			require.NoError(t, connector.Set(ctx, "list-pk", rk, []byte(rk), nil))
+		}
+
+		seen := map[string]bool{}
+		token := ""
+		for {
+			items, next, err := connector.List(ctx, ConnectorMainIndex, 2, token)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(items), 2)
+			for _, item := range items {
+				if item.PartitionKey == "list-pk" {
+					seen[item.RangeKey] = true
+				}
+			}
+			if next == "" {
+				break
+			}
+			token = next
+		}
+		require.Len(t, seen, 5)
+	})
+
+	t.Run("LockIsExclusiveUntilUnlocked", func(t *testing.T) {
+		lock, err := connector.Lock(ctx, "lock-key", 10*time.Second)
+		require.NoError(t, err)
+
+		shortCtx, shortCancel := context.WithTimeout(ctx, 1*time.Second)
+		defer shortCancel()
+		_, err = connector.Lock(shortCtx, "lock-key", 10*time.Second)
+		require.Error(t, err, "second lock must not be acquired while the first is held")
+
+		require.NoError(t, lock.Unlock(ctx))
+		lock, err = connector.Lock(ctx, "lock-key", 10*time.Second)
+		require.NoError(t, err)
+		require.NoError(t, lock.Unlock(ctx))
+	})
+
+	t.Run("PublishesOnlyIncreasingCounterValues", func(t *testing.T) {
+		require.NoError(t, connector.PublishCounterInt64(ctx, "counter-key", 10))
+		require.NoError(t, connector.PublishCounterInt64(ctx, "counter-key", 5))
+
+		val, err := connector.getCounterValue(ctx, "counter-key")
+		require.NoError(t, err)
+		require.Equal(t, int64(10), val)
+
+		require.NoError(t, connector.PublishCounterInt64(ctx, "counter-key", 20))
+		val, err = connector.getCounterValue(ctx, "counter-key")
+		require.NoError(t, err)
+		require.Equal(t, int64(20), val)
+	})
+}
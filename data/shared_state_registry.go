@@ -17,6 +17,8 @@ type SharedStateRegistry interface {
 	GetCounterInt64(key string, ignoreRollbackOf int64) CounterInt64SharedVariable
 	GetLockTtl() time.Duration
 	GetFallbackTimeout() time.Duration
+	GetClusterKey() string
+	GetConnector() Connector
 }
 
 type sharedStateRegistry struct {
@@ -195,3 +197,11 @@ func (r *sharedStateRegistry) GetLockTtl() time.Duration {
 func (r *sharedStateRegistry) GetFallbackTimeout() time.Duration {
 	return r.fallbackTimeout
 }
+
+func (r *sharedStateRegistry) GetClusterKey() string {
+	return r.clusterKey
+}
+
+func (r *sharedStateRegistry) GetConnector() Connector {
+	return r.connector
+}
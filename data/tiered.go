@@ -0,0 +1,441 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	tieredL1 = "l1"
+	tieredL2 = "l2"
+
+	tieredInstanceTagBits = 16
+	tieredInstanceTagMask = 1<<tieredInstanceTagBits - 1
+
+	// Invalidation records only need to outlive their delivery to other instances
+	tieredInvalidationRecordTTL = 1 * time.Minute
+)
+
+// tieredInvalidation is stored in L2 for each invalidation message, as pub/sub only transports int64 values.
+type tieredInvalidation struct {
+	// Prev is the previous message of the same instance, so that a receiver can tell it missed a message
+	Prev       int64       `json:"prev"`
+	Partitions []string    `json:"partitions,omitempty"`
+	Keys       [][2]string `json:"keys,omitempty"`
+}
+
+var _ PartitionBatchDeleter = (*TieredConnector)(nil)
+
+// TieredConnector serves reads from an in-memory L1 and falls back to a remote L2, promoting L2 hits into L1.
+// Writes and deletes go through to both tiers, so L2 remains the source of truth shared by all instances.
+type TieredConnector struct {
+	id          string
+	logger      *zerolog.Logger
+	appCtx      context.Context
+	l1          *MemoryConnector
+	l2          Connector
+	l1TTL       time.Duration
+	initializer *util.Initializer
+
+	// Keys written to L1 by partition key along with their L1 expiry, so that a partition can be evicted
+	// without flushing the whole L1. Bounded by the max items of L1.
+	l1KeysMu    sync.Mutex
+	l1Keys      map[string]map[string]time.Time
+	l1KeysCount int
+	l1MaxKeys   int
+
+	// Invalidation messages are increasing (some connectors only accept counter values greater than
+	// the current one) and carry a random per-instance tag in the lower bits so that an instance can
+	// ignore its own messages.
+	instanceTag int64
+	lastInvMsg  atomic.Int64
+	// Last message received from each other instance by tag, only accessed by the watcher
+	lastRecvMsg map[int64]int64
+}
+
+func NewTieredConnector(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	id string,
+	cfg *common.TieredConnectorConfig,
+) (*TieredConnector, error) {
+	lg := logger.With().Str("connector", id).Logger()
+	lg.Debug().Interface("config", cfg).Msg("creating tiered connector")
+
+	if cfg.L2 == nil {
+		return nil, fmt.Errorf("l2 connector config is required for tiered connector")
+	}
+
+	l1, err := NewMemoryConnector(ctx, logger, id+"-"+tieredL1, cfg.L1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create l1 connector: %w", err)
+	}
+	l2, err := NewConnector(ctx, logger, cfg.L2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create l2 connector: %w", err)
+	}
+
+	c := &TieredConnector{
+		id:          id,
+		logger:      &lg,
+		appCtx:      ctx,
+		l1:          l1,
+		l2:          l2,
+		l1TTL:       cfg.L1TTL.Duration(),
+		l1Keys:      make(map[string]map[string]time.Time),
+		l1MaxKeys:   cfg.L1.MaxItems,
+		instanceTag: rand.Int63n(tieredInstanceTagMask) + 1, // #nosec G404
+		lastRecvMsg: make(map[int64]int64),
+	}
+
+	if cfg.Invalidation != nil && *cfg.Invalidation {
+		// L2 might not be connected yet, so the watcher is set up (and re-established) in the background
+		c.initializer = util.NewInitializer(ctx, &lg, nil)
+		task := util.NewBootstrapTask(c.invalidationTaskName(), c.watchInvalidations)
+		if err := c.initializer.ExecuteTasks(ctx, task); err != nil {
+			lg.Warn().Err(err).Msg("failed to watch l1 invalidations on first attempt (will keep retrying in the background)")
+		}
+	}
+
+	return c, nil
+}
+
+func (t *TieredConnector) Id() string {
+	return t.id
+}
+
+func (t *TieredConnector) Get(ctx context.Context, index, partitionKey, rangeKey string, metadata interface{}) ([]byte, error) {
+	value, err := t.l1.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err == nil {
+		telemetry.MetricTieredConnectorGetHitTotal.WithLabelValues(t.id, tieredL1).Inc()
+		return value, nil
+	}
+	telemetry.MetricTieredConnectorGetMissTotal.WithLabelValues(t.id, tieredL1).Inc()
+
+	value, err = t.l2.Get(ctx, index, partitionKey, rangeKey, metadata)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			telemetry.MetricTieredConnectorGetMissTotal.WithLabelValues(t.id, tieredL2).Inc()
+		}
+		return nil, err
+	}
+	telemetry.MetricTieredConnectorGetHitTotal.WithLabelValues(t.id, tieredL2).Inc()
+
+	// Promote into L1 so that next reads are served locally. Reverse index reads are looked up by a wildcard
+	// partition key while the concrete one is not known, so they are not promoted.
+	if index != ConnectorReverseIndex && !strings.HasSuffix(partitionKey, "*") {
+		_ = t.setL1(ctx, partitionKey, rangeKey, value, nil)
+	}
+
+	return value, nil
+}
+
+func (t *TieredConnector) Set(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	if err := t.l2.Set(ctx, partitionKey, rangeKey, value, ttl); err != nil {
+		return err
+	}
+	// Stored for longer than the request lifecycle so the value must be copied (see Connector.Set)
+	return t.setL1(ctx, partitionKey, rangeKey, append([]byte(nil), value...), ttl)
+}
+
+// SetIndexed writes through to L2 with SetIndexed when it keeps partition indexes, so that DeletePartition
+// finds the entry there.
+func (t *TieredConnector) SetIndexed(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	l2, ok := t.l2.(PartitionIndexer)
+	if !ok {
+		return t.Set(ctx, partitionKey, rangeKey, value, ttl)
+	}
+	if err := l2.SetIndexed(ctx, partitionKey, rangeKey, value, ttl); err != nil {
+		return err
+	}
+	return t.setL1(ctx, partitionKey, rangeKey, append([]byte(nil), value...), ttl)
+}
+
+// WaitForWrites waits for L1 (and L2 when it applies writes asynchronously too), so that a stale L1 entry is not read
+// right after a new value is written.
+func (t *TieredConnector) WaitForWrites() {
+	t.l1.WaitForWrites()
+	if w, ok := t.l2.(WriteWaiter); ok {
+		w.WaitForWrites()
+	}
+}
+
+func (t *TieredConnector) Delete(ctx context.Context, partitionKey, rangeKey string) error {
+	t.deleteL1(ctx, partitionKey, rangeKey)
+	if err := t.l2.Delete(ctx, partitionKey, rangeKey); err != nil {
+		return err
+	}
+	t.publishInvalidation(ctx, &tieredInvalidation{Keys: [][2]string{{partitionKey, rangeKey}}})
+	return nil
+}
+
+// DeletePartition deletes the partition from L2 and evicts the L1 entries written for it.
+func (t *TieredConnector) DeletePartition(ctx context.Context, partitionKey string) error {
+	return t.DeletePartitions(ctx, []string{partitionKey})
+}
+
+// DeletePartitions deletes the partitions from L2 and evicts their L1 entries, other instances are notified
+// with a single invalidation message for all of them.
+func (t *TieredConnector) DeletePartitions(ctx context.Context, partitionKeys []string) error {
+	l2, ok := t.l2.(PartitionDeleter)
+	if !ok {
+		return fmt.Errorf("l2 connector %s does not support deleting partitions", t.l2.Id())
+	}
+	var errs []error
+	deleted := make([]string, 0, len(partitionKeys))
+	for _, pk := range partitionKeys {
+		if err := l2.DeletePartition(ctx, pk); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted = append(deleted, pk)
+	}
+	// Evicted after L2 so that concurrent reads do not promote the deleted entries again
+	for _, pk := range partitionKeys {
+		t.evictL1Partition(ctx, pk)
+	}
+	if len(deleted) > 0 {
+		t.publishInvalidation(ctx, &tieredInvalidation{Partitions: deleted})
+	}
+	return errors.Join(errs...)
+}
+
+// ListPartition is served by L2 only, as L1 cannot enumerate the entries of a partition.
+func (t *TieredConnector) ListPartition(ctx context.Context, partitionKey, beforeRangeKey string, limit int) ([]KeyValuePair, error) {
+	l2, ok := t.l2.(PartitionLister)
+	if !ok {
+		return nil, fmt.Errorf("l2 connector %s does not support listing partitions", t.l2.Id())
+	}
+	return l2.ListPartition(ctx, partitionKey, beforeRangeKey, limit)
+}
+
+func (t *TieredConnector) List(ctx context.Context, index string, limit int, paginationToken string) ([]KeyValuePair, string, error) {
+	return t.l2.List(ctx, index, limit, paginationToken)
+}
+
+func (t *TieredConnector) Lock(ctx context.Context, key string, ttl time.Duration) (DistributedLock, error) {
+	return t.l2.Lock(ctx, key, ttl)
+}
+
+func (t *TieredConnector) WatchCounterInt64(ctx context.Context, key string) (<-chan int64, func(), error) {
+	return t.l2.WatchCounterInt64(ctx, key)
+}
+
+func (t *TieredConnector) PublishCounterInt64(ctx context.Context, key string, value int64) error {
+	return t.l2.PublishCounterInt64(ctx, key, value)
+}
+
+// l1TTLFor bounds the TTL of L1 entries so that changes made by other instances are eventually picked up.
+func (t *TieredConnector) l1TTLFor(ttl *time.Duration) *time.Duration {
+	l1TTL := t.l1TTL
+	if ttl != nil && *ttl > 0 && (l1TTL <= 0 || *ttl < l1TTL) {
+		l1TTL = *ttl
+	}
+	return &l1TTL
+}
+
+// setL1 writes an entry to L1 and tracks its key by partition.
+func (t *TieredConnector) setL1(ctx context.Context, partitionKey, rangeKey string, value []byte, ttl *time.Duration) error {
+	l1TTL := t.l1TTLFor(ttl)
+	if err := t.l1.Set(ctx, partitionKey, rangeKey, value, l1TTL); err != nil {
+		return err
+	}
+
+	var expiry time.Time
+	if *l1TTL > 0 {
+		expiry = time.Now().Add(*l1TTL)
+	}
+
+	t.l1KeysMu.Lock()
+	defer t.l1KeysMu.Unlock()
+	if t.l1KeysCount >= t.l1MaxKeys {
+		t.pruneL1KeysLocked()
+		if t.l1KeysCount >= t.l1MaxKeys {
+			// Every L1 entry must be tracked to be evictable, so L1 is flushed along with the keys
+			t.l1.Clear()
+			t.l1Keys = make(map[string]map[string]time.Time)
+			t.l1KeysCount = 0
+		}
+	}
+	keys, ok := t.l1Keys[partitionKey]
+	if !ok {
+		keys = make(map[string]time.Time)
+		t.l1Keys[partitionKey] = keys
+	}
+	if _, ok := keys[rangeKey]; !ok {
+		t.l1KeysCount++
+	}
+	keys[rangeKey] = expiry
+	return nil
+}
+
+// pruneL1KeysLocked forgets the keys whose L1 entries have expired.
+func (t *TieredConnector) pruneL1KeysLocked() {
+	now := time.Now()
+	for pk, keys := range t.l1Keys {
+		for rk, expiry := range keys {
+			if !expiry.IsZero() && expiry.Before(now) {
+				delete(keys, rk)
+				t.l1KeysCount--
+			}
+		}
+		if len(keys) == 0 {
+			delete(t.l1Keys, pk)
+		}
+	}
+}
+
+func (t *TieredConnector) deleteL1(ctx context.Context, partitionKey, rangeKey string) {
+	t.l1KeysMu.Lock()
+	if keys, ok := t.l1Keys[partitionKey]; ok {
+		if _, ok := keys[rangeKey]; ok {
+			delete(keys, rangeKey)
+			t.l1KeysCount--
+		}
+		if len(keys) == 0 {
+			delete(t.l1Keys, partitionKey)
+		}
+	}
+	t.l1KeysMu.Unlock()
+	_ = t.l1.Delete(ctx, partitionKey, rangeKey)
+}
+
+func (t *TieredConnector) evictL1Partition(ctx context.Context, partitionKey string) {
+	t.l1KeysMu.Lock()
+	keys := t.l1Keys[partitionKey]
+	delete(t.l1Keys, partitionKey)
+	t.l1KeysCount -= len(keys)
+	t.l1KeysMu.Unlock()
+	for rk := range keys {
+		_ = t.l1.Delete(ctx, partitionKey, rk)
+	}
+}
+
+func (t *TieredConnector) clearL1() {
+	t.l1KeysMu.Lock()
+	defer t.l1KeysMu.Unlock()
+	t.l1.Clear()
+	t.l1Keys = make(map[string]map[string]time.Time)
+	t.l1KeysCount = 0
+}
+
+// publishInvalidation stores the invalidated keys in L2 and notifies other instances, when invalidation is enabled.
+func (t *TieredConnector) publishInvalidation(ctx context.Context, inv *tieredInvalidation) {
+	if t.initializer == nil {
+		return
+	}
+	msg, prev := t.nextInvalidationMessage()
+	inv.Prev = prev
+	value, err := common.SonicCfg.Marshal(inv)
+	if err == nil {
+		ttl := tieredInvalidationRecordTTL
+		err = t.l2.Set(ctx, t.invalidationKey(), strconv.FormatInt(msg, 10), value, &ttl)
+	}
+	if err != nil {
+		// Receivers flush their whole L1 when the record is missing
+		t.logger.Warn().Err(err).Msg("failed to store l1 invalidation record")
+	}
+	if err := t.l2.PublishCounterInt64(ctx, t.invalidationKey(), msg); err != nil {
+		t.logger.Warn().Err(err).Strs("partitionKeys", inv.Partitions).Int("keys", len(inv.Keys)).Msg("failed to publish l1 invalidation")
+	}
+}
+
+// nextInvalidationMessage returns a new message along with the previous message of this instance.
+func (t *TieredConnector) nextInvalidationMessage() (msg, prev int64) {
+	for {
+		last := t.lastInvMsg.Load()
+		msg := time.Now().UnixMilli()<<tieredInstanceTagBits | t.instanceTag
+		if msg <= last {
+			msg = (last>>tieredInstanceTagBits+1)<<tieredInstanceTagBits | t.instanceTag
+		}
+		if t.lastInvMsg.CompareAndSwap(last, msg) {
+			return msg, last
+		}
+	}
+}
+
+// applyInvalidation evicts the L1 entries invalidated by another instance. The whole L1 is flushed when the
+// record cannot be read or a previous message of the same instance was missed.
+func (t *TieredConnector) applyInvalidation(msg int64) {
+	ctx, cancel := context.WithTimeout(t.appCtx, 5*time.Second)
+	defer cancel()
+
+	tag := msg & tieredInstanceTagMask
+	lastRecv, known := t.lastRecvMsg[tag]
+	t.lastRecvMsg[tag] = msg
+
+	var inv tieredInvalidation
+	raw, err := t.l2.Get(ctx, ConnectorMainIndex, t.invalidationKey(), strconv.FormatInt(msg, 10), nil)
+	if err == nil {
+		err = common.SonicCfg.Unmarshal(raw, &inv)
+	}
+	if err == nil && known && inv.Prev != lastRecv {
+		err = fmt.Errorf("missed invalidation messages between %d and %d", lastRecv, inv.Prev)
+	}
+	if err != nil {
+		t.logger.Debug().Err(err).Int64("message", msg).Msg("flushing l1 due to invalidation from another instance")
+		t.clearL1()
+		return
+	}
+
+	t.logger.Debug().Int64("message", msg).Strs("partitionKeys", inv.Partitions).Int("keys", len(inv.Keys)).Msg("evicting l1 entries due to invalidation from another instance")
+	for _, pk := range inv.Partitions {
+		t.evictL1Partition(ctx, pk)
+	}
+	for _, key := range inv.Keys {
+		t.deleteL1(ctx, key[0], key[1])
+	}
+}
+
+func (t *TieredConnector) invalidationKey() string {
+	return fmt.Sprintf("tiered/%s/invalidations", t.id)
+}
+
+func (t *TieredConnector) invalidationTaskName() string {
+	return fmt.Sprintf("tiered-invalidation/%s", t.id)
+}
+
+// watchInvalidations evicts L1 entries whenever another instance deletes them. Messages only carry an int64
+// (the invalidated keys are read from L2) and might be coalesced, which is detected as a missed message.
+func (t *TieredConnector) watchInvalidations(ctx context.Context) error {
+	updates, cleanup, err := t.l2.WatchCounterInt64(t.appCtx, t.invalidationKey())
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if cleanup != nil {
+			defer cleanup()
+		}
+		for {
+			select {
+			case <-t.appCtx.Done():
+				return
+			case msg, ok := <-updates:
+				if !ok {
+					t.initializer.MarkTaskAsFailed(t.invalidationTaskName(), errors.New("tiered connector invalidation channel closed unexpectedly"))
+					return
+				}
+				if msg == 0 || msg&tieredInstanceTagMask == t.instanceTag {
+					continue
+				}
+				t.applyInvalidation(msg)
+				telemetry.MetricTieredConnectorL1InvalidationsTotal.WithLabelValues(t.id).Inc()
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newTieredTestConnector(t *testing.T, ctx context.Context, redisAddr string, invalidation bool) *TieredConnector {
+	logger := zerolog.New(io.Discard)
+	cfg := &common.ConnectorConfig{
+		Id:     "tiered-test",
+		Driver: common.DriverTiered,
+		Tiered: &common.TieredConnectorConfig{
+			L1TTL: common.Duration(1 * time.Minute),
+			L2: &common.ConnectorConfig{
+				Driver: common.DriverRedis,
+				Redis: &common.RedisConnectorConfig{
+					Addr:        redisAddr,
+					InitTimeout: common.Duration(2 * time.Second),
+					GetTimeout:  common.Duration(2 * time.Second),
+					SetTimeout:  common.Duration(2 * time.Second),
+				},
+			},
+			Invalidation: util.BoolPtr(invalidation),
+		},
+	}
+	require.NoError(t, cfg.SetDefaults("cache"))
+	require.NoError(t, cfg.Validate())
+
+	connector, err := NewConnector(ctx, &logger, cfg)
+	require.NoError(t, err)
+	return connector.(*TieredConnector)
+}
+
+func TestTieredConnector(t *testing.T) {
+	t.Run("WritesThroughAndPromotesL2HitsIntoL1", func(t *testing.T) {
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		writer := newTieredTestConnector(t, ctx, m.Addr(), false)
+		reader := newTieredTestConnector(t, ctx, m.Addr(), false)
+
+		require.NoError(t, writer.Set(ctx, "evm:1:100", "rk", []byte("value"), nil))
+		require.Eventually(t, func() bool {
+			val, err := writer.l1.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			return err == nil && string(val) == "value"
+		}, time.Second, 10*time.Millisecond, "value must be written to writer's l1")
+		val, err := writer.l2.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+
+		// Reader has nothing in l1, so it is served from l2 and the value is promoted
+		_, err = reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+		val, err = reader.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+		require.Eventually(t, func() bool {
+			val, err := reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			return err == nil && string(val) == "value"
+		}, time.Second, 10*time.Millisecond, "l2 hit must be promoted into reader's l1")
+
+		// Served from l1 even if l2 loses the value
+		m.FlushAll()
+		val, err = reader.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+	})
+
+	t.Run("DoesNotPromoteReverseIndexHitsUnderWildcardKey", func(t *testing.T) {
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		writer := newTieredTestConnector(t, ctx, m.Addr(), false)
+		reader := newTieredTestConnector(t, ctx, m.Addr(), false)
+
+		require.NoError(t, writer.Set(ctx, "evm:1:100", "rk", []byte("value"), nil))
+		val, err := reader.Get(ctx, ConnectorReverseIndex, "evm:1:*", "rk", nil)
+		require.NoError(t, err)
+		require.Equal(t, "value", string(val))
+
+		time.Sleep(50 * time.Millisecond)
+		_, err = reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:*", "rk", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound), "reverse index hit must not be stored under the wildcard key")
+		_, err = reader.l1.Get(ctx, ConnectorReverseIndex, "evm:1:*", "rk", nil)
+		require.True(t, common.HasErrorCode(err, common.ErrCodeRecordNotFound))
+	})
+
+	t.Run("EvictsL1OfOtherInstancesOnDelete", func(t *testing.T) {
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		writer := newTieredTestConnector(t, ctx, m.Addr(), true)
+		reader := newTieredTestConnector(t, ctx, m.Addr(), true)
+		require.NoError(t, writer.initializer.WaitForTasks(ctx))
+		require.NoError(t, reader.initializer.WaitForTasks(ctx))
+
+		require.NoError(t, writer.Set(ctx, "evm:1:100", "rk", []byte("value"), nil))
+		require.Eventually(t, func() bool {
+			_, err := reader.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			if err != nil {
+				return false
+			}
+			_, err = reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, writer.Delete(ctx, "evm:1:100", "rk"))
+		require.Eventually(t, func() bool {
+			_, err := reader.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			return common.HasErrorCode(err, common.ErrCodeRecordNotFound)
+		}, 2*time.Second, 10*time.Millisecond, "reader's l1 must be evicted after delete on another instance")
+	})
+
+	t.Run("EvictsOnlyDeletedPartitionsFromL1OfOtherInstances", func(t *testing.T) {
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		writer := newTieredTestConnector(t, ctx, m.Addr(), true)
+		reader := newTieredTestConnector(t, ctx, m.Addr(), true)
+		require.NoError(t, writer.initializer.WaitForTasks(ctx))
+		require.NoError(t, reader.initializer.WaitForTasks(ctx))
+
+		for _, pk := range []string{"evm:1:100", "evm:1:101", "evm:1:102"} {
+			require.NoError(t, writer.SetIndexed(ctx, pk, "rk", []byte("value"), nil))
+			_, err := reader.Get(ctx, ConnectorMainIndex, pk, "rk", nil)
+			require.NoError(t, err)
+		}
+		reader.l1.WaitForWrites()
+
+		require.NoError(t, writer.DeletePartitions(ctx, []string{"evm:1:100", "evm:1:101"}))
+		require.Eventually(t, func() bool {
+			_, err1 := reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+			_, err2 := reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:101", "rk", nil)
+			return err1 != nil && err2 != nil
+		}, 2*time.Second, 10*time.Millisecond, "reader's l1 must be evicted for deleted partitions")
+		_, err = reader.l1.Get(ctx, ConnectorMainIndex, "evm:1:102", "rk", nil)
+		require.NoError(t, err, "other partitions must be kept in reader's l1")
+
+		records := 0
+		for _, key := range m.Keys() {
+			if strings.HasPrefix(key, writer.invalidationKey()+":") {
+				records++
+			}
+		}
+		require.Equal(t, 1, records, "a single invalidation must be published per batch")
+	})
+
+	t.Run("FlushesL1WhenInvalidationRecordIsMissing", func(t *testing.T) {
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		connector := newTieredTestConnector(t, ctx, m.Addr(), true)
+		require.NoError(t, connector.Set(ctx, "evm:1:100", "rk", []byte("value"), nil))
+		connector.l1.WaitForWrites()
+
+		connector.applyInvalidation(1<<tieredInstanceTagBits | (connector.instanceTag+1)&tieredInstanceTagMask)
+		_, err = connector.l1.Get(ctx, ConnectorMainIndex, "evm:1:100", "rk", nil)
+		require.Error(t, err)
+	})
+}
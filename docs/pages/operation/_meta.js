@@ -8,6 +8,12 @@ module.exports = {
 	"batch": {
 		title: "Batching",
 	},
+	"websocket": {
+		title: "WebSocket",
+	},
+	"events": {
+		title: "Block head events",
+	},
 	"directives": {
 		title: "Directives",
 	},
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/erpc/erpc/auth"
@@ -13,12 +15,16 @@ import (
 
 // API Key structure for management
 type ApiKey struct {
-	Key                string    `json:"key"`
-	UserId             string    `json:"userId"`
-	PerSecondRateLimit *int64    `json:"perSecondRateLimit,omitempty"`
-	Enabled            bool      `json:"enabled"`
-	CreatedAt          time.Time `json:"createdAt"`
-	UpdatedAt          time.Time `json:"updatedAt"`
+	// Key is the id of the key in the database, which is a hash when keyHashing is enabled
+	Key                string            `json:"key"`
+	KeyPrefix          string            `json:"keyPrefix,omitempty"`
+	UserId             string            `json:"userId"`
+	PerSecondRateLimit *int64            `json:"perSecondRateLimit,omitempty"`
+	Quota              *common.UserQuota `json:"quota,omitempty"`
+	Enabled            bool              `json:"enabled"`
+	CreatedAt          time.Time         `json:"createdAt"`
+	UpdatedAt          time.Time         `json:"updatedAt"`
+	auth.ApiKeyScope
 }
 
 func (e *ERPC) AdminAuthenticate(ctx context.Context, method string, ap *auth.AuthPayload) (*common.User, error) {
@@ -49,6 +55,16 @@ func (e *ERPC) AdminHandleRequest(ctx context.Context, nq *common.NormalizedRequ
 		return e.handleUpdateApiKey(ctx, nq)
 	case "erpc_deleteApiKey":
 		return e.handleDeleteApiKey(ctx, nq)
+	case "erpc_getUsage":
+		return e.handleGetUsage(ctx, nq)
+	case "erpc_resetUsage":
+		return e.handleResetUsage(ctx, nq)
+	case "erpc_migrateApiKeys":
+		return e.handleMigrateApiKeys(ctx, nq)
+	case "erpc_listDisputes":
+		return e.handleListDisputes(ctx, nq)
+	case "erpc_getDispute":
+		return e.handleGetDispute(ctx, nq)
 
 	default:
 		return nil, common.NewErrEndpointUnsupported(
@@ -78,6 +94,150 @@ func (e *ERPC) findDatabaseConnectorById(projectId, connectorId string) (data.Co
 	return preparedProject.consumerAuthRegistry.FindDatabaseConnector(connectorId)
 }
 
+// findDatabaseStrategyById finds a database auth strategy by its connector ID within a project
+func (e *ERPC) findDatabaseStrategyById(projectId, connectorId string) (*auth.DatabaseStrategy, error) {
+	if e.projectsRegistry == nil {
+		return nil, fmt.Errorf("projects registry not configured")
+	}
+
+	preparedProject := e.projectsRegistry.preparedProjects[projectId]
+	if preparedProject == nil {
+		return nil, fmt.Errorf("project '%s' not found", projectId)
+	}
+	if preparedProject.consumerAuthRegistry == nil {
+		return nil, fmt.Errorf("project '%s' has no auth registry", projectId)
+	}
+
+	return preparedProject.consumerAuthRegistry.FindDatabaseStrategy(connectorId)
+}
+
+func (e *ERPC) findDisputeStore(projectId string) (*DisputeStore, error) {
+	if e.projectsRegistry == nil {
+		return nil, fmt.Errorf("projects registry not configured")
+	}
+
+	preparedProject := e.projectsRegistry.preparedProjects[projectId]
+	if preparedProject == nil {
+		return nil, fmt.Errorf("project '%s' not found", projectId)
+	}
+	if preparedProject.disputeStore == nil {
+		return nil, fmt.Errorf("project '%s' has no disputes config", projectId)
+	}
+
+	return preparedProject.disputeStore, nil
+}
+
+// parseUserQuota parses the optional quota of an API key from admin request params
+func parseUserQuota(params map[string]interface{}) (*common.UserQuota, error) {
+	raw, exists := params["quota"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("quota must be an object")
+	}
+
+	quota := &common.UserQuota{}
+	targets := map[string]*int64{
+		"dailyRequests":       &quota.DailyRequests,
+		"monthlyRequests":     &quota.MonthlyRequests,
+		"dailyComputeUnits":   &quota.DailyComputeUnits,
+		"monthlyComputeUnits": &quota.MonthlyComputeUnits,
+	}
+	for name, value := range fields {
+		target, ok := targets[name]
+		if !ok {
+			return nil, fmt.Errorf("quota.%s is not supported", name)
+		}
+		number, ok := value.(float64)
+		if !ok || number < 0 {
+			return nil, fmt.Errorf("quota.%s must be a non-negative number", name)
+		}
+		*target = int64(number)
+	}
+
+	return quota, nil
+}
+
+// parseApiKeyScope parses the optional scope of an API key from admin request params
+func parseApiKeyScope(params map[string]interface{}) (*auth.ApiKeyScope, error) {
+	scope := &auth.ApiKeyScope{}
+	lists := map[string]*[]string{
+		"allowedNetworks": &scope.AllowedNetworks,
+		"allowedMethods":  &scope.AllowedMethods,
+		"allowedOrigins":  &scope.AllowedOrigins,
+		"allowedCIDRs":    &scope.AllowedCIDRs,
+	}
+	for name, target := range lists {
+		raw, exists := params[name]
+		if !exists || raw == nil {
+			continue
+		}
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array", name)
+		}
+		for _, item := range items {
+			switch v := item.(type) {
+			case string:
+				*target = append(*target, v)
+			case float64:
+				// Chain ids are commonly provided as numbers
+				if name != "allowedNetworks" {
+					return nil, fmt.Errorf("%s must only contain strings", name)
+				}
+				*target = append(*target, strconv.FormatInt(int64(v), 10))
+			default:
+				return nil, fmt.Errorf("%s must only contain strings", name)
+			}
+		}
+	}
+
+	if raw, exists := params["expiresAt"]; exists && raw != nil {
+		switch v := raw.(type) {
+		case string:
+			expiresAt, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("expiresAt must be an RFC3339 timestamp or unix seconds: %w", err)
+			}
+			scope.ExpiresAt = &expiresAt
+		case float64:
+			expiresAt := time.Unix(int64(v), 0).UTC()
+			scope.ExpiresAt = &expiresAt
+		default:
+			return nil, fmt.Errorf("expiresAt must be an RFC3339 timestamp or unix seconds")
+		}
+	}
+
+	if err := scope.Normalize(); err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+// applyApiKeyScope stores the (normalized) scope into the API key data, removing cleared restrictions
+func applyApiKeyScope(userData map[string]interface{}, scope *auth.ApiKeyScope) {
+	lists := map[string][]string{
+		"allowedNetworks": scope.AllowedNetworks,
+		"allowedMethods":  scope.AllowedMethods,
+		"allowedOrigins":  scope.AllowedOrigins,
+		"allowedCIDRs":    scope.AllowedCIDRs,
+	}
+	for name, values := range lists {
+		if len(values) > 0 {
+			userData[name] = values
+		} else {
+			delete(userData, name)
+		}
+	}
+	if scope.ExpiresAt != nil {
+		userData["expiresAt"] = scope.ExpiresAt.UTC().Format(time.RFC3339)
+	} else {
+		delete(userData, "expiresAt")
+	}
+}
+
 // handleAddApiKey adds a new API key
 func (e *ERPC) handleAddApiKey(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
 	jrr, err := nq.JsonRpcRequest()
@@ -86,7 +246,7 @@ func (e *ERPC) handleAddApiKey(ctx context.Context, nq *common.NormalizedRequest
 	}
 
 	if len(jrr.Params) < 1 {
-		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, connectorId, apiKey, userId, perSecondRateLimit?}"))
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, connectorId, apiKey?, userId, perSecondRateLimit?, quota?, enabled?, allowedNetworks?, allowedMethods?, allowedOrigins?, allowedCIDRs?, expiresAt?}"))
 	}
 
 	params, ok := jrr.Params[0].(map[string]interface{})
@@ -104,9 +264,20 @@ func (e *ERPC) handleAddApiKey(ctx context.Context, nq *common.NormalizedRequest
 		return nil, common.NewErrInvalidRequest(fmt.Errorf("connectorId is required and must be a string"))
 	}
 
-	apiKey, ok := params["apiKey"].(string)
-	if !ok || apiKey == "" {
-		return nil, common.NewErrInvalidRequest(fmt.Errorf("apiKey is required and must be a string"))
+	// A random key is generated when none is provided
+	apiKey := ""
+	if apiKeyVal, exists := params["apiKey"]; exists && apiKeyVal != nil {
+		if apiKey, ok = apiKeyVal.(string); !ok {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("apiKey must be a string"))
+		}
+	}
+	if apiKey == "" {
+		if apiKey, err = auth.GenerateApiKey(); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(apiKey, auth.ApiKeyHashPrefix) {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("apiKey must not start with %s", auth.ApiKeyHashPrefix))
 	}
 
 	userId, ok := params["userId"].(string)
@@ -124,33 +295,60 @@ func (e *ERPC) handleAddApiKey(ctx context.Context, nq *common.NormalizedRequest
 		}
 	}
 
-	connector, err := e.findDatabaseConnectorById(projectId, connectorId)
+	quota, err := parseUserQuota(params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find connector: %w", err)
+		return nil, common.NewErrInvalidRequest(err)
+	}
+
+	scope, err := parseApiKeyScope(params)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(err)
 	}
 
+	enabled := true // New API keys are enabled by default
+	if enabledVal, exists := params["enabled"]; exists && enabledVal != nil {
+		if enabled, ok = enabledVal.(bool); !ok {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("enabled must be a boolean"))
+		}
+	}
+
+	strategy, err := e.findDatabaseStrategyById(projectId, connectorId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find database strategy: %w", err)
+	}
+	keyId := strategy.KeyId(apiKey)
+
 	// Create user data
 	userData := map[string]interface{}{
 		"userId":  userId,
-		"enabled": true, // New API keys are enabled by default
+		"enabled": enabled,
 	}
 	if perSecondRateLimit != nil {
 		userData["perSecondRateLimit"] = *perSecondRateLimit
 	}
+	if !quota.IsEmpty() {
+		userData["quota"] = quota
+	}
+	applyApiKeyScope(userData, scope)
+	if strategy.HashesKeys() {
+		userData["keyPrefix"] = auth.ApiKeyDisplayPrefix(apiKey)
+	}
 
 	userDataBytes, err := json.Marshal(userData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal user data: %w", err)
 	}
 
-	err = connector.Set(ctx, apiKey, userId, userDataBytes, nil)
+	err = strategy.GetConnector().Set(ctx, keyId, userId, userDataBytes, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store API key: %w", err)
 	}
 
+	// This is the only time the plaintext key is returned, when hashing is enabled it cannot be recovered
 	result := map[string]interface{}{
 		"success": true,
 		"apiKey":  apiKey,
+		"keyId":   keyId,
 		"userId":  userId,
 	}
 
@@ -244,6 +442,19 @@ func (e *ERPC) handleListApiKeys(ctx context.Context, nq *common.NormalizedReque
 			}
 		}
 
+		if _, ok := userData["quota"]; ok {
+			if quota, err := parseUserQuota(userData); err == nil && !quota.IsEmpty() {
+				apiKey.Quota = quota
+			}
+		}
+
+		if scope, err := parseApiKeyScope(userData); err == nil {
+			apiKey.ApiKeyScope = *scope
+		}
+		if keyPrefix, ok := userData["keyPrefix"].(string); ok {
+			apiKey.KeyPrefix = keyPrefix
+		}
+
 		apiKeys = append(apiKeys, apiKey)
 	}
 
@@ -298,12 +509,15 @@ func (e *ERPC) handleUpdateApiKey(ctx context.Context, nq *common.NormalizedRequ
 		return nil, common.NewErrInvalidRequest(fmt.Errorf("updates is required and must be an object"))
 	}
 
-	connector, err := e.findDatabaseConnectorById(projectId, connectorId)
+	strategy, err := e.findDatabaseStrategyById(projectId, connectorId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find connector: %w", err)
+		return nil, fmt.Errorf("failed to find database strategy: %w", err)
 	}
+	connector := strategy.GetConnector()
+	// The API key might be provided as its raw value or its id (e.g. hashed key from erpc_listApiKeys)
+	keyId := strategy.KeyId(apiKey)
 
-	currentBytes, err := connector.Get(ctx, data.ConnectorMainIndex, apiKey, "*", nil)
+	currentBytes, err := connector.Get(ctx, data.ConnectorMainIndex, keyId, "*", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current API key data: %w", err)
 	}
@@ -319,22 +533,49 @@ func (e *ERPC) handleUpdateApiKey(ctx context.Context, nq *common.NormalizedRequ
 		return nil, fmt.Errorf("missing or invalid userId in current data")
 	}
 
+	if enabledVal, exists := updates["enabled"]; exists {
+		if _, ok := enabledVal.(bool); !ok {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("updates.enabled must be a boolean"))
+		}
+	}
+
 	// Apply updates
 	for key, value := range updates {
 		currentData[key] = value
 	}
 
+	// Validate and normalize the resulting scope, a null value clears a restriction
+	scope, err := parseApiKeyScope(currentData)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("invalid updates: %w", err))
+	}
+	applyApiKeyScope(currentData, scope)
+
+	// Same for the quota, so that a misspelled field is rejected instead of silently ignored
+	quota, err := parseUserQuota(currentData)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("invalid updates: %w", err))
+	}
+	if quota.IsEmpty() {
+		delete(currentData, "quota")
+	} else {
+		currentData["quota"] = quota
+	}
+
 	// Save updated data to the same location
 	updatedBytes, err := json.Marshal(currentData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal updated data: %w", err)
 	}
 
-	err = connector.Set(ctx, apiKey, userId, updatedBytes, nil)
+	err = connector.Set(ctx, keyId, userId, updatedBytes, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update API key: %w", err)
 	}
 
+	// Make sure the new scope (e.g. disabled or restricted key) applies right away on this instance
+	strategy.InvalidateCache(keyId)
+
 	result := map[string]interface{}{
 		"success": true,
 		"apiKey":  apiKey,
@@ -380,13 +621,16 @@ func (e *ERPC) handleDeleteApiKey(ctx context.Context, nq *common.NormalizedRequ
 		return nil, common.NewErrInvalidRequest(fmt.Errorf("apiKey is required and must be a string"))
 	}
 
-	connector, err := e.findDatabaseConnectorById(projectId, connectorId)
+	strategy, err := e.findDatabaseStrategyById(projectId, connectorId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find connector: %w", err)
+		return nil, fmt.Errorf("failed to find database strategy: %w", err)
 	}
+	connector := strategy.GetConnector()
+	// The API key might be provided as its raw value or its id (e.g. hashed key from erpc_listApiKeys)
+	keyId := strategy.KeyId(apiKey)
 
 	// Get current data to find the userId (range key) for deletion
-	currentBytes, err := connector.Get(ctx, data.ConnectorMainIndex, apiKey, "*", nil)
+	currentBytes, err := connector.Get(ctx, data.ConnectorMainIndex, keyId, "*", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current API key data: %w", err)
 	}
@@ -402,10 +646,11 @@ func (e *ERPC) handleDeleteApiKey(ctx context.Context, nq *common.NormalizedRequ
 		return nil, fmt.Errorf("missing or invalid userId in current data")
 	}
 
-	err = connector.Delete(ctx, apiKey, userId)
+	err = connector.Delete(ctx, keyId, userId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete API key: %w", err)
 	}
+	strategy.InvalidateCache(keyId)
 
 	result := map[string]interface{}{
 		"success": true,
@@ -421,6 +666,326 @@ func (e *ERPC) handleDeleteApiKey(ctx context.Context, nq *common.NormalizedRequ
 	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
 }
 
+// handleGetUsage returns the daily and monthly usage of an API key along with its quota
+func (e *ERPC) handleGetUsage(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jrr.Params) < 1 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, connectorId, apiKey}"))
+	}
+
+	params, ok := jrr.Params[0].(map[string]interface{})
+	if !ok {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("first parameter must be an object"))
+	}
+
+	projectId, ok := params["projectId"].(string)
+	if !ok || projectId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("projectId is required and must be a string"))
+	}
+
+	connectorId, ok := params["connectorId"].(string)
+	if !ok || connectorId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("connectorId is required and must be a string"))
+	}
+
+	apiKey, ok := params["apiKey"].(string)
+	if !ok || apiKey == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("apiKey is required and must be a string"))
+	}
+
+	strategy, err := e.findDatabaseStrategyById(projectId, connectorId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find database strategy: %w", err)
+	}
+
+	currentBytes, err := strategy.GetConnector().Get(ctx, data.ConnectorMainIndex, strategy.KeyId(apiKey), "*", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current API key data: %w", err)
+	}
+
+	var currentData map[string]interface{}
+	if err := json.Unmarshal(currentBytes, &currentData); err != nil {
+		return nil, fmt.Errorf("failed to parse current data: %w", err)
+	}
+	userId, _ := currentData["userId"].(string)
+	quota, err := parseUserQuota(currentData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quota of API key: %w", err)
+	}
+
+	usage, err := strategy.GetUsage(ctx, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"apiKey":  apiKey,
+		"userId":  userId,
+		"quota":   quota,
+		"daily":   usage.Daily,
+		"monthly": usage.Monthly,
+	}
+
+	jrrs, err := common.NewJsonRpcResponse(jrr.ID, result, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
+}
+
+// handleResetUsage resets the daily and/or monthly usage of an API key
+func (e *ERPC) handleResetUsage(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jrr.Params) < 1 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, connectorId, apiKey, window?}"))
+	}
+
+	params, ok := jrr.Params[0].(map[string]interface{})
+	if !ok {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("first parameter must be an object"))
+	}
+
+	projectId, ok := params["projectId"].(string)
+	if !ok || projectId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("projectId is required and must be a string"))
+	}
+
+	connectorId, ok := params["connectorId"].(string)
+	if !ok || connectorId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("connectorId is required and must be a string"))
+	}
+
+	apiKey, ok := params["apiKey"].(string)
+	if !ok || apiKey == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("apiKey is required and must be a string"))
+	}
+
+	window := ""
+	if windowVal, exists := params["window"]; exists && windowVal != nil {
+		windowStr, ok := windowVal.(string)
+		if !ok || (windowStr != auth.QuotaWindowDaily && windowStr != auth.QuotaWindowMonthly) {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("window must be either '%s' or '%s'", auth.QuotaWindowDaily, auth.QuotaWindowMonthly))
+		}
+		window = windowStr
+	}
+
+	strategy, err := e.findDatabaseStrategyById(projectId, connectorId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find database strategy: %w", err)
+	}
+
+	if err := strategy.ResetUsage(ctx, apiKey, window); err != nil {
+		return nil, fmt.Errorf("failed to reset usage: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"success": true,
+		"apiKey":  apiKey,
+		"window":  window,
+	}
+
+	jrrs, err := common.NewJsonRpcResponse(jrr.ID, result, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
+}
+
+// handleMigrateApiKeys re-stores API keys that are still stored by their raw value as hashed keys
+func (e *ERPC) handleMigrateApiKeys(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jrr.Params) < 1 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, connectorId}"))
+	}
+
+	params, ok := jrr.Params[0].(map[string]interface{})
+	if !ok {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("first parameter must be an object"))
+	}
+
+	projectId, ok := params["projectId"].(string)
+	if !ok || projectId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("projectId is required and must be a string"))
+	}
+
+	connectorId, ok := params["connectorId"].(string)
+	if !ok || connectorId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("connectorId is required and must be a string"))
+	}
+
+	strategy, err := e.findDatabaseStrategyById(projectId, connectorId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find database strategy: %w", err)
+	}
+	if !strategy.HashesKeys() {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("keyHashing must be enabled on the database strategy to migrate API keys"))
+	}
+
+	migration, err := strategy.MigratePlaintextKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate API keys: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"success":  true,
+		"migrated": migration.Migrated,
+		"skipped":  migration.Skipped,
+	}
+
+	jrrs, err := common.NewJsonRpcResponse(jrr.ID, result, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
+}
+
+// handleListDisputes lists recorded consensus disputes and shadow mismatches of a project
+func (e *ERPC) handleListDisputes(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jrr.Params) < 1 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, kind?, networkId?, method?, upstream?, since?, limit?, paginationToken?}"))
+	}
+
+	params, ok := jrr.Params[0].(map[string]interface{})
+	if !ok {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("first parameter must be an object"))
+	}
+
+	projectId, ok := params["projectId"].(string)
+	if !ok || projectId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("projectId is required and must be a string"))
+	}
+
+	filter := &DisputeFilter{}
+	if kind, ok := params["kind"].(string); ok && kind != "" {
+		switch common.DisputeKind(kind) {
+		case common.DisputeKindConsensus, common.DisputeKindShadow:
+			filter.Kind = common.DisputeKind(kind)
+		default:
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("kind must be one of: consensus, shadow"))
+		}
+	}
+	if networkId, ok := params["networkId"].(string); ok {
+		filter.NetworkId = networkId
+	}
+	if method, ok := params["method"].(string); ok {
+		filter.Method = method
+	}
+	if ups, ok := params["upstream"].(string); ok {
+		filter.Upstream = ups
+	}
+	if since, ok := params["since"].(string); ok && since != "" {
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("since must be an RFC3339 timestamp: %w", err))
+		}
+	}
+
+	limit := 50 // default
+	if limitVal, exists := params["limit"]; exists && limitVal != nil {
+		if limitFloat, ok := limitVal.(float64); ok {
+			limit = int(min(limitFloat, disputeListMaxLimit))
+		}
+	}
+
+	paginationToken := ""
+	if tokenVal, exists := params["paginationToken"]; exists && tokenVal != nil {
+		if tokenStr, ok := tokenVal.(string); ok {
+			paginationToken = tokenStr
+		}
+	}
+
+	store, err := e.findDisputeStore(projectId)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(err)
+	}
+
+	records, nextToken, err := store.List(ctx, filter, limit, paginationToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"disputes":      records,
+		"nextToken":     nextToken,
+		"hasMore":       nextToken != "",
+		"totalReturned": len(records),
+	}
+
+	jrrs, err := common.NewJsonRpcResponse(jrr.ID, result, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
+}
+
+// handleGetDispute returns a recorded dispute including the request and response bodies
+func (e *ERPC) handleGetDispute(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jrr.Params) < 1 {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {projectId, id}"))
+	}
+
+	params, ok := jrr.Params[0].(map[string]interface{})
+	if !ok {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("first parameter must be an object"))
+	}
+
+	projectId, ok := params["projectId"].(string)
+	if !ok || projectId == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("projectId is required and must be a string"))
+	}
+
+	id, ok := params["id"].(string)
+	if !ok || id == "" {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("id is required and must be a string"))
+	}
+
+	store, err := e.findDisputeStore(projectId)
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(err)
+	}
+
+	record, err := store.Get(ctx, id)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("dispute '%s' not found", id))
+		}
+		return nil, fmt.Errorf("failed to get dispute: %w", err)
+	}
+
+	jrrs, err := common.NewJsonRpcResponse(jrr.ID, record, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
+}
+
 // handleConfig returns the eRPC configuration
 func (e *ERPC) handleConfig(ctx context.Context, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
 	jrr, err := nq.JsonRpcRequest()
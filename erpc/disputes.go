@@ -0,0 +1,275 @@
+package erpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const (
+	disputeIdPrefix     = "dispute_"
+	disputePartitionKey = "disputes:"
+
+	// disputeQueueSize bounds the records waiting to be written, further records are dropped
+	disputeQueueSize = 1000
+	// disputeListBatchSize is the number of entries read at once when a filter is used
+	disputeListBatchSize = 200
+	// disputeListMaxScanned bounds the entries examined by a single List call, a page token is returned beyond it
+	disputeListMaxScanned = 10_000
+	// disputeListMaxLimit bounds the records returned by a single List call
+	disputeListMaxLimit = 500
+)
+
+// DisputeStore persists consensus disputes and shadow mismatches of a project into a connector.
+// Records are indexed by project (partition key) and by an id ordered by time (range key), so that
+// they can be listed newest first. They are written by a single background writer.
+type DisputeStore struct {
+	appCtx    context.Context
+	logger    *zerolog.Logger
+	projectId string
+	cfg       *common.DisputesConfig
+	connector data.PartitionLister
+	queue     chan *common.DisputeRecord
+}
+
+var _ common.DisputeRecorder = &DisputeStore{}
+
+func NewDisputeStore(appCtx context.Context, logger *zerolog.Logger, projectId string, cfg *common.DisputesConfig) (*DisputeStore, error) {
+	if cfg.Connector == nil {
+		return nil, fmt.Errorf("disputes connector config is nil")
+	}
+	lg := logger.With().Str("component", "disputes").Logger()
+	connector, err := data.NewConnector(appCtx, &lg, cfg.Connector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create disputes connector: %w", err)
+	}
+	lister, ok := connector.(data.PartitionLister)
+	if !ok {
+		return nil, fmt.Errorf("disputes connector %s does not support listing partitions", cfg.Connector.Id)
+	}
+	s := &DisputeStore{
+		appCtx:    appCtx,
+		logger:    &lg,
+		projectId: projectId,
+		cfg:       cfg,
+		connector: lister,
+		queue:     make(chan *common.DisputeRecord, disputeQueueSize),
+	}
+	go s.writeLoop()
+	return s, nil
+}
+
+// enabled tells whether records of the given kind are kept.
+func (s *DisputeStore) enabled(kind common.DisputeKind) bool {
+	switch kind {
+	case common.DisputeKindConsensus:
+		return s.cfg.Consensus == nil || *s.cfg.Consensus
+	case common.DisputeKindShadow:
+		return s.cfg.Shadow == nil || *s.cfg.Shadow
+	}
+	return true
+}
+
+func (s *DisputeStore) partitionKey() string {
+	return disputePartitionKey + s.projectId
+}
+
+// RecordDispute truncates bodies and queues the record for the background writer so that requests are not
+// delayed. When the queue is full the record is dropped.
+func (s *DisputeStore) RecordDispute(_ context.Context, record *common.DisputeRecord) {
+	if record == nil || !s.enabled(record.Kind) {
+		return
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	if record.Id == "" {
+		record.Id = newDisputeId(record.Timestamp)
+	}
+	if record.ProjectId == "" {
+		record.ProjectId = s.projectId
+	}
+	record.Request, record.RequestTruncated = truncateBody(record.Request, s.cfg.MaxBodySize)
+	for _, p := range record.Participants {
+		if p != nil {
+			p.Response, p.ResponseTruncated = truncateBody(p.Response, s.cfg.MaxBodySize)
+		}
+	}
+
+	select {
+	case s.queue <- record:
+	default:
+		telemetry.MetricDisputeRecordsDroppedTotal.WithLabelValues(s.projectId, string(record.Kind)).Inc()
+		s.logger.Debug().Str("disputeId", record.Id).Str("kind", string(record.Kind)).Msg("dropped dispute record because the write queue is full")
+	}
+}
+
+func (s *DisputeStore) writeLoop() {
+	for {
+		select {
+		case <-s.appCtx.Done():
+			return
+		case record := <-s.queue:
+			s.write(record)
+		}
+	}
+}
+
+func (s *DisputeStore) write(record *common.DisputeRecord) {
+	// Use the app context since the request context is usually done by the time the record is written
+	ctx, cancel := context.WithTimeout(s.appCtx, 10*time.Second)
+	defer cancel()
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		s.logger.Error().Err(err).Str("disputeId", record.Id).Msg("failed to marshal dispute record")
+		return
+	}
+	ttl := s.cfg.Retention.Duration()
+	// Connectors that cannot list a partition on their own (e.g. redis) only list entries written to its index
+	if indexer, ok := s.connector.(data.PartitionIndexer); ok {
+		err = indexer.SetIndexed(ctx, s.partitionKey(), record.Id, value, &ttl)
+	} else {
+		err = s.connector.Set(ctx, s.partitionKey(), record.Id, value, &ttl)
+	}
+	if err != nil {
+		s.logger.Warn().Err(err).Str("disputeId", record.Id).Msg("failed to store dispute record")
+		return
+	}
+	s.logger.Debug().Str("disputeId", record.Id).Str("kind", string(record.Kind)).Str("networkId", record.NetworkId).Msg("stored dispute record")
+}
+
+// Get returns a dispute record of the project by its id.
+func (s *DisputeStore) Get(ctx context.Context, id string) (*common.DisputeRecord, error) {
+	value, err := s.connector.Get(ctx, data.ConnectorMainIndex, s.partitionKey(), id, nil)
+	if err != nil {
+		return nil, err
+	}
+	record := &common.DisputeRecord{}
+	if err := json.Unmarshal(value, record); err != nil {
+		return nil, fmt.Errorf("invalid dispute record: %w", err)
+	}
+	return record, nil
+}
+
+// DisputeFilter narrows down listed records, empty fields match everything and Upstream supports wildcards.
+type DisputeFilter struct {
+	Kind      common.DisputeKind
+	NetworkId string
+	Method    string
+	Upstream  string
+	Since     time.Time
+}
+
+func (f *DisputeFilter) matches(record *common.DisputeRecord) bool {
+	if f.Kind != "" && record.Kind != f.Kind {
+		return false
+	}
+	if f.NetworkId != "" && record.NetworkId != f.NetworkId {
+		return false
+	}
+	if f.Method != "" && record.Method != f.Method {
+		return false
+	}
+	if !f.Since.IsZero() && record.Timestamp.Before(f.Since) {
+		return false
+	}
+	if f.Upstream != "" {
+		for _, p := range record.Participants {
+			if p == nil {
+				continue
+			}
+			if match, err := common.WildcardMatch(f.Upstream, p.Upstream); err == nil && match {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// List returns up to "limit" records (at most disputeListMaxLimit) of the project matching the filter, newest first.
+// The returned token is the id to continue after, and is empty once all records were examined. Bodies are omitted,
+// they can be fetched via Get.
+func (s *DisputeStore) List(ctx context.Context, filter *DisputeFilter, limit int, paginationToken string) ([]*common.DisputeRecord, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be greater than 0")
+	}
+	limit = min(limit, disputeListMaxLimit)
+
+	// Ids are ordered by time, so records older than "since" are all found after this key
+	sinceKey := ""
+	if filter != nil && !filter.Since.IsZero() {
+		sinceKey = disputeIdPrefix + formatDisputeTimestamp(filter.Since)
+	}
+
+	records := make([]*common.DisputeRecord, 0, limit)
+	cursor := paginationToken
+	scanned := 0
+	for scanned < disputeListMaxScanned {
+		batch := limit - len(records)
+		if filter != nil {
+			batch = max(batch, disputeListBatchSize)
+		}
+		items, err := s.connector.ListPartition(ctx, s.partitionKey(), cursor, batch)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, item := range items {
+			if item.RangeKey < sinceKey {
+				return records, "", nil
+			}
+			scanned++
+			cursor = item.RangeKey
+			record := &common.DisputeRecord{}
+			if err := json.Unmarshal(item.Value, record); err != nil {
+				continue // Skip invalid records
+			}
+			if filter != nil && !filter.matches(record) {
+				continue
+			}
+			record.Request = ""
+			for _, p := range record.Participants {
+				if p != nil {
+					p.Response = ""
+				}
+			}
+			records = append(records, record)
+			if len(records) >= limit {
+				return records, cursor, nil
+			}
+		}
+		if len(items) < batch {
+			return records, "", nil
+		}
+	}
+
+	return records, cursor, nil
+}
+
+// newDisputeId returns a unique id which sorts by time of the dispute.
+func newDisputeId(ts time.Time) string {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return disputeIdPrefix + formatDisputeTimestamp(ts) + "_" + hex.EncodeToString(buf)
+}
+
+// formatDisputeTimestamp zero-pads milliseconds so that ids sort lexicographically by time.
+func formatDisputeTimestamp(ts time.Time) string {
+	return fmt.Sprintf("%013d", ts.UnixMilli())
+}
+
+func truncateBody(body string, maxSize int) (string, bool) {
+	if maxSize <= 0 || len(body) <= maxSize {
+		return body, false
+	}
+	return body[:maxSize], true
+}
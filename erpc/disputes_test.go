@@ -0,0 +1,250 @@
+package erpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/erpc/erpc/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDisputesConfig(t *testing.T, shadow bool) *common.DisputesConfig {
+	t.Helper()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(m.Close)
+
+	cfg := &common.DisputesConfig{
+		Connector: &common.ConnectorConfig{
+			Id:     "disputes",
+			Driver: common.DriverRedis,
+			Redis: &common.RedisConnectorConfig{
+				Addr:        m.Addr(),
+				InitTimeout: common.Duration(2 * time.Second),
+				GetTimeout:  common.Duration(2 * time.Second),
+				SetTimeout:  common.Duration(2 * time.Second),
+			},
+		},
+		MaxBodySize: 16,
+		Shadow:      &shadow,
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+	return cfg
+}
+
+func newTestDisputeStore(t *testing.T, projectId string, shadow bool) *DisputeStore {
+	t.Helper()
+	logger := zerolog.Nop()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store, err := NewDisputeStore(ctx, &logger, projectId, newTestDisputesConfig(t, shadow))
+	require.NoError(t, err)
+	return store
+}
+
+// listDisputes waits for the expected number of records of the store to be written and returns them with their bodies.
+func listDisputes(t *testing.T, store *DisputeStore, count int) []*common.DisputeRecord {
+	t.Helper()
+	var listed []*common.DisputeRecord
+	require.Eventually(t, func() bool {
+		records, _, err := store.List(context.Background(), nil, 100, "")
+		if err != nil || len(records) < count {
+			return false
+		}
+		listed = records
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Len(t, listed, count)
+	records := make([]*common.DisputeRecord, 0, count)
+	for _, r := range listed {
+		records = append(records, waitForDispute(t, store, r.Id))
+	}
+	return records
+}
+
+func waitForDispute(t *testing.T, store *DisputeStore, id string) *common.DisputeRecord {
+	t.Helper()
+	var record *common.DisputeRecord
+	require.Eventually(t, func() bool {
+		r, err := store.Get(context.Background(), id)
+		if err != nil {
+			return false
+		}
+		record = r
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+	return record
+}
+
+func TestDisputeStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("StoresRecordWithTruncatedBodies", func(t *testing.T) {
+		store := newTestDisputeStore(t, "main", true)
+		record := &common.DisputeRecord{
+			Kind:        common.DisputeKindConsensus,
+			NetworkId:   "evm:1",
+			Method:      "eth_getBalance",
+			Request:     `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x1","latest"]}`,
+			Outcome:     "result",
+			OutcomeHash: "abc",
+			Participants: []*common.DisputeParticipant{
+				{Upstream: "rpc1", ResponseHash: "abc", Response: `"0x1"`, AgreesWithOutcome: true},
+				{Upstream: "rpc2", ResponseHash: "def", Response: `"0x1000000000000000000"`},
+			},
+		}
+		store.RecordDispute(ctx, record)
+		require.True(t, strings.HasPrefix(record.Id, disputeIdPrefix))
+
+		stored := waitForDispute(t, store, record.Id)
+		assert.Equal(t, "main", stored.ProjectId)
+		assert.Equal(t, common.DisputeKindConsensus, stored.Kind)
+		assert.Len(t, stored.Request, 16)
+		assert.True(t, stored.RequestTruncated)
+		require.Len(t, stored.Participants, 2)
+		assert.Equal(t, `"0x1"`, stored.Participants[0].Response)
+		assert.False(t, stored.Participants[0].ResponseTruncated)
+		assert.Len(t, stored.Participants[1].Response, 16)
+		assert.True(t, stored.Participants[1].ResponseTruncated)
+	})
+
+	t.Run("SkipsDisabledKinds", func(t *testing.T) {
+		store := newTestDisputeStore(t, "main", false)
+		record := &common.DisputeRecord{Kind: common.DisputeKindShadow, NetworkId: "evm:1", Method: "eth_call"}
+		store.RecordDispute(ctx, record)
+		assert.Empty(t, record.Id)
+	})
+
+	t.Run("ListsMatchingRecordsNewestFirst", func(t *testing.T) {
+		store := newTestDisputeStore(t, "main", true)
+		now := time.Now()
+		records := []*common.DisputeRecord{
+			{
+				Kind:         common.DisputeKindConsensus,
+				NetworkId:    "evm:1",
+				Method:       "eth_getBalance",
+				Timestamp:    now.Add(-2 * time.Hour),
+				Request:      "{}",
+				Participants: []*common.DisputeParticipant{{Upstream: "rpc1", Response: `"0x1"`}},
+			},
+			{
+				Kind:         common.DisputeKindConsensus,
+				NetworkId:    "evm:1",
+				Method:       "eth_getBalance",
+				Timestamp:    now.Add(-time.Hour),
+				Participants: []*common.DisputeParticipant{{Upstream: "rpc2"}},
+			},
+			{
+				Kind:         common.DisputeKindShadow,
+				NetworkId:    "evm:10",
+				Method:       "eth_call",
+				Timestamp:    now,
+				Participants: []*common.DisputeParticipant{{Upstream: "rpc1"}, {Upstream: "shadow1"}},
+			},
+		}
+		for _, r := range records {
+			store.RecordDispute(ctx, r)
+		}
+		for _, r := range records {
+			waitForDispute(t, store, r.Id)
+		}
+
+		all, _, err := store.List(ctx, nil, 100, "")
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		assert.Equal(t, records[2].Id, all[0].Id)
+		assert.Equal(t, records[1].Id, all[1].Id)
+		assert.Equal(t, records[0].Id, all[2].Id)
+		assert.Empty(t, all[2].Request)
+		assert.Empty(t, all[2].Participants[0].Response)
+
+		byUpstream, _, err := store.List(ctx, &DisputeFilter{Upstream: "rpc1"}, 100, "")
+		require.NoError(t, err)
+		require.Len(t, byUpstream, 2)
+
+		byWildcard, _, err := store.List(ctx, &DisputeFilter{Upstream: "shadow*"}, 100, "")
+		require.NoError(t, err)
+		require.Len(t, byWildcard, 1)
+		assert.Equal(t, records[2].Id, byWildcard[0].Id)
+
+		byKind, _, err := store.List(ctx, &DisputeFilter{Kind: common.DisputeKindConsensus, Since: now.Add(-90 * time.Minute)}, 100, "")
+		require.NoError(t, err)
+		require.Len(t, byKind, 1)
+		assert.Equal(t, records[1].Id, byKind[0].Id)
+	})
+
+	t.Run("PagesThroughMatchingRecordsNewestFirst", func(t *testing.T) {
+		store := newTestDisputeStore(t, "main", true)
+		other := newTestDisputeStore(t, "other", true)
+		other.connector = store.connector
+		now := time.Now()
+		var consensusIds []string
+		for i := 0; i < 30; i++ {
+			record := &common.DisputeRecord{
+				Kind:      common.DisputeKindShadow,
+				NetworkId: "evm:1",
+				Method:    "eth_call",
+				Timestamp: now.Add(time.Duration(i) * time.Second),
+			}
+			if i%5 == 0 {
+				record.Kind = common.DisputeKindConsensus
+			}
+			store.RecordDispute(ctx, record)
+			if record.Kind == common.DisputeKindConsensus {
+				consensusIds = append([]string{record.Id}, consensusIds...)
+			}
+			// Records of another project sharing the connector must not be listed
+			other.RecordDispute(ctx, &common.DisputeRecord{Kind: common.DisputeKindConsensus, NetworkId: "evm:1", Timestamp: record.Timestamp})
+		}
+		waitForDispute(t, store, consensusIds[len(consensusIds)-1])
+		require.Eventually(t, func() bool {
+			all, _, err := store.List(ctx, nil, 100, "")
+			return err == nil && len(all) == 30
+		}, 5*time.Second, 20*time.Millisecond)
+
+		filter := &DisputeFilter{Kind: common.DisputeKindConsensus}
+		var listed []string
+		token := ""
+		for page := 0; page < 3; page++ {
+			records, nextToken, err := store.List(ctx, filter, 2, token)
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			for _, r := range records {
+				listed = append(listed, r.Id)
+			}
+			token = nextToken
+		}
+		assert.Equal(t, consensusIds, listed)
+		records, nextToken, err := store.List(ctx, filter, 2, token)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+		assert.Empty(t, nextToken)
+
+		recent, _, err := store.List(ctx, &DisputeFilter{Since: now.Add(25 * time.Second)}, 100, "")
+		require.NoError(t, err)
+		assert.Len(t, recent, 5)
+	})
+
+	t.Run("DropsRecordsWhenQueueIsFull", func(t *testing.T) {
+		logger := zerolog.Nop()
+		store := &DisputeStore{
+			appCtx:    ctx,
+			logger:    &logger,
+			projectId: "main",
+			cfg:       &common.DisputesConfig{MaxBodySize: 16},
+			queue:     make(chan *common.DisputeRecord, 1),
+		}
+		first := &common.DisputeRecord{Kind: common.DisputeKindConsensus}
+		store.RecordDispute(ctx, first)
+		store.RecordDispute(ctx, &common.DisputeRecord{Kind: common.DisputeKindConsensus})
+		require.Len(t, store.queue, 1)
+		assert.Same(t, first, <-store.queue)
+	})
+}
@@ -39,6 +39,9 @@ func NewERPC(
 	if err != nil {
 		return nil, err
 	}
+	if err := rateLimitersRegistry.ConnectStore(appCtx); err != nil {
+		return nil, err
+	}
 
 	proxyPoolRegistry, err := clients.NewProxyPoolRegistry(cfg.ProxyPools, logger)
 	if err != nil {
@@ -0,0 +1,443 @@
+package erpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	EvmFilterKindLogs   = "logs"
+	EvmFilterKindBlocks = "blocks"
+
+	// Maximum concurrent block lookups when a block filter has fallen behind by many blocks
+	evmFilterFetchConcurrency = 10
+
+	// Used when shared state is not configured with a lock ttl
+	evmFilterDefaultLockTtl = 10 * time.Second
+)
+
+// evmFilterState is what is persisted in the shared state connector for each filter.
+type evmFilterState struct {
+	Kind     string                 `json:"kind"`
+	Criteria map[string]interface{} `json:"criteria,omitempty"`
+
+	// Cursor is the last block number already returned to the client via eth_getFilterChanges
+	Cursor int64 `json:"cursor"`
+}
+
+// EvmFilterManager emulates node-side filters (eth_newFilter, eth_newBlockFilter, eth_getFilterChanges, etc.)
+// on top of eth_getLogs and block lookups, so that filters survive upstream failover and can be served by any replica.
+type EvmFilterManager struct {
+	logger       *zerolog.Logger
+	network      *Network
+	cfg          *common.EvmFiltersConfig
+	connector    data.Connector
+	partitionKey string
+	lockTtl      time.Duration
+}
+
+func NewEvmFilterManager(
+	logger *zerolog.Logger,
+	network *Network,
+	cfg *common.EvmFiltersConfig,
+	sharedState data.SharedStateRegistry,
+) *EvmFilterManager {
+	lg := logger.With().Str("component", "evmFilters").Str("networkId", network.Id()).Logger()
+	lockTtl := sharedState.GetLockTtl()
+	if lockTtl <= 0 {
+		lockTtl = evmFilterDefaultLockTtl
+	}
+	return &EvmFilterManager{
+		logger:       &lg,
+		network:      network,
+		cfg:          cfg,
+		connector:    sharedState.GetConnector(),
+		partitionKey: fmt.Sprintf("%s/evmFilters/%s/%s", sharedState.GetClusterKey(), network.ProjectId(), network.Id()),
+		lockTtl:      lockTtl,
+	}
+}
+
+// Handle answers filter-related methods, for any other method it returns handled=false.
+func (m *EvmFilterManager) Handle(ctx context.Context, nq *common.NormalizedRequest) (handled bool, resp *common.NormalizedResponse, err error) {
+	method, err := nq.Method()
+	if err != nil {
+		return false, nil, err
+	}
+
+	var result interface{}
+	switch method {
+	case "eth_newFilter":
+		result, err = m.newLogsFilter(ctx, nq)
+	case "eth_newBlockFilter":
+		result, err = m.newFilter(ctx, &evmFilterState{Kind: EvmFilterKindBlocks})
+	case "eth_getFilterChanges":
+		result, err = m.getFilterChanges(ctx, nq)
+	case "eth_getFilterLogs":
+		result, err = m.getFilterLogs(ctx, nq)
+	case "eth_uninstallFilter":
+		result, err = m.uninstallFilter(ctx, nq)
+	default:
+		return false, nil, nil
+	}
+	if err != nil {
+		return true, nil, err
+	}
+
+	id := nq.ID()
+	if id == nil {
+		id = util.RandomID()
+	}
+	jrr, err := common.NewJsonRpcResponse(id, result, nil)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), nil
+}
+
+func (m *EvmFilterManager) newLogsFilter(ctx context.Context, nq *common.NormalizedRequest) (interface{}, error) {
+	params, err := m.requestParams(ctx, nq)
+	if err != nil {
+		return nil, err
+	}
+	criteria := make(map[string]interface{})
+	if len(params) > 0 && params[0] != nil {
+		raw, ok := params[0].(map[string]interface{})
+		if !ok {
+			return nil, newEvmFilterInvalidParamsError("filter criteria must be an object")
+		}
+		for k, v := range raw {
+			switch k {
+			case "fromBlock", "toBlock", "address", "topics":
+				if v != nil {
+					criteria[k] = v
+				}
+			case "blockHash":
+				return nil, newEvmFilterInvalidParamsError("blockHash is not supported for filters, use eth_getLogs instead")
+			}
+		}
+	}
+	return m.newFilter(ctx, &evmFilterState{Kind: EvmFilterKindLogs, Criteria: criteria})
+}
+
+func (m *EvmFilterManager) newFilter(ctx context.Context, state *evmFilterState) (interface{}, error) {
+	// Similar to nodes, only changes after the filter is created are returned by eth_getFilterChanges
+	latest, err := m.latestBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state.Cursor = latest
+
+	id, err := newEvmRandomId()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store(ctx, id, state); err != nil {
+		return nil, err
+	}
+	m.logger.Debug().Str("filterId", id).Str("kind", state.Kind).Int64("cursor", latest).Msg("created emulated filter")
+	return id, nil
+}
+
+func (m *EvmFilterManager) getFilterChanges(ctx context.Context, nq *common.NormalizedRequest) (interface{}, error) {
+	id, err := m.filterIdParam(ctx, nq)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := m.connector.Lock(ctx, m.partitionKey+"/"+id, m.lockTtl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock for filter %s: %w", id, err)
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			m.logger.Warn().Err(err).Str("filterId", id).Msg("failed to release filter lock")
+		}
+	}()
+
+	state, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, newEvmFilterNotFoundError()
+	}
+
+	latest, err := m.latestBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from := state.Cursor + 1
+	to := latest
+	if state.Kind == EvmFilterKindLogs {
+		if bn, ok := evmFilterBlockParam(state.Criteria["fromBlock"]); ok && bn > from {
+			from = bn
+		}
+		if bn, ok := evmFilterBlockParam(state.Criteria["toBlock"]); ok && bn < to {
+			to = bn
+		}
+	}
+	if m.cfg.MaxBlocksPerPoll > 0 && to-from+1 > m.cfg.MaxBlocksPerPoll {
+		// Remaining blocks will be returned on the next polls
+		to = from + m.cfg.MaxBlocksPerPoll - 1
+	}
+
+	var result interface{}
+	if from > to {
+		if state.Kind == EvmFilterKindLogs {
+			result = []json.RawMessage{}
+		} else {
+			result = []string{}
+		}
+	} else if state.Kind == EvmFilterKindLogs {
+		result, err = m.fetchLogs(ctx, state.Criteria, from, to)
+		if err != nil {
+			return nil, err
+		}
+		state.Cursor = to
+	} else {
+		result, err = m.fetchBlockHashes(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		state.Cursor = to
+	}
+
+	// Stored even when nothing has changed so that the ttl is extended on every poll
+	if err := m.store(ctx, id, state); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (m *EvmFilterManager) getFilterLogs(ctx context.Context, nq *common.NormalizedRequest) (interface{}, error) {
+	id, err := m.filterIdParam(ctx, nq)
+	if err != nil {
+		return nil, err
+	}
+	state, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || state.Kind != EvmFilterKindLogs {
+		return nil, newEvmFilterNotFoundError()
+	}
+
+	criteria := make(map[string]interface{}, len(state.Criteria))
+	for k, v := range state.Criteria {
+		criteria[k] = v
+	}
+	result, err := forwardInternalRequest(ctx, m.network, common.NewJsonRpcRequest("eth_getLogs", []interface{}{criteria}), false)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(result), nil
+}
+
+func (m *EvmFilterManager) uninstallFilter(ctx context.Context, nq *common.NormalizedRequest) (interface{}, error) {
+	id, err := m.filterIdParam(ctx, nq)
+	if err != nil {
+		return nil, err
+	}
+	state, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return false, nil
+	}
+	if err := m.connector.Delete(ctx, m.partitionKey, id); err != nil {
+		return nil, err
+	}
+	m.waitForWrites()
+	return true, nil
+}
+
+func (m *EvmFilterManager) fetchLogs(ctx context.Context, criteria map[string]interface{}, from, to int64) (json.RawMessage, error) {
+	filter := map[string]interface{}{
+		"fromBlock": fmt.Sprintf("0x%x", from),
+		"toBlock":   fmt.Sprintf("0x%x", to),
+	}
+	for _, k := range []string{"address", "topics"} {
+		if v, ok := criteria[k]; ok {
+			filter[k] = v
+		}
+	}
+	result, err := forwardInternalRequest(ctx, m.network, common.NewJsonRpcRequest("eth_getLogs", []interface{}{filter}), false)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(result), nil
+}
+
+func (m *EvmFilterManager) fetchBlockHashes(ctx context.Context, from, to int64) ([]string, error) {
+	hashes := make([]string, to-from+1)
+	errs := make([]error, len(hashes))
+	sem := make(chan struct{}, evmFilterFetchConcurrency)
+	var wg sync.WaitGroup
+	for bn := from; bn <= to; bn++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, bn int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			jrq, err := evm.BuildGetBlockByNumberRequest(bn, false)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			result, err := forwardInternalRequest(ctx, m.network, jrq, false)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			var block struct {
+				Hash string `json:"hash"`
+			}
+			if err := common.SonicCfg.Unmarshal(result, &block); err != nil {
+				errs[i] = err
+				return
+			}
+			if block.Hash == "" {
+				errs[i] = fmt.Errorf("block %d is not available yet", bn)
+				return
+			}
+			hashes[i] = block.Hash
+		}(int(bn-from), bn)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+func (m *EvmFilterManager) latestBlockNumber(ctx context.Context) (int64, error) {
+	if bn := m.network.EvmHighestLatestBlockNumber(ctx); bn > 0 {
+		return bn, nil
+	}
+
+	// State poller might be disabled for all upstreams
+	result, err := forwardInternalRequest(ctx, m.network, common.NewJsonRpcRequest("eth_blockNumber", []interface{}{}), true)
+	if err != nil {
+		return 0, err
+	}
+	var hexNum string
+	if err := common.SonicCfg.Unmarshal(result, &hexNum); err != nil {
+		return 0, err
+	}
+	return common.HexToInt64(hexNum)
+}
+
+func (m *EvmFilterManager) load(ctx context.Context, id string) (*evmFilterState, error) {
+	raw, err := m.connector.Get(ctx, data.ConnectorMainIndex, m.partitionKey, id, nil)
+	if err != nil {
+		if common.HasErrorCode(err, common.ErrCodeRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	state := &evmFilterState{}
+	if err := common.SonicCfg.Unmarshal(raw, state); err != nil {
+		return nil, fmt.Errorf("failed to decode filter %s: %w", id, err)
+	}
+	return state, nil
+}
+
+func (m *EvmFilterManager) store(ctx context.Context, id string, state *evmFilterState) error {
+	value, err := common.SonicCfg.Marshal(state)
+	if err != nil {
+		return err
+	}
+	ttl := m.cfg.Ttl.Duration()
+	if err := m.connector.Set(ctx, m.partitionKey, id, value, &ttl); err != nil {
+		return err
+	}
+	m.waitForWrites()
+	return nil
+}
+
+// waitForWrites makes a filter write visible before it is answered, otherwise an immediate poll
+// might not find the filter or read a stale cursor on connectors that apply writes asynchronously.
+func (m *EvmFilterManager) waitForWrites() {
+	if w, ok := m.connector.(data.WriteWaiter); ok {
+		w.WaitForWrites()
+	}
+}
+
+func (m *EvmFilterManager) requestParams(ctx context.Context, nq *common.NormalizedRequest) ([]interface{}, error) {
+	jrq, err := nq.JsonRpcRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jrq.RLockWithTrace(ctx)
+	defer jrq.RUnlock()
+	return jrq.Params, nil
+}
+
+func (m *EvmFilterManager) filterIdParam(ctx context.Context, nq *common.NormalizedRequest) (string, error) {
+	params, err := m.requestParams(ctx, nq)
+	if err != nil {
+		return "", err
+	}
+	if len(params) < 1 {
+		return "", newEvmFilterInvalidParamsError("filter id is required")
+	}
+	id, ok := params[0].(string)
+	if !ok || id == "" {
+		return "", newEvmFilterInvalidParamsError("filter id must be a string")
+	}
+	return strings.ToLower(id), nil
+}
+
+// evmFilterBlockParam returns the block number of a filter's fromBlock/toBlock, tags such as
+// "latest" are not numeric and are naturally bounded by the latest block of the network.
+func evmFilterBlockParam(v interface{}) (int64, bool) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "0x") {
+		return 0, false
+	}
+	bn, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return 0, false
+	}
+	return bn, true
+}
+
+func newEvmFilterInvalidParamsError(msg string) error {
+	return common.NewErrJsonRpcExceptionInternal(
+		0,
+		common.JsonRpcErrorInvalidArgument,
+		msg,
+		nil,
+		nil,
+	)
+}
+
+func newEvmFilterNotFoundError() error {
+	// Same message as nodes so that client libraries recreate the filter
+	return common.NewErrEndpointClientSideException(
+		common.NewErrJsonRpcExceptionInternal(
+			0,
+			common.JsonRpcErrorCallException,
+			"filter not found",
+			nil,
+			nil,
+		),
+	)
+}
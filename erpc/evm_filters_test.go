@@ -0,0 +1,121 @@
+package erpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvmFilterManager(t *testing.T) {
+	t.Run("EmulatesBlockAndLogFiltersAcrossMultipleUpstreams", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		// Either upstream might serve the requests as filters are not bound to a single upstream anymore
+		for _, host := range []string{"http://rpc1.localhost", "http://rpc2.localhost"} {
+			gock.New(host).
+				Post("").
+				Persist().
+				Filter(func(request *http.Request) bool {
+					body := util.SafeReadBody(request)
+					return strings.Contains(body, "eth_getBlockByNumber") && strings.Contains(body, "0x22228889")
+				}).
+				Reply(200).
+				JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x22228889","hash":"0xb1"}}`))
+			gock.New(host).
+				Post("").
+				Persist().
+				Filter(func(request *http.Request) bool {
+					body := util.SafeReadBody(request)
+					return strings.Contains(body, "eth_getLogs") && strings.Contains(body, "0x2222222222222222222222222222222222222222")
+				}).
+				Reply(200).
+				JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":[{"address":"0x2222222222222222222222222222222222222222","blockNumber":"0x2222888a","logIndex":"0x0","topics":[]}]}`))
+		}
+
+		cfg := createWebSocketTestConfig()
+		cfg.Projects[0].Networks[0].Evm.Filters = &common.EvmFiltersConfig{
+			Enabled: util.BoolPtr(true),
+		}
+		cfg.Projects[0].Upstreams = append(cfg.Projects[0].Upstreams, &common.UpstreamConfig{
+			Id:       "rpc2",
+			Type:     common.UpstreamTypeEvm,
+			Endpoint: "http://rpc2.localhost",
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		})
+		sendRequest, _, _, shutdown, erpcInstance := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		call := func(body string) map[string]interface{} {
+			status, _, respBody := sendRequest(body, nil, nil)
+			assert.Equal(t, http.StatusOK, status, respBody)
+			var resp map[string]interface{}
+			require.NoError(t, sonic.UnmarshalString(respBody, &resp), respBody)
+			return resp
+		}
+
+		ctx := context.Background()
+		nw, err := erpcInstance.GetNetwork(ctx, "test_project", "evm:123")
+		require.NoError(t, err)
+		advanceLatestBlock := func(bn int64) {
+			require.Eventually(t, func() bool {
+				// Suggestions are skipped while another update is in progress, so they are retried
+				for _, ups := range nw.upstreamsRegistry.GetNetworkUpstreams(ctx, nw.Id()) {
+					ups.EvmStatePoller().SuggestLatestBlock(bn)
+				}
+				return nw.EvmHighestLatestBlockNumber(ctx) == bn
+			}, 5*time.Second, 50*time.Millisecond)
+		}
+
+		resp := call(`{"jsonrpc":"2.0","id":1,"method":"eth_newBlockFilter","params":[]}`)
+		blockFilterId, ok := resp["result"].(string)
+		require.True(t, ok, "expected filter id, got: %v", resp)
+
+		resp = call(`{"jsonrpc":"2.0","id":2,"method":"eth_getFilterChanges","params":["` + blockFilterId + `"]}`)
+		assert.Equal(t, []interface{}{}, resp["result"])
+
+		advanceLatestBlock(0x22228889)
+		resp = call(`{"jsonrpc":"2.0","id":3,"method":"eth_getFilterChanges","params":["` + blockFilterId + `"]}`)
+		assert.Equal(t, []interface{}{"0xb1"}, resp["result"])
+
+		resp = call(`{"jsonrpc":"2.0","id":4,"method":"eth_newFilter","params":[{"address":"0x2222222222222222222222222222222222222222"}]}`)
+		logsFilterId, ok := resp["result"].(string)
+		require.True(t, ok, "expected filter id, got: %v", resp)
+
+		advanceLatestBlock(0x2222888a)
+		resp = call(`{"jsonrpc":"2.0","id":5,"method":"eth_getFilterChanges","params":["` + logsFilterId + `"]}`)
+		logs, ok := resp["result"].([]interface{})
+		require.True(t, ok, "expected logs, got: %v", resp)
+		require.Len(t, logs, 1)
+		assert.Equal(t, "0x2222888a", logs[0].(map[string]interface{})["blockNumber"])
+
+		// Nothing new since the last poll
+		resp = call(`{"jsonrpc":"2.0","id":6,"method":"eth_getFilterChanges","params":["` + logsFilterId + `"]}`)
+		assert.Equal(t, []interface{}{}, resp["result"])
+
+		resp = call(`{"jsonrpc":"2.0","id":7,"method":"eth_getFilterLogs","params":["` + logsFilterId + `"]}`)
+		assert.Len(t, resp["result"], 1)
+
+		resp = call(`{"jsonrpc":"2.0","id":8,"method":"eth_uninstallFilter","params":["` + logsFilterId + `"]}`)
+		assert.Equal(t, true, resp["result"])
+		resp = call(`{"jsonrpc":"2.0","id":9,"method":"eth_uninstallFilter","params":["` + logsFilterId + `"]}`)
+		assert.Equal(t, false, resp["result"])
+
+		resp = call(`{"jsonrpc":"2.0","id":10,"method":"eth_getFilterChanges","params":["` + logsFilterId + `"]}`)
+		errObj, ok := resp["error"].(map[string]interface{})
+		require.True(t, ok, "expected error, got: %v", resp)
+		assert.Contains(t, errObj["message"], "filter not found")
+	})
+}
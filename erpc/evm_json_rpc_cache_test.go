@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -1468,6 +1469,173 @@ func TestEvmJsonRpcCache_FinalityAndRetry(t *testing.T) {
 	})
 }
 
+func TestEvmJsonRpcCache_ReorgInvalidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newSetRequest := func(t *testing.T, mockNetwork *Network, ups *upstream.Upstream, cache *evm.EvmJsonRpcCache, reqBody, respBody string) {
+		req := common.NewNormalizedRequest([]byte(reqBody))
+		req.SetNetwork(mockNetwork)
+		req.SetCacheDal(cache)
+		resp := common.NewNormalizedResponse().WithRequest(req).WithBody(stringToReaderCloser(respBody))
+		resp.SetUpstream(ups)
+		req.SetLastValidResponse(ctx, resp)
+		require.NoError(t, cache.Set(ctx, req, resp))
+	}
+
+	t.Run("DeletesEntriesOfReorgedBlocksWhenDifferentHashIsObserved", func(t *testing.T) {
+		mockConnectors, mockNetwork, mockUpstreams, cache := createCacheTestFixtures(ctx, []upsTestCfg{
+			{id: "upsA", syncing: common.EvmSyncingStateNotSyncing, finBn: 10, lstBn: 15},
+		})
+		policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+			Network:  "evm:123",
+			Method:   "*",
+			Finality: common.DataFinalityStateUnfinalized,
+			TTL:      common.Duration(5 * time.Minute),
+		}, mockConnectors[0])
+		require.NoError(t, err)
+		cache.SetPolicies([]*data.CachePolicy{policy})
+
+		mockConnectors[0].On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		deleted := sync.Map{}
+		mockConnectors[0].On("Delete", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			deleted.Store(args.String(1), true)
+		}).Return(nil)
+
+		newSetRequest(t, mockNetwork, mockUpstreams[0], cache,
+			`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x11117780"],"id":1}`,
+			`{"result":"0x1"}`)
+		newSetRequest(t, mockNetwork, mockUpstreams[0], cache,
+			`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x11117782"],"id":1}`,
+			`{"result":"0x2"}`)
+		newSetRequest(t, mockNetwork, mockUpstreams[0], cache,
+			`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x11117781",false],"id":1}`,
+			`{"result":{"number":"0x11117781","hash":"0xaaa"}}`)
+
+		// Same hash again must not invalidate anything
+		cache.RecordBlockHash("evm:123", 0x11117781, "0xaaa")
+		mockConnectors[0].AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+
+		cache.RecordBlockHash("evm:123", 0x11117781, "0xbbb")
+		require.Eventually(t, func() bool {
+			_, okReorged := deleted.Load("evm:123:286357377")
+			_, okDescendant := deleted.Load("evm:123:286357378")
+			return okReorged && okDescendant
+		}, 2*time.Second, 20*time.Millisecond)
+		_, ok := deleted.Load("evm:123:286357376")
+		assert.False(t, ok, "entries below the reorged block must be kept")
+	})
+
+	t.Run("DeletesEntriesWrittenByOtherInstancesOnSharedConnector", func(t *testing.T) {
+		_, _, _, cache := createCacheTestFixtures(ctx, []upsTestCfg{
+			{id: "upsA", syncing: common.EvmSyncingStateNotSyncing, finBn: 10, lstBn: 15},
+		})
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		connector, err := data.NewRedisConnector(ctx, &log.Logger, "shared", &common.RedisConnectorConfig{
+			URI:         "redis://" + m.Addr(),
+			InitTimeout: common.Duration(2 * time.Second),
+			GetTimeout:  common.Duration(2 * time.Second),
+			SetTimeout:  common.Duration(2 * time.Second),
+		})
+		require.NoError(t, err)
+		policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+			Network:  "evm:123",
+			Method:   "*",
+			Finality: common.DataFinalityStateUnfinalized,
+			TTL:      common.Duration(5 * time.Minute),
+		}, connector)
+		require.NoError(t, err)
+		cache.SetPolicies([]*data.CachePolicy{policy})
+
+		// Entries written by another instance are not known to this instance's tracker
+		ttl := 5 * time.Minute
+		require.NoError(t, connector.SetIndexed(ctx, "evm:123:100", "kept", []byte(`"0x1"`), &ttl))
+		require.NoError(t, connector.SetIndexed(ctx, "evm:123:101", "byNumber", []byte(`"0x2"`), &ttl))
+		require.NoError(t, connector.SetIndexed(ctx, "evm:123:102", "descendant", []byte(`"0x3"`), &ttl))
+		require.NoError(t, connector.SetIndexed(ctx, "evm:123:0xaaa", "byHash", []byte(`"0x4"`), &ttl))
+
+		cache.RecordBlockHash("evm:123", 100, "0x100")
+		cache.RecordBlockHash("evm:123", 101, "0xaaa")
+		cache.RecordBlockHash("evm:123", 102, "0xccc")
+		cache.RecordBlockHash("evm:123", 101, "0xbbb")
+
+		require.Eventually(t, func() bool {
+			for pk, rk := range map[string]string{"evm:123:101": "byNumber", "evm:123:102": "descendant", "evm:123:0xaaa": "byHash"} {
+				if _, err := connector.Get(ctx, data.ConnectorMainIndex, pk, rk, nil); err == nil {
+					return false
+				}
+			}
+			return true
+		}, 2*time.Second, 20*time.Millisecond)
+		_, err = connector.Get(ctx, data.ConnectorReverseIndex, "evm:123:*", "byNumber", nil)
+		assert.Error(t, err, "reverse index of deleted entries must be removed")
+		value, err := connector.Get(ctx, data.ConnectorMainIndex, "evm:123:100", "kept", nil)
+		require.NoError(t, err)
+		assert.Equal(t, `"0x1"`, string(value))
+	})
+
+	t.Run("IndexesUnfinalizedEntriesSoThatPartitionsAreDeletedWithoutScanning", func(t *testing.T) {
+		_, mockNetwork, mockUpstreams, cache := createCacheTestFixtures(ctx, []upsTestCfg{
+			{id: "upsA", syncing: common.EvmSyncingStateNotSyncing, finBn: 10, lstBn: 15},
+		})
+		m, err := miniredis.Run()
+		require.NoError(t, err)
+		defer m.Close()
+		connector, err := data.NewRedisConnector(ctx, &log.Logger, "shared", &common.RedisConnectorConfig{
+			URI:         "redis://" + m.Addr(),
+			InitTimeout: common.Duration(2 * time.Second),
+			GetTimeout:  common.Duration(2 * time.Second),
+			SetTimeout:  common.Duration(2 * time.Second),
+		})
+		require.NoError(t, err)
+		policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+			Network:  "evm:123",
+			Method:   "*",
+			Finality: common.DataFinalityStateUnfinalized,
+			TTL:      common.Duration(5 * time.Minute),
+		}, connector)
+		require.NoError(t, err)
+		cache.SetPolicies([]*data.CachePolicy{policy})
+
+		newSetRequest(t, mockNetwork, mockUpstreams[0], cache,
+			`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x11117781"],"id":1}`,
+			`{"result":"0x1"}`)
+		assert.True(t, m.Exists("pidx#evm:123:286357377"), "unfinalized entries must be indexed by partition")
+
+		cache.RecordBlockHash("evm:123", 0x11117781, "0xaaa")
+		cache.RecordBlockHash("evm:123", 0x11117781, "0xbbb")
+		require.Eventually(t, func() bool {
+			return !m.Exists("pidx#evm:123:286357377") && len(m.Keys()) == 0
+		}, 2*time.Second, 20*time.Millisecond, "keys left: %v", m.Keys())
+	})
+
+	t.Run("IgnoresFinalizedBlocks", func(t *testing.T) {
+		mockConnectors, mockNetwork, mockUpstreams, cache := createCacheTestFixtures(ctx, []upsTestCfg{
+			{id: "upsA", syncing: common.EvmSyncingStateNotSyncing, finBn: 10, lstBn: 15},
+		})
+		policy, err := data.NewCachePolicy(&common.CachePolicyConfig{
+			Network:  "evm:123",
+			Method:   "*",
+			Finality: common.DataFinalityStateFinalized,
+		}, mockConnectors[0])
+		require.NoError(t, err)
+		cache.SetPolicies([]*data.CachePolicy{policy})
+
+		mockConnectors[0].On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		newSetRequest(t, mockNetwork, mockUpstreams[0], cache,
+			`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x5"],"id":1}`,
+			`{"result":"0x1"}`)
+		cache.RecordBlockHash("evm:123", 5, "0xaaa")
+		cache.RecordBlockHash("evm:123", 5, "0xbbb")
+
+		time.Sleep(100 * time.Millisecond)
+		mockConnectors[0].AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestEvmJsonRpcCache_MatchParams(t *testing.T) {
 	mockConnector := data.NewMockConnector("test")
 
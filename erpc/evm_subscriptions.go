@@ -0,0 +1,641 @@
+package erpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/architecture/evm"
+	"github.com/erpc/erpc/clients"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/upstream"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+)
+
+const (
+	EvmSubscriptionNewHeads = "newHeads"
+	EvmSubscriptionLogs     = "logs"
+
+	// When we fall behind (e.g. after a polling gap) only the most recent blocks are
+	// backfilled, subscribers are not expected to rely on newHeads for full history.
+	evmSubscriptionMaxBackfill = 16
+	// Number of recent block hashes remembered to de-duplicate heads coming from
+	// multiple sources (upstream subscriptions and polling) and to detect re-orgs.
+	evmSubscriptionRecentHeads = 128
+)
+
+type EvmSubscriptionNotifyFunc func(subscriptionId string, result []byte)
+
+// EvmSubscriptionManager fans out eth_subscribe notifications of a single network to
+// any number of clients. New heads are received from a small number of upstream
+// websocket subscriptions (when such upstreams exist). While none of them is live, heads
+// are synthesized from the latest block tracked by the upstreams' state pollers, so an
+// upstream failing or going stale never stops notifications.
+// Logs subscriptions are synthesized from each new head via eth_getLogs.
+type EvmSubscriptionManager struct {
+	appCtx  context.Context
+	logger  *zerolog.Logger
+	network *Network
+	cfg     *common.WebSocketServerConfig
+
+	mu          sync.Mutex
+	subscribers map[string]*evmSubscriber
+	stream      *evmHeadsStream
+}
+
+type evmSubscriber struct {
+	id        string
+	kind      string
+	logFilter map[string]interface{}
+	filterKey string
+	notify    EvmSubscriptionNotifyFunc
+}
+
+type evmHead struct {
+	number int64
+	hash   string
+	header []byte
+}
+
+// evmHeadsStream lives as long as there is at least one subscriber on the network.
+type evmHeadsStream struct {
+	manager *EvmSubscriptionManager
+	ctx     context.Context
+	cancel  context.CancelFunc
+	heads   chan *evmHead
+
+	mu         sync.Mutex
+	lastNumber int64
+	recent     map[int64]string
+
+	// liveUpstreams is the number of upstream subscriptions currently delivering heads
+	liveUpstreams atomic.Int32
+}
+
+func NewEvmSubscriptionManager(
+	appCtx context.Context,
+	logger *zerolog.Logger,
+	network *Network,
+	cfg *common.WebSocketServerConfig,
+) *EvmSubscriptionManager {
+	lg := logger.With().Str("component", "subscriptions").Str("networkId", network.Id()).Logger()
+	return &EvmSubscriptionManager{
+		appCtx:      appCtx,
+		logger:      &lg,
+		network:     network,
+		cfg:         cfg,
+		subscribers: make(map[string]*evmSubscriber),
+	}
+}
+
+// Subscribe registers a new subscription and returns its id. The notify function
+// must never block as it is called from the shared dispatch loop of the network.
+func (m *EvmSubscriptionManager) Subscribe(kind string, logFilter map[string]interface{}, notify EvmSubscriptionNotifyFunc) (string, error) {
+	sub := &evmSubscriber{
+		kind:   kind,
+		notify: notify,
+	}
+	switch kind {
+	case EvmSubscriptionNewHeads:
+	case EvmSubscriptionLogs:
+		filter, key, err := normalizeEvmLogFilter(logFilter)
+		if err != nil {
+			return "", err
+		}
+		sub.logFilter = filter
+		sub.filterKey = key
+	default:
+		return "", common.NewErrJsonRpcExceptionInternal(
+			0,
+			common.JsonRpcErrorInvalidArgument,
+			fmt.Sprintf("unsupported subscription type: %s", kind),
+			nil,
+			nil,
+		)
+	}
+
+	id, err := newEvmRandomId()
+	if err != nil {
+		return "", err
+	}
+	sub.id = id
+
+	m.mu.Lock()
+	m.subscribers[id] = sub
+	if m.stream == nil {
+		m.stream = m.startStream()
+	}
+	m.mu.Unlock()
+
+	telemetry.MetricWebSocketSubscriptionsActive.WithLabelValues(m.network.ProjectId(), m.network.Label(), kind).Inc()
+	m.logger.Debug().Str("subscriptionId", id).Str("kind", kind).Msg("added new subscription")
+
+	return id, nil
+}
+
+// Unsubscribe removes a subscription and returns false if it did not exist.
+// When the last subscription is removed, upstream subscriptions and polling are stopped.
+func (m *EvmSubscriptionManager) Unsubscribe(id string) bool {
+	m.mu.Lock()
+	sub, ok := m.subscribers[id]
+	if !ok {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.subscribers, id)
+	if len(m.subscribers) == 0 && m.stream != nil {
+		m.stream.cancel()
+		m.stream = nil
+	}
+	m.mu.Unlock()
+
+	telemetry.MetricWebSocketSubscriptionsActive.WithLabelValues(m.network.ProjectId(), m.network.Label(), sub.kind).Dec()
+	m.logger.Debug().Str("subscriptionId", id).Str("kind", sub.kind).Msg("removed subscription")
+
+	return true
+}
+
+func (m *EvmSubscriptionManager) startStream() *evmHeadsStream {
+	ctx, cancel := context.WithCancel(m.appCtx)
+	st := &evmHeadsStream{
+		manager: m,
+		ctx:     ctx,
+		cancel:  cancel,
+		heads:   make(chan *evmHead, 256),
+		recent:  make(map[int64]string),
+	}
+
+	go st.dispatchLoop()
+	go st.pollLoop()
+
+	followed := 0
+	for _, ups := range m.network.upstreamsRegistry.GetNetworkUpstreams(ctx, m.network.networkId) {
+		if followed >= m.cfg.MaxUpstreamSubscriptions {
+			break
+		}
+		if _, ok := ups.Client.(clients.WsJsonRpcClient); ok {
+			followed++
+			go st.followUpstream(ups)
+		}
+	}
+
+	m.logger.Info().Int("upstreamSubscriptions", followed).Msg("started new heads stream for subscriptions")
+
+	return st
+}
+
+func (m *EvmSubscriptionManager) snapshot() []*evmSubscriber {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subs := make([]*evmSubscriber, 0, len(m.subscribers))
+	for _, sub := range m.subscribers {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func (m *EvmSubscriptionManager) forward(ctx context.Context, jrq *common.JsonRpcRequest, skipCacheRead bool) ([]byte, error) {
+	return forwardInternalRequest(ctx, m.network, jrq, skipCacheRead)
+}
+
+// forwardInternalRequest sends a request originated by eRPC itself through the network so that it
+// benefits from failover, retries, integrity checks and caching like any other request.
+func forwardInternalRequest(ctx context.Context, network *Network, jrq *common.JsonRpcRequest, skipCacheRead bool) ([]byte, error) {
+	jrq.ID = util.RandomID()
+	nq := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	nq.SetNetwork(network)
+	nq.ApplyDirectiveDefaults(network.Config().DirectiveDefaults)
+	if skipCacheRead {
+		dr := &common.RequestDirectives{}
+		if nq.Directives() != nil {
+			dr = nq.Directives().Clone()
+		}
+		dr.SkipCacheRead = true
+		nq.SetDirectives(dr)
+	}
+
+	resp, err := network.Forward(ctx, nq)
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err != nil {
+		return nil, err
+	}
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if jrr == nil {
+		return nil, fmt.Errorf("nil json-rpc response")
+	}
+	if jrr.Error != nil {
+		return nil, jrr.Error
+	}
+
+	// Copy to detach from the underlying response buffers which are released above
+	return append([]byte(nil), jrr.GetResultBytes()...), nil
+}
+
+func (m *EvmSubscriptionManager) fetchHead(ctx context.Context, blockNumber int64) (*evmHead, error) {
+	jrq, err := evm.BuildGetBlockByNumberRequest(blockNumber, false)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.forward(ctx, jrq, false)
+	if err != nil {
+		return nil, err
+	}
+	return parseEvmHead(result)
+}
+
+func (m *EvmSubscriptionManager) fetchLogs(ctx context.Context, blockHash string, filter map[string]interface{}) ([]json.RawMessage, error) {
+	query := map[string]interface{}{
+		"blockHash": blockHash,
+	}
+	for k, v := range filter {
+		query[k] = v
+	}
+	result, err := m.forward(ctx, common.NewJsonRpcRequest("eth_getLogs", []interface{}{query}), false)
+	if err != nil {
+		return nil, err
+	}
+	var logs []json.RawMessage
+	if err := common.SonicCfg.Unmarshal(result, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// push de-duplicates heads coming from different sources. A head is emitted when it is
+// higher than anything seen so far, or when its hash differs from what we previously
+// saw for the same height (i.e. a re-org).
+func (st *evmHeadsStream) push(head *evmHead) {
+	st.mu.Lock()
+	if prev, ok := st.recent[head.number]; ok && prev == head.hash {
+		st.mu.Unlock()
+		return
+	}
+	if st.lastNumber > 0 && head.number <= st.lastNumber-evmSubscriptionRecentHeads {
+		st.mu.Unlock()
+		return
+	}
+	st.recent[head.number] = head.hash
+	if head.number > st.lastNumber {
+		st.lastNumber = head.number
+		for n := range st.recent {
+			if n <= st.lastNumber-evmSubscriptionRecentHeads {
+				delete(st.recent, n)
+			}
+		}
+	}
+	st.mu.Unlock()
+
+	select {
+	case st.heads <- head:
+	default:
+		st.manager.logger.Warn().Int64("blockNumber", head.number).Msg("dropping new head as subscription dispatch is lagging behind")
+	}
+}
+
+func (st *evmHeadsStream) latestNumber() int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.lastNumber
+}
+
+func (st *evmHeadsStream) dispatchLoop() {
+	defer func() {
+		if rec := recover(); rec != nil {
+			telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+				"subscriptions-dispatch",
+				fmt.Sprintf("network:%s", st.manager.network.Id()),
+				common.ErrorFingerprint(rec),
+			).Inc()
+			st.manager.logger.Error().
+				Interface("panic", rec).
+				Str("stack", string(debug.Stack())).
+				Msgf("unexpected panic on subscriptions dispatch loop")
+		}
+	}()
+
+	for {
+		select {
+		case <-st.ctx.Done():
+			return
+		case head := <-st.heads:
+			st.dispatch(head)
+		}
+	}
+}
+
+func (st *evmHeadsStream) dispatch(head *evmHead) {
+	m := st.manager
+	subs := m.snapshot()
+
+	filters := make(map[string][]*evmSubscriber)
+	for _, sub := range subs {
+		switch sub.kind {
+		case EvmSubscriptionNewHeads:
+			sub.notify(sub.id, head.header)
+		case EvmSubscriptionLogs:
+			filters[sub.filterKey] = append(filters[sub.filterKey], sub)
+		}
+	}
+
+	// Subscribers with identical filters share a single eth_getLogs call per block
+	for _, group := range filters {
+		ctx, cancel := context.WithTimeout(st.ctx, 30*time.Second)
+		logs, err := m.fetchLogs(ctx, head.hash, group[0].logFilter)
+		cancel()
+		if err != nil {
+			m.logger.Warn().Err(err).Int64("blockNumber", head.number).Str("blockHash", head.hash).Msg("failed to fetch logs for subscriptions")
+			continue
+		}
+		for _, lg := range logs {
+			for _, sub := range group {
+				sub.notify(sub.id, lg)
+			}
+		}
+	}
+
+	telemetry.MetricWebSocketSubscriptionHeadsTotal.WithLabelValues(m.network.ProjectId(), m.network.Label()).Inc()
+}
+
+// pollLoop synthesizes heads while no upstream subscription is live (e.g. only http upstreams exist, or
+// websocket upstreams fail or go stale). It follows the latest block tracked by the state pollers of the
+// upstreams, woken up by their block head events and checked every HeadsPollInterval in case one was missed,
+// so no request is sent unless a new block has to be fetched.
+func (st *evmHeadsStream) pollLoop() {
+	m := st.manager
+	interval := m.cfg.HeadsPollInterval.Duration()
+	if interval <= 0 {
+		return
+	}
+
+	advanced := make(chan struct{}, 1)
+	if m.network.metricsTracker != nil {
+		unsubscribe := m.network.metricsTracker.OnBlockHeadEvent(m.network.networkId, func(event *health.BlockHeadEvent) {
+			if event.Type != health.BlockHeadEventLatest {
+				return
+			}
+			select {
+			case advanced <- struct{}{}:
+			default:
+			}
+		})
+		defer unsubscribe()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if st.liveUpstreams.Load() == 0 {
+			st.poll()
+		}
+		select {
+		case <-st.ctx.Done():
+			return
+		case <-advanced:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (st *evmHeadsStream) poll() {
+	m := st.manager
+	ctx, cancel := context.WithTimeout(st.ctx, 30*time.Second)
+	defer cancel()
+
+	latest := m.network.EvmHighestLatestBlockNumber(ctx)
+	last := st.latestNumber()
+	if latest <= last {
+		return
+	}
+	from := last + 1
+	if last == 0 {
+		from = latest
+	} else if latest-last > evmSubscriptionMaxBackfill {
+		from = latest - evmSubscriptionMaxBackfill + 1
+	}
+
+	for bn := from; bn <= latest; bn++ {
+		head, err := m.fetchHead(ctx, bn)
+		if err != nil {
+			if st.ctx.Err() == nil {
+				m.logger.Warn().Err(err).Int64("blockNumber", bn).Msg("failed to fetch new head for subscriptions")
+			}
+			return
+		}
+		st.push(head)
+	}
+}
+
+// followUpstream keeps a newHeads subscription open on a websocket upstream. If the
+// upstream fails or stops sending heads it is re-subscribed with a backoff, while
+// other sources keep feeding the stream in the meantime. The upstream subscription
+// is always unsubscribed before re-subscribing or when the stream is stopped.
+func (st *evmHeadsStream) followUpstream(ups *upstream.Upstream) {
+	m := st.manager
+	lg := m.logger.With().Str("upstreamId", ups.Id()).Logger()
+
+	staleAfter := 10 * m.cfg.HeadsPollInterval.Duration()
+	if staleAfter < 30*time.Second {
+		staleAfter = 30 * time.Second
+	}
+
+	attempt := 0
+	for st.ctx.Err() == nil {
+		// Each upstream subscription gets its own handler so that late notifications of a previous one are never consumed
+		notifications := make(chan []byte, 64)
+		ctx := clients.WithWsSubscriptionHandler(st.ctx, func(result []byte) {
+			select {
+			case notifications <- result:
+			default:
+			}
+		})
+		subId, err := st.upstreamCall(ctx, ups, "eth_subscribe", []interface{}{EvmSubscriptionNewHeads})
+		if err != nil {
+			attempt++
+			delay := time.Duration(1<<min(attempt, 5)) * time.Second
+			lg.Warn().Err(err).Int("attempt", attempt).Dur("retryIn", delay).Msg("failed to subscribe to new heads on upstream")
+			select {
+			case <-st.ctx.Done():
+				return
+			case <-time.After(delay):
+				continue
+			}
+		}
+		attempt = 0
+		lg.Debug().Str("upstreamSubscriptionId", subId).Msg("subscribed to new heads on upstream")
+
+		alive := st.consumeUpstream(&lg, notifications, staleAfter)
+
+		// The stream context may already be cancelled, so unsubscribing relies on the app context instead
+		if _, err := st.upstreamCall(m.appCtx, ups, "eth_unsubscribe", []interface{}{subId}); err != nil {
+			lg.Debug().Err(err).Str("upstreamSubscriptionId", subId).Msg("failed to unsubscribe from new heads on upstream")
+		} else {
+			lg.Debug().Str("upstreamSubscriptionId", subId).Msg("unsubscribed from new heads on upstream")
+		}
+		if !alive {
+			return
+		}
+	}
+}
+
+// consumeUpstream pushes heads of an upstream subscription into the stream until
+// it goes stale. Returns false when the stream is stopped.
+func (st *evmHeadsStream) consumeUpstream(lg *zerolog.Logger, notifications chan []byte, staleAfter time.Duration) bool {
+	timer := time.NewTimer(staleAfter)
+	defer timer.Stop()
+
+	// Polling is paused from the first head received until the subscription goes stale
+	live := false
+	defer func() {
+		if live {
+			st.liveUpstreams.Add(-1)
+		}
+	}()
+
+	for {
+		select {
+		case <-st.ctx.Done():
+			return false
+		case <-timer.C:
+			lg.Warn().Dur("staleAfter", staleAfter).Msg("no new heads received from upstream subscription, re-subscribing")
+			return true
+		case result := <-notifications:
+			head, err := parseEvmHead(result)
+			if err != nil {
+				lg.Debug().Err(err).Msg("ignoring invalid new head received from upstream subscription")
+				continue
+			}
+			st.push(head)
+			if !live {
+				live = true
+				st.liveUpstreams.Add(1)
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(staleAfter)
+		}
+	}
+}
+
+func (st *evmHeadsStream) upstreamCall(ctx context.Context, ups *upstream.Upstream, method string, params []interface{}) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	jrq := common.NewJsonRpcRequest(method, params)
+	jrq.ID = util.RandomID()
+	nq := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	resp, err := ups.Forward(ctx, nq, true)
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err != nil {
+		return "", err
+	}
+	jrr, err := resp.JsonRpcResponse(ctx)
+	if err != nil {
+		return "", err
+	}
+	if jrr == nil {
+		return "", fmt.Errorf("nil json-rpc response")
+	}
+	if jrr.Error != nil {
+		return "", jrr.Error
+	}
+	var result interface{}
+	if err := common.SonicCfg.Unmarshal(jrr.GetResultBytes(), &result); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v", result), nil
+}
+
+// parseEvmHead extracts number and hash of a block and strips the fields which are
+// not part of a header (as emitted by newHeads subscriptions).
+func parseEvmHead(block []byte) (*evmHead, error) {
+	var fields map[string]json.RawMessage
+	if err := common.SonicCfg.Unmarshal(block, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, errors.New("block is null")
+	}
+	var number, hash string
+	if err := common.SonicCfg.Unmarshal(fields["number"], &number); err != nil {
+		return nil, fmt.Errorf("invalid block number: %w", err)
+	}
+	if err := common.SonicCfg.Unmarshal(fields["hash"], &hash); err != nil {
+		return nil, fmt.Errorf("invalid block hash: %w", err)
+	}
+	bn, err := common.HexToInt64(number)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range []string{"transactions", "uncles", "size", "totalDifficulty", "withdrawals"} {
+		delete(fields, f)
+	}
+	header, err := common.SonicCfg.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evmHead{
+		number: bn,
+		hash:   hash,
+		header: header,
+	}, nil
+}
+
+// normalizeEvmLogFilter only keeps the fields that make sense for a per-block logs
+// query and returns a key so that identical filters share the same eth_getLogs call.
+func normalizeEvmLogFilter(filter map[string]interface{}) (map[string]interface{}, string, error) {
+	normalized := make(map[string]interface{})
+	for k, v := range filter {
+		switch k {
+		case "address", "topics":
+			if v != nil {
+				normalized[k] = v
+			}
+		case "fromBlock", "toBlock", "blockHash":
+			// Ignored as subscriptions only follow new blocks
+		default:
+			return nil, "", common.NewErrJsonRpcExceptionInternal(
+				0,
+				common.JsonRpcErrorInvalidArgument,
+				fmt.Sprintf("unsupported logs filter field: %s", k),
+				nil,
+				nil,
+			)
+		}
+	}
+	// encoding/json sorts map keys which makes the key stable for equivalent filters
+	key, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, "", err
+	}
+	return normalized, string(key), nil
+}
+
+// newEvmRandomId returns a random 128-bit hex id, similar to ids nodes issue for subscriptions and filters.
+func newEvmRandomId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,355 @@
+package erpc
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/erpc/erpc/util"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	EvmTxBroadcastOutcomeSuccess      = "success"
+	EvmTxBroadcastOutcomeAlreadyKnown = "already_known"
+	EvmTxBroadcastOutcomeNonceUsed    = "nonce_used"
+	EvmTxBroadcastOutcomeError        = "error"
+	EvmTxBroadcastOutcomeDeduplicated = "deduplicated"
+
+	// Broadcasts keep going after the first success (and after the client is gone) so that the
+	// transaction reaches every selected upstream, this bounds how long that can take.
+	evmTxBroadcastTimeout = 30 * time.Second
+)
+
+type evmTxBroadcastEntry struct {
+	done      chan struct{}
+	upstream  common.Upstream
+	err       error
+	expiresAt time.Time
+}
+
+func (e *evmTxBroadcastEntry) isDone() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+type evmTxBroadcastResult struct {
+	upstream common.Upstream
+	outcome  string
+	err      error
+}
+
+// EvmTxBroadcaster sends eth_sendRawTransaction to several upstreams in parallel and answers with
+// the tx hash as soon as one of them accepts it. Repeated submissions of the same signed payload
+// within the dedup window are answered from memory without being broadcasted again.
+type EvmTxBroadcaster struct {
+	logger  *zerolog.Logger
+	network *Network
+	cfg     *common.EvmTxBroadcastConfig
+
+	recent    sync.Map // map[string]*evmTxBroadcastEntry keyed by tx hash
+	lastSweep atomic.Int64
+}
+
+func NewEvmTxBroadcaster(logger *zerolog.Logger, network *Network, cfg *common.EvmTxBroadcastConfig) *EvmTxBroadcaster {
+	lg := logger.With().Str("component", "evmTxBroadcast").Str("networkId", network.Id()).Logger()
+	return &EvmTxBroadcaster{
+		logger:  &lg,
+		network: network,
+		cfg:     cfg,
+	}
+}
+
+// Handle broadcasts the raw transaction to the upstreams selected for the request. It returns handled=false
+// when the payload cannot be decoded or no upstream is eligible, so that the request is forwarded as usual.
+func (b *EvmTxBroadcaster) Handle(ctx context.Context, req *common.NormalizedRequest, upsList []common.Upstream) (handled bool, resp *common.NormalizedResponse, err error) {
+	ctx, span := common.StartDetailSpan(ctx, "EvmTxBroadcaster.Handle")
+	defer span.End()
+
+	txHash, err := evmRawTransactionHash(ctx, req)
+	if err != nil {
+		b.logger.Debug().Err(err).Msg("could not compute tx hash, forwarding raw transaction as usual")
+		return false, nil, nil
+	}
+	targets := b.selectUpstreams(req, upsList)
+	if len(targets) == 0 {
+		return false, nil, nil
+	}
+
+	for {
+		entry := &evmTxBroadcastEntry{done: make(chan struct{})}
+		v, loaded := b.recent.LoadOrStore(txHash, entry)
+		if !loaded {
+			b.sweep()
+			b.broadcast(ctx, req, txHash, targets, entry)
+			resp, err := b.waitForEntry(ctx, req, txHash, entry, false)
+			return true, resp, err
+		}
+
+		prev := v.(*evmTxBroadcastEntry)
+		if prev.isDone() && time.Now().After(prev.expiresAt) {
+			b.recent.CompareAndDelete(txHash, prev)
+			continue
+		}
+		resp, err := b.waitForEntry(ctx, req, txHash, prev, true)
+		return true, resp, err
+	}
+}
+
+func (b *EvmTxBroadcaster) waitForEntry(ctx context.Context, req *common.NormalizedRequest, txHash string, entry *evmTxBroadcastEntry, deduplicated bool) (*common.NormalizedResponse, error) {
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	if deduplicated {
+		telemetry.MetricNetworkTxBroadcastTotal.WithLabelValues(
+			b.network.ProjectId(), b.network.Label(), entry.upstream.Id(), EvmTxBroadcastOutcomeDeduplicated,
+		).Inc()
+		b.logger.Debug().Str("txHash", txHash).Msg("raw transaction already broadcasted recently, skipping")
+	}
+
+	id := req.ID()
+	if id == nil {
+		id = util.RandomID()
+	}
+	jrr, err := common.NewJsonRpcResponse(id, txHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp := common.NewNormalizedResponse().WithRequest(req).WithJsonRpcResponse(jrr)
+	resp.SetUpstream(entry.upstream)
+	req.SetLastUpstream(entry.upstream)
+	return resp, nil
+}
+
+// broadcast sends the transaction to all targets and completes the entry as soon as one of them accepts it,
+// or when all of them failed.
+func (b *EvmTxBroadcaster) broadcast(ctx context.Context, req *common.NormalizedRequest, txHash string, targets []common.Upstream, entry *evmTxBroadcastEntry) {
+	startedAt := time.Now()
+	bctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), evmTxBroadcastTimeout)
+
+	results := make(chan *evmTxBroadcastResult, len(targets))
+	for _, u := range targets {
+		go func() {
+			results <- b.sendTo(bctx, req, txHash, u)
+		}()
+	}
+
+	go func() {
+		defer cancel()
+		errs := &sync.Map{}
+		accepted := false
+		for range targets {
+			r := <-results
+			telemetry.MetricNetworkTxBroadcastTotal.WithLabelValues(
+				b.network.ProjectId(), b.network.Label(), r.upstream.Id(), r.outcome,
+			).Inc()
+			if r.err != nil {
+				b.logger.Debug().Err(r.err).Str("txHash", txHash).Str("upstreamId", r.upstream.Id()).Msg("upstream rejected raw transaction")
+				errs.Store(r.upstream, r.err)
+				continue
+			}
+			b.logger.Debug().Str("txHash", txHash).Str("upstreamId", r.upstream.Id()).Str("outcome", r.outcome).Msg("upstream accepted raw transaction")
+			if !accepted {
+				accepted = true
+				entry.upstream = r.upstream
+				entry.expiresAt = time.Now().Add(b.cfg.DedupWindow.Duration())
+				close(entry.done)
+			}
+		}
+		if !accepted {
+			entry.err = common.NewErrUpstreamsExhausted(
+				req,
+				errs,
+				b.network.ProjectId(),
+				b.network.Id(),
+				"eth_sendRawTransaction",
+				time.Since(startedAt),
+				len(targets),
+				0,
+				0,
+				len(targets),
+			)
+			close(entry.done)
+			// Failed submissions are not remembered so that clients can retry right away
+			b.recent.CompareAndDelete(txHash, entry)
+		}
+	}()
+}
+
+func (b *EvmTxBroadcaster) sendTo(ctx context.Context, req *common.NormalizedRequest, txHash string, u common.Upstream) *evmTxBroadcastResult {
+	result := &evmTxBroadcastResult{upstream: u}
+
+	nq, err := b.cloneRequest(ctx, req)
+	if err != nil {
+		result.outcome, result.err = EvmTxBroadcastOutcomeError, err
+		return result
+	}
+	resp, err := b.network.doForward(ctx, u, nq, false)
+	if resp != nil {
+		resp.Release()
+	}
+
+	switch {
+	case err == nil:
+		result.outcome = EvmTxBroadcastOutcomeSuccess
+	case isEvmTxAlreadyKnownError(err):
+		result.outcome = EvmTxBroadcastOutcomeAlreadyKnown
+	case isEvmTxNonceTooLowError(err) && b.isKnownByUpstream(ctx, u, txHash):
+		// Nonce is used by this very transaction (e.g. it was already mined), rather than a conflicting one
+		result.outcome = EvmTxBroadcastOutcomeNonceUsed
+	default:
+		result.outcome, result.err = EvmTxBroadcastOutcomeError, err
+	}
+	return result
+}
+
+func (b *EvmTxBroadcaster) cloneRequest(ctx context.Context, req *common.NormalizedRequest) (*common.NormalizedRequest, error) {
+	var nq *common.NormalizedRequest
+	if body := req.Body(); body != nil {
+		nq = common.NewNormalizedRequest(append([]byte(nil), body...))
+	} else {
+		jrq, err := req.JsonRpcRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		body, err := common.SonicCfg.Marshal(jrq)
+		if err != nil {
+			return nil, err
+		}
+		nq = common.NewNormalizedRequest(body)
+	}
+	if dirs := req.Directives(); dirs != nil {
+		nq.SetDirectives(dirs.Clone())
+	}
+	nq.CopyHttpContextFrom(req)
+	nq.SetNetwork(b.network)
+	return nq, nil
+}
+
+func (b *EvmTxBroadcaster) isKnownByUpstream(ctx context.Context, u common.Upstream, txHash string) bool {
+	jrq := common.NewJsonRpcRequest("eth_getTransactionByHash", []interface{}{txHash})
+	jrq.ID = util.RandomID()
+	nq := common.NewNormalizedRequestFromJsonRpcRequest(jrq)
+	nq.SetNetwork(b.network)
+	resp, err := b.network.doForward(ctx, u, nq, true)
+	if resp != nil {
+		defer resp.Release()
+	}
+	if err != nil || resp == nil {
+		return false
+	}
+	return !resp.IsResultEmptyish(ctx)
+}
+
+func (b *EvmTxBroadcaster) selectUpstreams(req *common.NormalizedRequest, upsList []common.Upstream) []common.Upstream {
+	useUpstream := ""
+	if dr := req.Directives(); dr != nil {
+		useUpstream = dr.UseUpstream
+	}
+
+	targets := make([]common.Upstream, 0, len(upsList))
+	for _, u := range upsList {
+		if useUpstream != "" {
+			if match, _ := common.WildcardMatch(useUpstream, u.Id()); !match {
+				continue
+			}
+		}
+		if len(b.cfg.Upstreams) > 0 {
+			matched := false
+			for _, pattern := range b.cfg.Upstreams {
+				if match, _ := common.WildcardMatch(pattern, u.Id()); match {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		targets = append(targets, u)
+		if b.cfg.MaxUpstreams > 0 && len(targets) >= b.cfg.MaxUpstreams {
+			break
+		}
+	}
+	return targets
+}
+
+// sweep drops expired entries, at most once per dedup window.
+func (b *EvmTxBroadcaster) sweep() {
+	now := time.Now()
+	last := b.lastSweep.Load()
+	if now.UnixNano()-last < int64(b.cfg.DedupWindow.Duration()) || !b.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	b.recent.Range(func(key, value any) bool {
+		entry := value.(*evmTxBroadcastEntry)
+		if entry.isDone() && now.After(entry.expiresAt) {
+			b.recent.CompareAndDelete(key, entry)
+		}
+		return true
+	})
+}
+
+// evmRawTransactionHash computes the hash of the signed transaction passed to eth_sendRawTransaction,
+// which is the keccak256 of its (typed envelope) bytes.
+func evmRawTransactionHash(ctx context.Context, req *common.NormalizedRequest) (string, error) {
+	jrq, err := req.JsonRpcRequest(ctx)
+	if err != nil {
+		return "", err
+	}
+	jrq.RLock()
+	defer jrq.RUnlock()
+	if len(jrq.Params) < 1 {
+		return "", fmt.Errorf("missing raw transaction param")
+	}
+	raw, ok := jrq.Params[0].(string)
+	if !ok {
+		return "", fmt.Errorf("raw transaction param must be a hex string")
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(raw, "0x"), "0X"))
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("raw transaction is empty")
+	}
+	hw := sha3.NewLegacyKeccak256()
+	hw.Write(data)
+	return "0x" + hex.EncodeToString(hw.Sum(nil)), nil
+}
+
+func isEvmTxAlreadyKnownError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "alreadyknown") ||
+		strings.Contains(msg, "already_known") ||
+		strings.Contains(msg, "known transaction") ||
+		strings.Contains(msg, "already imported") ||
+		strings.Contains(msg, "already in mempool") ||
+		strings.Contains(msg, "tx already exists")
+}
+
+func isEvmTxNonceTooLowError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") ||
+		strings.Contains(msg, "nonce is too low") ||
+		strings.Contains(msg, "oldnonce") ||
+		strings.Contains(msg, "nonce has already been used")
+}
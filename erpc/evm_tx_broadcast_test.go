@@ -0,0 +1,141 @@
+package erpc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func createTxBroadcastTestConfig() *common.Config {
+	cfg := createWebSocketTestConfig()
+	cfg.Projects[0].Networks[0].Evm.TxBroadcast = &common.EvmTxBroadcastConfig{
+		Enabled: util.BoolPtr(true),
+	}
+	cfg.Projects[0].Upstreams = append(cfg.Projects[0].Upstreams, &common.UpstreamConfig{
+		Id:       "rpc2",
+		Type:     common.UpstreamTypeEvm,
+		Endpoint: "http://rpc2.localhost",
+		Evm: &common.EvmUpstreamConfig{
+			ChainId: 123,
+		},
+	})
+	return cfg
+}
+
+func mockRawTransactionReply(host, rawTx, reply string) gock.Mock {
+	return gock.New(host).
+		Post("").
+		Times(1).
+		Filter(func(request *http.Request) bool {
+			body := util.SafeReadBody(request)
+			return strings.Contains(body, "eth_sendRawTransaction") && strings.Contains(body, rawTx)
+		}).
+		Reply(200).
+		JSON([]byte(reply)).
+		Mock
+}
+
+func TestEvmTxBroadcaster(t *testing.T) {
+	rawTx := "0x02f86b0101843b9aca00850c92a69c0082520894"
+	rawBytes, _ := hex.DecodeString(strings.TrimPrefix(rawTx, "0x"))
+	hw := sha3.NewLegacyKeccak256()
+	hw.Write(rawBytes)
+	txHash := "0x" + hex.EncodeToString(hw.Sum(nil))
+
+	call := func(t *testing.T, sendRequest func(string, map[string]string, map[string]string) (int, map[string]string, string), id int) map[string]interface{} {
+		status, _, respBody := sendRequest(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"eth_sendRawTransaction","params":["%s"]}`, id, rawTx), nil, nil)
+		var resp map[string]interface{}
+		require.NoError(t, sonic.UnmarshalString(respBody, &resp), respBody)
+		resp["status"] = status
+		return resp
+	}
+
+	t.Run("TreatsAlreadyKnownAsSuccessAndDeduplicatesResubmissions", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		rpc1Mock := mockRawTransactionReply("http://rpc1.localhost", rawTx, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"already known"}}`)
+		rpc2Mock := mockRawTransactionReply("http://rpc2.localhost", rawTx, `{"jsonrpc":"2.0","id":1,"result":"`+txHash+`"}`)
+
+		sendRequest, _, _, shutdown, _ := createServerTestFixtures(createTxBroadcastTestConfig(), t)
+		defer shutdown()
+
+		resp := call(t, sendRequest, 1)
+		assert.Equal(t, http.StatusOK, resp["status"])
+		assert.Equal(t, txHash, resp["result"])
+		assert.Equal(t, float64(1), resp["id"])
+
+		// Both upstreams received the transaction
+		require.Eventually(t, func() bool {
+			return rpc1Mock.Done() && rpc2Mock.Done()
+		}, 5*time.Second, 50*time.Millisecond)
+
+		// Resubmission is answered without reaching the upstreams (mocks above only match once)
+		resp = call(t, sendRequest, 2)
+		assert.Equal(t, http.StatusOK, resp["status"])
+		assert.Equal(t, txHash, resp["result"])
+		assert.Equal(t, float64(2), resp["id"])
+	})
+
+	t.Run("TreatsNonceTooLowAsSuccessWhenUpstreamKnowsTheTransaction", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		mockRawTransactionReply("http://rpc1.localhost", rawTx, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low: next nonce 5, tx nonce 4"}}`)
+		mockRawTransactionReply("http://rpc2.localhost", rawTx, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low: next nonce 5, tx nonce 4"}}`)
+		gock.New("http://rpc2.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_getTransactionByHash") && strings.Contains(body, txHash)
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":{"hash":"` + txHash + `","blockNumber":"0x1"}}`))
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getTransactionByHash")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+
+		sendRequest, _, _, shutdown, _ := createServerTestFixtures(createTxBroadcastTestConfig(), t)
+		defer shutdown()
+
+		resp := call(t, sendRequest, 1)
+		assert.Equal(t, http.StatusOK, resp["status"])
+		assert.Equal(t, txHash, resp["result"])
+	})
+
+	t.Run("ReturnsErrorWhenAllUpstreamsReject", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		mockRawTransactionReply("http://rpc1.localhost", rawTx, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"insufficient funds for gas * price + value"}}`)
+		mockRawTransactionReply("http://rpc2.localhost", rawTx, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"insufficient funds for gas * price + value"}}`)
+
+		sendRequest, _, _, shutdown, _ := createServerTestFixtures(createTxBroadcastTestConfig(), t)
+		defer shutdown()
+
+		resp := call(t, sendRequest, 1)
+		errObj, ok := resp["error"].(map[string]interface{})
+		require.True(t, ok, "expected error, got: %v", resp)
+		assert.Contains(t, errObj["message"], "insufficient funds")
+	})
+}
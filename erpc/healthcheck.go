@@ -82,7 +82,7 @@ func (s *HttpServer) handleHealthCheck(
 		headers := r.Header
 		queryArgs := r.URL.Query()
 
-		ap, err := auth.NewPayloadFromHttp("healthcheck", r.RemoteAddr, headers, queryArgs)
+		ap, err := auth.NewPayloadFromHttp("healthcheck", r.RemoteAddr, headers, queryArgs, r.TLS, auth.NewRequestDigest(r.Method, r.URL.Path, nil))
 		if err != nil {
 			handleErrorResponse(ctx, &logger, startedAt, nil, err, w, encoder, writeFatalError, &common.TRUE)
 			return
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"path"
@@ -44,6 +45,7 @@ type HttpServer struct {
 	healthCheckAuthRegistry *auth.AuthRegistry
 	draining                *atomic.Bool
 	gzipPool                *util.GzipReaderPool
+	evmSubscriptions        sync.Map // map[string]*EvmSubscriptionManager
 }
 
 func NewHttpServer(
@@ -95,6 +97,12 @@ func NewHttpServer(
 	// Create handler with timeout
 	handlerWithTimeout := TimeoutHandler(h, reqMaxTimeout)
 
+	// WebSocket connections and event streams are long-lived so they must bypass both gzip and the request timeout
+	if cfg.WebSocket != nil && cfg.WebSocket.Enabled != nil && *cfg.WebSocket.Enabled {
+		handlerWithTimeout = srv.createWebSocketHandler(handlerWithTimeout)
+	}
+	handlerWithTimeout = srv.createEventStreamHandler(handlerWithTimeout)
+
 	// Create IPv4 server if configured
 	if cfg.ListenV4 != nil && *cfg.ListenV4 {
 		srv.serverV4 = &http.Server{
@@ -146,32 +154,10 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		encoder := common.SonicCfg.NewEncoder(w)
 		encoder.SetEscapeHTML(false)
 
-		// Get host without port number
-		host := r.Host
-		if colonIndex := strings.Index(host, ":"); colonIndex != -1 {
-			host = host[:colonIndex]
-		}
-
-		var projectId, architecture, chainId string
 		var isAdmin, isHealthCheck bool
 		var err error
 
-		// Check aliasing rules
-		if s.serverCfg.Aliasing != nil {
-			for _, rule := range s.serverCfg.Aliasing.Rules {
-				matched, err := common.WildcardMatch(rule.MatchDomain, host)
-				if err != nil {
-					s.logger.Error().Err(err).Interface("rule", rule).Msg("failed to match aliasing rule")
-					continue
-				}
-				if matched {
-					projectId = rule.ServeProject
-					architecture = rule.ServeArchitecture
-					chainId = rule.ServeChain
-					break
-				}
-			}
-		}
+		projectId, architecture, chainId := s.resolveAliasing(r)
 
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-ERPC-Version", common.ErpcVersion)
@@ -328,6 +314,7 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		}
 
 		responses := make([]interface{}, len(requests))
+		computeUnits := make([]uint, len(requests))
 		var wg sync.WaitGroup
 
 		headers := r.Header
@@ -335,6 +322,13 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 
 		parseRequestsSpan.End()
 
+		// Signed requests cover the whole (decompressed) body, which is only kept (and lazily hashed) for them
+		var signedBody []byte
+		if headers.Get("X-ERPC-Signature") != "" {
+			signedBody = body
+		}
+		digest := auth.NewRequestDigest(r.Method, r.URL.Path, signedBody)
+
 		// We no longer need the top-level body; drop reference early to free its backing array
 		body = nil
 
@@ -377,9 +371,9 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 				var err error
 
 				if project != nil {
-					ap, err = auth.NewPayloadFromHttp(method, r.RemoteAddr, headers, queryArgs)
+					ap, err = auth.NewPayloadFromHttp(method, r.RemoteAddr, headers, queryArgs, r.TLS, digest)
 				} else if isAdmin {
-					ap, err = auth.NewPayloadFromHttp(method, r.RemoteAddr, headers, queryArgs)
+					ap, err = auth.NewPayloadFromHttp(method, r.RemoteAddr, headers, queryArgs, r.TLS, digest)
 				}
 				if err != nil {
 					responses[index] = processErrorBody(&rlg, &startedAt, nq, err, &common.TRUE)
@@ -387,6 +381,19 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 					return
 				}
 
+				// Wallets sign in with SIWE before being authenticated
+				if project != nil && auth.IsSiweMethod(method) {
+					resp, err := project.HandleSiweRequest(requestCtx, nq, ap)
+					if err != nil {
+						responses[index] = processErrorBody(&rlg, &startedAt, nq, err, &common.TRUE)
+						common.EndRequestSpan(requestCtx, nil, err)
+						return
+					}
+					responses[index] = resp
+					common.EndRequestSpan(requestCtx, resp, nil)
+					return
+				}
+
 				if isAdmin {
 					_, err := s.erpc.AdminAuthenticate(requestCtx, method, ap)
 					if err != nil {
@@ -488,6 +495,7 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 				}
 
 				responses[index] = resp
+				computeUnits[index] = project.ComputeUnits(method)
 				common.EndRequestSpan(requestCtx, resp, nil)
 			}(i, reqBody, headers, queryArgs)
 		}
@@ -516,6 +524,8 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 		}
 
 		common.InjectHTTPResponseTraceContext(httpCtx, w)
+		setComputeUnitsHeader(computeUnits, w)
+		setRateLimitHeaders(responses, w)
 
 		if isBatch {
 			statusSet := false
@@ -629,6 +639,33 @@ func (s *HttpServer) createRequestHandler() http.Handler {
 	})
 }
 
+// resolveAliasing returns the project, architecture and chain pre-selected by
+// the first aliasing rule matching the request host (if any).
+func (s *HttpServer) resolveAliasing(r *http.Request) (projectId, architecture, chainId string) {
+	if s.serverCfg.Aliasing == nil {
+		return "", "", ""
+	}
+
+	// Get host without port number
+	host := r.Host
+	if colonIndex := strings.Index(host, ":"); colonIndex != -1 {
+		host = host[:colonIndex]
+	}
+
+	for _, rule := range s.serverCfg.Aliasing.Rules {
+		matched, err := common.WildcardMatch(rule.MatchDomain, host)
+		if err != nil {
+			s.logger.Error().Err(err).Interface("rule", rule).Msg("failed to match aliasing rule")
+			continue
+		}
+		if matched {
+			return rule.ServeProject, rule.ServeArchitecture, rule.ServeChain
+		}
+	}
+
+	return "", "", ""
+}
+
 func (s *HttpServer) parseUrlPath(
 	r *http.Request,
 	preSelectedProjectId,
@@ -925,6 +962,54 @@ func setResponseHeaders(ctx context.Context, res interface{}, w http.ResponseWri
 	}
 }
 
+// setComputeUnitsHeader reports the total compute units consumed by all requests of a (batch) call.
+func setComputeUnitsHeader(computeUnits []uint, w http.ResponseWriter) {
+	var total uint
+	for _, cu := range computeUnits {
+		total += cu
+	}
+	if total > 0 {
+		w.Header().Set("X-ERPC-Compute-Units", fmt.Sprintf("%d", total))
+	}
+}
+
+// setRateLimitHeaders tells clients how long to back off when a request was rejected by a self-imposed
+// rate limit rule. For batch calls the rule that takes the longest to replenish is reported.
+func setRateLimitHeaders(responses []interface{}, w http.ResponseWriter) {
+	var hit *common.RateLimitRuleInfo
+	for _, res := range responses {
+		var cause error
+		switch v := res.(type) {
+		case *HttpJsonRpcErrorResponse:
+			cause = v.Cause
+		case map[string]interface{}:
+			cause, _ = v["cause"].(error)
+		case error:
+			cause = v
+		}
+		if cause == nil {
+			continue
+		}
+		if rule := common.LookupRateLimitRule(cause); rule != nil && (hit == nil || rule.ResetIn > hit.ResetIn) {
+			hit = rule
+		}
+	}
+	if hit == nil {
+		return
+	}
+
+	// Headers carry whole seconds, rounded up so that clients do not retry before the rule is replenished
+	resetSeconds := int64(math.Ceil(hit.ResetIn.Duration().Seconds()))
+	if resetSeconds < 1 {
+		resetSeconds = 1
+	}
+	w.Header().Set("RateLimit-Limit", fmt.Sprintf("%d", hit.MaxCount))
+	// The permit was rejected, so nothing is left for the client until the rule is replenished
+	w.Header().Set("RateLimit-Remaining", "0")
+	w.Header().Set("RateLimit-Reset", fmt.Sprintf("%d", resetSeconds))
+	w.Header().Set("Retry-After", fmt.Sprintf("%d", resetSeconds))
+}
+
 func determineResponseStatusCode(respOrErr interface{}) int {
 	statusCode := http.StatusOK
 	if err, ok := respOrErr.(error); ok {
@@ -1281,6 +1366,10 @@ func (s *HttpServer) createTLSConfig() (*tls.Config, error) {
 		}
 		tlsConfig.ClientCAs = caCertPool
 		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	} else if s.erpc != nil && s.erpc.cfg != nil && s.erpc.cfg.HasAuthStrategy(common.AuthTypeMtls) {
+		// Client certificates are verified by each mtls strategy against its own CA bundle, and clients
+		// without a certificate can still authenticate via other strategies
+		tlsConfig.ClientAuth = tls.RequestClientCert
 	}
 
 	tlsConfig.InsecureSkipVerify = s.serverCfg.TLS.InsecureSkipVerify
@@ -0,0 +1,242 @@
+package erpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/auth"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+const (
+	eventStreamPathSuffix = "/events"
+
+	// Comment lines are sent periodically so that proxies and load balancers do not
+	// consider an idle stream dead (e.g. on chains with long block times).
+	eventStreamHeartbeatInterval = 15 * time.Second
+
+	// Maximum number of events buffered per client, when a client cannot keep up
+	// the stream is closed so that it reconnects and receives a fresh snapshot.
+	eventStreamBufferSize = 256
+)
+
+type eventStreamPayload struct {
+	NetworkId           string `json:"networkId"`
+	BlockNumber         string `json:"blockNumber"`
+	PreviousBlockNumber string `json:"previousBlockNumber,omitempty"`
+	UpstreamId          string `json:"upstreamId,omitempty"`
+	Finality            string `json:"finality,omitempty"`
+}
+
+func isEventStreamRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasSuffix(path.Clean(r.URL.Path), eventStreamPathSuffix)
+}
+
+func (s *HttpServer) createEventStreamHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isEventStreamRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Parse the path without the /events suffix so that all the usual forms are supported,
+		// i.e. /<project>/<architecture>/<chainId>/events, network aliases and domain aliasing.
+		nr := r.Clone(r.Context())
+		nr.URL.Path = strings.TrimSuffix(path.Clean(r.URL.Path), eventStreamPathSuffix)
+		projectId, architecture, chainId := s.resolveAliasing(r)
+		projectId, architecture, chainId, isAdmin, _, err := s.parseUrlPath(nr, projectId, architecture, chainId)
+		if err != nil || isAdmin || architecture == "" || chainId == "" {
+			// Not targeting a network (e.g. a project named "events") so it is handled as usual
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+					"event-stream-handler",
+					"",
+					common.ErrorFingerprint(rec),
+				).Inc()
+				s.logger.Error().
+					Interface("panic", rec).
+					Str("stack", string(debug.Stack())).
+					Msgf("unexpected panic on event stream handler")
+			}
+		}()
+
+		s.handleEventStream(w, r, projectId, architecture, chainId)
+	})
+}
+
+func (s *HttpServer) handleEventStream(w http.ResponseWriter, r *http.Request, projectId, architecture, chainId string) {
+	startedAt := time.Now()
+	httpCtx := r.Context()
+
+	networkId := fmt.Sprintf("%s:%s", architecture, chainId)
+	lg := s.logger.With().Str("component", "proxy").Str("transport", "sse").Str("projectId", projectId).Str("networkId", networkId).Logger()
+
+	project, err := s.erpc.GetProject(projectId)
+	if err != nil {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, err)
+		return
+	}
+
+	if project.Config.CORS != nil {
+		if !s.handleCORS(httpCtx, w, r, project.Config.CORS) {
+			return
+		}
+	}
+
+	// Events replace polling eth_blockNumber, therefore the same auth rules apply
+	method := "eth_blockNumber"
+	ap, err := auth.NewPayloadFromHttp(method, r.RemoteAddr, r.Header, r.URL.Query(), r.TLS, auth.NewRequestDigest(r.Method, r.URL.Path, nil))
+	if err != nil {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, err)
+		return
+	}
+	user, err := project.AuthenticateConsumer(httpCtx, method, ap)
+	if err != nil {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, err)
+		return
+	}
+	if !user.IsNetworkAllowed(networkId) {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, common.NewErrAuthForbidden(
+			"", user.Id, fmt.Sprintf("network %s is not allowed for this user", networkId),
+		))
+		return
+	}
+
+	if architecture != string(common.ArchitectureEvm) {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, common.NewErrInvalidRequest(fmt.Errorf(
+			"events are only supported for evm networks, for example /<project>/evm/42161/events",
+		)))
+		return
+	}
+
+	nw, err := project.GetNetwork(httpCtx, networkId)
+	if err != nil {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, err)
+		return
+	}
+	if nw.metricsTracker == nil {
+		s.writeStreamHandshakeError(httpCtx, &lg, &startedAt, w, common.NewErrInvalidRequest(fmt.Errorf(
+			"network %s does not track block heads", networkId,
+		)))
+		return
+	}
+
+	s.serveEventStream(w, r, project, nw, &lg)
+}
+
+func (s *HttpServer) serveEventStream(w http.ResponseWriter, r *http.Request, project *PreparedProject, nw *Network, lg *zerolog.Logger) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Server's write timeout would otherwise terminate the stream after a while
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		lg.Debug().Err(err).Msg("could not reset write deadline for event stream")
+	}
+
+	events := make(chan *health.BlockHeadEvent, eventStreamBufferSize)
+	var overflowOnce sync.Once
+	unsubscribe := nw.metricsTracker.OnBlockHeadEvent(nw.Id(), func(event *health.BlockHeadEvent) {
+		if event.Type == health.BlockHeadEventBlockHash {
+			// Observed on every poll of every upstream, clients only care about heads advancing
+			return
+		}
+		select {
+		case events <- event:
+		default:
+			overflowOnce.Do(func() {
+				lg.Warn().Str("remoteAddr", r.RemoteAddr).Msg("event stream client is too slow, closing the stream")
+				cancel()
+			})
+		}
+	})
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Disable response buffering on nginx-like reverse proxies
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	projectId := project.Config.Id
+	networkLabel := nw.Label()
+	telemetry.MetricEventStreamClientsActive.WithLabelValues(projectId, networkLabel).Inc()
+	defer telemetry.MetricEventStreamClientsActive.WithLabelValues(projectId, networkLabel).Dec()
+	lg.Debug().Str("remoteAddr", r.RemoteAddr).Msg("event stream client connected")
+
+	write := func(event *health.BlockHeadEvent) error {
+		payload := eventStreamPayload{
+			NetworkId:   event.NetworkId,
+			BlockNumber: fmt.Sprintf("0x%x", event.BlockNumber),
+			UpstreamId:  event.UpstreamId,
+			Finality:    event.Finality,
+		}
+		if event.PreviousBlockNumber > 0 {
+			payload.PreviousBlockNumber = fmt.Sprintf("0x%x", event.PreviousBlockNumber)
+		}
+		data, err := common.SonicCfg.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return err
+		}
+		telemetry.MetricEventStreamEventsTotal.WithLabelValues(projectId, networkLabel, string(event.Type)).Inc()
+		return rc.Flush()
+	}
+
+	// Send current state first so clients do not have to wait for the next block
+	if bn := nw.EvmHighestLatestBlockNumber(ctx); bn > 0 {
+		if err := write(&health.BlockHeadEvent{Type: health.BlockHeadEventLatest, NetworkId: nw.Id(), BlockNumber: bn}); err != nil {
+			return
+		}
+	}
+	if bn := nw.EvmHighestFinalizedBlockNumber(ctx); bn > 0 {
+		if err := write(&health.BlockHeadEvent{Type: health.BlockHeadEventFinalized, NetworkId: nw.Id(), BlockNumber: bn}); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			lg.Debug().Str("remoteAddr", r.RemoteAddr).Msg("event stream client disconnected")
+			return
+		case <-s.appCtx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case event := <-events:
+			if err := write(event); err != nil {
+				lg.Debug().Err(err).Msg("failed to write to event stream client")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package erpc
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStreamEvent struct {
+	name string
+	data map[string]interface{}
+}
+
+func readTestStreamEvent(t *testing.T, reader *bufio.Reader) testStreamEvent {
+	ev := testStreamEvent{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if ev.name != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, sonic.UnmarshalString(strings.TrimPrefix(line, "data: "), &ev.data))
+		}
+	}
+}
+
+func TestHttpServer_EventStream(t *testing.T) {
+	t.Run("StreamsCurrentStateAndNewBlockHeads", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		_, _, baseURL, shutdown, erpcInstance := createServerTestFixtures(createWebSocketTestConfig(), t)
+		defer shutdown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/test_project/evm/123/events", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		ev := readTestStreamEvent(t, reader)
+		assert.Equal(t, "latestBlock", ev.name)
+		assert.Equal(t, "evm:123", ev.data["networkId"])
+		assert.Equal(t, "0x11118888", ev.data["blockNumber"])
+
+		ev = readTestStreamEvent(t, reader)
+		assert.Equal(t, "finalizedBlock", ev.name)
+		assert.Equal(t, "0x11117777", ev.data["blockNumber"])
+
+		project, err := erpcInstance.GetProject("test_project")
+		require.NoError(t, err)
+		nw, err := project.GetNetwork(ctx, "evm:123")
+		require.NoError(t, err)
+		ups := common.NewFakeUpstream("rpc1")
+
+		nw.metricsTracker.SetLatestBlockNumber(ups, 0x11118889)
+		ev = readTestStreamEvent(t, reader)
+		assert.Equal(t, "latestBlock", ev.name)
+		assert.Equal(t, "0x11118889", ev.data["blockNumber"])
+		assert.Equal(t, "0x11118888", ev.data["previousBlockNumber"])
+
+		nw.metricsTracker.RecordBlockHeadLargeRollback(ups, "finalized", 0x11117777, 0x100)
+		ev = readTestStreamEvent(t, reader)
+		assert.Equal(t, "largeRollback", ev.name)
+		assert.Equal(t, "rpc1", ev.data["upstreamId"])
+		assert.Equal(t, "finalized", ev.data["finality"])
+		assert.Equal(t, "0x100", ev.data["blockNumber"])
+	})
+
+	t.Run("RejectsUnknownProject", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(createWebSocketTestConfig(), t)
+		defer shutdown()
+
+		resp, err := http.Get(baseURL + "/unknown_project/evm/123/events")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "ErrProjectNotFound")
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"runtime"
 	pprof "runtime/pprof"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -7918,6 +7919,177 @@ func TestHttpServer_EvmGetBlockByNumber(t *testing.T) {
 	})
 }
 
+func TestHttpServer_ComputeUnits(t *testing.T) {
+	cfg := &common.Config{
+		Server: &common.ServerConfig{
+			MaxTimeout: common.Duration(5 * time.Second).Ptr(),
+		},
+		Projects: []*common.ProjectConfig{
+			{
+				Id:              "test_project",
+				RateLimitBudget: "cu-budget",
+				Networks: []*common.NetworkConfig{
+					{
+						Architecture: common.ArchitectureEvm,
+						Evm: &common.EvmNetworkConfig{
+							ChainId: 123,
+						},
+					},
+				},
+				Upstreams: []*common.UpstreamConfig{
+					{
+						Type:     common.UpstreamTypeEvm,
+						Endpoint: "http://rpc1.localhost",
+						Evm: &common.EvmUpstreamConfig{
+							ChainId: 123,
+						},
+						JsonRpc: &common.JsonRpcUpstreamConfig{
+							SupportsBatch: &common.FALSE,
+						},
+					},
+				},
+			},
+		},
+		RateLimiters: &common.RateLimiterConfig{
+			ComputeUnits: map[string]uint{
+				"eth_getBalance": 7,
+			},
+			Budgets: []*common.RateLimitBudgetConfig{
+				{
+					Id: "cu-budget",
+					Rules: []*common.RateLimitRuleConfig{
+						{
+							Method:   "*",
+							MaxCount: 10,
+							Period:   common.Duration(1 * time.Minute),
+							Unit:     common.RateLimitUnitComputeUnit,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(cfg, t)
+	defer shutdown()
+
+	gock.New("http://rpc1.localhost").
+		Post("/").
+		Filter(func(request *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  "0x123456",
+		})
+
+	statusCode, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","0x1"],"id":1}`, nil, nil)
+	assert.Equal(t, http.StatusOK, statusCode, body)
+	assert.Contains(t, body, "0x123456")
+	assert.Equal(t, "7", headers["X-Erpc-Compute-Units"])
+
+	// 7 out of 10 compute units are already consumed, so another call of the same method exceeds the budget
+	statusCode, headers, _ = sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","0x1"],"id":2}`, nil, nil)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode)
+	assert.Empty(t, headers["X-Erpc-Compute-Units"])
+}
+
+func TestHttpServer_RateLimitHeaders(t *testing.T) {
+	cfg := &common.Config{
+		Server: &common.ServerConfig{
+			MaxTimeout: common.Duration(5 * time.Second).Ptr(),
+		},
+		Projects: []*common.ProjectConfig{
+			{
+				Id:              "test_project",
+				RateLimitBudget: "project-budget",
+				Networks: []*common.NetworkConfig{
+					{
+						Architecture: common.ArchitectureEvm,
+						Evm: &common.EvmNetworkConfig{
+							ChainId: 123,
+						},
+					},
+				},
+				Upstreams: []*common.UpstreamConfig{
+					{
+						Type:     common.UpstreamTypeEvm,
+						Endpoint: "http://rpc1.localhost",
+						Evm: &common.EvmUpstreamConfig{
+							ChainId: 123,
+						},
+						JsonRpc: &common.JsonRpcUpstreamConfig{
+							SupportsBatch: &common.FALSE,
+						},
+					},
+				},
+			},
+		},
+		RateLimiters: &common.RateLimiterConfig{
+			Budgets: []*common.RateLimitBudgetConfig{
+				{
+					Id: "project-budget",
+					Rules: []*common.RateLimitRuleConfig{
+						{
+							Method:   "eth_getBalance",
+							MaxCount: 1,
+							Period:   common.Duration(1 * time.Minute),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	sendRequest, _, _, shutdown, _ := createServerTestFixtures(cfg, t)
+	defer shutdown()
+
+	gock.New("http://rpc1.localhost").
+		Post("/").
+		Filter(func(request *http.Request) bool {
+			return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+		}).
+		Reply(200).
+		JSON(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  "0x123456",
+		})
+
+	statusCode, headers, body := sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","0x1"],"id":1}`, nil, nil)
+	assert.Equal(t, http.StatusOK, statusCode, body)
+	assert.Empty(t, headers["Retry-After"])
+	assert.Empty(t, headers["Ratelimit-Limit"])
+
+	statusCode, headers, body = sendRequest(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","0x1"],"id":2}`, nil, nil)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode)
+	assert.Equal(t, "1", headers["Ratelimit-Limit"])
+	assert.Equal(t, "0", headers["Ratelimit-Remaining"])
+
+	reset, err := strconv.Atoi(headers["Ratelimit-Reset"])
+	require.NoError(t, err)
+	assert.True(t, reset >= 1 && reset <= 60, "reset must be within the rule period, got %d", reset)
+	assert.Equal(t, headers["Ratelimit-Reset"], headers["Retry-After"])
+
+	var errResp map[string]interface{}
+	require.NoError(t, sonic.UnmarshalString(body, &errResp))
+	errData, ok := errResp["error"].(map[string]interface{})["data"].(map[string]interface{})
+	require.True(t, ok, "error data must describe the rule that was hit: %s", body)
+	assert.Equal(t, "project-budget", errData["budget"])
+	assert.Equal(t, "eth_getBalance", errData["method"])
+	assert.EqualValues(t, 1, errData["maxCount"])
+}
+
 func createServerTestFixtures(cfg *common.Config, t *testing.T) (
 	func(body string, headers map[string]string, queryParams map[string]string) (int, map[string]string, string),
 	func(host string) (int, map[string]string, string),
@@ -8063,6 +8235,127 @@ func createServerTestFixtures(cfg *common.Config, t *testing.T) (
 	}, erpcInstance
 }
 
+func TestHttpServer_SiweNonceRateLimits(t *testing.T) {
+	createConfig := func(rateLimitBudget string, perIp uint) *common.Config {
+		return &common.Config{
+			Server: &common.ServerConfig{
+				MaxTimeout: common.Duration(5 * time.Second).Ptr(),
+			},
+			Projects: []*common.ProjectConfig{
+				{
+					Id:              "test_project",
+					RateLimitBudget: rateLimitBudget,
+					Auth: &common.AuthConfig{
+						Strategies: []*common.AuthStrategyConfig{
+							{
+								Type: common.AuthTypeSiwe,
+								Siwe: &common.SiweStrategyConfig{
+									AllowedDomains: []string{"app.example.com"},
+									Connector: &common.ConnectorConfig{
+										Id:     "siwe",
+										Driver: common.DriverMemory,
+										Memory: &common.MemoryConnectorConfig{
+											MaxItems: 100, MaxTotalSize: "1MB",
+										},
+									},
+									NonceRateLimitPerIp: perIp,
+								},
+							},
+						},
+					},
+					Networks: []*common.NetworkConfig{
+						{
+							Architecture: common.ArchitectureEvm,
+							Evm: &common.EvmNetworkConfig{
+								ChainId: 123,
+							},
+						},
+					},
+					Upstreams: []*common.UpstreamConfig{
+						{
+							Type:     common.UpstreamTypeEvm,
+							Endpoint: "http://rpc1.localhost",
+							Evm: &common.EvmUpstreamConfig{
+								ChainId: 123,
+							},
+						},
+					},
+				},
+			},
+			RateLimiters: &common.RateLimiterConfig{
+				Budgets: []*common.RateLimitBudgetConfig{
+					{
+						Id: "project-budget",
+						Rules: []*common.RateLimitRuleConfig{
+							{
+								Method:   "erpc_siweNonce",
+								MaxCount: 1,
+								Period:   common.Duration(1 * time.Minute),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	nonceRequest := `{"jsonrpc":"2.0","id":1,"method":"erpc_siweNonce","params":[]}`
+
+	t.Run("AppliesProjectRateLimitBudget", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		sendRequest, _, _, shutdown, _ := createServerTestFixtures(createConfig("project-budget", 0), t)
+		defer shutdown()
+
+		statusCode, _, body := sendRequest(nonceRequest, nil, nil)
+		require.Equal(t, http.StatusOK, statusCode, body)
+		statusCode, _, body = sendRequest(nonceRequest, nil, nil)
+		assert.Equal(t, http.StatusTooManyRequests, statusCode, body)
+		assert.Contains(t, body, "project-budget")
+	})
+
+	t.Run("LimitsNoncesPerClientIpIgnoringForwardedFor", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		sendRequest, _, _, shutdown, _ := createServerTestFixtures(createConfig("", 1), t)
+		defer shutdown()
+
+		statusCode, _, body := sendRequest(nonceRequest, map[string]string{"X-Forwarded-For": "203.0.113.1"}, nil)
+		require.Equal(t, http.StatusOK, statusCode, body)
+		statusCode, _, body = sendRequest(nonceRequest, map[string]string{"X-Forwarded-For": "203.0.113.2"}, nil)
+		assert.Equal(t, http.StatusTooManyRequests, statusCode, body)
+		assert.Contains(t, body, "siwe-nonce:")
+	})
+
+	t.Run("HonoursForwardedForFromTrustedProxies", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		cfg := createConfig("", 1)
+		cfg.Projects[0].Auth.Strategies[0].Siwe.TrustedProxies = []string{"127.0.0.1", "::1"}
+		sendRequest, _, _, shutdown, _ := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		statusCode, _, body := sendRequest(nonceRequest, map[string]string{"X-Forwarded-For": "203.0.113.1"}, nil)
+		require.Equal(t, http.StatusOK, statusCode, body)
+		statusCode, _, body = sendRequest(nonceRequest, map[string]string{"X-Forwarded-For": "203.0.113.2"}, nil)
+		require.Equal(t, http.StatusOK, statusCode, body)
+		statusCode, _, body = sendRequest(nonceRequest, map[string]string{"X-Forwarded-For": "203.0.113.1"}, nil)
+		assert.Equal(t, http.StatusTooManyRequests, statusCode, body)
+		assert.Contains(t, body, "siwe-nonce:203.0.113.1")
+	})
+
+	t.Run("LimitsNoncesPerClientIpByDefault", func(t *testing.T) {
+		cfg := createConfig("", 0)
+		require.NoError(t, cfg.SetDefaults(nil))
+		assert.Equal(t, uint(5), cfg.Projects[0].Auth.Strategies[0].Siwe.NonceRateLimitPerIp)
+	})
+}
+
 func TestHttpServer_Evm_GetLogs_MemoryProfile(t *testing.T) {
 	util.ResetGock()
 	defer util.ResetGock()
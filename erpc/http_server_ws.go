@@ -0,0 +1,549 @@
+package erpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erpc/erpc/auth"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+	"golang.org/x/net/websocket"
+)
+
+// Maximum number of outgoing messages buffered per websocket client, when a client
+// cannot keep up with its responses and notifications the connection is closed.
+const wsOutboxSize = 1024
+
+type wsServerConn struct {
+	server  *HttpServer
+	conn    *websocket.Conn
+	request *http.Request
+	project *PreparedProject
+	network *Network
+	subs    *EvmSubscriptionManager
+	logger  *zerolog.Logger
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	outbox    chan []byte
+	closeOnce sync.Once
+
+	// inFlight bounds the messages handled concurrently, the read loop waits for a slot (backpressure)
+	inFlight chan struct{}
+
+	subsMu        sync.Mutex
+	subscriptions map[string]struct{}
+
+	// siweDigest is shared by all messages so that a signed SIWE message sent with the handshake is verified
+	// (and consumes its nonce) only once per connection
+	siweDigest *auth.RequestDigest
+}
+
+func isWebSocketUpgrade(r *http.Request) bool {
+	if r.Method != http.MethodGet || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, token := range strings.Split(r.Header.Get("Connection"), ",") {
+		if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *HttpServer) createWebSocketHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+					"websocket-handler",
+					"",
+					common.ErrorFingerprint(rec),
+				).Inc()
+				s.logger.Error().
+					Interface("panic", rec).
+					Str("stack", string(debug.Stack())).
+					Msgf("unexpected panic on websocket handler")
+			}
+		}()
+
+		s.handleWebSocket(w, r)
+	})
+}
+
+func (s *HttpServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	startedAt := time.Now()
+	httpCtx := r.Context()
+	rejectUpgrade := func(lg *zerolog.Logger, err error) {
+		s.writeStreamHandshakeError(httpCtx, lg, &startedAt, w, err)
+	}
+
+	projectId, architecture, chainId := s.resolveAliasing(r)
+	projectId, architecture, chainId, isAdmin, _, err := s.parseUrlPath(r, projectId, architecture, chainId)
+	if err != nil {
+		rejectUpgrade(s.logger, err)
+		return
+	}
+	if isAdmin || architecture == "" || chainId == "" {
+		rejectUpgrade(s.logger, common.NewErrInvalidRequest(fmt.Errorf(
+			"websocket connections must target a network, for example /<project>/evm/42161 or via domain aliasing",
+		)))
+		return
+	}
+
+	networkId := fmt.Sprintf("%s:%s", architecture, chainId)
+	lg := s.logger.With().Str("component", "proxy").Str("transport", "websocket").Str("projectId", projectId).Str("networkId", networkId).Logger()
+
+	project, err := s.erpc.GetProject(projectId)
+	if err != nil {
+		rejectUpgrade(&lg, err)
+		return
+	}
+
+	// Browsers do not enforce CORS on websockets, therefore allowed origins are checked here
+	if origin := r.Header.Get("Origin"); origin != "" && project.Config.CORS != nil {
+		if !s.isOriginAllowed(project.Config.CORS, origin) {
+			telemetry.MetricCORSDisallowedOriginTotal.WithLabelValues(r.URL.Path, origin).Inc()
+			rejectUpgrade(&lg, common.NewErrAuthUnauthorized("", fmt.Sprintf("origin %s is not allowed", origin)))
+			return
+		}
+	}
+
+	nw, err := project.GetNetwork(httpCtx, networkId)
+	if err != nil {
+		rejectUpgrade(&lg, err)
+		return
+	}
+
+	wsSrv := websocket.Server{
+		// Origin is already verified above against project's CORS config (if any)
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			c := s.newWsServerConn(conn, r, project, nw, &lg)
+			c.serve()
+		},
+	}
+	wsSrv.ServeHTTP(w, r)
+}
+
+// writeStreamHandshakeError responds with a regular json-rpc error when a long-lived
+// stream (websocket or server-sent events) cannot be established.
+func (s *HttpServer) writeStreamHandshakeError(httpCtx context.Context, lg *zerolog.Logger, startedAt *time.Time, w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := common.SonicCfg.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	handleErrorResponse(
+		httpCtx,
+		lg,
+		startedAt,
+		nil,
+		err,
+		w,
+		encoder,
+		func(ctx context.Context, statusCode int, body error) {},
+		s.serverCfg.IncludeErrorDetails,
+	)
+}
+
+func (s *HttpServer) isOriginAllowed(corsConfig *common.CORSConfig, origin string) bool {
+	for _, allowedOrigin := range corsConfig.AllowedOrigins {
+		match, err := common.WildcardMatch(allowedOrigin, origin)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("failed to match CORS origin")
+			continue
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *HttpServer) getEvmSubscriptionManager(nw *Network) *EvmSubscriptionManager {
+	key := fmt.Sprintf("%s/%s", nw.ProjectId(), nw.Id())
+	if m, ok := s.evmSubscriptions.Load(key); ok {
+		return m.(*EvmSubscriptionManager)
+	}
+	m, _ := s.evmSubscriptions.LoadOrStore(key, NewEvmSubscriptionManager(s.appCtx, s.logger, nw, s.serverCfg.WebSocket))
+	return m.(*EvmSubscriptionManager)
+}
+
+func (s *HttpServer) newWsServerConn(conn *websocket.Conn, r *http.Request, project *PreparedProject, nw *Network, lg *zerolog.Logger) *wsServerConn {
+	ctx, cancel := context.WithCancel(s.appCtx)
+	var inFlight chan struct{}
+	if limit := s.serverCfg.WebSocket.MaxInFlightPerConnection; limit > 0 {
+		inFlight = make(chan struct{}, limit)
+	}
+	return &wsServerConn{
+		server:        s,
+		conn:          conn,
+		request:       r,
+		project:       project,
+		network:       nw,
+		subs:          s.getEvmSubscriptionManager(nw),
+		logger:        lg,
+		ctx:           ctx,
+		cancel:        cancel,
+		outbox:        make(chan []byte, wsOutboxSize),
+		inFlight:      inFlight,
+		subscriptions: make(map[string]struct{}),
+		siweDigest:    auth.NewRequestDigest(r.Method, r.URL.Path, nil),
+	}
+}
+
+func (c *wsServerConn) serve() {
+	// Deadlines of the http server still apply to the hijacked connection, they must be reset
+	// so that idle subscribers are not disconnected after server's read/write timeout.
+	_ = c.conn.SetDeadline(time.Time{})
+	c.conn.MaxPayloadBytes = c.server.serverCfg.WebSocket.MaxMessageSize
+	c.conn.PayloadType = websocket.TextFrame
+
+	telemetry.MetricWebSocketConnectionsActive.WithLabelValues(c.project.Config.Id, c.network.Label()).Inc()
+	c.logger.Debug().Str("remoteAddr", c.request.RemoteAddr).Msg("websocket client connected")
+
+	defer func() {
+		c.close()
+		c.subsMu.Lock()
+		for id := range c.subscriptions {
+			c.subs.Unsubscribe(id)
+		}
+		c.subscriptions = nil
+		c.subsMu.Unlock()
+		telemetry.MetricWebSocketConnectionsActive.WithLabelValues(c.project.Config.Id, c.network.Label()).Dec()
+		c.logger.Debug().Str("remoteAddr", c.request.RemoteAddr).Msg("websocket client disconnected")
+	}()
+
+	go c.writeLoop()
+	go func() {
+		<-c.ctx.Done()
+		c.close()
+	}()
+
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(c.conn, &msg); err != nil {
+			if !errors.Is(err, io.EOF) && c.ctx.Err() == nil {
+				c.logger.Debug().Err(err).Msg("websocket client read failed, closing connection")
+			}
+			return
+		}
+		if c.inFlight == nil {
+			go c.handleMessage(msg)
+			continue
+		}
+		select {
+		case c.inFlight <- struct{}{}:
+		case <-c.ctx.Done():
+			return
+		}
+		go func() {
+			defer func() { <-c.inFlight }()
+			c.handleMessage(msg)
+		}()
+	}
+}
+
+func (c *wsServerConn) close() {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		_ = c.conn.Close()
+	})
+}
+
+func (c *wsServerConn) writeLoop() {
+	writeTimeout := 30 * time.Second
+	if c.server.serverCfg.WriteTimeout != nil && *c.server.serverCfg.WriteTimeout > 0 {
+		writeTimeout = c.server.serverCfg.WriteTimeout.Duration()
+	}
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case msg := <-c.outbox:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := c.conn.Write(msg); err != nil {
+				c.logger.Debug().Err(err).Msg("failed to write to websocket client, closing connection")
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *wsServerConn) enqueue(msg []byte) {
+	select {
+	case c.outbox <- msg:
+	case <-c.ctx.Done():
+	default:
+		c.logger.Warn().Str("remoteAddr", c.request.RemoteAddr).Msg("closing websocket client as it cannot keep up with outgoing messages")
+		c.close()
+	}
+}
+
+func (c *wsServerConn) notify(subscriptionId string, result []byte) {
+	msg := make([]byte, 0, len(result)+128)
+	msg = append(msg, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"`...)
+	msg = append(msg, subscriptionId...)
+	msg = append(msg, `","result":`...)
+	msg = append(msg, result...)
+	msg = append(msg, `}}`...)
+	c.enqueue(msg)
+}
+
+func (c *wsServerConn) handleMessage(msg []byte) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			telemetry.MetricUnexpectedPanicTotal.WithLabelValues(
+				"websocket-request-handler",
+				fmt.Sprintf("project:%s network:%s", c.project.Config.Id, c.network.Id()),
+				common.ErrorFingerprint(rec),
+			).Inc()
+			c.logger.Error().
+				Interface("panic", rec).
+				Str("stack", string(debug.Stack())).
+				Msgf("unexpected panic on websocket request handler")
+		}
+	}()
+
+	body := bytes.TrimSpace(msg)
+	if len(body) == 0 {
+		return
+	}
+	c.logger.Info().RawJSON("body", body).Msgf("received websocket request")
+
+	buf := &bytes.Buffer{}
+	var err error
+	if body[0] == '[' {
+		var requests []json.RawMessage
+		if err := common.SonicCfg.Unmarshal(body, &requests); err != nil {
+			startedAt := time.Now()
+			c.write(buf, processErrorBody(c.logger, &startedAt, nil, common.NewErrJsonRpcRequestUnmarshal(err, body), &common.TRUE))
+			return
+		}
+		responses := make([]interface{}, len(requests))
+		var wg sync.WaitGroup
+		for i, raw := range requests {
+			wg.Add(1)
+			go func(index int, raw json.RawMessage) {
+				defer wg.Done()
+				responses[index] = c.handleRequest(raw)
+			}(i, raw)
+		}
+		wg.Wait()
+		_, err = NewBatchResponseWriter(responses).WriteTo(buf)
+		for _, resp := range responses {
+			if r, ok := resp.(*common.NormalizedResponse); ok {
+				go r.Release()
+			}
+		}
+		if err != nil {
+			c.logger.Error().Err(err).Msg("failed to write websocket batch response")
+			return
+		}
+		c.enqueue(buf.Bytes())
+		return
+	}
+
+	c.write(buf, c.handleRequest(body))
+}
+
+func (c *wsServerConn) write(buf *bytes.Buffer, res interface{}) {
+	var err error
+	switch v := res.(type) {
+	case *common.NormalizedResponse:
+		_, err = v.WriteTo(buf)
+		go v.Release()
+	case *HttpJsonRpcErrorResponse:
+		_, err = writeJsonRpcError(buf, v)
+	default:
+		err = common.SonicCfg.NewEncoder(buf).Encode(res)
+	}
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to write websocket response")
+		return
+	}
+	c.enqueue(buf.Bytes())
+}
+
+func (c *wsServerConn) handleRequest(raw json.RawMessage) interface{} {
+	startedAt := time.Now()
+	includeErrorDetails := c.server.serverCfg.IncludeErrorDetails
+
+	nq := common.NewNormalizedRequest(raw)
+	requestCtx := common.StartRequestSpan(c.ctx, nq)
+
+	if err := nq.Validate(); err != nil {
+		resp := processErrorBody(c.logger, &startedAt, nq, err, &common.TRUE)
+		common.EndRequestSpan(requestCtx, nil, resp)
+		return resp
+	}
+
+	method, _ := nq.Method()
+	rlg := c.logger.With().Str("method", method).Logger()
+
+	headers := c.request.Header
+	queryArgs := c.request.URL.Query()
+	ap, err := auth.NewPayloadFromHttp(method, c.request.RemoteAddr, headers, queryArgs, c.request.TLS, nil)
+	if err != nil {
+		common.EndRequestSpan(requestCtx, nil, err)
+		return processErrorBody(&rlg, &startedAt, nq, err, &common.TRUE)
+	}
+	if ap.Type == common.AuthTypeSiwe {
+		ap.Digest = c.siweDigest
+	}
+	user, err := c.project.AuthenticateConsumer(requestCtx, method, ap)
+	if err != nil {
+		common.EndRequestSpan(requestCtx, nil, err)
+		return processErrorBody(&rlg, &startedAt, nq, err, &common.TRUE)
+	}
+	if user != nil {
+		rlg = rlg.With().Str("userId", user.Id).Logger()
+	}
+	nq.SetUser(user)
+
+	var resp *common.NormalizedResponse
+	switch method {
+	case "eth_subscribe", "eth_unsubscribe":
+		// Subscriptions are served without going through Project.Forward, so network access and rate limits are checked here
+		networkId := c.network.Id()
+		if !user.IsNetworkAllowed(networkId) {
+			err = common.NewErrAuthForbidden("", user.Id, fmt.Sprintf("network %s is not allowed for this user", networkId))
+			break
+		}
+		if err = c.project.acquireRateLimitPermit(requestCtx, nq); err != nil {
+			break
+		}
+		if err = c.network.acquireRateLimitPermit(requestCtx, nq); err != nil {
+			break
+		}
+		if method == "eth_subscribe" {
+			resp, err = c.subscribe(nq)
+		} else {
+			resp, err = c.unsubscribe(nq)
+		}
+	default:
+		nq.SetNetwork(c.network)
+		nq.ApplyDirectiveDefaults(c.network.Config().DirectiveDefaults)
+		nq.EnrichFromHttp(headers, queryArgs)
+
+		ctx, cancel := context.WithTimeout(requestCtx, c.server.serverCfg.MaxTimeout.Duration())
+		resp, err = c.project.Forward(ctx, c.network.Id(), nq)
+		cancel()
+	}
+
+	if err != nil {
+		if resp != nil {
+			go resp.Release()
+		}
+		common.EndRequestSpan(requestCtx, nil, err)
+		return processErrorBody(&rlg, &startedAt, nq, err, includeErrorDetails)
+	}
+
+	common.EndRequestSpan(requestCtx, resp, nil)
+	return resp
+}
+
+func (c *wsServerConn) subscribe(nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrq, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	jrq.RLock()
+	id := jrq.ID
+	params := jrq.Params
+	jrq.RUnlock()
+
+	if len(params) == 0 {
+		return nil, newWsInvalidParamsError("missing subscription type")
+	}
+	kind, ok := params[0].(string)
+	if !ok {
+		return nil, newWsInvalidParamsError("subscription type must be a string")
+	}
+	var filter map[string]interface{}
+	if len(params) > 1 && params[1] != nil {
+		filter, ok = params[1].(map[string]interface{})
+		if !ok {
+			return nil, newWsInvalidParamsError("subscription filter must be an object")
+		}
+	}
+
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	if c.subscriptions == nil {
+		return nil, common.NewErrEndpointRequestCanceled(c.ctx.Err())
+	}
+	if limit := c.server.serverCfg.WebSocket.MaxSubscriptionsPerConnection; limit > 0 && len(c.subscriptions) >= limit {
+		return nil, newWsInvalidParamsError(fmt.Sprintf("maximum of %d subscriptions per connection reached", limit))
+	}
+	subId, err := c.subs.Subscribe(kind, filter, c.notify)
+	if err != nil {
+		return nil, err
+	}
+	c.subscriptions[subId] = struct{}{}
+
+	jrr, err := common.NewJsonRpcResponse(id, subId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), nil
+}
+
+func (c *wsServerConn) unsubscribe(nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
+	jrq, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	jrq.RLock()
+	id := jrq.ID
+	params := jrq.Params
+	jrq.RUnlock()
+
+	if len(params) == 0 {
+		return nil, newWsInvalidParamsError("missing subscription id")
+	}
+	subId, ok := params[0].(string)
+	if !ok {
+		return nil, newWsInvalidParamsError("subscription id must be a string")
+	}
+
+	// Clients can only cancel subscriptions created on their own connection
+	removed := false
+	c.subsMu.Lock()
+	if _, ok := c.subscriptions[subId]; ok {
+		delete(c.subscriptions, subId)
+		removed = c.subs.Unsubscribe(subId)
+	}
+	c.subsMu.Unlock()
+
+	jrr, err := common.NewJsonRpcResponse(id, removed, nil)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewNormalizedResponse().WithRequest(nq).WithJsonRpcResponse(jrr), nil
+}
+
+func newWsInvalidParamsError(message string) error {
+	return common.NewErrJsonRpcExceptionInternal(
+		0,
+		common.JsonRpcErrorInvalidArgument,
+		message,
+		nil,
+		nil,
+	)
+}
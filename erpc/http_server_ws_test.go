@@ -0,0 +1,514 @@
+package erpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/util"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/h2non/gock"
+	"github.com/spruceid/siwe-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func createWebSocketTestConfig() *common.Config {
+	return &common.Config{
+		Server: &common.ServerConfig{
+			MaxTimeout: common.Duration(5 * time.Second).Ptr(),
+			WebSocket: &common.WebSocketServerConfig{
+				HeadsPollInterval: common.Duration(100 * time.Millisecond),
+			},
+		},
+		Projects: []*common.ProjectConfig{
+			{
+				Id: "test_project",
+				Networks: []*common.NetworkConfig{
+					{
+						Architecture: common.ArchitectureEvm,
+						Evm: &common.EvmNetworkConfig{
+							ChainId: 123,
+						},
+					},
+				},
+				Upstreams: []*common.UpstreamConfig{
+					{
+						Id:       "rpc1",
+						Type:     common.UpstreamTypeEvm,
+						Endpoint: "http://rpc1.localhost",
+						Evm: &common.EvmUpstreamConfig{
+							ChainId: 123,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dialTestWebSocket(t *testing.T, baseURL string) *websocket.Conn {
+	wsUrl := "ws" + strings.TrimPrefix(baseURL, "http") + "/test_project/evm/123"
+	conn, err := websocket.Dial(wsUrl, "", baseURL)
+	require.NoError(t, err)
+	return conn
+}
+
+func receiveWebSocketMessage(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var msg string
+	require.NoError(t, websocket.Message.Receive(conn, &msg))
+	var obj map[string]interface{}
+	require.NoError(t, sonic.UnmarshalString(msg, &obj), "invalid json received: %s", msg)
+	return obj
+}
+
+func signTestSiweMessage(t *testing.T, nonce string) (string, string) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	message, err := siwe.InitMessage("app.example.com", address, "https://app.example.com", nonce, map[string]interface{}{
+		"expirationTime": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	signature, err := crypto.Sign(accounts.TextHash([]byte(message.String())), key)
+	require.NoError(t, err)
+	signature[64] += 27
+	return message.String(), hexutil.Encode(signature)
+}
+
+func TestHttpServer_WebSocket(t *testing.T) {
+	t.Run("ForwardsJsonRpcRequests", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1234"}`))
+
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(createWebSocketTestConfig(), t)
+		defer shutdown()
+
+		conn := dialTestWebSocket(t, baseURL)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":"abc","method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x1"]}`))
+		resp := receiveWebSocketMessage(t, conn)
+		assert.Equal(t, "abc", resp["id"])
+		assert.Equal(t, "0x1234", resp["result"])
+
+		require.NoError(t, websocket.Message.Send(conn, `[{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x1"]},{"jsonrpc":"2.0","id":2,"method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x1"]}]`))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		var batch string
+		require.NoError(t, websocket.Message.Receive(conn, &batch))
+		var batchResp []map[string]interface{}
+		require.NoError(t, sonic.UnmarshalString(batch, &batchResp))
+		require.Len(t, batchResp, 2)
+		assert.Equal(t, "0x1234", batchResp[0]["result"])
+		assert.Equal(t, "0x1234", batchResp[1]["result"])
+	})
+
+	t.Run("SynthesizesNewHeadsAndLogsFromHttpUpstreams", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		// Heads follow the latest block of the state poller mocks, the network is not polled for it
+		var blockNumberCalls atomic.Int32
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				if strings.Contains(util.SafeReadBody(request), "eth_blockNumber") {
+					blockNumberCalls.Add(1)
+				}
+				return false
+			}).
+			Reply(200)
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_getBlockByNumber") && strings.Contains(body, "0x11118888")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x11118888","hash":"0xaaaa","parentHash":"0xbbbb","transactions":["0xcccc"]}}`))
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				body := util.SafeReadBody(request)
+				return strings.Contains(body, "eth_getLogs") && strings.Contains(body, "0xaaaa")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":[{"address":"0x2222222222222222222222222222222222222222","blockHash":"0xaaaa","blockNumber":"0x11118888","logIndex":"0x0","topics":[]}]}`))
+
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(createWebSocketTestConfig(), t)
+		defer shutdown()
+
+		conn := dialTestWebSocket(t, baseURL)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`))
+		resp := receiveWebSocketMessage(t, conn)
+		headsSubId, ok := resp["result"].(string)
+		require.True(t, ok, "expected subscription id, got: %v", resp)
+
+		notif := receiveWebSocketMessage(t, conn)
+		assert.Equal(t, "eth_subscription", notif["method"])
+		params := notif["params"].(map[string]interface{})
+		assert.Equal(t, headsSubId, params["subscription"])
+		head := params["result"].(map[string]interface{})
+		assert.Equal(t, "0x11118888", head["number"])
+		assert.Equal(t, "0xaaaa", head["hash"])
+		assert.NotContains(t, head, "transactions")
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":2,"method":"eth_unsubscribe","params":["`+headsSubId+`"]}`))
+		resp = receiveWebSocketMessage(t, conn)
+		assert.Equal(t, true, resp["result"])
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":3,"method":"eth_subscribe","params":["logs",{"address":"0x2222222222222222222222222222222222222222"}]}`))
+		resp = receiveWebSocketMessage(t, conn)
+		logsSubId, ok := resp["result"].(string)
+		require.True(t, ok, "expected subscription id, got: %v", resp)
+
+		notif = receiveWebSocketMessage(t, conn)
+		params = notif["params"].(map[string]interface{})
+		assert.Equal(t, logsSubId, params["subscription"])
+		lg := params["result"].(map[string]interface{})
+		assert.Equal(t, "0xaaaa", lg["blockHash"])
+		assert.Zero(t, blockNumberCalls.Load())
+	})
+
+	t.Run("AppliesRateLimitBudgetsToSubscriptions", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		cfg := createWebSocketTestConfig()
+		cfg.RateLimiters = &common.RateLimiterConfig{
+			Budgets: []*common.RateLimitBudgetConfig{
+				{
+					Id: "subscriptions",
+					Rules: []*common.RateLimitRuleConfig{
+						{
+							Method:   "eth_subscribe",
+							MaxCount: 1,
+							Period:   common.Duration(time.Minute),
+						},
+					},
+				},
+			},
+		}
+		cfg.Projects[0].RateLimitBudget = "subscriptions"
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		conn := dialTestWebSocket(t, baseURL)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`))
+		resp := receiveWebSocketMessage(t, conn)
+		_, ok := resp["result"].(string)
+		require.True(t, ok, "expected subscription id, got: %v", resp)
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":2,"method":"eth_subscribe","params":["newHeads"]}`))
+		for {
+			resp = receiveWebSocketMessage(t, conn)
+			if resp["method"] == nil {
+				break
+			}
+		}
+		require.Nil(t, resp["result"], "expected no subscription, got: %v", resp)
+		errObj, ok := resp["error"].(map[string]interface{})
+		require.True(t, ok, "expected error, got: %v", resp)
+		assert.Contains(t, errObj["message"], "rate-limit")
+	})
+
+	t.Run("BoundsMessagesHandledConcurrentlyPerConnection", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		delay := 300 * time.Millisecond
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+			}).
+			Reply(200).
+			Delay(delay).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1234"}`))
+
+		cfg := createWebSocketTestConfig()
+		cfg.Server.WebSocket.MaxInFlightPerConnection = 1
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		conn := dialTestWebSocket(t, baseURL)
+		defer conn.Close()
+
+		start := time.Now()
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, websocket.Message.Send(conn, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x%d"]}`, i, i)))
+		}
+		for i := 1; i <= 3; i++ {
+			resp := receiveWebSocketMessage(t, conn)
+			assert.Equal(t, "0x1234", resp["result"])
+		}
+		// Messages are handled one after the other instead of concurrently
+		assert.GreaterOrEqual(t, time.Since(start), 3*delay)
+	})
+
+	t.Run("RejectsUnsupportedSubscriptionType", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(createWebSocketTestConfig(), t)
+		defer shutdown()
+
+		conn := dialTestWebSocket(t, baseURL)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newPendingTransactions"]}`))
+		resp := receiveWebSocketMessage(t, conn)
+		errObj, ok := resp["error"].(map[string]interface{})
+		require.True(t, ok, "expected error, got: %v", resp)
+		assert.Equal(t, float64(common.JsonRpcErrorInvalidArgument), errObj["code"])
+	})
+
+	t.Run("RejectsSubscriptionsOnNetworksNotAllowedForUser", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		cfg := createWebSocketTestConfig()
+		cfg.Projects[0].Auth = &common.AuthConfig{
+			Strategies: []*common.AuthStrategyConfig{
+				{
+					Type: common.AuthTypeDatabase,
+					Database: &common.DatabaseStrategyConfig{
+						Connector: &common.ConnectorConfig{
+							Id:     "keys",
+							Driver: common.DriverMemory,
+							Memory: &common.MemoryConnectorConfig{
+								MaxItems: 100, MaxTotalSize: "1MB",
+							},
+						},
+					},
+				},
+			},
+		}
+		_, _, baseURL, shutdown, erpcInstance := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		prj, err := erpcInstance.GetProject("test_project")
+		require.NoError(t, err)
+		strategy, err := prj.consumerAuthRegistry.FindDatabaseStrategy("keys")
+		require.NoError(t, err)
+		require.NoError(t, strategy.GetConnector().Set(context.Background(), "other-network-key", "*", []byte(`{"userId":"restricted","allowedNetworks":["1"]}`), nil))
+
+		wsUrl := "ws" + strings.TrimPrefix(baseURL, "http") + "/test_project/evm/123?secret=other-network-key"
+		conn, err := websocket.Dial(wsUrl, "", baseURL)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`))
+		resp := receiveWebSocketMessage(t, conn)
+		require.Nil(t, resp["result"], "expected no subscription, got: %v", resp)
+		errObj, ok := resp["error"].(map[string]interface{})
+		require.True(t, ok, "expected error, got: %v", resp)
+		assert.Contains(t, errObj["message"], "network evm:123 is not allowed")
+	})
+
+	t.Run("AuthenticatesAllMessagesOfConnectionWithSingleUseSiweNonce", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1234"}`))
+
+		cfg := createWebSocketTestConfig()
+		cfg.Projects[0].Auth = &common.AuthConfig{
+			Strategies: []*common.AuthStrategyConfig{
+				{
+					Type: common.AuthTypeSiwe,
+					Siwe: &common.SiweStrategyConfig{
+						AllowedDomains: []string{"app.example.com"},
+						Connector: &common.ConnectorConfig{
+							Id:     "siwe",
+							Driver: common.DriverMemory,
+							Memory: &common.MemoryConnectorConfig{
+								MaxItems: 100, MaxTotalSize: "1MB",
+							},
+						},
+					},
+				},
+			},
+		}
+		sendRequest, _, baseURL, shutdown, _ := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		statusCode, _, body := sendRequest(`{"jsonrpc":"2.0","id":1,"method":"erpc_siweNonce","params":[]}`, nil, nil)
+		require.Equal(t, http.StatusOK, statusCode, body)
+		var nonceResp map[string]interface{}
+		require.NoError(t, sonic.UnmarshalString(body, &nonceResp))
+		nonce := nonceResp["result"].(map[string]interface{})["nonce"].(string)
+
+		message, signature := signTestSiweMessage(t, nonce)
+		wsUrl := "ws" + strings.TrimPrefix(baseURL, "http") + "/test_project/evm/123?" + url.Values{
+			"message":   []string{message},
+			"signature": []string{signature},
+		}.Encode()
+		conn, err := websocket.Dial(wsUrl, "", baseURL)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, websocket.Message.Send(conn, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x1"]}`, i)))
+			resp := receiveWebSocketMessage(t, conn)
+			assert.Equal(t, "0x1234", resp["result"], "message %d must be authenticated, got: %v", i, resp)
+		}
+
+		// The nonce is still consumed for other connections
+		other, err := websocket.Dial(wsUrl, "", baseURL)
+		require.NoError(t, err)
+		defer other.Close()
+		require.NoError(t, websocket.Message.Send(other, `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x1111111111111111111111111111111111111111","0x1"]}`))
+		resp := receiveWebSocketMessage(t, other)
+		assert.Nil(t, resp["result"])
+		assert.Contains(t, resp, "error")
+	})
+
+	t.Run("UnsubscribesFromWebSocketUpstreamWhenLastSubscriptionStops", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+
+		var mu sync.Mutex
+		var upstreamMethods []string
+		upstreamSrv := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+			for {
+				var msg string
+				if err := websocket.Message.Receive(conn, &msg); err != nil {
+					return
+				}
+				var req map[string]interface{}
+				if err := sonic.UnmarshalString(msg, &req); err != nil {
+					return
+				}
+				method, _ := req["method"].(string)
+				mu.Lock()
+				upstreamMethods = append(upstreamMethods, method)
+				mu.Unlock()
+
+				var result interface{}
+				switch method {
+				case "eth_chainId":
+					result = "0x7b"
+				case "eth_subscribe":
+					result = "0xupstreamsub"
+				case "eth_unsubscribe":
+					result = true
+				case "eth_blockNumber":
+					result = "0x11118888"
+				case "eth_getBlockByNumber":
+					result = map[string]interface{}{"number": "0x11118888", "hash": "0xaaaa", "parentHash": "0xbbbb"}
+				}
+				resp, _ := sonic.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": result})
+				_ = websocket.Message.Send(conn, string(resp))
+			}
+		}))
+		defer upstreamSrv.Close()
+
+		cfg := createWebSocketTestConfig()
+		cfg.Projects[0].Upstreams = append(cfg.Projects[0].Upstreams, &common.UpstreamConfig{
+			Id:       "rpc2",
+			Type:     common.UpstreamTypeEvm,
+			Endpoint: "ws" + strings.TrimPrefix(upstreamSrv.URL, "http"),
+			Evm: &common.EvmUpstreamConfig{
+				ChainId: 123,
+			},
+		})
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_blockNumber")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x11118888"}`))
+		gock.New("http://rpc1.localhost").
+			Post("").
+			Persist().
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getBlockByNumber")
+			}).
+			Reply(200).
+			JSON([]byte(`{"jsonrpc":"2.0","id":1,"result":{"number":"0x11118888","hash":"0xaaaa","parentHash":"0xbbbb"}}`))
+
+		_, _, baseURL, shutdown, _ := createServerTestFixtures(cfg, t)
+		defer shutdown()
+
+		countUpstreamCalls := func(method string) int {
+			mu.Lock()
+			defer mu.Unlock()
+			n := 0
+			for _, m := range upstreamMethods {
+				if m == method {
+					n++
+				}
+			}
+			return n
+		}
+
+		conn := dialTestWebSocket(t, baseURL)
+		defer conn.Close()
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":1,"method":"eth_subscribe","params":["newHeads"]}`))
+		resp := receiveWebSocketMessage(t, conn)
+		subId, ok := resp["result"].(string)
+		require.True(t, ok, "expected subscription id, got: %v", resp)
+		require.Eventually(t, func() bool { return countUpstreamCalls("eth_subscribe") > 0 }, 5*time.Second, 20*time.Millisecond)
+		assert.Equal(t, 0, countUpstreamCalls("eth_unsubscribe"))
+
+		require.NoError(t, websocket.Message.Send(conn, `{"jsonrpc":"2.0","id":2,"method":"eth_unsubscribe","params":["`+subId+`"]}`))
+		// Skip notifications which may still be in flight
+		for {
+			resp = receiveWebSocketMessage(t, conn)
+			if resp["method"] == nil {
+				break
+			}
+		}
+		assert.Equal(t, true, resp["result"])
+		require.Eventually(t, func() bool { return countUpstreamCalls("eth_unsubscribe") > 0 }, 5*time.Second, 20*time.Millisecond)
+	})
+}
@@ -43,10 +43,13 @@ type Network struct {
 	failsafeExecutors        []*FailsafeExecutor
 	rateLimitersRegistry     *upstream.RateLimitersRegistry
 	cacheDal                 common.CacheDAL
+	evmFilters               *EvmFilterManager
+	evmTxBroadcaster         *EvmTxBroadcaster
 	metricsTracker           *health.Tracker
 	upstreamsRegistry        *upstream.UpstreamsRegistry
 	selectionPolicyEvaluator *PolicyEvaluator
 	initializer              *util.Initializer
+	disputeStore             *DisputeStore
 }
 
 func (n *Network) Bootstrap(ctx context.Context) error {
@@ -390,7 +393,7 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 	}
 
 	// 3) Apply rate limits
-	if err := n.acquireRateLimitPermit(req); err != nil {
+	if err := n.acquireRateLimitPermit(ctx, req); err != nil {
 		if mlx != nil {
 			mlx.Close(ctx, nil, err)
 		}
@@ -405,6 +408,19 @@ func (n *Network) Forward(ctx context.Context, req *common.NormalizedRequest) (*
 		return nil, err
 	}
 
+	// Raw transactions are sent to several upstreams at once rather than failing over one by one
+	if n.evmTxBroadcaster != nil && method == "eth_sendRawTransaction" {
+		if handled, resp, err := n.evmTxBroadcaster.Handle(ctx, req, upsList); handled {
+			if mlx != nil {
+				mlx.Close(ctx, resp, err)
+			}
+			if err != nil {
+				common.SetTraceSpanError(forwardSpan, err)
+			}
+			return resp, err
+		}
+	}
+
 	// 5) Iterate over upstreams and forward the request until success or fatal failure
 	tryForward := func(
 		u common.Upstream,
@@ -812,6 +828,13 @@ func (n *Network) GetMethodMetrics(method string) common.TrackedMetrics {
 	return mt
 }
 
+func (n *Network) DisputeRecorder() common.DisputeRecorder {
+	if n.disputeStore == nil {
+		return nil
+	}
+	return n.disputeStore
+}
+
 func (n *Network) Config() *common.NetworkConfig {
 	return n.cfg
 }
@@ -1137,7 +1160,7 @@ func (n *Network) normalizeResponse(ctx context.Context, req *common.NormalizedR
 	return nil
 }
 
-func (n *Network) acquireRateLimitPermit(req *common.NormalizedRequest) error {
+func (n *Network) acquireRateLimitPermit(ctx context.Context, req *common.NormalizedRequest) error {
 	if n.cfg.RateLimitBudget == "" {
 		return nil
 	}
@@ -1164,7 +1187,7 @@ func (n *Network) acquireRateLimitPermit(req *common.NormalizedRequest) error {
 
 	if len(rules) > 0 {
 		for _, rule := range rules {
-			permit := rule.Limiter.TryAcquirePermit()
+			permit := rlb.TryAcquirePermit(ctx, rule, method)
 			if !permit {
 				finality := req.Finality(context.Background())
 				telemetry.CounterHandle(telemetry.MetricNetworkRequestSelfRateLimited,
@@ -1178,8 +1201,7 @@ func (n *Network) acquireRateLimitPermit(req *common.NormalizedRequest) error {
 				return common.NewErrNetworkRateLimitRuleExceeded(
 					n.projectId,
 					n.networkId,
-					n.cfg.RateLimitBudget,
-					fmt.Sprintf("%+v", rule.Config),
+					rlb.RuleInfo(rule),
 				)
 			} else {
 				lg.Debug().Object("rateLimitRule", rule.Config).Msgf("network-level rate limit passed")
@@ -2136,6 +2136,112 @@ func TestConsensusPolicy(t *testing.T) {
 			},
 			expectedPendingMocks: 0,
 		},
+		{
+			name:        "weighted_own_node_outweighs_two_third_party_upstreams",
+			description: "Own node with weight 3 alone reaches threshold 3 while two third-party upstreams agreeing on another value only have 2 votes",
+			upstreams:   createWeightedTestUpstreams(3, "own"),
+			consensusConfig: &common.ConsensusPolicyConfig{
+				MaxParticipants:         3,
+				AgreementThreshold:      3,
+				DisputeBehavior:         common.ConsensusDisputeBehaviorReturnError,
+				LowParticipantsBehavior: common.ConsensusLowParticipantsBehaviorReturnError,
+				PreferNonEmpty:          &common.FALSE,
+				PreferLargerResponses:   &common.FALSE,
+				Weights: []*common.ConsensusWeightConfig{
+					{Group: "own", Weight: 3},
+				},
+			},
+			mockResponses: []mockResponse{
+				{status: 200, body: jsonRpcSuccess("0xaaa")}, // own node
+				{status: 200, body: jsonRpcSuccess("0xbbb")},
+				{status: 200, body: jsonRpcSuccess("0xbbb")},
+			},
+			expectedCalls:  []int{1, 1, 1},
+			expectedResult: &expectedResult{jsonRpcResult: "\"0xaaa\""},
+		},
+		{
+			name:        "weighted_votes_below_threshold_is_dispute",
+			description: "Threshold of 4 is not reached by the own node (3 votes) nor by two agreeing third-party upstreams (2 votes)",
+			upstreams:   createWeightedTestUpstreams(3, "own"),
+			consensusConfig: &common.ConsensusPolicyConfig{
+				MaxParticipants:         3,
+				AgreementThreshold:      4,
+				DisputeBehavior:         common.ConsensusDisputeBehaviorReturnError,
+				LowParticipantsBehavior: common.ConsensusLowParticipantsBehaviorReturnError,
+				PreferNonEmpty:          &common.FALSE,
+				PreferLargerResponses:   &common.FALSE,
+				Weights: []*common.ConsensusWeightConfig{
+					{Upstream: "test-up-1", Weight: 3},
+				},
+			},
+			mockResponses: []mockResponse{
+				{status: 200, body: jsonRpcSuccess("0xaaa")}, // own node
+				{status: 200, body: jsonRpcSuccess("0xbbb")},
+				{status: 200, body: jsonRpcSuccess("0xbbb")},
+			},
+			expectedCalls: []int{1, 1, 1},
+			expectedError: &expectedError{
+				code:     common.ErrCodeConsensusDispute,
+				contains: "not enough agreement among responses",
+			},
+		},
+		{
+			name:        "weight_by_agreement_rate_unanimous_still_reaches_threshold",
+			description: "All upstreams agreed with consensus 90% of the time, their unanimous responses still reach threshold 3",
+			upstreams:   createTestUpstreams(3),
+			consensusConfig: &common.ConsensusPolicyConfig{
+				MaxParticipants:         3,
+				AgreementThreshold:      3,
+				DisputeBehavior:         common.ConsensusDisputeBehaviorReturnError,
+				LowParticipantsBehavior: common.ConsensusLowParticipantsBehaviorReturnError,
+				PreferNonEmpty:          &common.FALSE,
+				PreferLargerResponses:   &common.FALSE,
+				WeightByAgreementRate:   true,
+			},
+			mockResponses: []mockResponse{
+				{status: 200, body: jsonRpcSuccess("0xaaa")},
+				{status: 200, body: jsonRpcSuccess("0xaaa")},
+				{status: 200, body: jsonRpcSuccess("0xaaa")},
+			},
+			expectedCalls:  []int{1, 1, 1},
+			expectedResult: &expectedResult{jsonRpcResult: "\"0xaaa\""},
+			setupFn: func(t *testing.T, ctx context.Context, reg *upstream.UpstreamsRegistry) {
+				for _, ups := range reg.GetNetworkUpstreams(ctx, util.EvmNetworkId(123)) {
+					for i := 0; i < 10; i++ {
+						ups.Tracker().RecordUpstreamRequest(ups, "eth_randomMethod")
+					}
+					ups.Tracker().RecordUpstreamMisbehavior(ups, "eth_randomMethod")
+				}
+			},
+		},
+		{
+			name:        "fractional_weights_summing_to_same_votes_are_a_tie",
+			description: "0.1 + 0.2 agreeing upstreams tie with a single 0.3 upstream despite floating point rounding, so there is no unique leader",
+			upstreams:   createTestUpstreams(3),
+			consensusConfig: &common.ConsensusPolicyConfig{
+				MaxParticipants:         3,
+				AgreementThreshold:      1,
+				DisputeBehavior:         common.ConsensusDisputeBehaviorAcceptMostCommonValidResult,
+				LowParticipantsBehavior: common.ConsensusLowParticipantsBehaviorAcceptMostCommonValidResult,
+				PreferNonEmpty:          &common.FALSE,
+				PreferLargerResponses:   &common.FALSE,
+				Weights: []*common.ConsensusWeightConfig{
+					{Upstream: "test-up-1", Weight: 0.1},
+					{Upstream: "test-up-2", Weight: 0.2},
+					{Upstream: "test-up-3", Weight: 0.3},
+				},
+			},
+			mockResponses: []mockResponse{
+				{status: 200, body: jsonRpcSuccess("0xaaa")},
+				{status: 200, body: jsonRpcSuccess("0xaaa")},
+				{status: 200, body: jsonRpcSuccess("0xbbb")},
+			},
+			expectedCalls: []int{1, 1, 1},
+			expectedError: &expectedError{
+				code:     common.ErrCodeConsensusDispute,
+				contains: "not enough agreement among responses",
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -2292,6 +2398,81 @@ func TestConsensusGoroutineCancellationIntegration(t *testing.T) {
 	}
 }
 
+func TestConsensusPolicy_RecordsDisputes(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+	defer util.AssertNoPendingMocks(t, 0)
+
+	upstreams := createTestUpstreams(3)
+	results := []string{"0x1", "0x1234567890abcdef1234", "0x1234567890abcdef1234"}
+	for i, upstreamCfg := range upstreams {
+		// The dissenting upstream responds first so that it participates before the agreement short-circuits
+		gock.New(upstreamCfg.Endpoint).
+			Post("").
+			Filter(func(request *http.Request) bool {
+				return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+			}).
+			Reply(200).
+			Delay(time.Duration(i*20) * time.Millisecond).
+			JSON(jsonRpcSuccess(results[i]))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ntw, _ := setupNetworkForConsensusTest(t, ctx, consensusTestCase{
+		upstreams: upstreams,
+		consensusConfig: &common.ConsensusPolicyConfig{
+			MaxParticipants:         3,
+			AgreementThreshold:      2,
+			DisputeBehavior:         common.ConsensusDisputeBehaviorReturnError,
+			LowParticipantsBehavior: common.ConsensusLowParticipantsBehaviorReturnError,
+		},
+	})
+	store := newTestDisputeStore(t, "prjA", true)
+	ntw.disputeStore = store
+
+	req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","latest"],"id":1}`))
+	req.SetNetwork(ntw)
+	resp, err := ntw.Forward(ctx, req)
+	require.NoError(t, err)
+	jrr, err := resp.JsonRpcResponse()
+	require.NoError(t, err)
+	assert.Equal(t, `"0x1234567890abcdef1234"`, jrr.GetResultString())
+
+	records := listDisputes(t, store, 1)
+	record := records[0]
+	assert.Equal(t, common.DisputeKindConsensus, record.Kind)
+	assert.Equal(t, "prjA", record.ProjectId)
+	assert.Equal(t, "evm:123", record.NetworkId)
+	assert.Equal(t, "eth_getBalance", record.Method)
+	assert.Equal(t, "result", record.Outcome)
+	assert.NotEmpty(t, record.OutcomeHash)
+	assert.Len(t, record.Request, 16)
+	assert.True(t, record.RequestTruncated)
+
+	require.Len(t, record.Participants, 3)
+	participants := make(map[string]*common.DisputeParticipant, 3)
+	for _, p := range record.Participants {
+		participants[p.Upstream] = p
+	}
+	dissenter := participants["test-up-1"]
+	require.NotNil(t, dissenter)
+	assert.False(t, dissenter.AgreesWithOutcome)
+	assert.NotEqual(t, record.OutcomeHash, dissenter.ResponseHash)
+	assert.Equal(t, `"0x1"`, dissenter.Response)
+	assert.False(t, dissenter.ResponseTruncated)
+	for _, id := range []string{"test-up-2", "test-up-3"} {
+		p := participants[id]
+		require.NotNil(t, p, "missing participant %s", id)
+		assert.True(t, p.AgreesWithOutcome)
+		assert.Equal(t, record.OutcomeHash, p.ResponseHash)
+		assert.Equal(t, `"0x1234567890abc`, p.Response)
+		assert.True(t, p.ResponseTruncated)
+	}
+}
+
 // Helper functions for test setup
 
 func runConsensusTest(t *testing.T, tc consensusTestCase) {
@@ -2473,6 +2654,13 @@ func createTestUpstreams(count int) []*common.UpstreamConfig {
 	return upstreams
 }
 
+// createWeightedTestUpstreams creates test upstreams where the first one belongs to the given group.
+func createWeightedTestUpstreams(count int, group string) []*common.UpstreamConfig {
+	upstreams := createTestUpstreams(count)
+	upstreams[0].Group = group
+	return upstreams
+}
+
 func jsonRpcSuccess(result interface{}) map[string]interface{} {
 	return map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -271,11 +271,29 @@ func (nr *NetworksRegistry) prepareNetwork(nwCfg *common.NetworkConfig) (*Networ
 	if err != nil {
 		return nil, err
 	}
+	network.disputeStore = nr.project.disputeStore
 
 	switch nwCfg.Architecture {
 	case "evm":
 		if nr.evmJsonRpcCache != nil {
 			network.cacheDal = nr.evmJsonRpcCache.WithProjectId(nr.project.Config.Id)
+			if nr.metricsTracker != nil {
+				// Block hashes observed by state pollers are used to invalidate cached data of reorged blocks
+				cache := nr.evmJsonRpcCache
+				nr.metricsTracker.OnBlockHeadEvent(network.networkId, func(event *health.BlockHeadEvent) {
+					if event.Type == health.BlockHeadEventBlockHash {
+						cache.RecordBlockHash(event.NetworkId, event.BlockNumber, event.BlockHash)
+					}
+				})
+			}
+		}
+		if nwCfg.Evm != nil && nwCfg.Evm.Filters != nil && nwCfg.Evm.Filters.Enabled != nil && *nwCfg.Evm.Filters.Enabled {
+			if ssr := nr.upstreamsRegistry.GetSharedStateRegistry(); ssr != nil {
+				network.evmFilters = NewEvmFilterManager(nr.logger, network, nwCfg.Evm.Filters, ssr)
+			}
+		}
+		if nwCfg.Evm != nil && nwCfg.Evm.TxBroadcast != nil && nwCfg.Evm.TxBroadcast.Enabled != nil && *nwCfg.Evm.TxBroadcast.Enabled {
+			network.evmTxBroadcaster = NewEvmTxBroadcaster(nr.logger, network, nwCfg.Evm.TxBroadcast)
 		}
 	default:
 		return nil, errors.New("unknown network architecture")
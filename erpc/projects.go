@@ -23,6 +23,7 @@ type PreparedProject struct {
 	consumerAuthRegistry *auth.AuthRegistry
 	rateLimitersRegistry *upstream.RateLimitersRegistry
 	upstreamsRegistry    *upstream.UpstreamsRegistry
+	disputeStore         *DisputeStore
 	cfgMu                sync.RWMutex
 }
 
@@ -36,6 +37,14 @@ func (p *PreparedProject) Bootstrap(appCtx context.Context) {
 	p.networksRegistry.Bootstrap(appCtx)
 }
 
+// ComputeUnits returns the cost of a method as configured for rate limiters (or the defaults otherwise).
+func (p *PreparedProject) ComputeUnits(method string) uint {
+	if p.rateLimitersRegistry == nil {
+		return 1
+	}
+	return p.rateLimitersRegistry.ComputeUnits(method)
+}
+
 func (p *PreparedProject) GetNetwork(ctx context.Context, networkId string) (*Network, error) {
 	return p.networksRegistry.GetNetwork(ctx, networkId)
 }
@@ -83,17 +92,76 @@ func (p *PreparedProject) AuthenticateConsumer(ctx context.Context, method strin
 	return nil, nil
 }
 
+// HandleSiweRequest issues SIWE nonces and session tokens, which wallets request before being authenticated.
+// As such they are only limited by the project rate limit budget, and nonces by client IP.
+func (p *PreparedProject) HandleSiweRequest(ctx context.Context, nq *common.NormalizedRequest, ap *auth.AuthPayload) (*common.NormalizedResponse, error) {
+	jrr, err := nq.JsonRpcRequest()
+	if err != nil {
+		return nil, err
+	}
+	if p.consumerAuthRegistry == nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("%s requires a siwe auth strategy with a connector", jrr.Method))
+	}
+	strategy, err := p.consumerAuthRegistry.FindSiweStrategy()
+	if err != nil {
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("%s requires a siwe auth strategy with a connector: %w", jrr.Method, err))
+	}
+	if err := p.acquireRateLimitPermit(ctx, nq); err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	switch jrr.Method {
+	case auth.SiweMethodNonce:
+		if err := p.acquireSiweNoncePermit(ctx, strategy, ap); err != nil {
+			return nil, err
+		}
+		result, err = strategy.IssueNonce(ctx)
+	case auth.SiweMethodSession:
+		if !strategy.SupportsSessions() {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("SIWE sessions are not enabled, set sessionTtl on the siwe strategy"))
+		}
+		var params map[string]interface{}
+		if len(jrr.Params) > 0 {
+			params, _ = jrr.Params[0].(map[string]interface{})
+		}
+		message, _ := params["message"].(string)
+		signature, _ := params["signature"].(string)
+		if message == "" || signature == "" {
+			return nil, common.NewErrInvalidRequest(fmt.Errorf("requires params: {message, signature}"))
+		}
+		result, err = strategy.CreateSession(ctx, auth.NewSiwePayload(message, signature))
+	default:
+		return nil, common.NewErrInvalidRequest(fmt.Errorf("unsupported SIWE method %s", jrr.Method))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	jrrs, err := common.NewJsonRpcResponse(jrr.ID, result, nil)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewNormalizedResponse().WithJsonRpcResponse(jrrs), nil
+}
+
 func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
 	start := time.Now()
 	ctx, span := common.StartDetailSpan(ctx, "Project.Forward")
 	defer span.End()
 
+	if user := nq.User(); !user.IsNetworkAllowed(networkId) {
+		err := common.NewErrAuthForbidden("", user.Id, fmt.Sprintf("network %s is not allowed for this user", networkId))
+		common.SetTraceSpanError(span, err)
+		return nil, err
+	}
+
 	network, err := p.networksRegistry.GetNetwork(ctx, networkId)
 	if err != nil {
 		common.SetTraceSpanError(span, err)
 		return nil, err
 	}
-	if err := p.acquireRateLimitPermit(nq); err != nil {
+	if err := p.acquireRateLimitPermit(ctx, nq); err != nil {
 		common.SetTraceSpanError(span, err)
 		return nil, err
 	}
@@ -106,6 +174,9 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 	telemetry.CounterHandle(telemetry.MetricNetworkRequestsReceived,
 		p.Config.Id, network.Label(), method, reqFinality.String(), nq.UserId(), nq.AgentName(),
 	).Inc()
+	telemetry.CounterHandle(telemetry.MetricNetworkComputeUnitsTotal,
+		p.Config.Id, network.Label(), method, reqFinality.String(), nq.UserId(), nq.AgentName(),
+	).Add(float64(p.ComputeUnits(method)))
 	lg := p.Logger.With().
 		Str("component", "proxy").
 		Str("projectId", p.Config.Id).
@@ -239,6 +310,12 @@ func (p *PreparedProject) Forward(ctx context.Context, networkId string, nq *com
 func (p *PreparedProject) doForward(ctx context.Context, network *Network, nq *common.NormalizedRequest) (*common.NormalizedResponse, error) {
 	switch network.cfg.Architecture {
 	case common.ArchitectureEvm:
+		// Filters are emulated by eRPC so they are not bound to a single upstream
+		if network.evmFilters != nil {
+			if handled, resp, err := network.evmFilters.Handle(ctx, nq); handled {
+				return resp, err
+			}
+		}
 		// Early, project-level pre-forward (cache-affecting, upstream-agnostic)
 		if handled, resp, err := evm.HandleProjectPreForward(ctx, network, nq); handled {
 			return evm.HandleNetworkPostForward(ctx, network, nq, resp, err)
@@ -250,7 +327,37 @@ func (p *PreparedProject) doForward(ctx context.Context, network *Network, nq *c
 	return evm.HandleNetworkPostForward(ctx, network, nq, resp, err)
 }
 
-func (p *PreparedProject) acquireRateLimitPermit(req *common.NormalizedRequest) error {
+// acquireSiweNoncePermit limits nonces by the address of the connection rather than X-Forwarded-For, which
+// clients could otherwise rotate to get unlimited nonces.
+func (p *PreparedProject) acquireSiweNoncePermit(ctx context.Context, strategy *auth.SiweStrategy, ap *auth.AuthPayload) error {
+	perSecond := strategy.NonceRateLimitPerIp()
+	if perSecond == 0 || p.rateLimitersRegistry == nil || ap == nil || ap.Network == nil {
+		return nil
+	}
+	clientIp := strategy.NonceClientIP(ap)
+
+	budget := "siwe-nonce:" + clientIp
+	limiter := p.rateLimitersRegistry.UserRateLimiter(budget, perSecond)
+	if !limiter.TryAcquirePermitsContext(ctx, 1) {
+		telemetry.MetricProjectRequestSelfRateLimited.WithLabelValues(
+			p.Config.Id,
+			auth.SiweMethodNonce,
+		).Inc()
+		return common.NewErrProjectRateLimitRuleExceeded(
+			p.Config.Id,
+			&common.RateLimitRuleInfo{
+				Budget:   budget,
+				Method:   auth.SiweMethodNonce,
+				MaxCount: perSecond,
+				Period:   common.Duration(time.Second),
+				ResetIn:  common.Duration(limiter.ResetIn().Round(time.Millisecond)),
+			},
+		)
+	}
+	return nil
+}
+
+func (p *PreparedProject) acquireRateLimitPermit(ctx context.Context, req *common.NormalizedRequest) error {
 	if p.Config.RateLimitBudget == "" {
 		return nil
 	}
@@ -277,7 +384,7 @@ func (p *PreparedProject) acquireRateLimitPermit(req *common.NormalizedRequest)
 
 	if len(rules) > 0 {
 		for _, rule := range rules {
-			permit := rule.Limiter.TryAcquirePermit()
+			permit := rlb.TryAcquirePermit(ctx, rule, method)
 			if !permit {
 				telemetry.MetricProjectRequestSelfRateLimited.WithLabelValues(
 					p.Config.Id,
@@ -285,8 +392,7 @@ func (p *PreparedProject) acquireRateLimitPermit(req *common.NormalizedRequest)
 				).Inc()
 				return common.NewErrProjectRateLimitRuleExceeded(
 					p.Config.Id,
-					p.Config.RateLimitBudget,
-					fmt.Sprintf("%+v", rule.Config),
+					rlb.RuleInfo(rule),
 				)
 			} else {
 				lg.Debug().Object("rateLimitRule", rule.Config).Msgf("project-level rate limit passed")
@@ -141,6 +141,14 @@ func (r *ProjectsRegistry) RegisterProject(prjCfg *common.ProjectConfig) (*Prepa
 		pp.consumerAuthRegistry = consumerAuthRegistry
 	}
 
+	if prjCfg.Disputes != nil {
+		disputeStore, err := NewDisputeStore(r.appCtx, &lg, prjCfg.Id, prjCfg.Disputes)
+		if err != nil {
+			return nil, err
+		}
+		pp.disputeStore = disputeStore
+	}
+
 	pp.upstreamsRegistry = upstreamsRegistry
 	pp.networksRegistry = NewNetworksRegistry(
 		pp,
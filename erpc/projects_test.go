@@ -16,6 +16,8 @@ import (
 	"github.com/erpc/erpc/util"
 	"github.com/h2non/gock"
 	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProject_Forward(t *testing.T) {
@@ -120,6 +122,123 @@ func TestProject_Forward(t *testing.T) {
 
 		log.Logger.Info().Msgf("Last Resp: %+v", lastResp)
 	})
+
+	t.Run("RecordsShadowMismatchAsDispute", func(t *testing.T) {
+		util.ResetGock()
+		defer util.ResetGock()
+		util.SetupMocksForEvmStatePoller()
+		defer util.AssertNoPendingMocks(t, 0)
+
+		for host, result := range map[string]string{
+			"rpc1": "0x1234567890abcdef1234",
+			"rpc2": "0x1",
+		} {
+			gock.New("http://" + host + ".localhost").
+				Post("").
+				Filter(func(request *http.Request) bool {
+					return strings.Contains(util.SafeReadBody(request), "eth_getBalance")
+				}).
+				Reply(200).
+				JSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+		}
+
+		rateLimitersRegistry, err := upstream.NewRateLimitersRegistry(&common.RateLimiterConfig{}, &log.Logger)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ssr, err := data.NewSharedStateRegistry(ctx, &log.Logger, &common.SharedStateConfig{
+			Connector: &common.ConnectorConfig{
+				Driver: "memory",
+				Memory: &common.MemoryConnectorConfig{
+					MaxItems: 100_000, MaxTotalSize: "1GB",
+				},
+			},
+		})
+		require.NoError(t, err)
+		prjReg, err := NewProjectsRegistry(
+			ctx,
+			&log.Logger,
+			[]*common.ProjectConfig{
+				{
+					Id:       "prjA",
+					Disputes: newTestDisputesConfig(t, true),
+					Networks: []*common.NetworkConfig{
+						{
+							Architecture: common.ArchitectureEvm,
+							Evm: &common.EvmNetworkConfig{
+								ChainId: 123,
+							},
+						},
+					},
+					Upstreams: []*common.UpstreamConfig{
+						{
+							Id:       "rpc1",
+							Endpoint: "http://rpc1.localhost",
+							Type:     common.UpstreamTypeEvm,
+							Evm: &common.EvmUpstreamConfig{
+								ChainId: 123,
+							},
+						},
+						{
+							Id:       "shadow1",
+							Endpoint: "http://rpc2.localhost",
+							Type:     common.UpstreamTypeEvm,
+							Evm: &common.EvmUpstreamConfig{
+								ChainId: 123,
+							},
+							Shadow: &common.ShadowUpstreamConfig{
+								Enabled: true,
+							},
+						},
+					},
+				},
+			},
+			ssr,
+			nil,
+			rateLimitersRegistry,
+			thirdparty.NewVendorsRegistry(),
+			nil, // ProxyPoolRegistry
+		)
+		require.NoError(t, err)
+		prjReg.Bootstrap(ctx)
+		time.Sleep(100 * time.Millisecond)
+
+		prj, err := prjReg.GetProject("prjA")
+		require.NoError(t, err)
+
+		req := common.NewNormalizedRequest([]byte(`{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x123","latest"],"id":1}`))
+		resp, err := prj.Forward(ctx, "evm:123", req)
+		require.NoError(t, err)
+		jrr, err := resp.JsonRpcResponse()
+		require.NoError(t, err)
+		assert.Equal(t, `"0x1234567890abcdef1234"`, jrr.GetResultString())
+
+		records := listDisputes(t, prj.disputeStore, 1)
+		record := records[0]
+		assert.Equal(t, common.DisputeKindShadow, record.Kind)
+		assert.Equal(t, "prjA", record.ProjectId)
+		assert.Equal(t, "evm:123", record.NetworkId)
+		assert.Equal(t, "eth_getBalance", record.Method)
+		assert.Equal(t, "result", record.Outcome)
+		assert.NotEmpty(t, record.OutcomeHash)
+		assert.Len(t, record.Request, 16)
+		assert.True(t, record.RequestTruncated)
+
+		require.Len(t, record.Participants, 2)
+		primary, shadow := record.Participants[0], record.Participants[1]
+		assert.Equal(t, "rpc1", primary.Upstream)
+		assert.True(t, primary.AgreesWithOutcome)
+		assert.Equal(t, record.OutcomeHash, primary.ResponseHash)
+		assert.Equal(t, `"0x1234567890abc`, primary.Response)
+		assert.True(t, primary.ResponseTruncated)
+		assert.Equal(t, "shadow1", shadow.Upstream)
+		assert.False(t, shadow.AgreesWithOutcome)
+		assert.NotEqual(t, record.OutcomeHash, shadow.ResponseHash)
+		assert.Equal(t, `"0x1"`, shadow.Response)
+		assert.False(t, shadow.ResponseTruncated)
+	})
 }
 func TestProject_TimeoutScenarios(t *testing.T) {
 	t.Run("UpstreamTimeout", func(t *testing.T) {
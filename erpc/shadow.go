@@ -241,9 +241,68 @@ func (p *PreparedProject) executeShadowRequests(ctx context.Context, network *Ne
 					Object("originalResponse", resp).
 					Object("shadowResponse", shadowResp).
 					Msg("shadow response hash mismatch")
+				p.recordShadowDispute(shadowCtx, network, method, shadowReq, resp, ups, shadowResp, expectedHash, shadowHash)
 			}
 
 			shadowResp.Release()
 		}()
 	}
 }
+
+// recordShadowDispute persists a shadow mismatch when the project keeps dispute records.
+func (p *PreparedProject) recordShadowDispute(
+	ctx context.Context,
+	network *Network,
+	method string,
+	req *common.NormalizedRequest,
+	resp *common.NormalizedResponse,
+	shadowUps *upstream.Upstream,
+	shadowResp *common.NormalizedResponse,
+	expectedHash string,
+	shadowHash string,
+) {
+	if p.disputeStore == nil {
+		return
+	}
+
+	primary := &common.DisputeParticipant{
+		ResponseHash:      expectedHash,
+		AgreesWithOutcome: true,
+	}
+	if ups := resp.Upstream(); ups != nil {
+		primary.Upstream = ups.Id()
+		primary.Vendor = ups.VendorName()
+	}
+	if jrr, err := resp.JsonRpcResponse(ctx); err == nil && jrr != nil {
+		primary.ResponseSize = jrr.ResultLength()
+		primary.Response = string(jrr.GetResultBytes())
+	}
+
+	shadow := &common.DisputeParticipant{
+		Upstream:     shadowUps.Id(),
+		Vendor:       shadowUps.VendorName(),
+		ResponseHash: shadowHash,
+	}
+	if jrr, err := shadowResp.JsonRpcResponse(ctx); err == nil && jrr != nil {
+		shadow.ResponseSize = jrr.ResultLength()
+		shadow.Response = string(jrr.GetResultBytes())
+	}
+
+	record := &common.DisputeRecord{
+		Kind:         common.DisputeKindShadow,
+		NetworkId:    network.Id(),
+		Method:       method,
+		Outcome:      "result",
+		OutcomeHash:  expectedHash,
+		Participants: []*common.DisputeParticipant{primary, shadow},
+	}
+	// The raw body is dropped once the request is parsed
+	if body := req.Body(); len(body) > 0 {
+		record.Request = string(body)
+	} else if jrq, err := req.JsonRpcRequest(ctx); err == nil {
+		if body, err := common.SonicCfg.Marshal(jrq); err == nil {
+			record.Request = string(body)
+		}
+	}
+	p.disputeStore.RecordDispute(ctx, record)
+}
@@ -174,9 +174,44 @@ type Tracker struct {
 	headLagGaugeCache             sync.Map // map[ubKey]prometheus.Gauge
 	finalizationLagGaugeCache     sync.Map // map[ubKey]prometheus.Gauge
 	cordonedGaugeCache            sync.Map // map[cordKey]prometheus.Gauge
+	cooldownCounterCache          sync.Map // map[cdKey]prometheus.Counter
 	rollbackGaugeCache            sync.Map // map[ubKey]prometheus.Gauge
+
+	// Listeners notified when network-level block heads move or an upstream rolls back
+	blockHeadListeners  map[string]map[uint64]BlockHeadListener // map[networkId]map[listenerId]BlockHeadListener
+	blockHeadListenerId uint64
+	blockHeadListenerMu sync.RWMutex
+}
+
+type BlockHeadEventType string
+
+const (
+	BlockHeadEventLatest        BlockHeadEventType = "latestBlock"
+	BlockHeadEventFinalized     BlockHeadEventType = "finalizedBlock"
+	BlockHeadEventLargeRollback BlockHeadEventType = "largeRollback"
+	BlockHeadEventBlockHash     BlockHeadEventType = "blockHash"
+)
+
+// BlockHeadEvent is emitted when the highest latest or finalized block of a network advances,
+// when an upstream reports a block head that is too far behind what it reported before,
+// or when the hash of a block is observed on an upstream.
+type BlockHeadEvent struct {
+	Type      BlockHeadEventType
+	NetworkId string
+
+	// BlockNumber is the new network-level value, or the new upstream value for large rollbacks.
+	BlockNumber         int64
+	PreviousBlockNumber int64
+
+	// Only set for large rollbacks and block hashes
+	UpstreamId string
+	Finality   string
+	BlockHash  string
 }
 
+// BlockHeadListener is called synchronously from the state pollers, so it must not block.
+type BlockHeadListener func(event *BlockHeadEvent)
+
 // urdoKey uniquely identifies a MetricUpstreamRequestDuration time series.
 type urdoKey struct {
 	project   string
@@ -314,6 +349,26 @@ func (t *Tracker) getCordonedGauge(up common.Upstream, method, reason string) pr
 	return actual.(prometheus.Gauge)
 }
 
+type cdKey struct {
+	project  string
+	vendor   string
+	network  string
+	upstream string
+	category string
+}
+
+func (t *Tracker) getCooldownCounter(up common.Upstream, method string) prometheus.Counter {
+	key := cdKey{t.projectId, up.VendorName(), up.NetworkLabel(), up.Id(), method}
+	if v, ok := t.cooldownCounterCache.Load(key); ok {
+		return v.(prometheus.Counter)
+	}
+	c := telemetry.MetricUpstreamCooldownSecondsTotal.WithLabelValues(
+		key.project, key.vendor, key.network, key.upstream, key.category,
+	)
+	actual, _ := t.cooldownCounterCache.LoadOrStore(key, c)
+	return actual.(prometheus.Counter)
+}
+
 func (t *Tracker) getRollbackGauge(up common.Upstream) prometheus.Gauge {
 	key := ubKey{t.projectId, up.VendorName(), up.NetworkLabel(), up.Id()}
 	if v, ok := t.rollbackGaugeCache.Load(key); ok {
@@ -333,6 +388,7 @@ func NewTracker(logger *zerolog.Logger, projectId string, windowSize time.Durati
 		projectId:          projectId,
 		windowSize:         windowSize,
 		upstreamsByNetwork: make(map[string][]upstreamKey),
+		blockHeadListeners: make(map[string]map[uint64]BlockHeadListener),
 	}
 }
 
@@ -608,6 +664,12 @@ func (t *Tracker) RecordUpstreamRemoteRateLimited(up common.Upstream, method str
 	t.getRemoteRateLimitedCounter(up, method, userId, agentName).Inc()
 }
 
+// RecordUpstreamCooldown accounts the time an upstream is excluded from routing for the method
+// (or for all methods when method is "*") due to a backoff window requested by the remote endpoint.
+func (t *Tracker) RecordUpstreamCooldown(up common.Upstream, method string, d time.Duration) {
+	t.getCooldownCounter(up, method).Add(d.Seconds())
+}
+
 // --------------------------------------------
 // Accessors
 // --------------------------------------------
@@ -766,6 +828,12 @@ func (t *Tracker) SetLatestBlockNumber(upstream common.Upstream, blockNumber int
 		g := t.getLatestBlockGauge(t.projectId, "*", netLabel, "*")
 		g.Set(float64(blockNumber))
 		needsGlobalUpdate = true
+		t.emitBlockHeadEvent(&BlockHeadEvent{
+			Type:                BlockHeadEventLatest,
+			NetworkId:           net,
+			BlockNumber:         blockNumber,
+			PreviousBlockNumber: oldNtwVal,
+		})
 	}
 
 	// 2) Update this upstream's latest block
@@ -844,6 +912,12 @@ func (t *Tracker) SetFinalizedBlockNumber(upstream common.Upstream, blockNumber
 		g := t.getFinalizedBlockGauge(t.projectId, "*", netLabel, "*")
 		g.Set(float64(blockNumber))
 		needsGlobalUpdate = true
+		t.emitBlockHeadEvent(&BlockHeadEvent{
+			Type:                BlockHeadEventFinalized,
+			NetworkId:           net,
+			BlockNumber:         blockNumber,
+			PreviousBlockNumber: oldNtwVal,
+		})
 	}
 
 	// Update this upstream's finalized block
@@ -902,4 +976,55 @@ func (t *Tracker) RecordBlockHeadLargeRollback(upstream common.Upstream, finalit
 		Msgf("recording block rollback in tracker")
 
 	t.getRollbackGauge(upstream).Set(float64(rollback))
+
+	t.emitBlockHeadEvent(&BlockHeadEvent{
+		Type:                BlockHeadEventLargeRollback,
+		NetworkId:           net,
+		BlockNumber:         newVal,
+		PreviousBlockNumber: currentVal,
+		UpstreamId:          upstream.Id(),
+		Finality:            finality,
+	})
+}
+
+// RecordBlockHash notifies listeners about the hash of a block observed on an upstream,
+// for example so that cached data of reorged blocks can be invalidated.
+func (t *Tracker) RecordBlockHash(upstream common.Upstream, blockNumber int64, blockHash string) {
+	t.emitBlockHeadEvent(&BlockHeadEvent{
+		Type:        BlockHeadEventBlockHash,
+		NetworkId:   upstream.NetworkId(),
+		BlockNumber: blockNumber,
+		UpstreamId:  upstream.Id(),
+		BlockHash:   blockHash,
+	})
+}
+
+// OnBlockHeadEvent registers a listener for block head events of a network,
+// the returned function must be called to remove the listener.
+func (t *Tracker) OnBlockHeadEvent(networkId string, listener BlockHeadListener) func() {
+	t.blockHeadListenerMu.Lock()
+	t.blockHeadListenerId++
+	id := t.blockHeadListenerId
+	if t.blockHeadListeners[networkId] == nil {
+		t.blockHeadListeners[networkId] = make(map[uint64]BlockHeadListener)
+	}
+	t.blockHeadListeners[networkId][id] = listener
+	t.blockHeadListenerMu.Unlock()
+
+	return func() {
+		t.blockHeadListenerMu.Lock()
+		defer t.blockHeadListenerMu.Unlock()
+		delete(t.blockHeadListeners[networkId], id)
+		if len(t.blockHeadListeners[networkId]) == 0 {
+			delete(t.blockHeadListeners, networkId)
+		}
+	}
+}
+
+func (t *Tracker) emitBlockHeadEvent(event *BlockHeadEvent) {
+	t.blockHeadListenerMu.RLock()
+	defer t.blockHeadListenerMu.RUnlock()
+	for _, listener := range t.blockHeadListeners[event.NetworkId] {
+		listener(event)
+	}
 }
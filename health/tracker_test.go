@@ -9,6 +9,7 @@ import (
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
+	promUtil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 )
@@ -281,6 +282,25 @@ func TestTracker(t *testing.T) {
 		assert.GreaterOrEqual(t, metrics1.ResponseQuantiles.GetQuantile(0.90).Seconds(), 0.02)
 		assert.LessOrEqual(t, metrics1.ResponseQuantiles.GetQuantile(0.90).Seconds(), 0.03)
 	})
+
+	t.Run("CooldownMetricsUseMethodCategory", func(t *testing.T) {
+		tracker := NewTracker(&log.Logger, projectID, windowSize)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		tracker.Bootstrap(ctx)
+
+		ups := common.NewFakeUpstream("cooldown")
+		tracker.RecordUpstreamCooldown(ups, "eth_getLogs", 2*time.Second)
+		tracker.RecordUpstreamCooldown(ups, "eth_getLogs", time.Second)
+		tracker.RecordUpstreamCooldown(ups, "*", 5*time.Second)
+
+		assert.Equal(t, 3.0, promUtil.ToFloat64(telemetry.MetricUpstreamCooldownSecondsTotal.WithLabelValues(
+			projectID, ups.VendorName(), ups.NetworkLabel(), ups.Id(), "eth_getLogs",
+		)))
+		assert.Equal(t, 5.0, promUtil.ToFloat64(telemetry.MetricUpstreamCooldownSecondsTotal.WithLabelValues(
+			projectID, ups.VendorName(), ups.NetworkLabel(), ups.Id(), "*",
+		)))
+	})
 }
 
 func simulateRequestMetrics(tracker *Tracker, upstream common.Upstream, method string, total, errors int) {
@@ -449,3 +469,40 @@ func TestFinalizationLagPersistsAcrossResets(t *testing.T) {
 	metrics2Updated := tracker.GetUpstreamMethodMetrics(ups2, "method1")
 	assert.Equal(t, int64(0), metrics2Updated.FinalizationLag.Load(), "upstream2 should now be caught up in finalization")
 }
+
+func TestTrackerBlockHeadEvents(t *testing.T) {
+	tracker := NewTracker(&log.Logger, "test-project", time.Minute)
+
+	ups1 := common.NewFakeUpstream("upstream1")
+	ups2 := common.NewFakeUpstream("upstream2")
+
+	var events []*BlockHeadEvent
+	unsubscribe := tracker.OnBlockHeadEvent("evm:123", func(event *BlockHeadEvent) {
+		events = append(events, event)
+	})
+
+	tracker.SetLatestBlockNumber(ups1, 1000)
+	tracker.SetLatestBlockNumber(ups2, 990)  // behind network head, no event
+	tracker.SetLatestBlockNumber(ups2, 1001) // advances network head
+	tracker.SetFinalizedBlockNumber(ups1, 900)
+	tracker.RecordBlockHeadLargeRollback(ups2, "latest", 1001, 10)
+
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, BlockHeadEventLatest, events[0].Type)
+		assert.Equal(t, int64(1000), events[0].BlockNumber)
+		assert.Equal(t, BlockHeadEventLatest, events[1].Type)
+		assert.Equal(t, int64(1001), events[1].BlockNumber)
+		assert.Equal(t, int64(1000), events[1].PreviousBlockNumber)
+		assert.Equal(t, BlockHeadEventFinalized, events[2].Type)
+		assert.Equal(t, int64(900), events[2].BlockNumber)
+		assert.Equal(t, BlockHeadEventLargeRollback, events[3].Type)
+		assert.Equal(t, "upstream2", events[3].UpstreamId)
+		assert.Equal(t, "latest", events[3].Finality)
+		assert.Equal(t, int64(10), events[3].BlockNumber)
+		assert.Equal(t, int64(1001), events[3].PreviousBlockNumber)
+	}
+
+	unsubscribe()
+	tracker.SetLatestBlockNumber(ups1, 1002)
+	assert.Len(t, events, 4, "no events expected after unsubscribing")
+}
@@ -22,6 +22,12 @@ var (
 		Help:      "Total number of actual requests to upstreams.",
 	}, []string{"project", "vendor", "network", "upstream", "category", "attempt", "composite", "finality", "user", "agent_name"})
 
+	MetricUpstreamComputeUnitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_compute_units_total",
+		Help:      "Total number of compute units consumed by actual requests to upstreams.",
+	}, []string{"project", "vendor", "network", "upstream", "category", "finality", "user", "agent_name"})
+
 	MetricUpstreamErrorTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "upstream_request_errors_total",
@@ -40,6 +46,18 @@ var (
 		Help:      "Total number of remote rate limited requests by upstreams.",
 	}, []string{"project", "vendor", "network", "upstream", "category", "user", "agent_name"})
 
+	MetricUpstreamConcurrencyLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "upstream_concurrency_limit",
+		Help:      "Current adaptive limit of in-flight requests to an upstream.",
+	}, []string{"project", "vendor", "network", "upstream"})
+
+	MetricUpstreamCooldownSecondsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "upstream_cooldown_seconds_total",
+		Help:      "Total time upstreams were excluded from routing due to backoff windows requested by remote rate limits (category is '*' when the whole upstream is in cooldown).",
+	}, []string{"project", "vendor", "network", "upstream", "category"})
+
 	MetricUpstreamSkippedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "upstream_request_skipped_total",
@@ -172,6 +190,12 @@ var (
 		Help:      "Total number of requests received for a network.",
 	}, []string{"project", "network", "category", "finality", "user", "agent_name"})
 
+	MetricNetworkComputeUnitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_compute_units_total",
+		Help:      "Total number of compute units consumed by requests received for a network.",
+	}, []string{"project", "network", "category", "finality", "user", "agent_name"})
+
 	MetricNetworkMultiplexedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "network_multiplexed_request_total",
@@ -214,6 +238,24 @@ var (
 		Help:      "Maximum number of requests allowed per second for a rate limiter budget (including auto-tuner).",
 	}, []string{"budget", "method"})
 
+	MetricRateLimiterStoreFallbackTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "rate_limiter_store_fallback_total",
+		Help:      "Total number of permits decided by the local limiter because the shared rate limiter store was unreachable.",
+	}, []string{"budget", "method"})
+
+	MetricAuthRequestQuotaExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "auth_request_quota_exceeded_total",
+		Help:      "Total number of requests rejected because the daily or monthly quota of the api key is exhausted.",
+	}, []string{"project", "strategy", "user"})
+
+	MetricAuthQuotaAccountingFailureTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "auth_quota_accounting_failure_total",
+		Help:      "Total number of requests whose api key usage could not be accounted, by whether they were allowed (fail-open) or rejected.",
+	}, []string{"connector", "outcome"})
+
 	MetricAuthRequestSelfRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "auth_request_self_rate_limited_total",
@@ -262,6 +304,18 @@ var (
 		Help:      "Total number of cache get skips (i.e. no matching policy found).",
 	}, []string{"project", "network", "category"})
 
+	MetricCacheReorgDetectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_reorg_detected_total",
+		Help:      "Total number of reorgs detected by the cache by depth (number of replaced blocks).",
+	}, []string{"network", "depth"})
+
+	MetricCacheReorgInvalidatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "cache_reorg_invalidated_total",
+		Help:      "Total number of cache entries (or whole block partitions on connectors that support it) invalidated because a block they reference was reorged.",
+	}, []string{"network", "connector"})
+
 	MetricCacheSetOriginalBytes = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "cache_set_original_bytes_total",
@@ -292,6 +346,42 @@ var (
 		Help:      "Total number of CORS requests from disallowed origins.",
 	}, []string{"project", "origin"})
 
+	MetricWebSocketConnectionsActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "websocket_connections_active",
+		Help:      "Current number of open websocket client connections.",
+	}, []string{"project", "network"})
+
+	MetricWebSocketSubscriptionsActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "websocket_subscriptions_active",
+		Help:      "Current number of active eth_subscribe subscriptions.",
+	}, []string{"project", "network", "kind"})
+
+	MetricWebSocketSubscriptionHeadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "websocket_subscription_heads_total",
+		Help:      "Total number of new heads dispatched to subscriptions.",
+	}, []string{"project", "network"})
+
+	MetricEventStreamClientsActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "erpc",
+		Name:      "event_stream_clients_active",
+		Help:      "Number of currently connected server-sent events clients.",
+	}, []string{"project", "network"})
+
+	MetricEventStreamEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "event_stream_events_total",
+		Help:      "Total number of events sent to server-sent events clients.",
+	}, []string{"project", "network", "event"})
+
+	MetricNetworkTxBroadcastTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "network_tx_broadcast_total",
+		Help:      "Total number of raw transactions broadcasted to upstreams by outcome (success, already_known, nonce_used, error, deduplicated).",
+	}, []string{"project", "network", "upstream", "outcome"})
+
 	MetricRistrettoCacheCurrentCost = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "erpc",
 		Name:      "ristretto_cache_current_cost",
@@ -304,6 +394,30 @@ var (
 		Help:      "Total number of set operations that failed (dropped or rejected) in Ristretto cache.",
 	}, []string{"connector"})
 
+	MetricTieredConnectorGetHitTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "tiered_connector_get_hit_total",
+		Help:      "Total number of get hits of a tiered connector by tier (l1 or l2).",
+	}, []string{"connector", "tier"})
+
+	MetricTieredConnectorGetMissTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "tiered_connector_get_miss_total",
+		Help:      "Total number of get misses of a tiered connector by tier (l1 or l2).",
+	}, []string{"connector", "tier"})
+
+	MetricTieredConnectorL1InvalidationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "tiered_connector_l1_invalidations_total",
+		Help:      "Total number of L1 flushes of a tiered connector triggered by deletes on other instances.",
+	}, []string{"connector"})
+
+	MetricRedisFailoversTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "redis_failovers_total",
+		Help:      "Total number of Redis master switch announcements received from sentinels.",
+	}, []string{"connector"})
+
 	MetricShadowResponseIdenticalTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "shadow_response_identical_total",
@@ -322,6 +436,12 @@ var (
 		Help:      "Total number of shadow upstream requests that resulted in error.",
 	}, []string{"project", "vendor", "network", "upstream", "category", "error"})
 
+	MetricDisputeRecordsDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "erpc",
+		Name:      "dispute_records_dropped_total",
+		Help:      "Total number of dispute records dropped because the write queue was full.",
+	}, []string{"project", "kind"})
+
 	MetricConsensusTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "erpc",
 		Name:      "consensus_total",
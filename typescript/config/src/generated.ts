@@ -80,6 +80,19 @@ export interface ServerConfig {
   waitBeforeShutdown?: Duration;
   waitAfterShutdown?: Duration;
   includeErrorDetails?: boolean;
+  webSocket?: WebSocketServerConfig;
+}
+/**
+ * WebSocketServerConfig controls the websocket listener served on the same
+ * /<project>/<architecture>/<chainId> paths as http, including eth_subscribe support.
+ */
+export interface WebSocketServerConfig {
+  enabled?: boolean;
+  maxMessageSize?: number /* int */;
+  maxSubscriptionsPerConnection?: number /* int */;
+  maxInFlightPerConnection?: number /* int */;
+  maxUpstreamSubscriptions?: number /* int */;
+  headsPollInterval?: Duration;
 }
 export interface HealthCheckConfig {
   mode?: HealthCheckMode;
@@ -136,6 +149,16 @@ export interface CacheConfig {
   connectors?: TsConnectorConfig[];
   policies?: (CachePolicyConfig | undefined)[];
   compression?: CompressionConfig;
+  reorgs?: CacheReorgsConfig;
+}
+/**
+ * CacheReorgsConfig controls invalidation of unfinalized cache entries when a block they reference is reorged.
+ * Block hashes of the most recent TrackedBlocks heights are remembered (from state pollers and block responses)
+ * along with the cache entries written for those heights.
+ */
+export interface CacheReorgsConfig {
+  enabled?: boolean;
+  trackedBlocks?: number /* int64 */;
 }
 export interface CompressionConfig {
   enabled?: boolean;
@@ -168,6 +191,9 @@ export const DriverRedis: ConnectorDriverType = "redis";
 export const DriverPostgreSQL: ConnectorDriverType = "postgresql";
 export const DriverDynamoDB: ConnectorDriverType = "dynamodb";
 export const DriverGrpc: ConnectorDriverType = "grpc";
+export const DriverTiered: ConnectorDriverType = "tiered";
+export const DriverBadger: ConnectorDriverType = "badger";
+export const DriverScylla: ConnectorDriverType = "scylla";
 export interface ConnectorConfig {
   id?: string;
   driver: TsConnectorDriverType;
@@ -176,12 +202,55 @@ export interface ConnectorConfig {
   dynamodb?: DynamoDBConnectorConfig;
   postgresql?: PostgreSQLConnectorConfig;
   grpc?: GrpcConnectorConfig;
+  tiered?: TieredConnectorConfig;
+  badger?: BadgerConnectorConfig;
+  scylla?: ScyllaConnectorConfig;
+}
+/**
+ * TieredConnectorConfig layers an in-memory L1 over any remote L2 connector. Reads are served from L1 when possible,
+ * L2 hits are promoted into L1, and writes go through to both tiers.
+ */
+export interface TieredConnectorConfig {
+  l1?: MemoryConnectorConfig;
+  l2?: ConnectorConfig;
+  l1Ttl?: Duration;
+  /**
+   * When enabled deletes are broadcast via L2 pub/sub so that other instances evict them from their L1
+   */
+  invalidation?: boolean;
 }
 export interface GrpcConnectorConfig {
   bootstrap?: string;
   servers?: string[];
   headers?: { [key: string]: string};
 }
+/**
+ * BadgerConnectorConfig configures an embedded on-disk key-value store, useful for single-node
+ * deployments that need a persistent cache without an external database.
+ */
+export interface BadgerConnectorConfig {
+  dir: string;
+  syncWrites?: boolean;
+  gcInterval?: Duration;
+}
+/**
+ * ScyllaConnectorConfig configures a ScyllaDB (or any Cassandra-compatible) connector. The keyspace
+ * and tables are created if they do not exist.
+ */
+export interface ScyllaConnectorConfig {
+  hosts?: string[];
+  keyspace?: string;
+  table?: string;
+  username?: string;
+  tls?: TLSConfig;
+  consistency?: string;
+  replicationFactor?: number /* int */;
+  initTimeout?: Duration;
+  getTimeout?: Duration;
+  setTimeout?: Duration;
+  lockRetryInterval?: Duration;
+  statePollInterval?: Duration;
+}
 export interface MemoryConnectorConfig {
   maxItems: number /* int */;
   maxTotalSize: string;
@@ -201,8 +270,25 @@ export interface TLSConfig {
   caFile?: string;
   insecureSkipVerify?: boolean;
 }
+export type RedisConnectorMode = string;
+/**
+ * RedisModeStandalone connects to a single Redis server via "uri" (or "addr").
+ */
+export const RedisModeStandalone: RedisConnectorMode = "standalone";
+/**
+ * RedisModeCluster connects to a sharded Redis Cluster using "addrs" as seed nodes.
+ */
+export const RedisModeCluster: RedisConnectorMode = "cluster";
+/**
+ * RedisModeSentinel discovers the master named "masterName" via the sentinels in "addrs" and follows failovers.
+ */
+export const RedisModeSentinel: RedisConnectorMode = "sentinel";
 export interface RedisConnectorConfig {
+  mode?: RedisConnectorMode;
   addr?: string;
+  addrs?: string[];
+  masterName?: string;
+  sentinelUsername?: string;
   username?: string;
   db?: number /* int */;
   tls?: TLSConfig;
@@ -258,6 +344,30 @@ export interface ProjectConfig {
   scoreMetricsWindowSize?: Duration;
   scoreRefreshInterval?: Duration;
   healthCheck?: DeprecatedProjectHealthCheckConfig;
+  disputes?: DisputesConfig;
+}
+/**
+ * DisputesConfig persists consensus disputes and shadow upstream mismatches (request, each participant's
+ * response and the chosen outcome) so that data-quality issues of providers can be investigated after the fact.
+ */
+export interface DisputesConfig {
+  connector?: ConnectorConfig;
+  /**
+   * Retention is how long records are kept in the connector.
+   */
+  retention?: Duration;
+  /**
+   * MaxBodySize caps the number of bytes kept of the request and of each response body.
+   */
+  maxBodySize?: number /* int */;
+  /**
+   * Consensus records requests where consensus participants disagreed.
+   */
+  consensus?: boolean;
+  /**
+   * Shadow records responses of shadow upstreams that differ from the primary response.
+   */
+  shadow?: boolean;
 }
 export interface NetworkDefaults {
   rateLimitBudget?: string;
@@ -298,6 +408,8 @@ export interface UpstreamConfig {
   failsafe?: (FailsafeConfig | undefined)[];
   rateLimitBudget?: string;
   rateLimitAutoTune?: RateLimitAutoTuneConfig;
+  rateLimitCooldown?: RateLimitCooldownConfig;
+  concurrencyLimit?: ConcurrencyLimitConfig;
   routing?: RoutingConfig;
   shadow?: ShadowUpstreamConfig;
 }
@@ -339,6 +451,53 @@ export interface RateLimitAutoTuneConfig {
   minBudget: number /* int */;
   maxBudget: number /* int */;
 }
+export type RateLimitCooldownScope = string;
+export const RateLimitCooldownScopeMethod: RateLimitCooldownScope = "method";
+export const RateLimitCooldownScopeUpstream: RateLimitCooldownScope = "upstream";
+/**
+ * RateLimitCooldownConfig controls how long an upstream is excluded from routing after it responds
+ * with a rate limit error, based on the backoff window the upstream asked for (e.g. Retry-After).
+ */
+export interface RateLimitCooldownConfig {
+  enabled?: boolean;
+  /**
+   * Scope is either "method" (only the rate-limited method is skipped) or "upstream" (all methods).
+   */
+  scope?: RateLimitCooldownScope;
+  /**
+   * DefaultDuration is used when the upstream does not provide a backoff hint, 0 means no cooldown.
+   */
+  defaultDuration?: Duration;
+  maxDuration?: Duration;
+}
+export type ConcurrencyLimitAlgorithm = string;
+export const ConcurrencyLimitAlgorithmGradient: ConcurrencyLimitAlgorithm = "gradient";
+export const ConcurrencyLimitAlgorithmAimd: ConcurrencyLimitAlgorithm = "aimd";
+/**
+ * ConcurrencyLimitConfig caps the number of in-flight requests to an upstream, adapting the cap to the
+ * upstream's latency so that overloaded nodes get fewer concurrent requests. Requests beyond the cap are
+ * not queued but overflow to the next upstream.
+ */
+export interface ConcurrencyLimitConfig {
+  enabled?: boolean;
+  algorithm?: ConcurrencyLimitAlgorithm;
+  initialLimit?: number /* int */;
+  minLimit?: number /* int */;
+  maxLimit?: number /* int */;
+  adjustmentPeriod?: Duration;
+  /**
+   * LatencyQuantile of the upstream response times (as tracked for scoring) compared against the baseline.
+   */
+  latencyQuantile?: number /* float64 */;
+  /**
+   * Tolerance is how much slower than the baseline the upstream can get before the limit is decreased.
+   */
+  tolerance?: number /* float64 */;
+  /**
+   * BackoffRatio is the multiplicative decrease applied when the upstream is overloaded (aimd only).
+   */
+  backoffRatio?: number /* float64 */;
+}
 export interface JsonRpcUpstreamConfig {
   supportsBatch?: boolean;
   batchMaxSize?: number /* int */;
@@ -346,6 +505,17 @@ export interface JsonRpcUpstreamConfig {
   enableGzip?: boolean;
   headers?: { [key: string]: string};
   proxyPool?: string;
+  webSocket?: WebSocketUpstreamConfig;
+}
+/**
+ * WebSocketUpstreamConfig is only used when upstream endpoint is ws:// or wss://
+ */
+export interface WebSocketUpstreamConfig {
+  maxConnections?: number /* int */;
+  dialTimeout?: Duration;
+  reconnectMinDelay?: Duration;
+  reconnectMaxDelay?: Duration;
+  maxMessageSize?: number /* int */;
 }
 export interface EvmUpstreamConfig {
   chainId: number /* int64 */;
@@ -416,6 +586,26 @@ export interface ConsensusPolicyConfig {
   ignoreFields?: { [key: string]: string[]};
   preferNonEmpty?: boolean;
   preferLargerResponses?: boolean;
+  /**
+   * Weights give the votes of some upstreams more power (e.g. own nodes over third-party providers).
+   * When set, AgreementThreshold is compared with the total weight of agreeing participants instead of their count.
+   */
+  weights?: ConsensusWeightConfig[];
+  /**
+   * WeightByAgreementRate scales the weight of each upstream by its historical rate of agreement with
+   * consensus for the method, so that upstreams which often misbehave have less say when picking the winner.
+   * AgreementThreshold is still compared with the configured weights, so unanimous responses always reach it.
+   */
+  weightByAgreementRate?: boolean;
+}
+/**
+ * ConsensusWeightConfig sets the vote weight of upstreams matching an id and/or group (wildcards supported),
+ * the first matching entry is used and upstreams not matching any entry have a weight of 1.
+ */
+export interface ConsensusWeightConfig {
+  upstream?: string;
+  group?: string;
+  weight: number /* float64 */;
 }
 export interface PunishMisbehaviorConfig {
   disputeThreshold: number /* uint */;
@@ -423,17 +613,36 @@ export interface PunishMisbehaviorConfig {
   sitOutPenalty?: Duration;
 }
 export interface RateLimiterConfig {
+  store?: RateLimitStoreConfig;
   budgets: RateLimitBudgetConfig[];
+  /**
+   * ComputeUnits is the cost of each method (or wildcard pattern of methods) used by rules counting
+   * compute units. User-defined values are merged on top of DefaultMethodComputeUnits.
+   */
+  computeUnits?: Record<string, number>;
+}
+/**
+ * RateLimitStoreConfig defines a backend shared by all eRPC instances so that budgets are enforced
+ * cluster-wide instead of per instance. When the backend is unreachable budgets are enforced locally.
+ */
+export interface RateLimitStoreConfig {
+  connector: TsConnectorConfig;
+  keyPrefix?: string;
+  timeout?: Duration;
 }
 export interface RateLimitBudgetConfig {
   id: string;
   rules: RateLimitRuleConfig[];
 }
+export type RateLimitUnit = string;
+export const RateLimitUnitRequest: RateLimitUnit = "request";
+export const RateLimitUnitComputeUnit: RateLimitUnit = "computeUnit";
 export interface RateLimitRuleConfig {
   method: string;
   maxCount: number /* uint */;
   period: Duration;
   waitTime: Duration;
+  unit?: RateLimitUnit;
 }
 export interface ProxyPoolConfig {
   id: string;
@@ -472,6 +681,35 @@ export interface EvmNetworkConfig {
   getLogsMaxAllowedTopics?: number /* int64 */;
   getLogsSplitOnError?: boolean;
   getLogsSplitConcurrency?: number /* int */;
+  filters?: EvmFiltersConfig;
+  txBroadcast?: EvmTxBroadcastConfig;
+}
+/**
+ * EvmFiltersConfig controls emulation of eth_newFilter, eth_newBlockFilter and related methods,
+ * where filter state is owned by eRPC (and persisted in shared state) instead of a single upstream.
+ */
+export interface EvmFiltersConfig {
+  enabled?: boolean;
+  ttl?: Duration;
+  maxBlocksPerPoll?: number /* int64 */;
+}
+/**
+ * EvmTxBroadcastConfig sends eth_sendRawTransaction to several upstreams in parallel, so that a
+ * transaction is not lost when the mempool of a single node drops it.
+ */
+export interface EvmTxBroadcastConfig {
+  enabled?: boolean;
+  /**
+   * Upstreams limits broadcasting to upstreams matching any of these id patterns (e.g. "alchemy-*"),
+   * when empty all upstreams selected for the request are used.
+   */
+  upstreams?: string[];
+  maxUpstreams?: number /* int */;
+  /**
+   * DedupWindow is how long a successfully broadcasted payload is remembered, so that repeated
+   * submissions of the same signed transaction are answered without sending it again.
+   */
+  dedupWindow?: Duration;
 }
 export interface EvmIntegrityConfig {
   enforceHighestBlock?: boolean;
@@ -491,6 +729,8 @@ export const AuthTypeDatabase: AuthType = "database";
 export const AuthTypeJwt: AuthType = "jwt";
 export const AuthTypeSiwe: AuthType = "siwe";
 export const AuthTypeNetwork: AuthType = "network";
+export const AuthTypeMtls: AuthType = "mtls";
+export const AuthTypeHmac: AuthType = "hmac";
 export interface AuthConfig {
   strategies: TsAuthStrategyConfig[];
 }
@@ -504,6 +744,8 @@ export interface AuthStrategyConfig {
   database?: DatabaseStrategyConfig;
   jwt?: JwtStrategyConfig;
   siwe?: SiweStrategyConfig;
+  mtls?: MtlsStrategyConfig;
+  hmac?: HmacStrategyConfig;
 }
 export interface SecretStrategyConfig {
   id: string;
@@ -512,6 +754,27 @@ export interface SecretStrategyConfig {
 export interface DatabaseStrategyConfig {
   connector?: ConnectorConfig;
   cache?: DatabaseStrategyCacheConfig;
+  /**
+   * TrustedProxies are the only peers whose X-Forwarded-For is honoured when resolving the client IP for keys
+   * restricted to "allowedCIDRs", otherwise the IP of the connection is used
+   */
+  trustedProxies?: string[];
+  keyHashing?: DatabaseStrategyKeyHashingConfig;
+  /**
+   * QuotaFailOpen allows requests of api keys with quotas when their usage cannot be accounted (e.g. database is down)
+   */
+  quotaFailOpen?: boolean;
+}
+/**
+ * DatabaseStrategyKeyHashingConfig stores API keys as HMAC-SHA256 hashes (keyed by the pepper) instead of
+ * their raw value, so that read access to the database does not reveal any usable credential.
+ */
+export interface DatabaseStrategyKeyHashingConfig {
+  pepper: string;
+  /**
+   * PlaintextFallback also looks up keys by their raw value until existing keys are migrated via erpc_migrateApiKeys
+   */
+  plaintextFallback?: boolean;
 }
 export interface DatabaseStrategyCacheConfig {
   ttl?: number /* time in nanoseconds (time.Duration) */;
@@ -525,9 +788,102 @@ export interface JwtStrategyConfig {
   allowedAlgorithms: string[];
   requiredClaims: string[];
   verificationKeys: { [key: string]: string};
+  /**
+   * JwksUrl is fetched for verification keys (by "kid"), which are refreshed periodically and whenever
+   * a token is signed by an unknown key, so that keys can be rotated without a redeploy.
+   */
+  jwksUrl?: string;
+  jwksRefreshInterval?: Duration;
+  jwksMinRefreshInterval?: Duration;
+  /**
+   * ClockSkew is tolerated when validating "exp", "nbf" and "iat" claims.
+   */
+  clockSkew?: Duration;
+  claimsMapping?: JwtClaimsMappingConfig;
+}
+/**
+ * JwtClaimsMappingConfig names the claims (dot-separated for nested claims, e.g. "app_metadata.tier")
+ * from which the identity and limits of the user are derived, so that a single gateway can serve
+ * multiple tenants based on token contents alone.
+ */
+export interface JwtClaimsMappingConfig {
+  userId?: string;
+  rateLimitBudget?: string;
+  perSecondRateLimit?: string;
+  allowedNetworks?: string;
+  allowedMethods?: string;
 }
 export interface SiweStrategyConfig {
   allowedDomains: string[];
+  /**
+   * Connector stores nonces issued via erpc_siweNonce (and sessions), when set messages must use such a
+   * nonce and each nonce can only be used once, so that captured signatures cannot be replayed.
+   */
+  connector?: TsConnectorConfig;
+  nonceTtl?: Duration;
+  /**
+   * SessionTTL enables erpc_siweSession which exchanges a signed message for a session token valid this long
+   */
+  sessionTtl?: Duration;
+  /**
+   * NonceRateLimitPerIp limits how many nonces are issued per second to a client IP, nonces are issued
+   * before authentication so the project rate limit budget applies as well.
+   */
+  nonceRateLimitPerIp?: number /* uint */;
+  /**
+   * TrustedProxies are the only peers whose X-Forwarded-For is honoured when resolving the client IP for
+   * nonceRateLimitPerIp, otherwise the IP of the connection is used
+   */
+  trustedProxies?: string[];
+}
+/**
+ * HmacStrategyConfig authenticates requests signed with a per-key secret (stored in the connector), so that
+ * no reusable credential is sent along with requests and leaked into proxy logs.
+ */
+export interface HmacStrategyConfig {
+  connector: TsConnectorConfig;
+  /**
+   * MaxClockSkew is how far the signed timestamp can be from the server time, nonces are remembered for twice as long
+   */
+  maxClockSkew?: Duration;
+  /**
+   * CacheTTL is how long keys are kept in memory after being loaded from the connector
+   */
+  cacheTtl?: Duration;
+}
+export type MtlsIdentitySource = string;
+/**
+ * MtlsIdentitySourceSpiffeId uses the URI SAN with "spiffe://" scheme, e.g. spiffe://example.org/ns/prod/sa/indexer
+ */
+export const MtlsIdentitySourceSpiffeId: MtlsIdentitySource = "spiffeId";
+export const MtlsIdentitySourceUriSan: MtlsIdentitySource = "uriSan";
+export const MtlsIdentitySourceDnsSan: MtlsIdentitySource = "dnsSan";
+export const MtlsIdentitySourceEmailSan: MtlsIdentitySource = "emailSan";
+export const MtlsIdentitySourceCommonName: MtlsIdentitySource = "commonName";
+/**
+ * MtlsStrategyConfig authenticates clients by the certificate they present during the TLS handshake
+ * (requires server.tls to be enabled), e.g. services of a mesh identified by their SPIFFE ID.
+ */
+export interface MtlsStrategyConfig {
+  /**
+   * CAFile is a PEM bundle of the CAs that client certificates must chain to
+   */
+  caFile: string;
+  userIdFrom?: MtlsIdentitySource;
+  /**
+   * AllowedSANs and DeniedSANs are patterns matched against all SANs (URIs, DNS names, emails and IPs)
+   * of the certificate, deny rules take precedence.
+   */
+  allowedSANs?: string[];
+  deniedSANs?: string[];
+  /**
+   * RateLimitBudgets assigns a budget to users whose id matches the identity pattern, first match wins
+   */
+  rateLimitBudgets?: MtlsRateLimitBudgetConfig[];
+}
+export interface MtlsRateLimitBudgetConfig {
+  identity: string;
+  rateLimitBudget: string;
 }
 export interface NetworkStrategyConfig {
   allowedIPs: string[];
@@ -43,6 +43,8 @@ export {
   AuthTypeJwt,
   AuthTypeSiwe,
   AuthTypeNetwork,
+  AuthTypeMtls,
+  AuthTypeHmac,
   // Consensus related
   ConsensusLowParticipantsBehaviorReturnError,
   ConsensusLowParticipantsBehaviorAcceptMostCommonValidResult,
@@ -99,6 +101,8 @@ export type {
   JwtStrategyConfig,
   SiweStrategyConfig,
   NetworkStrategyConfig,
+  MtlsStrategyConfig,
+  HmacStrategyConfig,
   // Rate limits related
   RateLimiterConfig,
   RateLimitBudgetConfig,
@@ -1,13 +1,18 @@
 import type {
+    BadgerConnectorConfig,
     DynamoDBConnectorConfig,
     EvmNetworkConfig,
+    HmacStrategyConfig,
     AuthStrategyConfig as GenAuthStrategyConfig,
     JwtStrategyConfig,
     MemoryConnectorConfig,
+    MtlsStrategyConfig,
     NetworkStrategyConfig,
     PostgreSQLConnectorConfig,
     RedisConnectorConfig,
+    ScyllaConnectorConfig,
     SecretStrategyConfig,
+    TieredConnectorConfig,
     SiweStrategyConfig,
   } from "../generated";
   
@@ -55,7 +60,10 @@ import type {
     | "memory"
     | "redis"
     | "postgresql"
-    | "dynamodb";
+    | "dynamodb"
+    | "badger"
+    | "scylla"
+    | "tiered";
   
   /**
    * Connector config depending on the upstream type
@@ -80,6 +88,21 @@ import type {
         id: string;
         driver: "postgresql";
         postgresql: PostgreSQLConnectorConfig;
+      }
+    | {
+        id: string;
+        driver: "badger";
+        badger: BadgerConnectorConfig;
+      }
+    | {
+        id: string;
+        driver: "scylla";
+        scylla: ScyllaConnectorConfig;
+      }
+    | {
+        id: string;
+        driver: "tiered";
+        tiered: TieredConnectorConfig;
       };
   
   /**
@@ -111,14 +134,14 @@ import type {
   /**
    * Supported auth type
    */
-  export type AuthType = "secret" | "jwt" | "siwe" | "network";
+  export type AuthType = "secret" | "jwt" | "siwe" | "network" | "mtls" | "hmac";
   
   /**
    * Connector config depending on the upstream type
    */
   export type AuthStrategyConfig = Omit<
     GenAuthStrategyConfig,
-    "type" | "network" | "secret" | "jwt" | "siwe"
+    "type" | "network" | "secret" | "jwt" | "siwe" | "mtls" | "hmac"
   > &
     (
       | {
@@ -137,6 +160,14 @@ import type {
           type: "siwe";
           secret: SiweStrategyConfig;
         }
+      | {
+          type: "mtls";
+          mtls: MtlsStrategyConfig;
+        }
+      | {
+          type: "hmac";
+          hmac: HmacStrategyConfig;
+        }
     );
   
   /**
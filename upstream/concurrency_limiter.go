@@ -0,0 +1,182 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/erpc/erpc/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// baselineSmoothing is the weight of the latest latency in the long-term baseline, so the baseline follows
+// gradual changes of an upstream's latency (e.g. heavier traffic patterns) but not short overloads.
+const baselineSmoothing = 0.05
+
+// ConcurrencyLimiter caps the number of in-flight requests to an upstream. The cap is periodically adapted
+// by comparing the latency quantile tracked for the upstream against its long-term baseline:
+//   - "gradient" scales the limit by baseline/latency (plus some headroom) so it converges smoothly.
+//   - "aimd" adds 1 while the upstream is healthy and multiplies by the backoff ratio when it is not.
+//
+// Timeouts, capacity and server-side errors are treated as overload signals by both algorithms.
+type ConcurrencyLimiter struct {
+	logger   *zerolog.Logger
+	upstream *Upstream
+	tracker  *health.Tracker
+	cfg      *common.ConcurrencyLimitConfig
+
+	limit    atomic.Int64
+	inFlight atomic.Int64
+
+	mu             sync.Mutex
+	estimatedLimit float64
+	baseline       time.Duration
+	peakInFlight   int64
+	overloads      int
+	lastAdjustment time.Time
+}
+
+func NewConcurrencyLimiter(
+	logger *zerolog.Logger,
+	upstream *Upstream,
+	tracker *health.Tracker,
+	cfg *common.ConcurrencyLimitConfig,
+) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{
+		logger:         logger,
+		upstream:       upstream,
+		tracker:        tracker,
+		cfg:            cfg,
+		estimatedLimit: float64(cfg.InitialLimit),
+		lastAdjustment: time.Now(),
+	}
+	l.limit.Store(int64(cfg.InitialLimit))
+	return l
+}
+
+// TryAcquire reserves an in-flight slot without waiting, so that callers can overflow to other upstreams.
+func (l *ConcurrencyLimiter) TryAcquire() bool {
+	for {
+		current := l.inFlight.Load()
+		if current >= l.limit.Load() {
+			return false
+		}
+		if l.inFlight.CompareAndSwap(current, current+1) {
+			l.mu.Lock()
+			if current+1 > l.peakInFlight {
+				l.peakInFlight = current + 1
+			}
+			l.mu.Unlock()
+			return true
+		}
+	}
+}
+
+// Release frees the slot of a request that has ended with the given error (if any).
+func (l *ConcurrencyLimiter) Release(err error) {
+	l.inFlight.Add(-1)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if isOverloadSignal(err) {
+		l.overloads++
+	}
+	if time.Since(l.lastAdjustment) >= l.cfg.AdjustmentPeriod.Duration() {
+		l.adjust()
+	}
+}
+
+func (l *ConcurrencyLimiter) Limit() int64 {
+	return l.limit.Load()
+}
+
+func (l *ConcurrencyLimiter) InFlight() int64 {
+	return l.inFlight.Load()
+}
+
+// adjust must be called while holding the lock.
+func (l *ConcurrencyLimiter) adjust() {
+	latency := l.tracker.GetUpstreamMethodMetrics(l.upstream, "*").ResponseQuantiles.GetQuantile(l.cfg.LatencyQuantile)
+	if latency > 0 {
+		if l.baseline == 0 {
+			l.baseline = latency
+		} else {
+			l.baseline = time.Duration(float64(l.baseline)*(1-baselineSmoothing) + float64(latency)*baselineSmoothing)
+		}
+	}
+	overloaded := l.overloads > 0 || (latency > 0 && float64(latency) > float64(l.baseline)*l.cfg.Tolerance)
+	// The limit is only raised when it was actually needed, otherwise it would grow indefinitely while idle
+	saturated := float64(l.peakInFlight) >= l.estimatedLimit/2
+
+	current := l.estimatedLimit
+	next := current
+	switch l.cfg.Algorithm {
+	case common.ConcurrencyLimitAlgorithmAimd:
+		if overloaded {
+			next = current * l.cfg.BackoffRatio
+		} else if saturated {
+			next = current + 1
+		}
+	default:
+		gradient := 1.0
+		if latency > 0 {
+			gradient = math.Max(0.5, math.Min(1, float64(l.baseline)*l.cfg.Tolerance/float64(latency)))
+		}
+		if l.overloads > 0 {
+			gradient = math.Min(gradient, l.cfg.BackoffRatio)
+		}
+		// Square root of the limit is the headroom allowed on top of the gradient so that the limit can grow
+		target := current * gradient
+		if !overloaded && saturated {
+			target += math.Sqrt(current)
+		}
+		// Back off immediately but grow gradually
+		if target < current {
+			next = target
+		} else {
+			next = current*0.8 + target*0.2
+		}
+	}
+	next = math.Max(float64(l.cfg.MinLimit), math.Min(float64(l.cfg.MaxLimit), next))
+
+	l.estimatedLimit = next
+	newLimit := int64(math.Round(next))
+	if oldLimit := l.limit.Swap(newLimit); oldLimit != newLimit {
+		l.logger.Debug().
+			Int64("from", oldLimit).
+			Int64("to", newLimit).
+			Dur("latency", latency).
+			Dur("baseline", l.baseline).
+			Int("overloads", l.overloads).
+			Msg("adjusted upstream concurrency limit")
+	}
+	telemetry.MetricUpstreamConcurrencyLimit.WithLabelValues(
+		l.upstream.ProjectId,
+		l.upstream.VendorName(),
+		l.upstream.NetworkLabel(),
+		l.upstream.Id(),
+	).Set(float64(newLimit))
+
+	l.overloads = 0
+	l.peakInFlight = l.inFlight.Load()
+	l.lastAdjustment = time.Now()
+}
+
+func isOverloadSignal(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, context.DeadlineExceeded) || common.HasErrorCode(
+		err,
+		common.ErrCodeEndpointCapacityExceeded,
+		common.ErrCodeEndpointRequestTimeout,
+		common.ErrCodeFailsafeTimeoutExceeded,
+		common.ErrCodeEndpointServerSideException,
+	)
+}
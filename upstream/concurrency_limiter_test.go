@@ -0,0 +1,96 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/health"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConcurrencyLimiter(t *testing.T, algorithm common.ConcurrencyLimitAlgorithm) (*ConcurrencyLimiter, *Upstream, *health.Tracker) {
+	logger := zerolog.Nop()
+	cfg := &common.ConcurrencyLimitConfig{
+		Algorithm:        algorithm,
+		InitialLimit:     10,
+		MinLimit:         2,
+		MaxLimit:         20,
+		AdjustmentPeriod: common.Duration(time.Nanosecond),
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+
+	ups := &Upstream{
+		ProjectId: "test",
+		config:    &common.UpstreamConfig{Id: "rpc1"},
+		logger:    &logger,
+	}
+	tracker := health.NewTracker(&logger, "test", time.Minute)
+	return NewConcurrencyLimiter(&logger, ups, tracker, cfg), ups, tracker
+}
+
+func TestConcurrencyLimiter_CapsInFlightRequests(t *testing.T) {
+	limiter, _, _ := newTestConcurrencyLimiter(t, common.ConcurrencyLimitAlgorithmAimd)
+	limiter.cfg.AdjustmentPeriod = common.Duration(time.Hour)
+
+	for i := 0; i < 10; i++ {
+		require.True(t, limiter.TryAcquire())
+	}
+	assert.False(t, limiter.TryAcquire(), "requests beyond the limit must be rejected instead of queued")
+
+	limiter.Release(nil)
+	assert.True(t, limiter.TryAcquire())
+	assert.Equal(t, int64(10), limiter.InFlight())
+}
+
+func TestConcurrencyLimiter_Aimd(t *testing.T) {
+	limiter, _, _ := newTestConcurrencyLimiter(t, common.ConcurrencyLimitAlgorithmAimd)
+
+	t.Run("IncreasesAdditivelyWhenSaturated", func(t *testing.T) {
+		for i := 0; i < 6; i++ {
+			require.True(t, limiter.TryAcquire())
+		}
+		for i := 0; i < 6; i++ {
+			limiter.Release(nil)
+		}
+		assert.Equal(t, int64(11), limiter.Limit())
+	})
+
+	t.Run("DecreasesMultiplicativelyOnOverload", func(t *testing.T) {
+		require.True(t, limiter.TryAcquire())
+		limiter.Release(common.NewErrEndpointRequestTimeout(time.Second, nil))
+		assert.Less(t, limiter.Limit(), int64(11))
+	})
+
+	t.Run("NeverGoesBelowMinLimit", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			require.True(t, limiter.TryAcquire())
+			limiter.Release(context.DeadlineExceeded)
+		}
+		assert.Equal(t, int64(2), limiter.Limit())
+	})
+}
+
+func TestConcurrencyLimiter_GradientFollowsLatency(t *testing.T) {
+	limiter, ups, tracker := newTestConcurrencyLimiter(t, common.ConcurrencyLimitAlgorithmGradient)
+
+	// Establish the baseline latency of a healthy upstream
+	for i := 0; i < 100; i++ {
+		tracker.RecordUpstreamDuration(ups, "eth_call", 100*time.Millisecond, true, "none", common.DataFinalityStateUnknown, "")
+	}
+	require.True(t, limiter.TryAcquire())
+	limiter.Release(nil)
+	assert.Equal(t, int64(10), limiter.Limit(), "limit must not grow when it is not saturated")
+
+	// Latency increases well beyond the tolerance, so the limit is scaled down
+	for i := 0; i < 1000; i++ {
+		tracker.RecordUpstreamDuration(ups, "eth_call", time.Second, true, "none", common.DataFinalityStateUnknown, "")
+	}
+	require.True(t, limiter.TryAcquire())
+	limiter.Release(nil)
+	assert.Less(t, limiter.Limit(), int64(10))
+}
@@ -652,6 +652,8 @@ func createConsensusPolicy(logger *zerolog.Logger, cfg *common.ConsensusPolicyCo
 	builder = builder.WithPunishMisbehavior(cfg.PunishMisbehavior)
 	builder = builder.WithLowParticipantsBehavior(cfg.LowParticipantsBehavior)
 	builder = builder.WithLogger(logger)
+	builder = builder.WithWeights(cfg.Weights)
+	builder = builder.WithWeightByAgreementRate(cfg.WeightByAgreementRate)
 
 	// Set ignore fields if configured
 	if cfg.IgnoreFields != nil {
@@ -1,11 +1,12 @@
 package upstream
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/erpc/erpc/common"
 	"github.com/erpc/erpc/telemetry"
-	"github.com/failsafe-go/failsafe-go/ratelimiter"
 	"github.com/rs/zerolog"
 )
 
@@ -17,9 +18,19 @@ type RateLimiterBudget struct {
 	rulesMu  sync.RWMutex
 }
 
+// RateLimiter is satisfied by both in-process limiters and limiters backed by a shared store.
+type RateLimiter interface {
+	TryAcquirePermit() bool
+	TryAcquirePermits(permits uint) bool
+	// TryAcquirePermitsContext is like TryAcquirePermits, but stops waiting for permits once ctx is done.
+	TryAcquirePermitsContext(ctx context.Context, permits uint) bool
+	// ResetIn is the time until permits of the current period are replenished.
+	ResetIn() time.Duration
+}
+
 type RateLimitRule struct {
 	Config  *common.RateLimitRuleConfig
-	Limiter ratelimiter.RateLimiter[interface{}]
+	Limiter RateLimiter
 }
 
 func (b *RateLimiterBudget) GetRulesByMethod(method string) ([]*RateLimitRule, error) {
@@ -41,6 +52,28 @@ func (b *RateLimiterBudget) GetRulesByMethod(method string) ([]*RateLimitRule, e
 	return rules, nil
 }
 
+// TryAcquirePermit consumes the cost of a method from the rule, which is 1 for rules counting requests
+// and the compute units of the method for rules counting compute units. Waiting for permits stops once ctx is done.
+func (b *RateLimiterBudget) TryAcquirePermit(ctx context.Context, rule *RateLimitRule, method string) bool {
+	if rule.Config.Unit != common.RateLimitUnitComputeUnit {
+		return rule.Limiter.TryAcquirePermitsContext(ctx, 1)
+	}
+
+	return rule.Limiter.TryAcquirePermitsContext(ctx, b.registry.ComputeUnits(method))
+}
+
+// RuleInfo describes a rule of this budget (e.g. to tell clients which rule rejected their request).
+func (b *RateLimiterBudget) RuleInfo(rule *RateLimitRule) *common.RateLimitRuleInfo {
+	return &common.RateLimitRuleInfo{
+		Budget:   b.Id,
+		Method:   rule.Config.Method,
+		MaxCount: rule.Config.MaxCount,
+		Period:   rule.Config.Period,
+		Unit:     rule.Config.Unit,
+		ResetIn:  common.Duration(rule.Limiter.ResetIn().Round(time.Millisecond)),
+	}
+}
+
 func (b *RateLimiterBudget) AdjustBudget(rule *RateLimitRule, newMaxCount uint) error {
 	b.rulesMu.Lock()
 	defer b.rulesMu.Unlock()
@@ -52,6 +85,7 @@ func (b *RateLimiterBudget) AdjustBudget(rule *RateLimitRule, newMaxCount uint)
 		Period:   rule.Config.Period,
 		MaxCount: newMaxCount,
 		WaitTime: rule.Config.WaitTime,
+		Unit:     rule.Config.Unit,
 	}
 	newLimiter, err := b.registry.createRateLimiter(b.Id, newCfg)
 	if err != nil {
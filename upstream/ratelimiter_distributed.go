@@ -0,0 +1,170 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/telemetry"
+)
+
+// distributedRateLimiter enforces a rule across all instances sharing the same store using a sliding
+// window counter: the count of the previous window is weighted by how much of it still overlaps with
+// the sliding window ending now. When the store is not connected or does not respond in time the
+// in-process limiter is used instead, so that requests are never blocked by an unreachable store.
+type distributedRateLimiter struct {
+	registry *RateLimitersRegistry
+	budgetId string
+	rule     *common.RateLimitRuleConfig
+	local    RateLimiter
+	key      string
+
+	// Kept apart from the rule so that the limit can be changed while the shared counters are in use
+	maxCount atomic.Uint64
+}
+
+var _ RateLimiter = (*distributedRateLimiter)(nil)
+
+func newDistributedRateLimiter(
+	registry *RateLimitersRegistry,
+	budgetId string,
+	rule *common.RateLimitRuleConfig,
+	local RateLimiter,
+) *distributedRateLimiter {
+	l := &distributedRateLimiter{
+		registry: registry,
+		budgetId: budgetId,
+		rule:     rule,
+		local:    local,
+		// Hash tagged so that counters of all windows land on the same slot in Redis cluster mode
+		key: fmt.Sprintf("{%s:%s:%s}", registry.cfg.Store.KeyPrefix, budgetId, rule.Method),
+	}
+	l.maxCount.Store(uint64(rule.MaxCount))
+	return l
+}
+
+// setMaxCount changes the limit enforced on the shared counters, which keep the permits acquired so far.
+func (l *distributedRateLimiter) setMaxCount(maxCount uint) {
+	l.maxCount.Store(uint64(maxCount))
+}
+
+func (l *distributedRateLimiter) TryAcquirePermit() bool {
+	return l.TryAcquirePermits(1)
+}
+
+func (l *distributedRateLimiter) TryAcquirePermits(permits uint) bool {
+	return l.TryAcquirePermitsContext(context.Background(), permits)
+}
+
+// TryAcquirePermitsContext waits up to the wait time of the rule when the shared budget is exhausted but
+// enough permits are expected to be available by then, unless ctx is done first.
+func (l *distributedRateLimiter) TryAcquirePermitsContext(ctx context.Context, permits uint) bool {
+	store := l.registry.store.Load()
+	if store == nil {
+		return l.local.TryAcquirePermits(permits)
+	}
+
+	deadline := time.Now().Add(l.rule.WaitTime.Duration())
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+		storeCtx, cancel := context.WithTimeout(ctx, l.registry.cfg.Store.Timeout.Duration())
+		wait, err := l.tryAcquireShared(storeCtx, store, int64(permits))
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			l.registry.logger.Debug().Err(err).Str("budget", l.budgetId).Str("method", l.rule.Method).Msg("failed to acquire permit from rate limiter store, falling back to local limiter")
+			telemetry.MetricRateLimiterStoreFallbackTotal.WithLabelValues(l.budgetId, l.rule.Method).Inc()
+			return l.local.TryAcquirePermits(permits)
+		}
+		if wait == 0 {
+			return true
+		}
+		if wait < 0 || time.Now().Add(wait).After(deadline) {
+			return false
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-store.appCtx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// ResetIn is the time until the current window of the shared counter ends (windows are aligned to the
+// epoch so that they are the same on all instances).
+func (l *distributedRateLimiter) ResetIn() time.Duration {
+	if l.registry.store.Load() == nil {
+		return l.local.ResetIn()
+	}
+	period := l.rule.Period.Duration()
+	if period <= 0 {
+		period = time.Second
+	}
+	return period - time.Duration(time.Now().UnixNano()%int64(period))
+}
+
+// tryAcquireShared acquires the permits in a single atomic operation on the store. When they are rejected it
+// returns the estimated time until enough permits are available, or a negative duration if they never will be.
+func (l *distributedRateLimiter) tryAcquireShared(ctx context.Context, store *rateLimitStore, permits int64) (time.Duration, error) {
+	period := l.rule.Period.Duration()
+	if period <= 0 {
+		period = time.Second
+	}
+	now := time.Now().UnixNano()
+	window := now / int64(period)
+	elapsed := now % int64(period)
+	overlap := 1 - float64(elapsed)/float64(period)
+	limit := int64(l.maxCount.Load())
+
+	// Counters must outlive the next window, where they are used as the previous window
+	ttl := 2 * period
+	acquired, previous, current, err := store.connector.AcquireSlidingWindowPermits(
+		ctx,
+		fmt.Sprintf("%s:%d", l.key, window-1),
+		fmt.Sprintf("%s:%d", l.key, window),
+		overlap, permits, limit, ttl,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if acquired {
+		return 0, nil
+	}
+
+	return slidingWindowWait(period, time.Duration(elapsed), previous, current, permits, limit), nil
+}
+
+// slidingWindowWait estimates how long until the weighted count of the sliding window leaves room for the permits,
+// assuming no other permits are acquired meanwhile. It is negative when the permits exceed the limit.
+func slidingWindowWait(period, elapsed time.Duration, previous, current, permits, limit int64) time.Duration {
+	if permits > limit {
+		return -1
+	}
+	var wait time.Duration
+	if current+permits > limit {
+		// Only possible in a later window, where the current count becomes the previous (weighted) one
+		wait = period - elapsed
+		elapsed, previous, current = 0, current, 0
+	}
+	if previous > 0 {
+		// Weight of the previous window at which the permits fit: previous * (1 - e/period) + current + permits <= limit
+		fraction := 1 - float64(limit-current-permits)/float64(previous)
+		if until := time.Duration(math.Ceil(fraction*float64(period))) - elapsed; until > 0 {
+			wait += until
+		}
+	}
+	return max(wait, time.Millisecond)
+}
@@ -1,9 +1,17 @@
 package upstream
 
 import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/erpc/erpc/common"
+	"github.com/erpc/erpc/data"
 	"github.com/erpc/erpc/telemetry"
 	"github.com/failsafe-go/failsafe-go"
 	"github.com/failsafe-go/failsafe-go/ratelimiter"
@@ -14,13 +22,61 @@ type RateLimitersRegistry struct {
 	logger          *zerolog.Logger
 	cfg             *common.RateLimiterConfig
 	budgetsLimiters sync.Map
+	store           atomic.Pointer[rateLimitStore]
+	computeUnits    map[string]uint
+
+	// Wildcard keys of computeUnits ordered from the most specific to the least specific
+	computeUnitPatterns []string
+
+	// Per-second limiters of authenticated users (by id), created on demand and dropped once idle. The list
+	// orders them from the most to the least recently used, so that idle ones are found from its back.
+	userLimitersMu  sync.Mutex
+	userLimiters    map[string]*list.Element
+	userLimitersLRU *list.List
+}
+
+const (
+	userRateLimiterIdleTimeout = time.Minute
+	maxUserRateLimiters        = 100_000
+)
+
+type userRateLimiter struct {
+	userId      string
+	limit       uint
+	limiter     RateLimiter
+	local       *adjustableRateLimiter
+	distributed *distributedRateLimiter
+	lastUsed    time.Time
+}
+
+type rateLimitStore struct {
+	appCtx    context.Context
+	connector data.CounterConnector
 }
 
 func NewRateLimitersRegistry(cfg *common.RateLimiterConfig, logger *zerolog.Logger) (*RateLimitersRegistry, error) {
 	r := &RateLimitersRegistry{
-		cfg:    cfg,
-		logger: logger,
+		cfg:             cfg,
+		logger:          logger,
+		computeUnits:    common.DefaultMethodComputeUnits,
+		userLimiters:    make(map[string]*list.Element),
+		userLimitersLRU: list.New(),
+	}
+	if cfg != nil && len(cfg.ComputeUnits) > 0 {
+		r.computeUnits = cfg.ComputeUnits
+	}
+	for key := range r.computeUnits {
+		if strings.ContainsAny(key, "*?|!&()<>=") {
+			r.computeUnitPatterns = append(r.computeUnitPatterns, key)
+		}
 	}
+	sort.Slice(r.computeUnitPatterns, func(i, j int) bool {
+		a, b := r.computeUnitPatterns[i], r.computeUnitPatterns[j]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
 	err := r.bootstrap()
 	return r, err
 }
@@ -63,7 +119,31 @@ func (r *RateLimitersRegistry) bootstrap() error {
 	return nil
 }
 
-func (r *RateLimitersRegistry) createRateLimiter(budgetId string, rule *common.RateLimitRuleConfig) (ratelimiter.RateLimiter[interface{}], error) {
+// ConnectStore connects to the shared store (if configured) so that budgets are enforced across all
+// instances. Until the store is connected (and whenever it is unreachable) budgets are enforced locally.
+func (r *RateLimitersRegistry) ConnectStore(appCtx context.Context) error {
+	if r.cfg == nil || r.cfg.Store == nil || r.cfg.Store.Connector == nil {
+		return nil
+	}
+
+	connector, err := data.NewConnector(appCtx, r.logger, r.cfg.Store.Connector)
+	if err != nil {
+		return fmt.Errorf("failed to create rate limiter store connector: %w", err)
+	}
+	counter, ok := connector.(data.CounterConnector)
+	if !ok {
+		return fmt.Errorf("rate limiter store connector driver '%s' does not support atomic counters", r.cfg.Store.Connector.Driver)
+	}
+	r.store.Store(&rateLimitStore{
+		appCtx:    appCtx,
+		connector: counter,
+	})
+	r.logger.Info().Str("connector", connector.Id()).Msg("rate limiter budgets will be enforced across all instances sharing the store")
+
+	return nil
+}
+
+func (r *RateLimitersRegistry) createRateLimiter(budgetId string, rule *common.RateLimitRuleConfig) (RateLimiter, error) {
 	duration := rule.Period.Duration()
 	builder := ratelimiter.BurstyBuilder[interface{}](rule.MaxCount, duration)
 	if rule.WaitTime > 0 {
@@ -74,14 +154,183 @@ func (r *RateLimitersRegistry) createRateLimiter(budgetId string, rule *common.R
 		r.logger.Warn().Msgf("rate limit exceeded for rule '%v'", rule)
 	})
 
-	limiter := builder.Build()
+	limiter := newLocalRateLimiter(builder.Build(), duration)
 	r.logger.Debug().Str("budget", budgetId).Str("method", rule.Method).Msgf("rate limiter rule prepared with max: %d per %s", rule.MaxCount, rule.Period)
 
 	telemetry.MetricRateLimiterBudgetMaxCount.WithLabelValues(budgetId, rule.Method).Set(float64(rule.MaxCount))
 
+	if r.cfg != nil && r.cfg.Store != nil {
+		return newDistributedRateLimiter(r, budgetId, rule, limiter), nil
+	}
+
 	return limiter, nil
 }
 
+// UserRateLimiter returns the limiter enforcing the per-second rate limit of an authenticated user (or any other
+// client identity, e.g. an IP address prefixed by what it is limited for), which is shared
+// by all instances when a store is configured. Limiters idle for longer than a minute are dropped (their permits would
+// be replenished by then anyway) and the least recently used one is evicted when too many users are active at once.
+func (r *RateLimitersRegistry) UserRateLimiter(userId string, perSecond uint) RateLimiter {
+	r.userLimitersMu.Lock()
+	defer r.userLimitersMu.Unlock()
+
+	now := time.Now()
+	r.sweepUserRateLimiters(now)
+	if el, ok := r.userLimiters[userId]; ok {
+		url := el.Value.(*userRateLimiter)
+		// The limit of a user might change between tokens or database records, in which case the permits
+		// acquired so far in the current period still count towards the new limit
+		if url.limit != perSecond {
+			url.limit = perSecond
+			url.local.setLimit(perSecond)
+			if url.distributed != nil {
+				url.distributed.setMaxCount(perSecond)
+			}
+		}
+		url.lastUsed = now
+		r.userLimitersLRU.MoveToFront(el)
+		return url.limiter
+	}
+
+	url := &userRateLimiter{
+		userId:   userId,
+		limit:    perSecond,
+		local:    newAdjustableRateLimiter(perSecond, time.Second),
+		lastUsed: now,
+	}
+	url.limiter = url.local
+	if r.cfg != nil && r.cfg.Store != nil {
+		rule := &common.RateLimitRuleConfig{Method: "*", MaxCount: perSecond, Period: common.Duration(time.Second)}
+		url.distributed = newDistributedRateLimiter(r, "user:"+userId, rule, url.local)
+		url.limiter = url.distributed
+	}
+	if len(r.userLimiters) >= maxUserRateLimiters {
+		r.removeUserRateLimiter(r.userLimitersLRU.Back())
+	}
+	r.userLimiters[userId] = r.userLimitersLRU.PushFront(url)
+
+	return url.limiter
+}
+
+// sweepUserRateLimiters must be called while holding userLimitersMu.
+func (r *RateLimitersRegistry) sweepUserRateLimiters(now time.Time) {
+	for el := r.userLimitersLRU.Back(); el != nil; el = r.userLimitersLRU.Back() {
+		if now.Sub(el.Value.(*userRateLimiter).lastUsed) < userRateLimiterIdleTimeout {
+			return
+		}
+		r.removeUserRateLimiter(el)
+	}
+}
+
+// removeUserRateLimiter must be called while holding userLimitersMu.
+func (r *RateLimitersRegistry) removeUserRateLimiter(el *list.Element) {
+	if el == nil {
+		return
+	}
+	r.userLimitersLRU.Remove(el)
+	delete(r.userLimiters, el.Value.(*userRateLimiter).userId)
+}
+
+// adjustableRateLimiter is an in-process bursty limiter like localRateLimiter, whose limit can be changed
+// without losing track of the permits already acquired in the current period.
+type adjustableRateLimiter struct {
+	mu        sync.Mutex
+	limit     uint
+	period    time.Duration
+	startedAt time.Time
+	window    int64
+	acquired  uint
+}
+
+var _ RateLimiter = (*adjustableRateLimiter)(nil)
+
+func newAdjustableRateLimiter(limit uint, period time.Duration) *adjustableRateLimiter {
+	return &adjustableRateLimiter{
+		limit:     limit,
+		period:    period,
+		startedAt: time.Now(),
+	}
+}
+
+func (l *adjustableRateLimiter) setLimit(limit uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+func (l *adjustableRateLimiter) TryAcquirePermit() bool {
+	return l.TryAcquirePermits(1)
+}
+
+func (l *adjustableRateLimiter) TryAcquirePermits(permits uint) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if window := int64(time.Since(l.startedAt) / l.period); window != l.window {
+		l.window, l.acquired = window, 0
+	}
+	if l.acquired+permits > l.limit {
+		return false
+	}
+	l.acquired += permits
+	return true
+}
+
+// TryAcquirePermitsContext does not wait for permits, so ctx is not needed.
+func (l *adjustableRateLimiter) TryAcquirePermitsContext(_ context.Context, permits uint) bool {
+	return l.TryAcquirePermits(permits)
+}
+
+func (l *adjustableRateLimiter) ResetIn() time.Duration {
+	return l.period - time.Since(l.startedAt)%l.period
+}
+
+// localRateLimiter is an in-process bursty limiter, which replenishes all permits at the start of each
+// period. Periods are measured from when the limiter is built, so the start time is kept to tell when
+// permits are replenished next.
+type localRateLimiter struct {
+	ratelimiter.RateLimiter[interface{}]
+	period    time.Duration
+	startedAt time.Time
+}
+
+var _ RateLimiter = (*localRateLimiter)(nil)
+
+func newLocalRateLimiter(limiter ratelimiter.RateLimiter[interface{}], period time.Duration) *localRateLimiter {
+	return &localRateLimiter{
+		RateLimiter: limiter,
+		period:      period,
+		startedAt:   time.Now(),
+	}
+}
+
+// TryAcquirePermitsContext does not wait for permits, so ctx is not needed.
+func (l *localRateLimiter) TryAcquirePermitsContext(_ context.Context, permits uint) bool {
+	return l.TryAcquirePermits(permits)
+}
+
+func (l *localRateLimiter) ResetIn() time.Duration {
+	if l.period <= 0 {
+		return 0
+	}
+	return l.period - time.Since(l.startedAt)%l.period
+}
+
+// ComputeUnits returns the cost of a method, resolved from an exact match or otherwise the most specific
+// (longest) matching wildcard pattern. Methods not matching any pattern cost 1 compute unit.
+func (r *RateLimitersRegistry) ComputeUnits(method string) uint {
+	if cu, ok := r.computeUnits[method]; ok {
+		return cu
+	}
+	for _, pattern := range r.computeUnitPatterns {
+		if match, err := common.WildcardMatch(pattern, method); err == nil && match {
+			return r.computeUnits[pattern]
+		}
+	}
+
+	return 1
+}
+
 func (r *RateLimitersRegistry) GetBudget(budgetId string) (*RateLimiterBudget, error) {
 	if budgetId == "" {
 		return nil, nil
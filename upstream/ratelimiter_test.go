@@ -1,10 +1,13 @@
 package upstream
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/erpc/erpc/common"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -217,3 +220,327 @@ func TestRateLimiter_ExceedCapacity(t *testing.T) {
 	ok := rules[0].Limiter.TryAcquirePermit()
 	require.False(t, ok)
 }
+
+func TestRateLimitersRegistry_SharedStore(t *testing.T) {
+	logger := zerolog.Nop()
+	m, err := miniredis.Run()
+	require.NoError(t, err)
+	defer m.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sharedRule := &common.RateLimitRuleConfig{
+		Method:   "*",
+		MaxCount: 5,
+		Period:   common.Duration(1 * time.Minute),
+	}
+	newRegistry := func(rule *common.RateLimitRuleConfig) *RateLimitersRegistry {
+		cfg := &common.RateLimiterConfig{
+			Store: &common.RateLimitStoreConfig{
+				Connector: &common.ConnectorConfig{
+					Driver: common.DriverRedis,
+					Redis: &common.RedisConnectorConfig{
+						Addr: m.Addr(),
+					},
+				},
+				Timeout: common.Duration(1 * time.Second),
+			},
+			Budgets: []*common.RateLimitBudgetConfig{
+				{
+					Id:    "shared-budget",
+					Rules: []*common.RateLimitRuleConfig{rule},
+				},
+			},
+		}
+		require.NoError(t, cfg.SetDefaults())
+		require.NoError(t, cfg.Validate())
+		registry, err := NewRateLimitersRegistry(cfg, &logger)
+		require.NoError(t, err)
+		require.NoError(t, registry.ConnectStore(ctx))
+		return registry
+	}
+	acquire := func(registry *RateLimitersRegistry) bool {
+		budget, err := registry.GetBudget("shared-budget")
+		require.NoError(t, err)
+		rules, err := budget.GetRulesByMethod("eth_call")
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		return rules[0].Limiter.TryAcquirePermit()
+	}
+
+	first := newRegistry(sharedRule)
+	second := newRegistry(sharedRule)
+
+	t.Run("BudgetIsSharedAcrossInstances", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.True(t, acquire(first))
+		}
+		for i := 0; i < 2; i++ {
+			require.True(t, acquire(second))
+		}
+		require.False(t, acquire(first), "budget must be exhausted by permits of both instances")
+		require.False(t, acquire(second), "budget must be exhausted by permits of both instances")
+	})
+
+	t.Run("NeverExceedsBudgetUnderConcurrentAcquisitions", func(t *testing.T) {
+		rule := &common.RateLimitRuleConfig{
+			Method:   "*",
+			MaxCount: 20,
+			Period:   common.Duration(1 * time.Minute),
+		}
+		m.FlushAll()
+		registries := []*RateLimitersRegistry{newRegistry(rule), newRegistry(rule), newRegistry(rule)}
+
+		var acquired sync.WaitGroup
+		var mu sync.Mutex
+		granted := 0
+		for i := 0; i < 60; i++ {
+			acquired.Add(1)
+			go func(registry *RateLimitersRegistry) {
+				defer acquired.Done()
+				if acquire(registry) {
+					mu.Lock()
+					granted++
+					mu.Unlock()
+				}
+			}(registries[i%len(registries)])
+		}
+		acquired.Wait()
+		require.Equal(t, 20, granted)
+	})
+
+	t.Run("WaitsUpToWaitTimeForPermits", func(t *testing.T) {
+		m.FlushAll()
+		waiting := newRegistry(&common.RateLimitRuleConfig{
+			Method:   "*",
+			MaxCount: 2,
+			Period:   common.Duration(200 * time.Millisecond),
+			WaitTime: common.Duration(2 * time.Second),
+		})
+		require.True(t, acquire(waiting))
+		require.True(t, acquire(waiting))
+		start := time.Now()
+		require.True(t, acquire(waiting), "permit must be acquired once the window slides")
+		require.Greater(t, time.Since(start), time.Duration(0))
+		require.Less(t, time.Since(start), 2*time.Second)
+
+		m.FlushAll()
+		notWaiting := newRegistry(&common.RateLimitRuleConfig{
+			Method:   "*",
+			MaxCount: 2,
+			Period:   common.Duration(1 * time.Minute),
+			WaitTime: common.Duration(50 * time.Millisecond),
+		})
+		require.True(t, acquire(notWaiting))
+		require.True(t, acquire(notWaiting))
+		start = time.Now()
+		require.False(t, acquire(notWaiting), "permits are not expected within the wait time")
+		require.Less(t, time.Since(start), 50*time.Millisecond, "must not wait when permits are not expected in time")
+	})
+
+	t.Run("StopsWaitingForPermitsWhenRequestContextIsDone", func(t *testing.T) {
+		m.FlushAll()
+		registry := newRegistry(&common.RateLimitRuleConfig{
+			Method:   "*",
+			MaxCount: 1,
+			Period:   common.Duration(1 * time.Second),
+			WaitTime: common.Duration(5 * time.Second),
+		})
+		budget, err := registry.GetBudget("shared-budget")
+		require.NoError(t, err)
+		rules, err := budget.GetRulesByMethod("eth_call")
+		require.NoError(t, err)
+		require.True(t, budget.TryAcquirePermit(ctx, rules[0], "eth_call"))
+
+		reqCtx, reqCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer reqCancel()
+		start := time.Now()
+		require.False(t, budget.TryAcquirePermit(reqCtx, rules[0], "eth_call"))
+		require.Less(t, time.Since(start), 500*time.Millisecond, "must stop waiting once the request is done")
+	})
+
+	t.Run("FallsBackToLocalLimiterWhenStoreIsUnreachable", func(t *testing.T) {
+		m.Close()
+		for i := 0; i < 5; i++ {
+			require.True(t, acquire(first), "local limiter has its own budget")
+		}
+		require.False(t, acquire(first))
+	})
+}
+
+func TestRateLimitersRegistry_UserRateLimiter(t *testing.T) {
+	logger := zerolog.Nop()
+	registry, err := NewRateLimitersRegistry(nil, &logger)
+	require.NoError(t, err)
+
+	t.Run("EnforcesLimitPerUser", func(t *testing.T) {
+		require.True(t, registry.UserRateLimiter("user-1", 2).TryAcquirePermit())
+		require.True(t, registry.UserRateLimiter("user-1", 2).TryAcquirePermit())
+		require.False(t, registry.UserRateLimiter("user-1", 2).TryAcquirePermit())
+		require.True(t, registry.UserRateLimiter("user-2", 2).TryAcquirePermit(), "users must not share permits")
+
+		// A new limit applies to the permits already acquired by the user in the current period
+		require.True(t, registry.UserRateLimiter("user-1", 3).TryAcquirePermit())
+		require.False(t, registry.UserRateLimiter("user-1", 3).TryAcquirePermit())
+		require.False(t, registry.UserRateLimiter("user-1", 1).TryAcquirePermit())
+	})
+
+	t.Run("DropsIdleLimiters", func(t *testing.T) {
+		registry.userLimitersMu.Lock()
+		for _, el := range registry.userLimiters {
+			el.Value.(*userRateLimiter).lastUsed = time.Now().Add(-2 * userRateLimiterIdleTimeout)
+		}
+		registry.userLimitersMu.Unlock()
+
+		registry.UserRateLimiter("user-3", 1)
+
+		registry.userLimitersMu.Lock()
+		defer registry.userLimitersMu.Unlock()
+		assert.Len(t, registry.userLimiters, 1)
+		assert.Contains(t, registry.userLimiters, "user-3")
+	})
+
+	t.Run("EvictsLeastRecentlyUsedLimiter", func(t *testing.T) {
+		registry, err := NewRateLimitersRegistry(nil, &logger)
+		require.NoError(t, err)
+
+		for i := 0; i < maxUserRateLimiters; i++ {
+			registry.UserRateLimiter(fmt.Sprintf("user-%d", i), 1)
+		}
+		// Using the oldest limiter again makes the second oldest the least recently used one
+		registry.UserRateLimiter("user-0", 1)
+		registry.UserRateLimiter("new-user", 1)
+
+		registry.userLimitersMu.Lock()
+		defer registry.userLimitersMu.Unlock()
+		assert.Len(t, registry.userLimiters, maxUserRateLimiters)
+		assert.Contains(t, registry.userLimiters, "user-0")
+		assert.Contains(t, registry.userLimiters, "new-user")
+		assert.NotContains(t, registry.userLimiters, "user-1")
+	})
+}
+
+func TestSlidingWindowWait(t *testing.T) {
+	period := time.Second
+	// previous * (1 - 0.5) + 5 + 1 <= 10 once half of the period has elapsed
+	assert.Equal(t, 250*time.Millisecond, slidingWindowWait(period, 250*time.Millisecond, 8, 5, 1, 10))
+	// Current window is exhausted: wait for the next window and then for the previous count to decay
+	assert.Equal(t, 750*time.Millisecond+100*time.Millisecond, slidingWindowWait(period, 250*time.Millisecond, 0, 10, 1, 10))
+	// More permits than the limit never fit
+	assert.Less(t, slidingWindowWait(period, 0, 0, 0, 11, 10), time.Duration(0))
+}
+
+func TestRateLimitersRegistry_ComputeUnits(t *testing.T) {
+	logger := zerolog.Nop()
+
+	t.Run("DefaultsWithoutConfig", func(t *testing.T) {
+		registry, err := NewRateLimitersRegistry(nil, &logger)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint(1), registry.ComputeUnits("eth_chainId"))
+		assert.Equal(t, uint(300), registry.ComputeUnits("debug_traceBlockByNumber"))
+		assert.Equal(t, uint(100), registry.ComputeUnits("debug_getRawReceipts"), "must fall back to the wildcard pattern")
+		assert.Equal(t, uint(1), registry.ComputeUnits("custom_method"), "unknown methods must cost 1")
+	})
+
+	t.Run("UserDefinedValuesOverrideDefaults", func(t *testing.T) {
+		cfg := &common.RateLimiterConfig{
+			ComputeUnits: map[string]uint{
+				"eth_chainId":    3,
+				"custom_*":       40,
+				"custom_heavy_*": 80,
+			},
+		}
+		require.NoError(t, cfg.SetDefaults())
+		require.NoError(t, cfg.Validate())
+		registry, err := NewRateLimitersRegistry(cfg, &logger)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint(3), registry.ComputeUnits("eth_chainId"))
+		assert.Equal(t, uint(20), registry.ComputeUnits("eth_getLogs"), "defaults must be preserved")
+		assert.Equal(t, uint(40), registry.ComputeUnits("custom_light"))
+		assert.Equal(t, uint(80), registry.ComputeUnits("custom_heavy_trace"), "most specific pattern must win")
+	})
+}
+
+func TestRateLimiterBudget_ComputeUnitRules(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := &common.RateLimiterConfig{
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "cu-budget",
+				Rules: []*common.RateLimitRuleConfig{
+					{
+						Method:   "*",
+						MaxCount: 400,
+						Period:   common.Duration(1 * time.Minute),
+						Unit:     common.RateLimitUnitComputeUnit,
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+
+	registry, err := NewRateLimitersRegistry(cfg, &logger)
+	require.NoError(t, err)
+	budget, err := registry.GetBudget("cu-budget")
+	require.NoError(t, err)
+	acquire := func(method string) bool {
+		rules, err := budget.GetRulesByMethod(method)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		return budget.TryAcquirePermit(context.Background(), rules[0], method)
+	}
+
+	require.True(t, acquire("debug_traceBlockByNumber"), "300 out of 400 compute units")
+	require.False(t, acquire("debug_traceBlockByNumber"), "another trace exceeds the budget")
+	for i := 0; i < 100; i++ {
+		require.True(t, acquire("eth_chainId"), "cheap calls must use up the remaining compute units")
+	}
+	require.False(t, acquire("eth_chainId"))
+}
+
+func TestRateLimiterBudget_RuleInfo(t *testing.T) {
+	logger := zerolog.Nop()
+	cfg := &common.RateLimiterConfig{
+		Budgets: []*common.RateLimitBudgetConfig{
+			{
+				Id: "info-budget",
+				Rules: []*common.RateLimitRuleConfig{
+					{
+						Method:   "eth_getLogs",
+						MaxCount: 1,
+						Period:   common.Duration(1 * time.Second),
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.SetDefaults())
+	require.NoError(t, cfg.Validate())
+
+	registry, err := NewRateLimitersRegistry(cfg, &logger)
+	require.NoError(t, err)
+	budget, err := registry.GetBudget("info-budget")
+	require.NoError(t, err)
+	rules, err := budget.GetRulesByMethod("eth_getLogs")
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	require.True(t, budget.TryAcquirePermit(context.Background(), rules[0], "eth_getLogs"))
+	require.False(t, budget.TryAcquirePermit(context.Background(), rules[0], "eth_getLogs"))
+
+	info := budget.RuleInfo(rules[0])
+	require.Equal(t, "info-budget", info.Budget)
+	require.Equal(t, "eth_getLogs", info.Method)
+	require.Equal(t, uint(1), info.MaxCount)
+	require.Equal(t, common.RateLimitUnitRequest, info.Unit)
+	require.Greater(t, info.ResetIn.Duration(), time.Duration(0))
+	require.LessOrEqual(t, info.ResetIn.Duration(), time.Second)
+
+	// Permits are replenished once the reported time has passed
+	time.Sleep(info.ResetIn.Duration() + 10*time.Millisecond)
+	require.True(t, budget.TryAcquirePermit(context.Background(), rules[0], "eth_getLogs"))
+}
@@ -373,10 +373,32 @@ func (u *UpstreamsRegistry) GetSortedUpstreams(ctx context.Context, networkId, m
 		}
 		u.upstreamsMu.Unlock()
 
-		return castToCommonUpstreams(methodUpsList), nil
+		return castToCommonUpstreams(skipUpstreamsInCooldown(methodUpsList, method)), nil
 	}
 
-	return castToCommonUpstreams(upsList), nil
+	return castToCommonUpstreams(skipUpstreamsInCooldown(upsList, method)), nil
+}
+
+// skipUpstreamsInCooldown excludes upstreams that asked clients to back off for this method. When all of
+// them are in cooldown the list is returned as is, so that requests are still served on a best-effort basis.
+func skipUpstreamsInCooldown(upstreams []*Upstream, method string) []*Upstream {
+	var active []*Upstream
+	for i, ups := range upstreams {
+		if !ups.InCooldown(method) {
+			if active != nil {
+				active = append(active, ups)
+			}
+			continue
+		}
+		if active == nil {
+			active = make([]*Upstream, i, len(upstreams))
+			copy(active, upstreams[:i])
+		}
+	}
+	if len(active) == 0 {
+		return upstreams
+	}
+	return active
 }
 
 func (u *UpstreamsRegistry) RLockUpstreams() {
@@ -1168,6 +1190,10 @@ func (u *UpstreamsRegistry) GetMetricsTracker() *health.Tracker {
 	return u.metricsTracker
 }
 
+func (u *UpstreamsRegistry) GetSharedStateRegistry() data.SharedStateRegistry {
+	return u.sharedStateRegistry
+}
+
 func castToCommonUpstreams(upstreams []*Upstream) []common.Upstream {
 	commonUpstreams := make([]common.Upstream, len(upstreams))
 	for i, ups := range upstreams {
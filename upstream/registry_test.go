@@ -3,6 +3,7 @@ package upstream
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func init() {
@@ -854,6 +856,66 @@ func TestUpstreamsRegistry_ZeroLatencyHandling(t *testing.T) {
 	assert.Less(t, workingRank, failingRank, "Working upstream should be ranked higher (lower index) than failing upstream")
 }
 
+func TestUpstreamsRegistry_RateLimitCooldown(t *testing.T) {
+	util.ResetGock()
+	defer util.ResetGock()
+	util.SetupMocksForEvmStatePoller()
+
+	networkID := "evm:123"
+	ctx := context.Background()
+	logger := log.Logger
+	registry, _ := createTestRegistry(ctx, "test-project", &logger, 10*time.Second)
+
+	upstreams := map[string]*Upstream{}
+	for _, ups := range registry.GetAllUpstreams() {
+		ups.Config().RateLimitCooldown = &common.RateLimitCooldownConfig{}
+		require.NoError(t, ups.Config().RateLimitCooldown.SetDefaults())
+		upstreams[ups.Id()] = ups
+	}
+	rateLimited := func(retryAfter time.Duration) error {
+		return common.NewErrEndpointCapacityExceeded(nil).(*common.ErrEndpointCapacityExceeded).WithRetryAfter(retryAfter)
+	}
+	sortedIds := func(method string) []string {
+		sorted, err := registry.GetSortedUpstreams(ctx, networkID, method)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(sorted))
+		for _, ups := range sorted {
+			ids = append(ids, ups.Id())
+		}
+		return ids
+	}
+
+	t.Run("SkipsOnlyTheRateLimitedMethodUntilWindowEnds", func(t *testing.T) {
+		upstreams["rpc1"].startCooldown("eth_call", rateLimited(300*time.Millisecond))
+
+		assert.NotContains(t, sortedIds("eth_call"), "rpc1")
+		assert.Contains(t, sortedIds("eth_getBalance"), "rpc1")
+		assert.Eventually(t, func() bool {
+			return slices.Contains(sortedIds("eth_call"), "rpc1")
+		}, 2*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("SkipsAllMethodsWithUpstreamScope", func(t *testing.T) {
+		upstreams["rpc2"].Config().RateLimitCooldown.Scope = common.RateLimitCooldownScopeUpstream
+		upstreams["rpc2"].startCooldown("eth_call", rateLimited(10*time.Second))
+
+		assert.NotContains(t, sortedIds("eth_call"), "rpc2")
+		assert.NotContains(t, sortedIds("eth_getBalance"), "rpc2")
+	})
+
+	t.Run("IgnoresRateLimitsWithoutHintByDefault", func(t *testing.T) {
+		upstreams["rpc3"].startCooldown("eth_getLogs", rateLimited(0))
+		assert.Contains(t, sortedIds("eth_getLogs"), "rpc3")
+	})
+
+	t.Run("ReturnsAllUpstreamsWhenAllAreInCooldown", func(t *testing.T) {
+		for _, ups := range upstreams {
+			ups.startCooldown("eth_getTransactionByHash", rateLimited(10*time.Second))
+		}
+		assert.Len(t, sortedIds("eth_getTransactionByHash"), 3)
+	})
+}
+
 func createTestRegistry(ctx context.Context, projectID string, logger *zerolog.Logger, windowSize time.Duration) (*UpstreamsRegistry, *health.Tracker) {
 	metricsTracker := health.NewTracker(logger, projectID, windowSize)
 	metricsTracker.Bootstrap(ctx)
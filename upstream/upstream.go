@@ -55,7 +55,11 @@ type Upstream struct {
 	failsafeExecutors    []*FailsafeExecutor
 	rateLimitersRegistry *RateLimitersRegistry
 	rateLimiterAutoTuner *RateLimitAutoTuner
+	concurrencyLimiter   *ConcurrencyLimiter
 	evmStatePoller       common.EvmStatePoller
+
+	// cooldowns keeps when backoff windows requested by the remote endpoint end, by method ("*" for all)
+	cooldowns sync.Map // map[string]time.Time
 }
 
 func NewUpstream(
@@ -125,6 +129,7 @@ func NewUpstream(
 	pup.networkLabel.Store("n/a")
 
 	pup.initRateLimitAutoTuner()
+	pup.initConcurrencyLimiter()
 
 	if vn != nil {
 		cfgs, err := vn.GenerateConfigs(appCtx, &lg, cfg, nil)
@@ -392,7 +397,7 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 		}
 		if len(rules) > 0 {
 			for _, rule := range rules {
-				if !rule.Limiter.TryAcquirePermit() {
+				if !limitersBudget.TryAcquirePermit(ctx, rule, method) {
 					lg.Debug().Str("budget", cfg.RateLimitBudget).Msgf("upstream-level rate limit '%v' exceeded", rule.Config)
 					u.metricsTracker.RecordUpstreamSelfRateLimited(
 						u,
@@ -422,11 +427,17 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 	// Send the request based on client type
 	//
 	switch clientType {
-	case clients.ClientTypeHttpJsonRpc, clients.ClientTypeGrpcBds:
+	case clients.ClientTypeHttpJsonRpc, clients.ClientTypeWsJsonRpc, clients.ClientTypeGrpcBds:
 		tryForward := func(
 			ctx context.Context,
 			exec failsafe.Execution[*common.NormalizedResponse],
 		) (*common.NormalizedResponse, error) {
+			// Requests beyond the in-flight limit are not queued, so that they overflow to the next upstream
+			if u.concurrencyLimiter != nil && !u.concurrencyLimiter.TryAcquire() {
+				lg.Debug().Int64("limit", u.concurrencyLimiter.Limit()).Msgf("upstream-level concurrency limit exceeded")
+				return nil, common.NewErrUpstreamConcurrencyLimitExceeded(cfg.Id, u.concurrencyLimiter.Limit())
+			}
+
 			u.metricsTracker.RecordUpstreamRequest(
 				u,
 				method,
@@ -444,9 +455,24 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 				nrq.UserId(),
 				nrq.AgentName(),
 			).Inc()
+			if u.rateLimitersRegistry != nil {
+				telemetry.MetricUpstreamComputeUnitsTotal.WithLabelValues(
+					u.ProjectId,
+					u.VendorName(),
+					u.NetworkLabel(),
+					cfg.Id,
+					method,
+					finality.String(),
+					nrq.UserId(),
+					nrq.AgentName(),
+				).Add(float64(u.rateLimitersRegistry.ComputeUnits(method)))
+			}
 			timer := u.metricsTracker.RecordUpstreamDurationStart(u, method, nrq.CompositeType(), finality, nrq.UserId())
 
 			nrs, errCall := u.Client.SendRequest(ctx, nrq)
+			if u.concurrencyLimiter != nil {
+				u.concurrencyLimiter.Release(errCall)
+			}
 			isSuccess := false
 			if errCall == nil && nrs != nil {
 				nrs.SetUpstream(u)
@@ -501,7 +527,7 @@ func (u *Upstream) Forward(ctx context.Context, nrq *common.NormalizedRequest, b
 					}
 
 					if common.HasErrorCode(errCall, common.ErrCodeEndpointCapacityExceeded) {
-						u.recordRemoteRateLimit(method, nrq)
+						u.recordRemoteRateLimit(method, nrq, errCall)
 					}
 
 					// Only record metrics if error is not ignored
@@ -955,13 +981,20 @@ func (u *Upstream) initRateLimitAutoTuner() {
 	}
 }
 
+func (u *Upstream) initConcurrencyLimiter() {
+	cfg := u.config.ConcurrencyLimit
+	if cfg != nil && cfg.Enabled != nil && *cfg.Enabled {
+		u.concurrencyLimiter = NewConcurrencyLimiter(u.logger, u, u.metricsTracker, cfg)
+	}
+}
+
 func (u *Upstream) recordRequestSuccess(method string) {
 	if u.rateLimiterAutoTuner != nil {
 		u.rateLimiterAutoTuner.RecordSuccess(method)
 	}
 }
 
-func (u *Upstream) recordRemoteRateLimit(method string, nrq *common.NormalizedRequest) {
+func (u *Upstream) recordRemoteRateLimit(method string, nrq *common.NormalizedRequest, err error) {
 	u.metricsTracker.RecordUpstreamRemoteRateLimited(
 		u,
 		method,
@@ -971,6 +1004,68 @@ func (u *Upstream) recordRemoteRateLimit(method string, nrq *common.NormalizedRe
 	if u.rateLimiterAutoTuner != nil {
 		u.rateLimiterAutoTuner.RecordError(method)
 	}
+
+	u.startCooldown(method, err)
+}
+
+// startCooldown excludes the upstream (or only the method) from routing for the backoff window the remote
+// endpoint asked for, falling back to the configured default duration when no hint was provided.
+func (u *Upstream) startCooldown(method string, err error) {
+	cfg := u.Config().RateLimitCooldown
+	if cfg == nil || cfg.Enabled == nil || !*cfg.Enabled {
+		return
+	}
+
+	d := cfg.DefaultDuration.Duration()
+	var ce *common.ErrEndpointCapacityExceeded
+	if errors.As(err, &ce) && ce.RetryAfter() > 0 {
+		d = ce.RetryAfter()
+	}
+	if maxDuration := cfg.MaxDuration.Duration(); maxDuration > 0 && d > maxDuration {
+		d = maxDuration
+	}
+	if d <= 0 {
+		return
+	}
+
+	key := method
+	if cfg.Scope == common.RateLimitCooldownScopeUpstream {
+		key = "*"
+	}
+
+	now := time.Now()
+	until := now.Add(d)
+	for {
+		prev, loaded := u.cooldowns.LoadOrStore(key, until)
+		from := now
+		if loaded {
+			prevUntil := prev.(time.Time)
+			if !until.After(prevUntil) {
+				return
+			}
+			if !u.cooldowns.CompareAndSwap(key, prev, until) {
+				continue
+			}
+			if prevUntil.After(now) {
+				from = prevUntil
+			}
+		}
+		u.logger.Debug().Str("method", key).Dur("duration", d).Msg("upstream is in cooldown due to remote rate limit")
+		u.metricsTracker.RecordUpstreamCooldown(u, key, until.Sub(from))
+		return
+	}
+}
+
+// InCooldown tells whether the upstream must be skipped for the method because the remote endpoint asked
+// clients to back off (e.g. via Retry-After) and the window has not ended yet.
+func (u *Upstream) InCooldown(method string) bool {
+	now := time.Now()
+	for _, key := range []string{"*", method} {
+		if until, ok := u.cooldowns.Load(key); ok && now.Before(until.(time.Time)) {
+			return true
+		}
+	}
+	return false
 }
 
 func (u *Upstream) ShouldHandleMethod(method string) (v bool, err error) {
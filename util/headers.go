@@ -1,7 +1,9 @@
 package util
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"net/http"
 )
@@ -34,3 +36,46 @@ func ExtractUsefulHeaders(r *http.Response) map[string]interface{} {
 
 	return result
 }
+
+// ExtractBackoffHint returns how long a remote endpoint asked clients to back off for, based on the standard
+// Retry-After header (delta-seconds or HTTP-date) or the rate limit reset headers some vendors send instead.
+// Reset headers carry either delta-seconds or a unix timestamp (in seconds) depending on the vendor.
+func ExtractBackoffHint(r *http.Response) time.Duration {
+	if r == nil {
+		return 0
+	}
+	now := time.Now()
+
+	if v := strings.TrimSpace(r.Header.Get("Retry-After")); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			if secs > 0 {
+				return time.Duration(secs * float64(time.Second))
+			}
+		} else if at, err := http.ParseTime(v); err == nil {
+			if d := at.Sub(now); d > 0 {
+				return d
+			}
+		}
+	}
+
+	for _, h := range []string{"RateLimit-Reset", "X-RateLimit-Reset", "X-Rate-Limit-Reset"} {
+		v := strings.TrimSpace(r.Header.Get(h))
+		if v == "" {
+			continue
+		}
+		secs, err := strconv.ParseFloat(v, 64)
+		if err != nil || secs <= 0 {
+			continue
+		}
+		// Anything later than year 2001 cannot be a reasonable delta, so it must be a timestamp
+		if secs > 1e9 {
+			if d := time.Unix(0, int64(secs*float64(time.Second))).Sub(now); d > 0 {
+				return d
+			}
+			continue
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+
+	return 0
+}